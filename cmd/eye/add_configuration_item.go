@@ -27,10 +27,9 @@ OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
 package main
 
 import (
-	"database/sql"
 	"encoding/json"
+	"fmt"
 	"net/http"
-	"strconv"
 
 	"github.com/mjolnir42/soma/lib/proto"
 
@@ -69,53 +68,60 @@ func AddConfigurationItem(w http.ResponseWriter, r *http.Request, _ httprouter.P
 		return
 	}
 
-	if err = addItem(item, lookupID); err != nil {
+	if err = addItem(item, lookupID, details); err != nil {
 		dispatchInternalServerError(&w, err.Error())
 		return
 	}
 	dispatchNoContent(&w)
 }
 
-func addItem(item *ConfigurationItem, lookupID string) error {
+// addItem adds item under lookupID. details is the deployment the
+// item was created from and recorded in the item history.
+func addItem(item *ConfigurationItem, lookupID string, details *proto.Deployment) error {
 	var (
-		hostid int64
 		err    error
-		look   string
+		exists bool
 		jsonb  []byte
 	)
 
-	// string was generated from uint64, we need int now
-	if hostid, err = strconv.ParseInt(item.HostID, 10, 64); err != nil {
-		return err
-	}
 	if jsonb, err = json.Marshal(item); err != nil {
 		return err
 	}
 
-lookupcheck:
-	err = Eye.run.checkLookup.QueryRow(lookupID).Scan(&look)
-	if err == sql.ErrNoRows {
-		if _, err = Eye.run.insertLookup.Exec(
+	if exists, err = Eye.run.store.LookupExists(lookupID); err != nil {
+		return err
+	}
+	if !exists {
+		if err = Eye.run.store.InsertLookup(
 			lookupID,
-			int(hostid),
+			item.HostID,
 			item.Metric,
 		); err != nil {
 			return err
 		}
-		goto lookupcheck
-	} else if err != nil {
-		return err
-	}
-	if lookupID != look {
-		panic(`Database corrupted`)
+		// the insert is skipped if the host and metric are already
+		// registered under a different lookupID
+		if exists, err = Eye.run.store.LookupExists(lookupID); err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("Lookup %s collides with existing lookup for host %s, metric %s",
+				lookupID, item.HostID, item.Metric)
+		}
 	}
 
-	_, err = Eye.run.insertItem.Exec(
+	if err = Eye.run.store.InsertItem(
 		item.ConfigurationItemID.String(),
 		lookupID,
 		jsonb,
-	)
-	return err
+	); err != nil {
+		return err
+	}
+	if err = recordHistory(item.ConfigurationItemID.String(), lookupID,
+		historyAdded, details, nil, jsonb); err != nil {
+		return err
+	}
+	return touchLookup(lookupID)
 }
 
 // vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -27,7 +27,6 @@ OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
 package main
 
 import (
-	"database/sql"
 	"fmt"
 
 	"github.com/mjolnir42/soma/lib/proto"
@@ -35,9 +34,10 @@ import (
 
 func CheckUpdateOrInsertOrDelete(details *proto.Deployment) error {
 	var (
-		err              error
-		itemID, lookupID string
-		item             *ConfigurationItem
+		err      error
+		exists   bool
+		lookupID string
+		item     *ConfigurationItem
 	)
 
 	if lookupID, item, err = Itemize(details); err != nil {
@@ -47,31 +47,26 @@ func CheckUpdateOrInsertOrDelete(details *proto.Deployment) error {
 	fmt.Println(lookupID)
 	fmt.Println(item)
 
-	err = Eye.run.checkItem.QueryRow(item.ConfigurationItemID).Scan(&itemID)
+	if exists, err = Eye.run.store.ItemExists(item.ConfigurationItemID.String()); err != nil {
+		return err
+	}
 	switch details.Task {
 	case "rollout":
-		if err == sql.ErrNoRows {
-			return addItem(item, lookupID)
-		} else if err != nil {
-			return err
+		if !exists {
+			return addItem(item, lookupID, details)
 		}
 	case "deprovision":
-		if err == sql.ErrNoRows {
+		if !exists {
 			// nothing to do
 			return nil
-		} else if err != nil {
-			return err
 		}
 	}
 
-	if item.ConfigurationItemID.String() != itemID {
-		panic(`Database corrupted`)
-	}
 	switch details.Task {
 	case "rollout":
-		return updateItem(item, lookupID)
+		return updateItem(item, lookupID, details)
 	case "deprovision":
-		return deleteItem(itemID)
+		return deleteItem(item.ConfigurationItemID.String(), details)
 	default:
 		return fmt.Errorf(`Unknown Task requested`)
 	}
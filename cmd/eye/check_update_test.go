@@ -0,0 +1,166 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mjolnir42/soma/lib/proto"
+)
+
+func TestCheckUpdateOrInsertOrDelete(t *testing.T) {
+	first := `0b7c8f2e-3d4a-4b5c-9e6f-7a8b9c0d1e2f`
+	second := `1c8d9e3f-4e5b-4c6d-8f7a-8b9c0d1e2f3a`
+
+	type step struct {
+		instanceID string
+		task       string
+		mutate     func(*proto.Deployment)
+		wantErr    bool
+	}
+	tests := []struct {
+		name    string
+		seed    func(*memoryStore)
+		steps   []step
+		items   int
+		lookups int
+		history []string
+	}{
+		{
+			name: `rollout`,
+			steps: []step{
+				{instanceID: first, task: `rollout`},
+			},
+			items:   1,
+			lookups: 1,
+			history: []string{historyAdded},
+		},
+		{
+			name: `rollout of changed thresholds`,
+			steps: []step{
+				{instanceID: first, task: `rollout`},
+				{instanceID: first, task: `rollout`, mutate: func(d *proto.Deployment) {
+					d.CheckConfig.Thresholds[0].Value = 95
+				}},
+			},
+			items:   1,
+			lookups: 1,
+			history: []string{historyAdded, historyUpdated},
+		},
+		{
+			name: `deprovision`,
+			steps: []step{
+				{instanceID: first, task: `rollout`},
+				{instanceID: first, task: `deprovision`},
+			},
+			items:   0,
+			lookups: 0,
+			history: []string{historyAdded, historyDeleted},
+		},
+		{
+			name: `deprovision of unknown item`,
+			steps: []step{
+				{instanceID: first, task: `deprovision`},
+			},
+			items:   0,
+			lookups: 0,
+			history: []string{},
+		},
+		{
+			name: `two instances sharing a lookup`,
+			steps: []step{
+				{instanceID: first, task: `rollout`},
+				{instanceID: second, task: `rollout`},
+				{instanceID: first, task: `deprovision`},
+			},
+			items:   1,
+			lookups: 1,
+			history: []string{historyAdded, historyAdded, historyDeleted},
+		},
+		{
+			name: `unknown task`,
+			steps: []step{
+				{instanceID: first, task: `rollback`, wantErr: true},
+			},
+			items:   0,
+			lookups: 0,
+			history: []string{},
+		},
+		{
+			name: `validation failure`,
+			steps: []step{
+				{instanceID: first, task: `rollout`, wantErr: true, mutate: func(d *proto.Deployment) {
+					d.Monitoring.Name = ``
+				}},
+			},
+			items:   0,
+			lookups: 0,
+			history: []string{},
+		},
+		{
+			name: `lookup id collision`,
+			seed: func(s *memoryStore) {
+				// host and metric registered under a lookup ID that
+				// was calculated differently
+				s.InsertLookup(CalculateLookupID(`legacy`, `cpu.usage.percent`),
+					`42`, `cpu.usage.percent`)
+			},
+			steps: []step{
+				{instanceID: first, task: `rollout`, wantErr: true},
+			},
+			items:   0,
+			lookups: 1,
+			history: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		soma, store := newFakeSoma()
+		if tt.seed != nil {
+			tt.seed(store)
+		}
+
+		for i, s := range tt.steps {
+			d := testDeployment(s.instanceID, s.task)
+			if s.mutate != nil {
+				s.mutate(&d)
+			}
+			err := CheckUpdateOrInsertOrDelete(&d)
+			switch {
+			case s.wantErr && err == nil:
+				t.Errorf("%s: step %d: expected error", tt.name, i)
+			case !s.wantErr && err != nil:
+				t.Errorf("%s: step %d: unexpected error: %s",
+					tt.name, i, err.Error())
+			}
+		}
+
+		if len(store.items) != tt.items {
+			t.Errorf("%s: %d items, expected %d", tt.name,
+				len(store.items), tt.items)
+		}
+		if len(store.lookups) != tt.lookups {
+			t.Errorf("%s: %d lookups, expected %d", tt.name,
+				len(store.lookups), tt.lookups)
+		}
+		history := []string{}
+		for _, h := range store.history {
+			history = append(history, h.Change)
+		}
+		if !reflect.DeepEqual(history, tt.history) {
+			t.Errorf("%s: history %v, expected %v", tt.name,
+				history, tt.history)
+		}
+		soma.close()
+	}
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -25,9 +25,21 @@ type EyeConfig struct {
 	Daemon      EyeDaemon  `json:"daemon" valid:"required"`
 	Database    DbConfig   `json:"database" valid:"required"`
 	Soma        SomaConfig `json:"soma" valid:"required"`
-	run         EyeRuntime
+	Rules       string     `json:"itemization.rules" valid:"optional"`
+	Resync      uint64     `json:"resync.interval.minutes,string" valid:"-"`
+	// LookupScheme selects the host identifier used for lookups.
+	// LookupSchemeAsset keeps the asset ID of nodes for compatibility
+	// and is the default.
+	LookupScheme string `json:"lookup.scheme" valid:"optional,matches(^asset$|^uuid$)"`
+	run          EyeRuntime
 }
 
+// Lookup schemes
+const (
+	LookupSchemeAsset = `asset`
+	LookupSchemeUUID  = `uuid`
+)
+
 type DbConfig struct {
 	Host    string `json:"host" valid:"dns"`
 	User    string `json:"user" valid:"alphanum"`
@@ -41,6 +53,10 @@ type DbConfig struct {
 type SomaConfig struct {
 	url     *url.URL
 	Address string `json:"address" valid:"requrl"`
+	Secret  string `json:"secret" valid:"optional"`
+	// MonitoringID is the SOMA monitoring system served by this
+	// eye instance, required for resynchronisation
+	MonitoringID string `json:"monitoring.id" valid:"optional,uuid"`
 }
 
 type EyeDaemon struct {
@@ -53,19 +69,10 @@ type EyeDaemon struct {
 }
 
 type EyeRuntime struct {
-	conn         *sql.DB
-	checkItem    *sql.Stmt
-	updateItem   *sql.Stmt
-	checkLookup  *sql.Stmt
-	insertLookup *sql.Stmt
-	insertItem   *sql.Stmt
-	deleteItem   *sql.Stmt
-	deleteLookup *sql.Stmt
-	getLookup    *sql.Stmt
-	itemCount    *sql.Stmt
-	getConfig    *sql.Stmt
-	getItems     *sql.Stmt
-	retrieve     *sql.Stmt
+	conn     *sql.DB
+	store    Store
+	feedback chan struct{}
+	rules    *ItemizationRules
 }
 
 func (c *EyeConfig) readConfigFile(fname string) error {
@@ -95,6 +102,9 @@ func (c *EyeConfig) readConfigFile(fname string) error {
 	if ok, err := govalidator.ValidateStruct(c); !ok {
 		return err
 	}
+	if c.run.rules, err = loadItemizationRules(c.Rules); err != nil {
+		return err
+	}
 	c.Soma.url, _ = url.Parse(c.Soma.Address)
 	log.Printf("Configured SOMA base address: %s\n", c.Soma.url.String())
 	return nil
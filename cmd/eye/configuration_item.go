@@ -6,12 +6,46 @@
 
 package main
 
-import "github.com/satori/go.uuid"
+import (
+	"github.com/mjolnir42/soma/lib/threshold"
+	"github.com/satori/go.uuid"
+)
 
 type ConfigurationData struct {
 	Configurations []ConfigurationItem `json:"configurations"`
 }
 
+// BulkConfigurationData is the reply of a bulk lookup, with the
+// configurations grouped by lookup
+type BulkConfigurationData struct {
+	Lookups []LookupConfiguration `json:"lookups"`
+}
+
+// LookupConfiguration contains the configurations of a single lookup
+// and the revision they were last changed in
+type LookupConfiguration struct {
+	LookupID       string              `json:"lookup_id"`
+	Revision       uint64              `json:"revision"`
+	ETag           string              `json:"etag"`
+	Deleted        bool                `json:"deleted,omitempty"`
+	Configurations []ConfigurationItem `json:"configurations"`
+}
+
+// ConfigurationChanges is the reply of the changes feed. Revision is
+// the revision to request the following changes from.
+type ConfigurationChanges struct {
+	Revision uint64                `json:"revision"`
+	More     bool                  `json:"more"`
+	Changes  []LookupConfiguration `json:"changes"`
+}
+
+// BulkRequest selects the configurations of a bulk lookup, either by
+// lookup IDs or by host ID
+type BulkRequest struct {
+	LookupIDs []string `json:"lookup_ids,omitempty"`
+	HostID    string   `json:"host_id,omitempty"`
+}
+
 type ConfigurationList struct {
 	ConfigurationItemIDList []string `json:"configuration_item_id_list"`
 }
@@ -19,7 +53,8 @@ type ConfigurationList struct {
 type ConfigurationItem struct {
 	ConfigurationItemID uuid.UUID                `json:"configuration_item_id" valid:"-"`
 	Metric              string                   `json:"metric" valid:"printableascii"`
-	HostID              string                   `json:"host_id" valid:"numeric"`
+	HostID              string                   `json:"host_id" valid:"printableascii"`
+	ObjectType          string                   `json:"object_type,omitempty" valid:"-"`
 	Tags                []string                 `json:"tags,omitempty" valid:"-"`
 	Oncall              string                   `json:"oncall" valid:"-"`
 	Interval            uint64                   `json:"interval" valid:"-"`
@@ -40,4 +75,17 @@ type ConfigurationThreshold struct {
 	Value     int64  `json:"value" valid:"-"`
 }
 
+// thresholds returns the thresholds of the item for evaluation
+func (c *ConfigurationItem) thresholds() []threshold.Threshold {
+	t := make([]threshold.Threshold, 0, len(c.Thresholds))
+	for _, thr := range c.Thresholds {
+		t = append(t, threshold.Threshold{
+			Predicate: thr.Predicate,
+			Level:     thr.Level,
+			Value:     thr.Value,
+		})
+	}
+	return t
+}
+
 // vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -62,56 +62,129 @@ func pingDatabase() {
 	}
 }
 
+// prepareStatements prepares all statements of the PostgreSQL store
+// and sets it up as the store of eye
 func prepareStatements() {
 	var err error
+	s := &postgresStore{}
 
-	Eye.run.checkItem, err = Eye.run.conn.Prepare(stmtCheckItemExists)
+	s.checkItem, err = Eye.run.conn.Prepare(stmtCheckItemExists)
 	log.Println("Preparing: check_item")
 	abortOnError(err)
 
-	Eye.run.checkLookup, err = Eye.run.conn.Prepare(stmtCheckLookupExists)
+	s.checkLookup, err = Eye.run.conn.Prepare(stmtCheckLookupExists)
 	log.Println("Preparing: check_lookup")
 	abortOnError(err)
 
-	Eye.run.deleteItem, err = Eye.run.conn.Prepare(stmtDeleteConfigurationItem)
+	s.deleteItem, err = Eye.run.conn.Prepare(stmtDeleteConfigurationItem)
 	log.Println("Preparing: delete_item")
 	abortOnError(err)
 
-	Eye.run.deleteLookup, err = Eye.run.conn.Prepare(stmtDeleteLookupID)
+	s.deleteLookup, err = Eye.run.conn.Prepare(stmtDeleteLookupID)
 	log.Println("Preparing: delete_lookup")
 	abortOnError(err)
 
-	Eye.run.getConfig, err = Eye.run.conn.Prepare(stmtGetSingleConfiguration)
+	s.getConfig, err = Eye.run.conn.Prepare(stmtGetSingleConfiguration)
 	log.Println("Preparing: get_config")
 	abortOnError(err)
 
-	Eye.run.getItems, err = Eye.run.conn.Prepare(stmtGetConfigurationItemIds)
+	s.getItems, err = Eye.run.conn.Prepare(stmtGetConfigurationItemIds)
 	log.Println("Preparing: get_items")
 	abortOnError(err)
 
-	Eye.run.getLookup, err = Eye.run.conn.Prepare(stmtGetLookupIDForItem)
+	s.getLookup, err = Eye.run.conn.Prepare(stmtGetLookupIDForItem)
 	log.Println("Preparing: get_lookup")
 	abortOnError(err)
 
-	Eye.run.insertItem, err = Eye.run.conn.Prepare(stmtInsertConfigurationItem)
+	s.insertItem, err = Eye.run.conn.Prepare(stmtInsertConfigurationItem)
 	log.Println("Preparing: insert_item")
 	abortOnError(err)
 
-	Eye.run.insertLookup, err = Eye.run.conn.Prepare(stmtInsertLookupInformation)
+	s.insertLookup, err = Eye.run.conn.Prepare(stmtInsertLookupInformation)
 	log.Println("Preparing: insert_lookup")
 	abortOnError(err)
 
-	Eye.run.itemCount, err = Eye.run.conn.Prepare(stmtGetItemCountForLookupID)
+	s.itemCount, err = Eye.run.conn.Prepare(stmtGetItemCountForLookupID)
 	log.Println("Preparing: item_count")
 	abortOnError(err)
 
-	Eye.run.retrieve, err = Eye.run.conn.Prepare(stmtRetrieveConfigurationsByLookup)
+	s.retrieve, err = Eye.run.conn.Prepare(stmtRetrieveConfigurationsByLookup)
 	log.Println("Preparing: retrieve")
 	abortOnError(err)
 
-	Eye.run.updateItem, err = Eye.run.conn.Prepare(stmtUpdateConfigurationItem)
+	s.updateItem, err = Eye.run.conn.Prepare(stmtUpdateConfigurationItem)
 	log.Println("Preparing: update_item")
 	abortOnError(err)
+
+	_, err = Eye.run.conn.Exec(stmtMigrateHostID)
+	log.Println("Migrating: configuration_lookup.host_id")
+	abortOnError(err)
+
+	_, err = Eye.run.conn.Exec(stmtCreateItemHistory)
+	log.Println("Creating: configuration_item_history")
+	abortOnError(err)
+
+	s.addHistory, err = Eye.run.conn.Prepare(stmtInsertItemHistory)
+	log.Println("Preparing: add_history")
+	abortOnError(err)
+
+	s.getHistory, err = Eye.run.conn.Prepare(stmtGetItemHistory)
+	log.Println("Preparing: get_history")
+	abortOnError(err)
+
+	_, err = Eye.run.conn.Exec(stmtCreateFeedbackQueue)
+	log.Println("Creating: feedback_queue")
+	abortOnError(err)
+
+	s.enqueueFb, err = Eye.run.conn.Prepare(stmtEnqueueFeedback)
+	log.Println("Preparing: enqueue_feedback")
+	abortOnError(err)
+
+	s.dueFb, err = Eye.run.conn.Prepare(stmtGetDueFeedback)
+	log.Println("Preparing: due_feedback")
+	abortOnError(err)
+
+	s.ackFb, err = Eye.run.conn.Prepare(stmtAckFeedback)
+	log.Println("Preparing: ack_feedback")
+	abortOnError(err)
+
+	s.retryFb, err = Eye.run.conn.Prepare(stmtRetryFeedback)
+	log.Println("Preparing: retry_feedback")
+	abortOnError(err)
+
+	s.listFb, err = Eye.run.conn.Prepare(stmtListFeedback)
+	log.Println("Preparing: list_feedback")
+	abortOnError(err)
+
+	_, err = Eye.run.conn.Exec(stmtCreateLookupRevisions)
+	log.Println("Creating: lookup_revisions")
+	abortOnError(err)
+
+	s.touchLookup, err = Eye.run.conn.Prepare(stmtTouchLookup)
+	log.Println("Preparing: touch_lookup")
+	abortOnError(err)
+
+	s.getRevision, err = Eye.run.conn.Prepare(stmtGetLookupRevision)
+	log.Println("Preparing: get_revision")
+	abortOnError(err)
+
+	s.retrieveBulk, err = Eye.run.conn.Prepare(stmtRetrieveConfigurationsByLookups)
+	log.Println("Preparing: retrieve_bulk")
+	abortOnError(err)
+
+	s.retrieveHost, err = Eye.run.conn.Prepare(stmtRetrieveConfigurationsByHost)
+	log.Println("Preparing: retrieve_host")
+	abortOnError(err)
+
+	s.getChanges, err = Eye.run.conn.Prepare(stmtGetConfigurationChanges)
+	log.Println("Preparing: get_changes")
+	abortOnError(err)
+
+	s.curRevision, err = Eye.run.conn.Prepare(stmtGetCurrentRevision)
+	log.Println("Preparing: current_revision")
+	abortOnError(err)
+
+	Eye.run.store = s
 }
 
 // vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -31,6 +31,7 @@ import (
 	"net/http"
 
 	"github.com/julienschmidt/httprouter"
+	"github.com/mjolnir42/soma/lib/proto"
 	"github.com/satori/go.uuid"
 )
 
@@ -44,21 +45,24 @@ func DeleteConfigurationItem(w http.ResponseWriter, r *http.Request, params http
 		dispatchBadRequest(&w, err.Error())
 		return
 	}
-	if err = deleteItem(itemID); err != nil {
+	if err = deleteItem(itemID, nil); err != nil {
 		dispatchInternalServerError(&w, err.Error())
 		return
 	}
 	dispatchNoContent(&w)
 }
 
-func deleteItem(itemID string) error {
+// deleteItem removes item itemID. details is the deployment the
+// removal originates from and recorded in the item history, it is nil
+// for removals without deployment.
+func deleteItem(itemID string, details *proto.Deployment) error {
 	var (
-		lookupID string
-		count    int
-		err      error
+		lookupID, previous string
+		count              int
+		err                error
 	)
 
-	if err = Eye.run.getLookup.QueryRow(itemID).Scan(&lookupID); err == sql.ErrNoRows {
+	if lookupID, err = Eye.run.store.ItemLookup(itemID); err == sql.ErrNoRows {
 		// not being able to delete what we do not have is ok
 		return nil
 	} else if err != nil {
@@ -66,19 +70,28 @@ func deleteItem(itemID string) error {
 		return err
 	}
 
-	if _, err = Eye.run.deleteItem.Exec(itemID); err != nil {
+	if previous, err = Eye.run.store.ItemConfiguration(itemID); err != nil {
 		return err
 	}
 
-	if err = Eye.run.itemCount.QueryRow(lookupID).Scan(&count); err != nil {
+	if err = Eye.run.store.DeleteItem(itemID); err != nil {
+		return err
+	}
+	if err = recordHistory(itemID, lookupID, historyDeleted, details,
+		[]byte(previous), nil); err != nil {
 		return err
 	}
 
-	if count != 0 {
-		return nil
+	if count, err = Eye.run.store.LookupItemCount(lookupID); err != nil {
+		return err
+	}
+
+	if count == 0 {
+		if err = Eye.run.store.DeleteLookup(lookupID); err != nil {
+			return err
+		}
 	}
-	_, err = Eye.run.deleteLookup.Exec(lookupID)
-	return err
+	return touchLookup(lookupID)
 }
 
 // vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
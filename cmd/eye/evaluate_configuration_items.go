@@ -0,0 +1,110 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mjolnir42/soma/lib/threshold"
+)
+
+// EvaluationRequest is the metric value to evaluate
+type EvaluationRequest struct {
+	Value *float64 `json:"value"`
+}
+
+// EvaluationResult is the result of evaluating a value against all
+// configuration items of a lookup. Level is the highest breached
+// level of all items.
+type EvaluationResult struct {
+	LookupID string           `json:"lookup_id"`
+	Value    float64          `json:"value"`
+	Level    uint16           `json:"level"`
+	Items    []ItemEvaluation `json:"items"`
+}
+
+// ItemEvaluation is the result of evaluating a value against the
+// thresholds of a single configuration item
+type ItemEvaluation struct {
+	ConfigurationItemID string                `json:"configuration_item_id"`
+	Level               uint16                `json:"level"`
+	Breached            []threshold.Threshold `json:"breached"`
+}
+
+// EvaluateConfigurationItems evaluates the value in the request body
+// against the thresholds of all configuration items of a lookup
+func EvaluateConfigurationItems(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	var (
+		err     error
+		req     EvaluationRequest
+		reply   EvaluationResult
+		jsonb   []byte
+		configs []string
+		res     threshold.Result
+	)
+
+	reply.LookupID = params.ByName("lookup")
+	// lookup is supposed to be a sha256 hash
+	if len(reply.LookupID) != 64 {
+		dispatchBadRequest(&w, "Invalid lookup id format")
+		return
+	}
+
+	if err = json.NewDecoder(r.Body).Decode(&req); err != nil {
+		dispatchBadRequest(&w, err.Error())
+		return
+	}
+	if req.Value == nil {
+		dispatchBadRequest(&w, "value: non zero value required")
+		return
+	}
+	reply.Value = *req.Value
+
+	if configs, err = Eye.run.store.LookupConfigurations(reply.LookupID); err != nil {
+		dispatchInternalServerError(&w, err.Error())
+		return
+	}
+
+	reply.Items = []ItemEvaluation{}
+	for _, config := range configs {
+		item := ConfigurationItem{}
+		if err = json.Unmarshal([]byte(config), &item); err != nil {
+			dispatchInternalServerError(&w, err.Error())
+			return
+		}
+
+		if res, err = threshold.Evaluate(reply.Value, item.thresholds()); err != nil {
+			dispatchUnprocessable(&w, err.Error())
+			return
+		}
+		reply.Items = append(reply.Items, ItemEvaluation{
+			ConfigurationItemID: item.ConfigurationItemID.String(),
+			Level:               res.Level,
+			Breached:            res.Breached,
+		})
+		if res.Level > reply.Level {
+			reply.Level = res.Level
+		}
+	}
+	if len(reply.Items) == 0 {
+		dispatchNotFound(&w)
+		return
+	}
+
+	if jsonb, err = json.Marshal(reply); err != nil {
+		dispatchInternalServerError(&w, err.Error())
+		return
+	}
+	dispatchJSONOK(&w, &jsonb)
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
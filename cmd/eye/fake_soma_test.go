@@ -0,0 +1,131 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/mjolnir42/soma/lib/proto"
+)
+
+// fakeSoma is an in-process SOMA that serves deployments and records
+// the feedback it receives
+type fakeSoma struct {
+	mux         sync.Mutex
+	server      *httptest.Server
+	deployments map[string]proto.Deployment
+	feedback    []string
+}
+
+// newFakeSoma starts a fakeSoma and sets up the eye runtime to use it
+// together with an empty memoryStore
+func newFakeSoma() (*fakeSoma, *memoryStore) {
+	soma := &fakeSoma{
+		deployments: map[string]proto.Deployment{},
+	}
+	soma.server = httptest.NewServer(http.HandlerFunc(soma.serve))
+
+	store := newMemoryStore()
+	Eye.Soma.url, _ = url.Parse(soma.server.URL)
+	Eye.Soma.Secret = ``
+	Eye.LookupScheme = ``
+	Eye.run.store = store
+	Eye.run.rules = &builtinItemizationRules
+	Eye.run.feedback = make(chan struct{}, 1)
+	return soma, store
+}
+
+// close shuts down the server
+func (s *fakeSoma) close() {
+	s.server.Close()
+}
+
+// add makes deployment d available for retrieval
+func (s *fakeSoma) add(d proto.Deployment) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.deployments[d.CheckInstance.InstanceID] = d
+}
+
+// received returns the feedback received so far as <id>/<result>
+func (s *fakeSoma) received() []string {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return append([]string{}, s.feedback...)
+}
+
+// serve answers GET /deployments/id/<id> and records
+// PATCH /deployments/id/<id>/<result>
+func (s *fakeSoma) serve(w http.ResponseWriter, r *http.Request) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	result := proto.NewDeploymentResult()
+	path := strings.Split(strings.TrimPrefix(r.URL.Path, `/deployments/id/`), `/`)
+	switch {
+	case r.Method == http.MethodGet && len(path) == 1:
+		if d, ok := s.deployments[path[0]]; ok {
+			result.StatusCode = proto.StatusOK
+			*result.Deployments = append(*result.Deployments, d)
+		} else {
+			result.StatusCode = proto.StatusNotFound
+		}
+	case r.Method == http.MethodPatch && len(path) == 2:
+		s.feedback = append(s.feedback, strings.Join(path, `/`))
+		result.StatusCode = proto.StatusOK
+	default:
+		http.Error(w, http.StatusText(http.StatusNotFound),
+			http.StatusNotFound)
+		return
+	}
+
+	jsonb, _ := json.Marshal(&result)
+	w.Header().Set(`Content-Type`, `application/json`)
+	w.Write(jsonb)
+}
+
+// testDeployment returns a deployment of metric cpu.usage.percent
+// for task on node testnode with asset ID 42
+func testDeployment(instanceID, task string) proto.Deployment {
+	return proto.Deployment{
+		ID:         instanceID,
+		ObjectType: `node`,
+		Task:       task,
+		State:      `awaiting_` + task,
+		Monitoring: &proto.Monitoring{Name: `eye-test`},
+		Metric:     &proto.Metric{Path: `cpu.usage.percent`},
+		Team:       &proto.Team{Name: `testteam`},
+		Node: &proto.Node{
+			ID:      `4c5a8f1e-6b0d-4f8e-9d3c-2a1b0c9d8e7f`,
+			AssetID: 42,
+			Name:    `testnode`,
+		},
+		CheckConfig: &proto.CheckConfig{
+			Name:     `cpu`,
+			Interval: 60,
+			Thresholds: []proto.CheckConfigThreshold{{
+				Predicate: proto.Predicate{Symbol: proto.PredicateGreater},
+				Level:     proto.Level{Numeric: 1},
+				Value:     90,
+			}},
+		},
+		CheckInstance: &proto.CheckInstance{
+			InstanceID: instanceID,
+			Version:    1,
+		},
+	}
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
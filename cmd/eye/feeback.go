@@ -7,28 +7,58 @@
 package main
 
 import (
-	"fmt"
 	"log"
+	"net/http"
 	"net/url"
-	"time"
 
 	"github.com/go-resty/resty"
+	"github.com/mjolnir42/soma/lib/auth"
 )
 
+// Failed queues fail feedback for deployment id
 func Failed(id string) {
-	soma, _ := url.Parse(Eye.Soma.url.String())
-	soma.Path = fmt.Sprintf("/deployments/id/%s/failed", id)
-	client := resty.New().SetTimeout(500 * time.Millisecond)
-	log.Printf("Sending fail feedback for %s\n", id)
-	go client.R().Patch(soma.String())
+	log.Printf("Queueing fail feedback for %s\n", id)
+	queueFeedback(id, `failed`)
 }
 
+// Success queues success feedback for deployment id
 func Success(id string) {
-	soma, _ := url.Parse(Eye.Soma.url.String())
-	soma.Path = fmt.Sprintf("/deployments/id/%s/success", id)
-	client := resty.New().SetTimeout(500 * time.Millisecond)
-	log.Printf("Sending success feedback for %s\n", id)
-	go client.R().Patch(soma.String())
+	log.Printf("Queueing success feedback for %s\n", id)
+	queueFeedback(id, `success`)
+}
+
+// signRequest signs req with the configured monitoring system secret.
+// Requests are sent unsigned if no secret is configured.
+func signRequest(req *resty.Request, method string, u *url.URL, body []byte) *resty.Request {
+	if Eye.Soma.Secret == `` {
+		return req
+	}
+	timestamp := auth.MonitoringTimestamp()
+	return req.
+		SetHeader(auth.HeaderMonitoringTimestamp, timestamp).
+		SetHeader(auth.HeaderMonitoringSignature, auth.SignMonitoring(
+			[]byte(Eye.Soma.Secret),
+			method,
+			u.Path,
+			timestamp,
+			body,
+		))
+}
+
+// verifyRequest checks that the notification r with body was signed
+// by SOMA with the configured monitoring system secret
+func verifyRequest(r *http.Request, body []byte) bool {
+	if Eye.Soma.Secret == `` {
+		return true
+	}
+	return auth.VerifyMonitoring(
+		[]byte(Eye.Soma.Secret),
+		r.Method,
+		r.URL.Path,
+		r.Header.Get(auth.HeaderMonitoringTimestamp),
+		r.Header.Get(auth.HeaderMonitoringSignature),
+		body,
+	)
 }
 
 // vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
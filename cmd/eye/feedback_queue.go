@@ -0,0 +1,234 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty"
+	"github.com/julienschmidt/httprouter"
+	"github.com/mjolnir42/soma/lib/proto"
+)
+
+// feedbackMaxBackoff is the upper limit for the delay between two
+// delivery attempts of the same feedback
+const feedbackMaxBackoff = 10 * time.Minute
+
+// feedbackMaxAttempts is the number of delivery attempts after which
+// feedback is dropped
+const feedbackMaxAttempts = 100
+
+// feedbackReached lists the deployment states SOMA reports once a
+// feedback result has been applied. A rejected feedback for a
+// deployment already in one of these states is a duplicate, for
+// example after the reply to an earlier delivery was lost.
+var feedbackReached = map[string][]string{
+	`success`: {
+		proto.DeploymentActive,
+		proto.DeploymentDeprovisioned,
+	},
+	`failed`: {
+		proto.DeploymentRolloutFailed,
+		proto.DeploymentDeprovisionFailed,
+	},
+}
+
+// FeedbackList is the reply of the feedback status listing
+type FeedbackList struct {
+	Feedback []FeedbackItem `json:"feedback"`
+}
+
+// FeedbackItem is a feedback that has not been acknowledged by SOMA
+type FeedbackItem struct {
+	DeploymentID string    `json:"deployment_id"`
+	Result       string    `json:"result"`
+	QueuedAt     time.Time `json:"queued_at"`
+	Attempts     int       `json:"attempts"`
+	NextAttempt  time.Time `json:"next_attempt"`
+	LastError    string    `json:"last_error,omitempty"`
+}
+
+// queueFeedback persists feedback result for deployment id and wakes
+// up the delivery. A newer feedback for the same deployment replaces
+// the queued one. If the feedback can not be persisted, a single
+// delivery attempt is made.
+func queueFeedback(id, result string) {
+	if err := Eye.run.store.EnqueueFeedback(id, result); err != nil {
+		log.Printf("Failed to queue feedback for %s: %s\n", id, err.Error())
+		go sendFeedback(id, result)
+		return
+	}
+
+	select {
+	case Eye.run.feedback <- struct{}{}:
+	default:
+	}
+}
+
+// deliverFeedback sends all due feedback to SOMA, whenever new
+// feedback is queued and at least every five seconds
+func deliverFeedback() {
+	tick := time.NewTicker(5 * time.Second)
+	defer tick.Stop()
+
+	for {
+		select {
+		case <-tick.C:
+		case <-Eye.run.feedback:
+		}
+		deliverDueFeedback()
+	}
+}
+
+// deliverDueFeedback sends all feedback whose next delivery attempt
+// is due. Feedback that is not acknowledged is rescheduled with
+// exponential backoff, until it is dropped after feedbackMaxAttempts
+// attempts.
+func deliverDueFeedback() {
+	var (
+		err               error
+		due               []FeedbackItem
+		delay             time.Duration
+		acknowledged      bool
+		stale             bool
+		qerr, deliveryErr error
+	)
+
+	if due, err = Eye.run.store.DueFeedback(); err != nil {
+		log.Printf("Failed to load due feedback: %s\n", err.Error())
+		return
+	}
+
+	for _, fb := range due {
+		acknowledged, stale, deliveryErr = sendFeedback(fb.DeploymentID, fb.Result)
+		switch {
+		case acknowledged, stale:
+			qerr = Eye.run.store.AckFeedback(fb.DeploymentID, fb.QueuedAt)
+		case fb.Attempts+1 >= feedbackMaxAttempts:
+			log.Printf("Dropping %s feedback for %s after %d attempts: %s\n",
+				fb.Result, fb.DeploymentID, fb.Attempts+1,
+				deliveryErr.Error())
+			qerr = Eye.run.store.AckFeedback(fb.DeploymentID, fb.QueuedAt)
+		default:
+			delay = time.Second << uint(fb.Attempts)
+			if fb.Attempts > 16 || delay > feedbackMaxBackoff {
+				delay = feedbackMaxBackoff
+			}
+			qerr = Eye.run.store.RetryFeedback(
+				fb.DeploymentID,
+				fb.QueuedAt,
+				delay,
+				deliveryErr.Error(),
+			)
+		}
+		if qerr != nil {
+			log.Printf("Failed to update feedback queue for %s: %s\n",
+				fb.DeploymentID, qerr.Error())
+		}
+	}
+}
+
+// sendFeedback delivers feedback result for deployment id to SOMA.
+// It reports if SOMA acknowledged the feedback, or if the deployment
+// is unknown to SOMA and the feedback therefore stale. Feedback that
+// SOMA rejects because the deployment already reached the reported
+// result counts as acknowledged.
+func sendFeedback(id, result string) (acknowledged, stale bool, err error) {
+	var (
+		soma   *url.URL
+		client *resty.Client
+		resp   *resty.Response
+		res    proto.Result
+	)
+
+	soma, _ = url.Parse(Eye.Soma.url.String())
+	soma.Path = fmt.Sprintf("/deployments/id/%s/%s", id, result)
+	client = resty.New().SetTimeout(5 * time.Second)
+	log.Printf("Sending %s feedback for %s\n", result, id)
+
+	if resp, err = signRequest(
+		client.R(), http.MethodPatch, soma, nil,
+	).Patch(soma.String()); err != nil {
+		return
+	}
+	if resp.StatusCode() > 299 {
+		err = fmt.Errorf("%s", resp.Status())
+		return
+	}
+	if err = json.Unmarshal(resp.Body(), &res); err != nil {
+		return
+	}
+	switch res.StatusCode {
+	case 200:
+		acknowledged = true
+	case 404:
+		log.Printf("Dropping %s feedback for unknown deployment %s\n",
+			result, id)
+		stale = true
+	default:
+		err = fmt.Errorf("Feedback has statuscode %d", res.StatusCode)
+		if res.Errors != nil && len(*res.Errors) > 0 {
+			if feedbackApplied(result, (*res.Errors)[0]) {
+				log.Printf("Deployment %s already reached %s feedback\n",
+					id, result)
+				acknowledged = true
+				err = nil
+				return
+			}
+			err = fmt.Errorf("Feedback has statuscode %d: %s",
+				res.StatusCode, (*res.Errors)[0])
+		}
+	}
+	return
+}
+
+// feedbackApplied checks if msg is the error SOMA replies with when
+// the deployment is already in a state reached by feedback result
+func feedbackApplied(result, msg string) bool {
+	for _, state := range feedbackReached[result] {
+		if strings.HasPrefix(msg,
+			fmt.Sprintf("Illegal current state (%s)", state),
+		) {
+			return true
+		}
+	}
+	return false
+}
+
+// ListFeedback returns all feedback not yet acknowledged by SOMA
+func ListFeedback(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var (
+		list  FeedbackList
+		err   error
+		jsonb []byte
+	)
+
+	if list.Feedback, err = Eye.run.store.Feedback(); err != nil {
+		dispatchInternalServerError(&w, err.Error())
+		return
+	}
+	if list.Feedback == nil {
+		list.Feedback = []FeedbackItem{}
+	}
+
+	if jsonb, err = json.Marshal(list); err != nil {
+		dispatchInternalServerError(&w, err.Error())
+		return
+	}
+	dispatchJSONOK(&w, &jsonb)
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
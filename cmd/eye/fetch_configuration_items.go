@@ -9,11 +9,13 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"log"
 	"net/http"
 	"net/url"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/asaskevich/govalidator"
@@ -22,77 +24,186 @@ import (
 	"github.com/mjolnir42/soma/lib/proto"
 )
 
+// fetchConcurrency is the number of deployments of a batched
+// notification that are fetched concurrently
+const fetchConcurrency = 16
+
 type NotifyMessage struct {
-	UUID string `json:"uuid" valid:"uuidv4"`
-	Path string `json:"path" valid:"abspath"`
+	UUID  string   `json:"uuid,omitempty"`
+	UUIDs []string `json:"uuids,omitempty"`
+	Path  string   `json:"path"`
+}
+
+// errFetch is returned by fetchDeployment and carries the function
+// used to signal the failure to the notifying client
+type errFetch struct {
+	err      error
+	dispatch func(*http.ResponseWriter, string)
+}
+
+func (e *errFetch) Error() string {
+	return e.err.Error()
 }
 
 func FetchConfigurationItems(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
 	var (
-		dec    *json.Decoder
-		msg    NotifyMessage
-		err    error
-		soma   *url.URL
-		client *resty.Client
-		resp   *resty.Response
-		res    proto.Result
-		ok     bool
+		msg   NotifyMessage
+		err   error
+		body  []byte
+		jsonb []byte
 	)
-	dec = json.NewDecoder(r.Body)
-	if err = dec.Decode(&msg); err != nil {
+	if body, err = ioutil.ReadAll(r.Body); err != nil {
+		log.Printf("Failed to read notify event: %s\n", err.Error())
+		dispatchBadRequest(&w, err.Error())
+		return
+	}
+	if !verifyRequest(r, body) {
+		dispatchUnauthorized(&w, `Invalid signature`)
+		return
+	}
+	if err = json.Unmarshal(body, &msg); err != nil {
 		log.Printf("Received bad notify event: %s\n", err.Error())
 		dispatchBadRequest(&w, err.Error())
 		return
 	}
-	govalidator.SetFieldsRequiredByDefault(true)
-	govalidator.TagMap["abspath"] = govalidator.Validator(func(str string) bool {
-		return filepath.IsAbs(str)
-	})
-	if ok, err = govalidator.ValidateStruct(msg); !ok {
+	if err = validateNotifyMessage(&msg); err != nil {
 		log.Printf("Failed notify event verification: %s\n", err.Error())
 		dispatchBadRequest(&w, err.Error())
 		return
 	}
 
+	// single deployment notification
+	if msg.UUID != `` {
+		if err = fetchDeployment(msg.Path, msg.UUID); err != nil {
+			e := err.(*errFetch)
+			e.dispatch(&w, e.Error())
+			return
+		}
+		dispatchNoContent(&w)
+		return
+	}
+
+	// batched deployment notification, every deployment is
+	// acknowledged individually. The deployments are fetched
+	// concurrently to answer within the notification timeout.
+	reply := proto.NewPushNotificationReply()
+	reply.Acks = make([]proto.PushNotificationAck, len(msg.UUIDs))
+	wg := sync.WaitGroup{}
+	limit := make(chan struct{}, fetchConcurrency)
+	for i, id := range msg.UUIDs {
+		wg.Add(1)
+		limit <- struct{}{}
+		go func(i int, id string) {
+			defer wg.Done()
+			defer func() { <-limit }()
+
+			ack := proto.PushNotificationAck{
+				UUID:     id,
+				Accepted: true,
+			}
+			if err := fetchDeployment(msg.Path, id); err != nil {
+				ack.Accepted = false
+				ack.Error = err.Error()
+			}
+			reply.Acks[i] = ack
+		}(i, id)
+	}
+	wg.Wait()
+	if jsonb, err = json.Marshal(&reply); err != nil {
+		dispatchInternalServerError(&w, err.Error())
+		return
+	}
+	dispatchJSONOK(&w, &jsonb)
+}
+
+// validateNotifyMessage verifies that msg is either a single or a
+// batched deployment notification
+func validateNotifyMessage(msg *NotifyMessage) error {
+	if !filepath.IsAbs(msg.Path) {
+		return fmt.Errorf("path: %s does not validate as abspath", msg.Path)
+	}
+	switch {
+	case msg.UUID != `` && len(msg.UUIDs) > 0:
+		return fmt.Errorf(`uuid and uuids are mutually exclusive`)
+	case msg.UUID == `` && len(msg.UUIDs) == 0:
+		return fmt.Errorf(`uuid: non zero value required`)
+	case msg.UUID != ``:
+		if !govalidator.IsUUIDv4(msg.UUID) {
+			return fmt.Errorf("uuid: %s does not validate as uuidv4", msg.UUID)
+		}
+	default:
+		for _, id := range msg.UUIDs {
+			if !govalidator.IsUUIDv4(id) {
+				return fmt.Errorf("uuids: %s does not validate as uuidv4", id)
+			}
+		}
+	}
+	return nil
+}
+
+// fetchDeployment retrieves deployment id from SOMA, processes it and
+// sends the resulting feedback
+func fetchDeployment(path, id string) error {
+	var (
+		err     error
+		details *proto.Deployment
+	)
+
+	if details, err = retrieveDeployment(path, id); err != nil {
+		Failed(id)
+		return err
+	}
+	if err = CheckUpdateOrInsertOrDelete(details); err != nil {
+		log.Printf("Error processing fetched deployment: %s\n", err.Error())
+		Failed(id)
+		return &errFetch{err: err, dispatch: dispatchInternalServerError}
+	}
+	Success(id)
+	return nil
+}
+
+// retrieveDeployment retrieves deployment id from SOMA
+func retrieveDeployment(path, id string) (*proto.Deployment, error) {
+	var (
+		err    error
+		soma   *url.URL
+		client *resty.Client
+		resp   *resty.Response
+		res    proto.Result
+	)
+
 	soma, _ = url.Parse(Eye.Soma.url.String())
-	soma.Path = strings.Replace(fmt.Sprintf("%s/%s", msg.Path, msg.UUID), `//`, `/`, -1)
+	soma.Path = strings.Replace(fmt.Sprintf("%s/%s", path, id), `//`, `/`, -1)
 	client = resty.New().SetTimeout(500 * time.Millisecond)
 	log.Printf("Fetching deployment: %s\n", soma.String())
-	if resp, err = client.R().Get(soma.String()); err != nil || resp.StatusCode() > 299 {
+	if resp, err = signRequest(
+		client.R(), http.MethodGet, soma, nil,
+	).Get(soma.String()); err != nil || resp.StatusCode() > 299 {
 		if err == nil {
-			err = fmt.Errorf(resp.Status())
+			err = fmt.Errorf("%s", resp.Status())
 		}
 		log.Printf("Failed to fetch deployment from SOMA: %s\n", err.Error())
-		dispatchPrecondition(&w, err.Error())
-		Failed(msg.UUID)
-		return
+		return nil, &errFetch{err: err, dispatch: dispatchPrecondition}
 	}
 	if err = json.Unmarshal(resp.Body(), &res); err != nil {
 		log.Printf("Error deserializing deployment: %s\n", err.Error())
-		dispatchUnprocessable(&w, err.Error())
-		Failed(msg.UUID)
-		return
+		return nil, &errFetch{err: err, dispatch: dispatchUnprocessable}
 	}
 	if res.StatusCode != 200 {
 		log.Printf("Error in fetched deployment, Statuscode %d\n", res.StatusCode)
-		dispatchGone(&w, err.Error())
-		Failed(msg.UUID)
-		return
+		return nil, &errFetch{
+			err:      fmt.Errorf("Fetched deployment has statuscode %d", res.StatusCode),
+			dispatch: dispatchGone,
+		}
 	}
 	if len(*res.Deployments) != 1 {
 		log.Printf("Error, deployment contained wrong deployment count: %d\n", len(*res.Deployments))
-		dispatchPrecondition(&w, err.Error())
-		Failed(msg.UUID)
-		return
-	}
-	if err = CheckUpdateOrInsertOrDelete(&(*res.Deployments)[0]); err != nil {
-		log.Printf("Error processing fetched deployment: %s\n", err.Error())
-		dispatchInternalServerError(&w, err.Error())
-		Failed(msg.UUID)
-		return
+		return nil, &errFetch{
+			err:      fmt.Errorf("Fetched deployment count is %d", len(*res.Deployments)),
+			dispatch: dispatchPrecondition,
+		}
 	}
-	dispatchNoContent(&w)
-	Success(msg.UUID)
+	return &(*res.Deployments)[0], nil
 }
 
 // vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
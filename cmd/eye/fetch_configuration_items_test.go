@@ -0,0 +1,139 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/mjolnir42/soma/lib/proto"
+)
+
+func TestFetchConfigurationItems(t *testing.T) {
+	known := `2d9e0f4a-5f6c-4d7e-9a8b-9c0d1e2f3a4b`
+	other := `3e0f1a5b-6a7d-4e8f-8b9c-0d1e2f3a4b5c`
+	unknown := `4f1a2b6c-7b8e-4f9a-9c0d-1e2f3a4b5c6d`
+
+	tests := []struct {
+		name     string
+		msg      NotifyMessage
+		status   int
+		acks     map[string]bool
+		items    int
+		feedback []string
+	}{
+		{
+			name:     `rollout`,
+			msg:      NotifyMessage{UUID: known, Path: `/deployments/id`},
+			status:   http.StatusNoContent,
+			items:    1,
+			feedback: []string{known + `/success`},
+		},
+		{
+			name:     `unknown deployment`,
+			msg:      NotifyMessage{UUID: unknown, Path: `/deployments/id`},
+			status:   http.StatusGone,
+			feedback: []string{unknown + `/failed`},
+		},
+		{
+			name:     `relative path`,
+			msg:      NotifyMessage{UUID: known, Path: `deployments/id`},
+			status:   http.StatusBadRequest,
+			feedback: []string{},
+		},
+		{
+			name:     `invalid uuid`,
+			msg:      NotifyMessage{UUID: `not-a-uuid`, Path: `/deployments/id`},
+			status:   http.StatusBadRequest,
+			feedback: []string{},
+		},
+		{
+			name: `uuid and uuids`,
+			msg: NotifyMessage{UUID: known, UUIDs: []string{other},
+				Path: `/deployments/id`},
+			status:   http.StatusBadRequest,
+			feedback: []string{},
+		},
+		{
+			name: `batch`,
+			msg: NotifyMessage{UUIDs: []string{known, unknown, other},
+				Path: `/deployments/id`},
+			status: http.StatusOK,
+			acks: map[string]bool{
+				known:   true,
+				unknown: false,
+				other:   true,
+			},
+			items: 2,
+			feedback: []string{
+				known + `/success`,
+				other + `/success`,
+				unknown + `/failed`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		soma, store := newFakeSoma()
+		soma.add(testDeployment(known, `rollout`))
+		d := testDeployment(other, `rollout`)
+		d.Metric.Path = `cpu.load`
+		soma.add(d)
+
+		body, _ := json.Marshal(&tt.msg)
+		req := httptest.NewRequest(http.MethodPost, `/api/v1/notify/`,
+			bytes.NewReader(body))
+		rec := httptest.NewRecorder()
+		FetchConfigurationItems(rec, req, nil)
+
+		if rec.Code != tt.status {
+			t.Errorf("%s: status %d, expected %d", tt.name,
+				rec.Code, tt.status)
+		}
+		if tt.acks != nil {
+			reply := proto.NewPushNotificationReply()
+			if err := json.Unmarshal(rec.Body.Bytes(), &reply); err != nil {
+				t.Errorf("%s: %s", tt.name, err.Error())
+			}
+			acks := map[string]bool{}
+			for _, ack := range reply.Acks {
+				acks[ack.UUID] = ack.Accepted
+			}
+			if !reflect.DeepEqual(acks, tt.acks) {
+				t.Errorf("%s: acks %v, expected %v", tt.name,
+					acks, tt.acks)
+			}
+		}
+		if len(store.items) != tt.items {
+			t.Errorf("%s: %d items, expected %d", tt.name,
+				len(store.items), tt.items)
+		}
+
+		deliverDueFeedback()
+		feedback := soma.received()
+		sort.Strings(feedback)
+		if !reflect.DeepEqual(feedback, tt.feedback) {
+			t.Errorf("%s: feedback %v, expected %v", tt.name,
+				feedback, tt.feedback)
+		}
+		if queued, _ := store.Feedback(); len(queued) != 0 {
+			t.Errorf("%s: %d feedback not acknowledged", tt.name,
+				len(queued))
+		}
+		soma.close()
+	}
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -49,7 +49,7 @@ func GetConfigurationItem(w http.ResponseWriter, r *http.Request, params httprou
 		return
 	}
 
-	if err = Eye.run.getConfig.QueryRow(params.ByName("item")).Scan(&jConfig); err != nil {
+	if jConfig, err = Eye.run.store.ItemConfiguration(params.ByName("item")); err != nil {
 		if err == sql.ErrNoRows {
 			dispatchNotFound(&w)
 		} else {
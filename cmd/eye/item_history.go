@@ -0,0 +1,151 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mjolnir42/soma/lib/proto"
+	"github.com/satori/go.uuid"
+)
+
+// Changes recorded in the configuration item history
+const (
+	historyAdded   = `added`
+	historyUpdated = `updated`
+	historyDeleted = `deleted`
+)
+
+// ConfigurationHistory is the history of a configuration item, the
+// most recent change first
+type ConfigurationHistory struct {
+	ConfigurationItemID string                `json:"configuration_item_id"`
+	History             []ConfigurationChange `json:"history"`
+}
+
+// ConfigurationChange is a single change of a configuration item.
+// Configuration is the item as it was valid from ChangedAt on,
+// Previous the item as it was valid before.
+type ConfigurationChange struct {
+	HistoryID        uint64             `json:"history_id"`
+	LookupID         string             `json:"lookup_id"`
+	DeploymentID     string             `json:"deployment_id,omitempty"`
+	InstanceConfigID string             `json:"instance_config_id,omitempty"`
+	ConfigVersion    *uint64            `json:"config_version,omitempty"`
+	Change           string             `json:"change"`
+	ChangedAt        time.Time          `json:"changed_at"`
+	Previous         *ConfigurationItem `json:"previous,omitempty"`
+	Configuration    *ConfigurationItem `json:"configuration,omitempty"`
+}
+
+// recordHistory records a change of item itemID. previous and current
+// are the serialized item before and after the change, details the
+// deployment the change originates from. All of them may be nil.
+func recordHistory(itemID, lookupID, change string, details *proto.Deployment,
+	previous, current []byte) error {
+	record := HistoryRecord{
+		ItemID:        itemID,
+		LookupID:      lookupID,
+		Change:        change,
+		Previous:      previous,
+		Configuration: current,
+	}
+	if details != nil && details.CheckInstance != nil {
+		record.DeploymentID = details.CheckInstance.InstanceID
+		record.InstanceConfigID = details.CheckInstance.InstanceConfigID
+		if record.InstanceConfigID != `` {
+			version := details.CheckInstance.Version
+			record.ConfigVersion = &version
+		}
+	}
+	return Eye.run.store.AddHistory(record)
+}
+
+// GetConfigurationItemHistory returns the history of a configuration
+// item. With query parameter ?at=<RFC3339 timestamp> only the change
+// that was in effect at that time is returned.
+func GetConfigurationItemHistory(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	var (
+		err     error
+		at      time.Time
+		records []HistoryRecord
+		reply   ConfigurationHistory
+		jsonb   []byte
+	)
+
+	reply.ConfigurationItemID = params.ByName("item")
+	if _, err = uuid.FromString(reply.ConfigurationItemID); err != nil {
+		dispatchBadRequest(&w, err.Error())
+		return
+	}
+	if val := r.URL.Query().Get(`at`); val != `` {
+		if at, err = time.Parse(time.RFC3339, val); err != nil {
+			dispatchBadRequest(&w, err.Error())
+			return
+		}
+	}
+
+	if records, err = Eye.run.store.History(reply.ConfigurationItemID); err != nil {
+		dispatchInternalServerError(&w, err.Error())
+		return
+	}
+
+	reply.History = []ConfigurationChange{}
+	for _, record := range records {
+		// records are ordered by most recent change first, the first
+		// change not after at is the one in effect at that time
+		if !at.IsZero() && record.ChangedAt.After(at) {
+			continue
+		}
+
+		change := ConfigurationChange{
+			HistoryID:        record.HistoryID,
+			LookupID:         record.LookupID,
+			DeploymentID:     record.DeploymentID,
+			InstanceConfigID: record.InstanceConfigID,
+			ConfigVersion:    record.ConfigVersion,
+			Change:           record.Change,
+			ChangedAt:        record.ChangedAt,
+		}
+		for raw, item := range map[*[]byte]**ConfigurationItem{
+			&record.Previous:      &change.Previous,
+			&record.Configuration: &change.Configuration,
+		} {
+			if *raw == nil {
+				continue
+			}
+			*item = &ConfigurationItem{}
+			if err = json.Unmarshal(*raw, *item); err != nil {
+				dispatchInternalServerError(&w, err.Error())
+				return
+			}
+		}
+		reply.History = append(reply.History, change)
+
+		if !at.IsZero() {
+			break
+		}
+	}
+	if len(reply.History) == 0 {
+		dispatchNotFound(&w)
+		return
+	}
+
+	if jsonb, err = json.Marshal(reply); err != nil {
+		dispatchInternalServerError(&w, err.Error())
+		return
+	}
+	dispatchJSONOK(&w, &jsonb)
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -0,0 +1,273 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/mjolnir42/soma/lib/proto"
+	"github.com/nahanni/go-ucl"
+)
+
+// ItemizationRules describe how a deployment is converted into a
+// configuration item. The item key is the metric name under which
+// the item is stored and from which the lookupID is calculated.
+//
+// Keys and target hosts are templates that can reference the
+// following variables:
+//
+//	${metric}            path of the deployed metric
+//	${object}            name of the node, cluster or group
+//	${uuid}              id of the node, cluster or group
+//	${node}              name of the node
+//	${asset}             asset id of the node
+//	${attribute:<name>}  value of a service attribute
+//	${property:<name>}   value of a system property
+//	${custom:<name>}     value of a custom property
+//
+// A template fails if it references a variable without value, unless
+// a default value is configured for that variable.
+type ItemizationRules struct {
+	Default ItemizationRule   `json:"default"`
+	Rules   []ItemizationRule `json:"rules"`
+}
+
+// ItemizationRule applies to all metrics whose path matches Metric.
+// Metric is a shell pattern as supported by path.Match. Fields that
+// are not set are taken from the default rule.
+type ItemizationRule struct {
+	Metric     string              `json:"metric"`
+	Key        string              `json:"key"`
+	Targethost []string            `json:"targethost"`
+	Defaults   itemizationDefaults `json:"defaults"`
+}
+
+// itemizationDefaults maps template variables to their default values
+type itemizationDefaults map[string]string
+
+// UnmarshalJSON implements json.Unmarshaler. It skips all non-string
+// values, like the key order information added by the UCL parser.
+func (d *itemizationDefaults) UnmarshalJSON(b []byte) error {
+	raw := map[string]interface{}{}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	*d = itemizationDefaults{}
+	for k, v := range raw {
+		if s, ok := v.(string); ok {
+			(*d)[k] = s
+		}
+	}
+	return nil
+}
+
+// builtinItemizationRules are used if no rule file is configured
+var builtinItemizationRules = ItemizationRules{
+	Default: ItemizationRule{
+		Key: `${metric}`,
+		Targethost: []string{
+			`${property:fqdn}`,
+			`${node}.${property:dns_zone}`,
+			`${node}`,
+		},
+	},
+	Rules: []ItemizationRule{
+		{Metric: `disk.write.per.second`, Key: `${metric}:${attribute:filesystem}`},
+		{Metric: `disk.read.per.second`, Key: `${metric}:${attribute:filesystem}`},
+		{Metric: `disk.free`, Key: `${metric}:${attribute:filesystem}`},
+		{Metric: `disk.usage.percent`, Key: `${metric}:${attribute:filesystem}`},
+	},
+}
+
+// loadItemizationRules reads the itemization rules from fname. The
+// builtin rules are returned if fname is empty.
+func loadItemizationRules(fname string) (*ItemizationRules, error) {
+	if fname == `` {
+		log.Println(`No itemization rules configured, using builtin rules`)
+		return &builtinItemizationRules, nil
+	}
+
+	file, err := ioutil.ReadFile(fname)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Loading itemization rules from %s", fname)
+
+	// UCL parses into map[string]interface{}
+	parser := ucl.NewParser(bytes.NewBuffer(file))
+	uclData, err := parser.Ucl()
+	if err != nil {
+		return nil, fmt.Errorf("UCL error: %s", err)
+	}
+
+	// take detour via JSON to load UCL into struct
+	uclJSON, err := json.Marshal(uclData)
+	if err != nil {
+		return nil, err
+	}
+	rules := &ItemizationRules{}
+	if err = json.Unmarshal(uclJSON, rules); err != nil {
+		return nil, err
+	}
+
+	if rules.Default.Key == `` {
+		rules.Default.Key = builtinItemizationRules.Default.Key
+	}
+	if len(rules.Default.Targethost) == 0 {
+		rules.Default.Targethost = builtinItemizationRules.Default.Targethost
+	}
+	for i := range rules.Rules {
+		if rules.Rules[i].Metric == `` {
+			return nil, fmt.Errorf("Itemization rule %d has no metric", i)
+		}
+		if _, err = path.Match(rules.Rules[i].Metric, ``); err != nil {
+			return nil, fmt.Errorf("Itemization rule %d: %s",
+				i, err.Error())
+		}
+	}
+	return rules, nil
+}
+
+// lookup returns the effective rule for metric. It is the first rule
+// whose pattern matches, completed by the default rule.
+func (r *ItemizationRules) lookup(metric string) ItemizationRule {
+	rule := ItemizationRule{
+		Metric:     metric,
+		Key:        r.Default.Key,
+		Targethost: r.Default.Targethost,
+		Defaults:   itemizationDefaults{},
+	}
+	for k, v := range r.Default.Defaults {
+		rule.Defaults[k] = v
+	}
+
+	for i := range r.Rules {
+		if ok, _ := path.Match(r.Rules[i].Metric, metric); !ok {
+			continue
+		}
+		if r.Rules[i].Key != `` {
+			rule.Key = r.Rules[i].Key
+		}
+		if len(r.Rules[i].Targethost) > 0 {
+			rule.Targethost = r.Rules[i].Targethost
+		}
+		for k, v := range r.Rules[i].Defaults {
+			rule.Defaults[k] = v
+		}
+		break
+	}
+	return rule
+}
+
+// key returns the item key for details
+func (r *ItemizationRule) key(details *proto.Deployment) (string, error) {
+	return r.expand(r.Key, details)
+}
+
+// targethost returns the target host for details, built from the
+// first target host template that can be expanded
+func (r *ItemizationRule) targethost(details *proto.Deployment) string {
+	for _, tmpl := range r.Targethost {
+		if host, err := r.expand(tmpl, details); err == nil && host != `` {
+			return host
+		}
+	}
+	return itemizationValue(`object`, details)
+}
+
+// expand replaces all variables in tmpl with their values for
+// details
+func (r *ItemizationRule) expand(tmpl string, details *proto.Deployment) (string, error) {
+	var buf bytes.Buffer
+
+	for {
+		start := strings.Index(tmpl, `${`)
+		if start < 0 {
+			buf.WriteString(tmpl)
+			break
+		}
+		end := strings.Index(tmpl[start:], `}`)
+		if end < 0 {
+			return ``, fmt.Errorf("Unterminated variable in template %s", tmpl)
+		}
+		end += start
+
+		variable := tmpl[start+2 : end]
+		value := itemizationValue(variable, details)
+		if value == `` {
+			value = r.Defaults[variable]
+		}
+		if value == `` {
+			return ``, fmt.Errorf("Metric %s is missing %s",
+				details.Metric.Path, variable)
+		}
+		buf.WriteString(tmpl[:start])
+		buf.WriteString(value)
+		tmpl = tmpl[end+1:]
+	}
+	return buf.String(), nil
+}
+
+// itemizationValue returns the value of variable for details
+func itemizationValue(variable string, details *proto.Deployment) string {
+	kind, name := variable, ``
+	if i := strings.Index(variable, `:`); i >= 0 {
+		kind, name = variable[:i], variable[i+1:]
+	}
+
+	switch kind {
+	case `metric`:
+		return details.Metric.Path
+	case `object`, `uuid`:
+		id, name := deploymentObject(details)
+		if kind == `uuid` {
+			return id
+		}
+		return name
+	case `node`:
+		if details.Node != nil {
+			return details.Node.Name
+		}
+	case `asset`:
+		if details.Node != nil && details.Node.AssetID != 0 {
+			return strconv.FormatUint(details.Node.AssetID, 10)
+		}
+	case `attribute`:
+		return GetServiceAttributeValue(details, name)
+	case `property`:
+		if details.Properties == nil {
+			return ``
+		}
+		for _, prop := range *details.Properties {
+			if prop.Name == name {
+				return prop.Value
+			}
+		}
+	case `custom`:
+		if details.CustomProperties == nil {
+			return ``
+		}
+		for _, prop := range *details.CustomProperties {
+			if prop.Name == name {
+				return prop.Value
+			}
+		}
+	}
+	return ``
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
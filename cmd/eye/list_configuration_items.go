@@ -27,7 +27,6 @@ OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
 package main
 
 import (
-	"database/sql"
 	"encoding/json"
 	"net/http"
 
@@ -36,39 +35,15 @@ import (
 
 func ListConfigurationItems(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
 	var (
-		items *sql.Rows
 		list  ConfigurationList
-		item  string
 		err   error
 		jsonb []byte
 	)
 
-	if items, err = Eye.run.getItems.Query(); err != nil {
+	if list.ConfigurationItemIDList, err = Eye.run.store.ItemIDs(); err != nil {
 		dispatchInternalServerError(&w, err.Error())
 		return
 	}
-	defer items.Close()
-
-	for items.Next() {
-		if err = items.Scan(&item); err != nil {
-			if err == sql.ErrNoRows {
-				dispatchNotFound(&w)
-			} else {
-				dispatchInternalServerError(&w, err.Error())
-			}
-			return
-		}
-		i := item
-		list.ConfigurationItemIDList = append(list.ConfigurationItemIDList, i)
-	}
-	if err = items.Err(); err != nil {
-		if err == sql.ErrNoRows {
-			dispatchNotFound(&w)
-		} else {
-			dispatchInternalServerError(&w, err.Error())
-		}
-		return
-	}
 	if len(list.ConfigurationItemIDList) == 0 {
 		dispatchNotFound(&w)
 		return
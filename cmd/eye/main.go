@@ -47,12 +47,13 @@ var somaVersion string
 
 func main() {
 	var (
-		configFlag, configFile string
-		err                    error
-		versionFlag            bool
+		configFlag, configFile  string
+		err                     error
+		versionFlag, resyncFlag bool
 	)
 	flag.StringVar(&configFlag, "config", "/srv/eye/conf/eye.conf", "Configuration file location")
 	flag.BoolVar(&versionFlag, `version`, false, `Print version information`)
+	flag.BoolVar(&resyncFlag, `resync`, false, `Resynchronize all items with SOMA on startup`)
 	flag.Parse()
 
 	if versionFlag {
@@ -105,30 +106,43 @@ func main() {
 	connectToDatabase()
 	prepareStatements()
 	// Close() must be deferred here since it triggers on function exit
-	defer Eye.run.checkItem.Close()
-	defer Eye.run.checkLookup.Close()
-	defer Eye.run.deleteItem.Close()
-	defer Eye.run.deleteLookup.Close()
-	defer Eye.run.getConfig.Close()
-	defer Eye.run.getItems.Close()
-	defer Eye.run.getLookup.Close()
-	defer Eye.run.insertItem.Close()
-	defer Eye.run.insertLookup.Close()
-	defer Eye.run.itemCount.Close()
-	defer Eye.run.retrieve.Close()
-	defer Eye.run.updateItem.Close()
+	defer Eye.run.store.Close()
 	go pingDatabase()
+	Eye.run.feedback = make(chan struct{}, 1)
+	go deliverFeedback()
+
+	/*
+	 * Resynchronize with SOMA
+	 */
+	if resyncFlag || Eye.Resync > 0 {
+		if Eye.Soma.MonitoringID == `` {
+			log.Fatal("Missing required configuration config/soma/monitoring.id for resync")
+		}
+	}
+	if resyncFlag {
+		go resync()
+	}
+	if Eye.Resync > 0 {
+		go resyncLoop(Eye.Resync)
+	}
 
 	/*
 	 * Register http handlers
 	 */
 	router := httprouter.New()
+	router.GET("/api/v1/configuration/", RetrieveBulkConfigurationItems)
+	router.POST("/api/v1/configuration/", RetrieveBulkConfigurationItems)
 	router.GET("/api/v1/configuration/:lookup", RetrieveConfigurationItems)
+	router.GET("/api/v1/changes/", ListConfigurationChanges)
+	router.POST("/api/v1/evaluate/:lookup", EvaluateConfigurationItems)
 	router.GET("/api/v1/item/", ListConfigurationItems)
 	router.POST("/api/v1/item/", AddConfigurationItem)
 	router.GET("/api/v1/item/:item", GetConfigurationItem)
+	router.GET("/api/v1/item/:item/history", GetConfigurationItemHistory)
 	router.PUT("/api/v1/item/:item", UpdateConfigurationItem)
 	router.DELETE("/api/v1/item/:item", DeleteConfigurationItem)
+	router.GET("/api/v1/feedback", ListFeedback)
+	router.GET("/api/v1/feedback/", ListFeedback)
 	router.POST("/api/v1/notify/", FetchConfigurationItems)
 	router.POST("/api/v1/notify", FetchConfigurationItems)
 
@@ -0,0 +1,311 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/go-resty/resty"
+	"github.com/mjolnir42/soma/lib/proto"
+)
+
+// resyncPageSize is the number of deployments requested per page
+const resyncPageSize = 500
+
+// resyncLock ensures that only one resynchronisation runs at a time
+var resyncLock = make(chan struct{}, 1)
+
+// resyncReport records the changes made by a resynchronisation
+type resyncReport struct {
+	added     int
+	updated   int
+	removed   int
+	unchanged int
+	failed    int
+}
+
+// resyncLoop runs a resynchronisation with SOMA every interval
+// minutes
+func resyncLoop(interval uint64) {
+	tick := time.NewTicker(time.Duration(interval) * time.Minute)
+	defer tick.Stop()
+
+	for range tick.C {
+		resync()
+	}
+}
+
+// resync compares all deployments SOMA has for the configured
+// monitoring system with the local configuration items. Missing and
+// outdated items are added or updated, items that SOMA does not know
+// about are removed.
+func resync() {
+	select {
+	case resyncLock <- struct{}{}:
+		defer func() { <-resyncLock }()
+	default:
+		log.Println(`Resync: already running, skipping`)
+		return
+	}
+
+	var (
+		err        error
+		deployment []proto.Deployment
+		report     resyncReport
+		monitoring string
+	)
+
+	log.Printf("Resync: starting for monitoring system %s\n", Eye.Soma.MonitoringID)
+	if deployment, err = resyncList(); err != nil {
+		log.Printf("Resync: aborted, %s\n", err.Error())
+		return
+	}
+
+	known := make(map[string]bool, len(deployment))
+	for i := range deployment {
+		known[deployment[i].ID] = true
+		if deployment[i].State == proto.DeploymentBlocked {
+			// held by a maintenance window or a staged rollout,
+			// the local item is kept as it is
+			report.unchanged++
+			continue
+		}
+		name, err := resyncDeployment(&deployment[i], &report)
+		if err != nil {
+			log.Printf("Resync: deployment %s failed, %s\n",
+				deployment[i].ID, err.Error())
+			report.failed++
+			continue
+		}
+		if monitoring == `` {
+			monitoring = name
+		}
+	}
+
+	// without a successfully fetched deployment, the name of the
+	// monitoring system is unknown and items of other monitoring
+	// systems served by this eye can not be told apart
+	if monitoring == `` {
+		log.Println(`Resync: monitoring system name unknown, not removing items`)
+	} else if err = resyncRemove(monitoring, known, &report); err != nil {
+		log.Printf("Resync: removing items failed, %s\n", err.Error())
+	}
+
+	log.Printf("Resync: finished, added %d, updated %d, removed %d, unchanged %d, failed %d\n",
+		report.added, report.updated, report.removed, report.unchanged,
+		report.failed)
+}
+
+// resyncList pages through the list of active deployments for the
+// configured monitoring system
+func resyncList() ([]proto.Deployment, error) {
+	var (
+		err        error
+		soma       *url.URL
+		client     *resty.Client
+		resp       *resty.Response
+		deployment []proto.Deployment
+	)
+
+	client = resty.New().SetTimeout(5 * time.Second)
+	for offset := 0; ; offset += resyncPageSize {
+		res := proto.Result{}
+		soma, _ = url.Parse(Eye.Soma.url.String())
+		soma.Path = fmt.Sprintf("/monitoringsystem/%s/deployment/",
+			Eye.Soma.MonitoringID)
+		soma.RawQuery = fmt.Sprintf("active=true&limit=%d&offset=%d",
+			resyncPageSize, offset)
+
+		if resp, err = signRequest(
+			client.R(), http.MethodGet, soma, nil,
+		).Get(soma.String()); err != nil {
+			return nil, err
+		}
+		if resp.StatusCode() > 299 {
+			return nil, fmt.Errorf("%s", resp.Status())
+		}
+		if err = json.Unmarshal(resp.Body(), &res); err != nil {
+			return nil, err
+		}
+		if res.StatusCode == 404 {
+			// an empty page is returned as not found
+			break
+		}
+		if res.StatusCode != 200 {
+			return nil, fmt.Errorf("Deployment list has statuscode %d",
+				res.StatusCode)
+		}
+		if res.Deployments == nil {
+			break
+		}
+		deployment = append(deployment, *res.Deployments...)
+		if len(*res.Deployments) < resyncPageSize {
+			break
+		}
+	}
+	return deployment, nil
+}
+
+// resyncDeployment fetches and applies a single deployment and
+// returns the name of its monitoring system
+func resyncDeployment(d *proto.Deployment, report *resyncReport) (string, error) {
+	var (
+		err     error
+		details *proto.Deployment
+		changed string
+	)
+
+	if details, err = retrieveDeployment(`/deployments/id`, d.ID); err != nil {
+		resyncFeedback(d, false)
+		return ``, err
+	}
+	if changed, err = resyncApply(details); err != nil {
+		resyncFeedback(d, false)
+		return ``, err
+	}
+	switch changed {
+	case `added`:
+		report.added++
+	case `updated`:
+		report.updated++
+	case `removed`:
+		report.removed++
+	default:
+		report.unchanged++
+	}
+	resyncFeedback(d, true)
+
+	name := ``
+	if details.Monitoring != nil {
+		name = details.Monitoring.Name
+	}
+	return name, nil
+}
+
+// resyncApply brings the local configuration item for details in
+// line with the deployment and returns the change that was made
+func resyncApply(details *proto.Deployment) (string, error) {
+	var (
+		err                   error
+		lookupID, oldLookupID string
+		config                string
+		item, oldItem         *ConfigurationItem
+		current, stored       []byte
+	)
+
+	if lookupID, item, err = Itemize(details); err != nil {
+		return ``, err
+	}
+
+	config, err = Eye.run.store.ItemConfiguration(item.ConfigurationItemID.String())
+	switch {
+	case err == sql.ErrNoRows && details.Task == `rollout`:
+		return `added`, addItem(item, lookupID, details)
+	case err == sql.ErrNoRows:
+		return ``, nil
+	case err != nil:
+		return ``, err
+	}
+
+	if details.Task == `deprovision` {
+		return `removed`, deleteItem(item.ConfigurationItemID.String(), details)
+	}
+	if details.Task != `rollout` {
+		return ``, fmt.Errorf(`Unknown Task requested`)
+	}
+
+	if oldLookupID, err = Eye.run.store.ItemLookup(
+		item.ConfigurationItemID.String(),
+	); err != nil {
+		return ``, err
+	}
+	oldItem = &ConfigurationItem{}
+	if err = json.Unmarshal([]byte(config), oldItem); err != nil {
+		return ``, err
+	}
+	if current, err = json.Marshal(item); err != nil {
+		return ``, err
+	}
+	if stored, err = json.Marshal(oldItem); err != nil {
+		return ``, err
+	}
+	if lookupID == oldLookupID && bytes.Equal(current, stored) {
+		return ``, nil
+	}
+	return `updated`, updateItem(item, lookupID, details)
+}
+
+// resyncFeedback sends the feedback for d if SOMA is still waiting
+// for it
+func resyncFeedback(d *proto.Deployment, ok bool) {
+	switch d.State {
+	case `awaiting_rollout`, `rollout_in_progress`,
+		`awaiting_deprovision`, `deprovision_in_progress`:
+	default:
+		return
+	}
+	if ok {
+		Success(d.ID)
+	} else {
+		Failed(d.ID)
+	}
+}
+
+// resyncRemove deletes all local configuration items of monitoring
+// system monitoring that are not in known
+func resyncRemove(monitoring string, known map[string]bool,
+	report *resyncReport) error {
+	var (
+		err    error
+		itemID string
+		items  []string
+	)
+
+	if items, err = Eye.run.store.ItemIDs(); err != nil {
+		return err
+	}
+
+	for _, itemID = range items {
+		var config string
+		item := &ConfigurationItem{}
+
+		if known[itemID] {
+			continue
+		}
+		if config, err = Eye.run.store.ItemConfiguration(itemID); err == sql.ErrNoRows {
+			continue
+		} else if err != nil {
+			return err
+		}
+		if err = json.Unmarshal([]byte(config), item); err != nil {
+			return err
+		}
+		if item.Metadata.Monitoring != monitoring {
+			continue
+		}
+		if err = deleteItem(itemID, nil); err != nil {
+			log.Printf("Resync: removing item %s failed, %s\n",
+				itemID, err.Error())
+			report.failed++
+			continue
+		}
+		report.removed++
+	}
+	return nil
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -0,0 +1,255 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/julienschmidt/httprouter"
+)
+
+// changesDefaultLimit is the number of changed lookups returned by
+// the changes feed if the request does not set a limit
+const changesDefaultLimit = 1000
+
+// changesMaxLimit is the maximum number of changed lookups returned
+// by the changes feed
+const changesMaxLimit = 10000
+
+// RetrieveBulkConfigurationItems returns the configurations of many
+// lookups at once. The lookups are selected by lookup IDs or by a
+// host ID, either as query parameters ?lookup=<id>&lookup=<id> and
+// ?host=<id> or as BulkRequest in the request body.
+func RetrieveBulkConfigurationItems(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var (
+		err     error
+		req     BulkRequest
+		reply   BulkConfigurationData
+		configs []StoredConfiguration
+		jsonb   []byte
+	)
+
+	switch r.Method {
+	case http.MethodPost:
+		if err = json.NewDecoder(r.Body).Decode(&req); err != nil {
+			dispatchBadRequest(&w, err.Error())
+			return
+		}
+	default:
+		req.LookupIDs = r.URL.Query()[`lookup`]
+		req.HostID = r.URL.Query().Get(`host`)
+	}
+
+	switch {
+	case len(req.LookupIDs) > 0 && req.HostID != ``:
+		dispatchBadRequest(&w, `lookup and host are mutually exclusive`)
+		return
+	case len(req.LookupIDs) > 0:
+		for _, lookup := range req.LookupIDs {
+			// lookup is supposed to be a sha256 hash
+			if len(lookup) != 64 {
+				dispatchBadRequest(&w, "Invalid lookup id format")
+				return
+			}
+		}
+		configs, err = Eye.run.store.BulkConfigurations(req.LookupIDs)
+	case req.HostID != ``:
+		if !govalidator.IsNumeric(req.HostID) && !govalidator.IsUUID(req.HostID) {
+			dispatchBadRequest(&w, "Invalid host id format")
+			return
+		}
+		configs, err = Eye.run.store.HostConfigurations(req.HostID)
+	default:
+		dispatchBadRequest(&w, `lookup or host required`)
+		return
+	}
+	if err != nil {
+		dispatchInternalServerError(&w, err.Error())
+		return
+	}
+
+	reply.Lookups = []LookupConfiguration{}
+	for _, c := range configs {
+		if err = appendLookupConfiguration(&reply.Lookups, c.LookupID,
+			c.Revision, false, c.Configuration); err != nil {
+			dispatchInternalServerError(&w, err.Error())
+			return
+		}
+	}
+
+	etag := bulkETag(reply.Lookups)
+	if etagMatch(r.Header.Get(`If-None-Match`), etag) {
+		dispatchNotModified(&w, etag)
+		return
+	}
+
+	if jsonb, err = json.Marshal(reply); err != nil {
+		dispatchInternalServerError(&w, err.Error())
+		return
+	}
+	w.Header().Set(`ETag`, etag)
+	dispatchJSONOK(&w, &jsonb)
+}
+
+// ListConfigurationChanges returns all lookups that changed after
+// the revision in query parameter ?since=<revision>. Lookups without
+// remaining configurations are returned as deleted. Query parameter
+// ?limit=<count> limits the number of returned lookups.
+func ListConfigurationChanges(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var (
+		err          error
+		since, limit uint64
+		current      uint64
+		reply        ConfigurationChanges
+		configs      []StoredConfiguration
+		jsonb        []byte
+		count        uint64
+	)
+
+	limit = changesDefaultLimit
+	for param, value := range map[string]*uint64{
+		`since`: &since,
+		`limit`: &limit,
+	} {
+		if val := r.URL.Query().Get(param); val != `` {
+			if *value, err = strconv.ParseUint(val, 10, 63); err != nil {
+				dispatchBadRequest(&w, err.Error())
+				return
+			}
+		}
+	}
+	if limit == 0 || limit > changesMaxLimit {
+		limit = changesMaxLimit
+	}
+
+	// read the current revision first, a change committed while the
+	// feed is assembled is then returned by the next request
+	if current, err = Eye.run.store.CurrentRevision(); err != nil {
+		dispatchInternalServerError(&w, err.Error())
+		return
+	}
+
+	if configs, err = Eye.run.store.Changes(since, limit); err != nil {
+		dispatchInternalServerError(&w, err.Error())
+		return
+	}
+
+	reply.Changes = []LookupConfiguration{}
+	for _, c := range configs {
+		if c.Revision > current {
+			continue
+		}
+		if err = appendLookupConfiguration(&reply.Changes, c.LookupID,
+			c.Revision, c.Deleted, c.Configuration); err != nil {
+			dispatchInternalServerError(&w, err.Error())
+			return
+		}
+	}
+
+	count = uint64(len(reply.Changes))
+	reply.More = count == limit
+	switch {
+	case count > 0:
+		reply.Revision = reply.Changes[count-1].Revision
+	case since > current:
+		reply.Revision = since
+	default:
+		reply.Revision = current
+	}
+
+	if jsonb, err = json.Marshal(reply); err != nil {
+		dispatchInternalServerError(&w, err.Error())
+		return
+	}
+	dispatchJSONOK(&w, &jsonb)
+}
+
+// appendLookupConfiguration appends configuration config of lookup to
+// list. Rows of the same lookup are expected to be consecutive.
+func appendLookupConfiguration(list *[]LookupConfiguration, lookup string,
+	revision uint64, deleted bool, config string) error {
+	n := len(*list)
+	if n == 0 || (*list)[n-1].LookupID != lookup {
+		*list = append(*list, LookupConfiguration{
+			LookupID:       lookup,
+			Revision:       revision,
+			ETag:           lookupETag(revision),
+			Deleted:        deleted,
+			Configurations: []ConfigurationItem{},
+		})
+		n++
+	}
+	if deleted || config == `` {
+		return nil
+	}
+
+	c := ConfigurationItem{}
+	if err := json.Unmarshal([]byte(config), &c); err != nil {
+		return err
+	}
+	(*list)[n-1].Configurations = append((*list)[n-1].Configurations, c)
+	return nil
+}
+
+// touchLookup records a new revision for all lookups. It must be
+// called after every change to the configurations of a lookup. The
+// store commits new revisions one after the other, a changes feed
+// client therefore can not skip a revision that is committed late.
+func touchLookup(lookups ...string) error {
+	seen := map[string]bool{}
+	for _, lookup := range lookups {
+		if seen[lookup] {
+			continue
+		}
+		seen[lookup] = true
+		if err := Eye.run.store.TouchLookup(lookup); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lookupETag returns the entity tag of a lookup revision
+func lookupETag(revision uint64) string {
+	return fmt.Sprintf("%q", strconv.FormatUint(revision, 10))
+}
+
+// bulkETag returns the entity tag of a bulk lookup reply
+func bulkETag(lookups []LookupConfiguration) string {
+	hash := sha256.New()
+	for _, l := range lookups {
+		fmt.Fprintf(hash, "%s:%d\n", l.LookupID, l.Revision)
+	}
+	return fmt.Sprintf("%q", hex.EncodeToString(hash.Sum(nil))[:32])
+}
+
+// etagMatch reports if the If-None-Match header value header matches
+// entity tag etag
+func etagMatch(header, etag string) bool {
+	if header == `` {
+		return false
+	}
+	for _, tag := range strings.Split(header, `,`) {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), `W/`)
+		if tag == `*` || tag == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
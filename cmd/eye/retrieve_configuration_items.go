@@ -36,11 +36,12 @@ import (
 
 func RetrieveConfigurationItems(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
 	var (
-		err            error
-		reply          ConfigurationData
-		jsonb          []byte
-		lookup, config string
-		rows           *sql.Rows
+		err      error
+		reply    ConfigurationData
+		jsonb    []byte
+		lookup   string
+		configs  []string
+		revision uint64
 	)
 
 	lookup = params.ByName("lookup")
@@ -50,27 +51,28 @@ func RetrieveConfigurationItems(w http.ResponseWriter, r *http.Request, params h
 		return
 	}
 
+	// conditional requests are answered from the lookup revision
+	// without loading the configurations
+	if revision, err = Eye.run.store.LookupRevision(lookup); err == nil {
+		etag := lookupETag(revision)
+		if etagMatch(r.Header.Get(`If-None-Match`), etag) {
+			dispatchNotModified(&w, etag)
+			return
+		}
+		w.Header().Set(`ETag`, etag)
+	} else if err != sql.ErrNoRows {
+		dispatchInternalServerError(&w, err.Error())
+		return
+	}
+
 	reply.Configurations = []ConfigurationItem{}
 
-	if rows, err = Eye.run.retrieve.Query(lookup); err != nil {
-		if err == sql.ErrNoRows {
-			dispatchNotFound(&w)
-		} else {
-			dispatchInternalServerError(&w, err.Error())
-		}
+	if configs, err = Eye.run.store.LookupConfigurations(lookup); err != nil {
+		dispatchInternalServerError(&w, err.Error())
 		return
 	}
-	defer rows.Close()
-
-	for rows.Next() {
-		if err = rows.Scan(&config); err != nil {
-			if err == sql.ErrNoRows {
-				dispatchNotFound(&w)
-			} else {
-				dispatchInternalServerError(&w, err.Error())
-			}
-			return
-		}
+
+	for _, config := range configs {
 		c := ConfigurationItem{}
 		if err = json.Unmarshal([]byte(config), &c); err != nil {
 			dispatchInternalServerError(&w, err.Error())
@@ -78,14 +80,6 @@ func RetrieveConfigurationItems(w http.ResponseWriter, r *http.Request, params h
 		}
 		reply.Configurations = append(reply.Configurations, c)
 	}
-	if err = rows.Err(); err != nil {
-		if err == sql.ErrNoRows {
-			dispatchNotFound(&w)
-		} else {
-			dispatchInternalServerError(&w, err.Error())
-		}
-		return
-	}
 
 	if jsonb, err = json.Marshal(reply); err != nil {
 		dispatchInternalServerError(&w, err.Error())
@@ -22,13 +22,13 @@ INSERT INTO eye.configuration_lookup (
             host_id,
             metric)
 SELECT $1::varchar,
-       $2::numeric,
+       $2::varchar,
        $3::text
 WHERE NOT EXISTS (
     SELECT lookup_id
     FROM   eye.configuration_lookup
     WHERE  lookup_id = $1::varchar
-    OR     ( host_id = $2::numeric AND metric = $3::text));`
+    OR     ( host_id = $2::varchar AND metric = $3::text));`
 
 const stmtInsertConfigurationItem = `
 INSERT INTO eye.configuration_items (
@@ -81,4 +81,208 @@ SELECT configuration
 FROM   eye.configuration_items
 WHERE  lookup_id = $1::varchar;`
 
+const stmtMigrateHostID = `
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT column_name
+        FROM   information_schema.columns
+        WHERE  table_schema = 'eye'
+          AND  table_name = 'configuration_lookup'
+          AND  column_name = 'host_id'
+          AND  data_type = 'numeric')
+    THEN
+        ALTER TABLE eye.configuration_lookup
+            ALTER COLUMN host_id TYPE varchar(64) USING host_id::varchar;
+    END IF;
+END
+$$;`
+
+const stmtCreateItemHistory = `
+CREATE TABLE IF NOT EXISTS eye.configuration_item_history (
+    history_id                  bigserial       PRIMARY KEY,
+    configuration_item_id       uuid            NOT NULL,
+    lookup_id                   varchar(64)     NOT NULL,
+    deployment_id               uuid,
+    instance_config_id          uuid,
+    config_version              integer,
+    change                      varchar(16)     NOT NULL CHECK ( change IN ( 'added', 'updated', 'deleted' ) ),
+    changed_at                  timestamptz(3)  NOT NULL DEFAULT NOW(),
+    previous                    jsonb,
+    configuration               jsonb
+);
+CREATE INDEX IF NOT EXISTS _configuration_item_history_item
+    ON eye.configuration_item_history ( configuration_item_id, history_id );`
+
+const stmtInsertItemHistory = `
+INSERT INTO eye.configuration_item_history (
+            configuration_item_id,
+            lookup_id,
+            deployment_id,
+            instance_config_id,
+            config_version,
+            change,
+            previous,
+            configuration)
+VALUES      ( $1::uuid,
+              $2::varchar,
+              $3::uuid,
+              $4::uuid,
+              $5::integer,
+              $6::varchar,
+              $7::jsonb,
+              $8::jsonb );`
+
+const stmtGetItemHistory = `
+SELECT   history_id,
+         lookup_id,
+         deployment_id,
+         instance_config_id,
+         config_version,
+         change,
+         changed_at,
+         previous,
+         configuration
+FROM     eye.configuration_item_history
+WHERE    configuration_item_id = $1::uuid
+ORDER BY history_id DESC;`
+
+const stmtCreateFeedbackQueue = `
+CREATE TABLE IF NOT EXISTS eye.feedback_queue (
+    deployment_id               uuid            PRIMARY KEY,
+    result                      varchar(16)     NOT NULL CHECK ( result IN ( 'success', 'failed' ) ),
+    queued_at                   timestamptz(3)  NOT NULL DEFAULT NOW(),
+    attempts                    integer         NOT NULL DEFAULT 0,
+    next_attempt                timestamptz(3)  NOT NULL DEFAULT NOW(),
+    last_error                  text            NOT NULL DEFAULT ''
+);`
+
+const stmtEnqueueFeedback = `
+INSERT INTO eye.feedback_queue (
+            deployment_id,
+            result)
+VALUES      ( $1::uuid, $2::varchar )
+ON CONFLICT ( deployment_id ) DO UPDATE
+SET         result = EXCLUDED.result,
+            queued_at = NOW(),
+            attempts = 0,
+            next_attempt = NOW(),
+            last_error = '';`
+
+const stmtGetDueFeedback = `
+SELECT   deployment_id,
+         result,
+         queued_at,
+         attempts
+FROM     eye.feedback_queue
+WHERE    next_attempt <= NOW()
+ORDER BY next_attempt
+LIMIT    100;`
+
+const stmtAckFeedback = `
+DELETE FROM eye.feedback_queue
+WHERE       deployment_id = $1::uuid
+  AND       queued_at = $2::timestamptz;`
+
+const stmtRetryFeedback = `
+UPDATE eye.feedback_queue
+SET    attempts = attempts + 1,
+       next_attempt = NOW() + $3::integer * INTERVAL '1 second',
+       last_error = $4::text
+WHERE  deployment_id = $1::uuid
+  AND  queued_at = $2::timestamptz;`
+
+const stmtListFeedback = `
+SELECT   deployment_id,
+         result,
+         queued_at,
+         attempts,
+         next_attempt,
+         last_error
+FROM     eye.feedback_queue
+ORDER BY queued_at;`
+
+const stmtCreateLookupRevisions = `
+CREATE SEQUENCE IF NOT EXISTS eye.lookup_revision;
+CREATE TABLE IF NOT EXISTS eye.lookup_revisions (
+    lookup_id                   varchar(64)     PRIMARY KEY,
+    revision                    bigint          NOT NULL,
+    deleted                     boolean         NOT NULL DEFAULT false
+);
+INSERT INTO eye.lookup_revisions (
+            lookup_id,
+            revision)
+SELECT      lookup_id,
+            nextval('eye.lookup_revision')
+FROM        eye.configuration_lookup
+ON CONFLICT ( lookup_id ) DO NOTHING;`
+
+const stmtTouchLookup = `
+INSERT INTO eye.lookup_revisions (
+            lookup_id,
+            revision,
+            deleted)
+SELECT      $1::varchar,
+            nextval('eye.lookup_revision'),
+            NOT EXISTS (
+                SELECT configuration_item_id
+                FROM   eye.configuration_items
+                WHERE  lookup_id = $1::varchar)
+FROM        ( SELECT pg_advisory_xact_lock(
+                     hashtext('eye.lookup_revision')) ) serialize
+ON CONFLICT ( lookup_id ) DO UPDATE
+SET         revision = EXCLUDED.revision,
+            deleted = EXCLUDED.deleted;`
+
+const stmtGetLookupRevision = `
+SELECT revision
+FROM   eye.lookup_revisions
+WHERE  lookup_id = $1::varchar
+  AND  NOT deleted;`
+
+const stmtRetrieveConfigurationsByLookups = `
+SELECT   eci.lookup_id,
+         elr.revision,
+         eci.configuration
+FROM     eye.configuration_items eci
+JOIN     eye.lookup_revisions elr
+  ON     eci.lookup_id = elr.lookup_id
+WHERE    eci.lookup_id = ANY($1::varchar[])
+ORDER BY eci.lookup_id,
+         eci.configuration_item_id;`
+
+const stmtRetrieveConfigurationsByHost = `
+SELECT   eci.lookup_id,
+         elr.revision,
+         eci.configuration
+FROM     eye.configuration_lookup ecl
+JOIN     eye.configuration_items eci
+  ON     ecl.lookup_id = eci.lookup_id
+JOIN     eye.lookup_revisions elr
+  ON     eci.lookup_id = elr.lookup_id
+WHERE    ecl.host_id = $1::varchar
+ORDER BY eci.lookup_id,
+         eci.configuration_item_id;`
+
+const stmtGetConfigurationChanges = `
+SELECT   elr.lookup_id,
+         elr.revision,
+         elr.deleted,
+         eci.configuration
+FROM     eye.lookup_revisions elr
+LEFT JOIN eye.configuration_items eci
+  ON     elr.lookup_id = eci.lookup_id
+WHERE    elr.lookup_id IN (
+         SELECT   lookup_id
+         FROM     eye.lookup_revisions
+         WHERE    revision > $1::bigint
+         ORDER BY revision
+         LIMIT    $2::integer)
+ORDER BY elr.revision,
+         eci.configuration_item_id;`
+
+const stmtGetCurrentRevision = `
+SELECT COALESCE(MAX(revision), 0)::bigint
+FROM   eye.lookup_revisions;`
+
 // vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -0,0 +1,110 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package main
+
+import "time"
+
+// Store is the database layer of eye. Methods that read a single
+// record return sql.ErrNoRows if the record does not exist.
+type Store interface {
+	// ItemExists reports if configuration item itemID exists
+	ItemExists(itemID string) (bool, error)
+	// ItemLookup returns the lookupID of configuration item itemID
+	ItemLookup(itemID string) (string, error)
+	// ItemConfiguration returns the serialized configuration item
+	ItemConfiguration(itemID string) (string, error)
+	// ItemIDs returns the IDs of all configuration items
+	ItemIDs() ([]string, error)
+	// InsertItem stores a new configuration item
+	InsertItem(itemID, lookupID string, config []byte) error
+	// UpdateItem replaces a configuration item
+	UpdateItem(itemID, lookupID string, config []byte) error
+	// DeleteItem removes a configuration item
+	DeleteItem(itemID string) error
+
+	// LookupExists reports if lookupID exists
+	LookupExists(lookupID string) (bool, error)
+	// InsertLookup stores lookupID for metric on host hostID, unless
+	// lookupID or the host and metric combination already exist
+	InsertLookup(lookupID, hostID, metric string) error
+	// DeleteLookup removes lookupID
+	DeleteLookup(lookupID string) error
+	// LookupItemCount returns the number of items of lookupID
+	LookupItemCount(lookupID string) (int, error)
+	// LookupConfigurations returns the serialized configuration
+	// items of lookupID
+	LookupConfigurations(lookupID string) ([]string, error)
+
+	// TouchLookup records a new revision for lookupID. Revisions
+	// must become visible in increasing order.
+	TouchLookup(lookupID string) error
+	// LookupRevision returns the revision of existing lookupID
+	LookupRevision(lookupID string) (uint64, error)
+	// CurrentRevision returns the most recent revision
+	CurrentRevision() (uint64, error)
+	// BulkConfigurations returns the configuration items of all
+	// lookupIDs, ordered by lookup
+	BulkConfigurations(lookupIDs []string) ([]StoredConfiguration, error)
+	// HostConfigurations returns the configuration items of host
+	// hostID, ordered by lookup
+	HostConfigurations(hostID string) ([]StoredConfiguration, error)
+	// Changes returns the configuration items of up to limit lookups
+	// that changed after revision since, ordered by revision
+	Changes(since, limit uint64) ([]StoredConfiguration, error)
+
+	// AddHistory records a change of a configuration item
+	AddHistory(change HistoryRecord) error
+	// History returns all changes of configuration item itemID, the
+	// most recent change first
+	History(itemID string) ([]HistoryRecord, error)
+
+	// EnqueueFeedback queues feedback result for deployment id,
+	// replacing queued feedback for the same deployment
+	EnqueueFeedback(id, result string) error
+	// DueFeedback returns queued feedback due for delivery
+	DueFeedback() ([]FeedbackItem, error)
+	// AckFeedback removes delivered feedback
+	AckFeedback(id string, queuedAt time.Time) error
+	// RetryFeedback reschedules feedback delivery after delay
+	RetryFeedback(id string, queuedAt time.Time, delay time.Duration, lastError string) error
+	// Feedback returns all queued feedback
+	Feedback() ([]FeedbackItem, error)
+
+	// Close releases all resources held by the store
+	Close()
+}
+
+// StoredConfiguration is a serialized configuration item together
+// with the revision of its lookup. Configuration is empty for lookups
+// without configuration items.
+type StoredConfiguration struct {
+	LookupID      string
+	Revision      uint64
+	Deleted       bool
+	Configuration string
+}
+
+// HistoryRecord is a single change of a configuration item. Previous
+// and Configuration are the serialized item before and after the
+// change and nil if the item did not exist.
+type HistoryRecord struct {
+	HistoryID        uint64
+	ItemID           string
+	LookupID         string
+	DeploymentID     string
+	InstanceConfigID string
+	ConfigVersion    *uint64
+	Change           string
+	ChangedAt        time.Time
+	Previous         []byte
+	Configuration    []byte
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -0,0 +1,373 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package main
+
+import (
+	"database/sql"
+	"sort"
+	"sync"
+	"time"
+)
+
+// memoryStore implements Store in memory for tests, mirroring the
+// semantics of the prepared statements of postgresStore
+type memoryStore struct {
+	mux       sync.Mutex
+	items     map[string]memoryItem
+	lookups   map[string]memoryLookup
+	revisions map[string]memoryRevision
+	revision  uint64
+	history   []HistoryRecord
+	feedback  map[string]FeedbackItem
+}
+
+type memoryItem struct {
+	lookupID string
+	config   string
+}
+
+type memoryLookup struct {
+	hostID string
+	metric string
+}
+
+type memoryRevision struct {
+	revision uint64
+	deleted  bool
+}
+
+// newMemoryStore returns an empty memoryStore
+func newMemoryStore() *memoryStore {
+	return &memoryStore{
+		items:     map[string]memoryItem{},
+		lookups:   map[string]memoryLookup{},
+		revisions: map[string]memoryRevision{},
+		feedback:  map[string]FeedbackItem{},
+	}
+}
+
+func (s *memoryStore) ItemExists(itemID string) (bool, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	_, ok := s.items[itemID]
+	return ok, nil
+}
+
+func (s *memoryStore) ItemLookup(itemID string) (string, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	item, ok := s.items[itemID]
+	if !ok {
+		return ``, sql.ErrNoRows
+	}
+	return item.lookupID, nil
+}
+
+func (s *memoryStore) ItemConfiguration(itemID string) (string, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	item, ok := s.items[itemID]
+	if !ok {
+		return ``, sql.ErrNoRows
+	}
+	return item.config, nil
+}
+
+func (s *memoryStore) ItemIDs() ([]string, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.itemIDs(func(memoryItem) bool { return true }), nil
+}
+
+func (s *memoryStore) InsertItem(itemID, lookupID string, config []byte) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if _, ok := s.items[itemID]; !ok {
+		s.items[itemID] = memoryItem{lookupID: lookupID, config: string(config)}
+	}
+	return nil
+}
+
+func (s *memoryStore) UpdateItem(itemID, lookupID string, config []byte) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if _, ok := s.items[itemID]; ok {
+		s.items[itemID] = memoryItem{lookupID: lookupID, config: string(config)}
+	}
+	return nil
+}
+
+func (s *memoryStore) DeleteItem(itemID string) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	delete(s.items, itemID)
+	return nil
+}
+
+func (s *memoryStore) LookupExists(lookupID string) (bool, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	_, ok := s.lookups[lookupID]
+	return ok, nil
+}
+
+func (s *memoryStore) InsertLookup(lookupID, hostID, metric string) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	for id, l := range s.lookups {
+		if id == lookupID || (l.hostID == hostID && l.metric == metric) {
+			return nil
+		}
+	}
+	s.lookups[lookupID] = memoryLookup{hostID: hostID, metric: metric}
+	return nil
+}
+
+func (s *memoryStore) DeleteLookup(lookupID string) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	delete(s.lookups, lookupID)
+	return nil
+}
+
+func (s *memoryStore) LookupItemCount(lookupID string) (int, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return len(s.itemIDs(func(i memoryItem) bool {
+		return i.lookupID == lookupID
+	})), nil
+}
+
+func (s *memoryStore) LookupConfigurations(lookupID string) ([]string, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	configs := []string{}
+	for _, id := range s.itemIDs(func(i memoryItem) bool {
+		return i.lookupID == lookupID
+	}) {
+		configs = append(configs, s.items[id].config)
+	}
+	return configs, nil
+}
+
+func (s *memoryStore) TouchLookup(lookupID string) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.revision++
+	s.revisions[lookupID] = memoryRevision{
+		revision: s.revision,
+		deleted: len(s.itemIDs(func(i memoryItem) bool {
+			return i.lookupID == lookupID
+		})) == 0,
+	}
+	return nil
+}
+
+func (s *memoryStore) LookupRevision(lookupID string) (uint64, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	rev, ok := s.revisions[lookupID]
+	if !ok || rev.deleted {
+		return 0, sql.ErrNoRows
+	}
+	return rev.revision, nil
+}
+
+func (s *memoryStore) CurrentRevision() (uint64, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.revision, nil
+}
+
+func (s *memoryStore) BulkConfigurations(lookupIDs []string) ([]StoredConfiguration, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	selected := map[string]bool{}
+	for _, id := range lookupIDs {
+		selected[id] = true
+	}
+	return s.configurations(func(lookupID string) bool {
+		return selected[lookupID]
+	}), nil
+}
+
+func (s *memoryStore) HostConfigurations(hostID string) ([]StoredConfiguration, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.configurations(func(lookupID string) bool {
+		l, ok := s.lookups[lookupID]
+		return ok && l.hostID == hostID
+	}), nil
+}
+
+func (s *memoryStore) Changes(since, limit uint64) ([]StoredConfiguration, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	changed := []string{}
+	for id, rev := range s.revisions {
+		if rev.revision > since {
+			changed = append(changed, id)
+		}
+	}
+	sort.Slice(changed, func(i, j int) bool {
+		return s.revisions[changed[i]].revision < s.revisions[changed[j]].revision
+	})
+	if uint64(len(changed)) > limit {
+		changed = changed[:limit]
+	}
+
+	list := []StoredConfiguration{}
+	for _, lookupID := range changed {
+		rev := s.revisions[lookupID]
+		ids := s.itemIDs(func(i memoryItem) bool {
+			return i.lookupID == lookupID
+		})
+		if len(ids) == 0 {
+			list = append(list, StoredConfiguration{
+				LookupID: lookupID,
+				Revision: rev.revision,
+				Deleted:  rev.deleted,
+			})
+			continue
+		}
+		for _, id := range ids {
+			list = append(list, StoredConfiguration{
+				LookupID:      lookupID,
+				Revision:      rev.revision,
+				Deleted:       rev.deleted,
+				Configuration: s.items[id].config,
+			})
+		}
+	}
+	return list, nil
+}
+
+func (s *memoryStore) AddHistory(change HistoryRecord) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	change.HistoryID = uint64(len(s.history) + 1)
+	change.ChangedAt = time.Now().UTC()
+	s.history = append(s.history, change)
+	return nil
+}
+
+func (s *memoryStore) History(itemID string) ([]HistoryRecord, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	list := []HistoryRecord{}
+	for i := len(s.history) - 1; i >= 0; i-- {
+		if s.history[i].ItemID == itemID {
+			list = append(list, s.history[i])
+		}
+	}
+	return list, nil
+}
+
+func (s *memoryStore) EnqueueFeedback(id, result string) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	now := time.Now().UTC()
+	s.feedback[id] = FeedbackItem{
+		DeploymentID: id,
+		Result:       result,
+		QueuedAt:     now,
+		NextAttempt:  now,
+	}
+	return nil
+}
+
+func (s *memoryStore) DueFeedback() ([]FeedbackItem, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	now := time.Now().UTC()
+	due := []FeedbackItem{}
+	for _, fb := range s.feedback {
+		if !fb.NextAttempt.After(now) {
+			due = append(due, fb)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].NextAttempt.Before(due[j].NextAttempt)
+	})
+	return due, nil
+}
+
+func (s *memoryStore) AckFeedback(id string, queuedAt time.Time) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if fb, ok := s.feedback[id]; ok && fb.QueuedAt.Equal(queuedAt) {
+		delete(s.feedback, id)
+	}
+	return nil
+}
+
+func (s *memoryStore) RetryFeedback(id string, queuedAt time.Time,
+	delay time.Duration, lastError string) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if fb, ok := s.feedback[id]; ok && fb.QueuedAt.Equal(queuedAt) {
+		fb.Attempts++
+		fb.NextAttempt = time.Now().UTC().Add(delay)
+		fb.LastError = lastError
+		s.feedback[id] = fb
+	}
+	return nil
+}
+
+func (s *memoryStore) Feedback() ([]FeedbackItem, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	list := []FeedbackItem{}
+	for _, fb := range s.feedback {
+		list = append(list, fb)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].QueuedAt.Before(list[j].QueuedAt)
+	})
+	return list, nil
+}
+
+func (s *memoryStore) Close() {}
+
+// itemIDs returns the sorted IDs of all items selected by match. The
+// caller must hold the lock.
+func (s *memoryStore) itemIDs(match func(memoryItem) bool) []string {
+	ids := []string{}
+	for id, item := range s.items {
+		if match(item) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// configurations returns the configurations of all items whose
+// lookup is selected by match and has a revision, ordered by lookup.
+// The caller must hold the lock.
+func (s *memoryStore) configurations(match func(string) bool) []StoredConfiguration {
+	list := []StoredConfiguration{}
+	for _, id := range s.itemIDs(func(i memoryItem) bool {
+		_, ok := s.revisions[i.lookupID]
+		return ok && match(i.lookupID)
+	}) {
+		list = append(list, StoredConfiguration{
+			LookupID:      s.items[id].lookupID,
+			Revision:      s.revisions[s.items[id].lookupID].revision,
+			Configuration: s.items[id].config,
+		})
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].LookupID < list[j].LookupID
+	})
+	return list
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
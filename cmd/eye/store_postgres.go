@@ -0,0 +1,405 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package main
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// postgresStore implements Store with the prepared statements of the
+// eye database
+type postgresStore struct {
+	checkItem    *sql.Stmt
+	updateItem   *sql.Stmt
+	checkLookup  *sql.Stmt
+	insertLookup *sql.Stmt
+	insertItem   *sql.Stmt
+	deleteItem   *sql.Stmt
+	deleteLookup *sql.Stmt
+	getLookup    *sql.Stmt
+	itemCount    *sql.Stmt
+	getConfig    *sql.Stmt
+	getItems     *sql.Stmt
+	retrieve     *sql.Stmt
+	enqueueFb    *sql.Stmt
+	dueFb        *sql.Stmt
+	ackFb        *sql.Stmt
+	retryFb      *sql.Stmt
+	listFb       *sql.Stmt
+	touchLookup  *sql.Stmt
+	addHistory   *sql.Stmt
+	getHistory   *sql.Stmt
+	getRevision  *sql.Stmt
+	retrieveBulk *sql.Stmt
+	retrieveHost *sql.Stmt
+	getChanges   *sql.Stmt
+	curRevision  *sql.Stmt
+}
+
+// ItemExists implements Store
+func (s *postgresStore) ItemExists(itemID string) (bool, error) {
+	var id string
+	switch err := s.checkItem.QueryRow(itemID).Scan(&id); err {
+	case nil:
+		return true, nil
+	case sql.ErrNoRows:
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+// ItemLookup implements Store
+func (s *postgresStore) ItemLookup(itemID string) (lookupID string, err error) {
+	err = s.getLookup.QueryRow(itemID).Scan(&lookupID)
+	return
+}
+
+// ItemConfiguration implements Store
+func (s *postgresStore) ItemConfiguration(itemID string) (config string, err error) {
+	err = s.getConfig.QueryRow(itemID).Scan(&config)
+	return
+}
+
+// ItemIDs implements Store
+func (s *postgresStore) ItemIDs() ([]string, error) {
+	return s.strings(s.getItems)
+}
+
+// InsertItem implements Store
+func (s *postgresStore) InsertItem(itemID, lookupID string, config []byte) error {
+	_, err := s.insertItem.Exec(itemID, lookupID, config)
+	return err
+}
+
+// UpdateItem implements Store
+func (s *postgresStore) UpdateItem(itemID, lookupID string, config []byte) error {
+	_, err := s.updateItem.Exec(itemID, lookupID, config)
+	return err
+}
+
+// DeleteItem implements Store
+func (s *postgresStore) DeleteItem(itemID string) error {
+	_, err := s.deleteItem.Exec(itemID)
+	return err
+}
+
+// LookupExists implements Store
+func (s *postgresStore) LookupExists(lookupID string) (bool, error) {
+	var id string
+	switch err := s.checkLookup.QueryRow(lookupID).Scan(&id); err {
+	case nil:
+		return true, nil
+	case sql.ErrNoRows:
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+// InsertLookup implements Store
+func (s *postgresStore) InsertLookup(lookupID, hostID, metric string) error {
+	_, err := s.insertLookup.Exec(lookupID, hostID, metric)
+	return err
+}
+
+// DeleteLookup implements Store
+func (s *postgresStore) DeleteLookup(lookupID string) error {
+	_, err := s.deleteLookup.Exec(lookupID)
+	return err
+}
+
+// LookupItemCount implements Store
+func (s *postgresStore) LookupItemCount(lookupID string) (count int, err error) {
+	err = s.itemCount.QueryRow(lookupID).Scan(&count)
+	return
+}
+
+// LookupConfigurations implements Store
+func (s *postgresStore) LookupConfigurations(lookupID string) ([]string, error) {
+	return s.strings(s.retrieve, lookupID)
+}
+
+// TouchLookup implements Store. The statement holds an advisory
+// lock until the new revision is committed, revisions are therefore
+// committed in the order they are drawn from the sequence.
+func (s *postgresStore) TouchLookup(lookupID string) error {
+	_, err := s.touchLookup.Exec(lookupID)
+	return err
+}
+
+// LookupRevision implements Store
+func (s *postgresStore) LookupRevision(lookupID string) (revision uint64, err error) {
+	err = s.getRevision.QueryRow(lookupID).Scan(&revision)
+	return
+}
+
+// CurrentRevision implements Store
+func (s *postgresStore) CurrentRevision() (revision uint64, err error) {
+	err = s.curRevision.QueryRow().Scan(&revision)
+	return
+}
+
+// BulkConfigurations implements Store
+func (s *postgresStore) BulkConfigurations(lookupIDs []string) ([]StoredConfiguration, error) {
+	return s.configurations(s.retrieveBulk, false, pq.Array(lookupIDs))
+}
+
+// HostConfigurations implements Store
+func (s *postgresStore) HostConfigurations(hostID string) ([]StoredConfiguration, error) {
+	return s.configurations(s.retrieveHost, false, hostID)
+}
+
+// Changes implements Store
+func (s *postgresStore) Changes(since, limit uint64) ([]StoredConfiguration, error) {
+	return s.configurations(s.getChanges, true, int64(since), int64(limit))
+}
+
+// AddHistory implements Store
+func (s *postgresStore) AddHistory(change HistoryRecord) error {
+	var (
+		deploymentID, instanceConfigID sql.NullString
+		version                        sql.NullInt64
+		prev, curr                     sql.NullString
+	)
+
+	deploymentID.String = change.DeploymentID
+	deploymentID.Valid = change.DeploymentID != ``
+	instanceConfigID.String = change.InstanceConfigID
+	instanceConfigID.Valid = change.InstanceConfigID != ``
+	if change.ConfigVersion != nil {
+		version.Int64, version.Valid = int64(*change.ConfigVersion), true
+	}
+	if change.Previous != nil {
+		prev.String, prev.Valid = string(change.Previous), true
+	}
+	if change.Configuration != nil {
+		curr.String, curr.Valid = string(change.Configuration), true
+	}
+
+	_, err := s.addHistory.Exec(
+		change.ItemID,
+		change.LookupID,
+		deploymentID,
+		instanceConfigID,
+		version,
+		change.Change,
+		prev,
+		curr,
+	)
+	return err
+}
+
+// History implements Store
+func (s *postgresStore) History(itemID string) ([]HistoryRecord, error) {
+	var (
+		err  error
+		rows *sql.Rows
+		list []HistoryRecord
+	)
+
+	if rows, err = s.getHistory.Query(itemID); err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			change                         HistoryRecord
+			deploymentID, instanceConfigID sql.NullString
+			version                        sql.NullInt64
+			prev, curr                     sql.NullString
+		)
+		if err = rows.Scan(
+			&change.HistoryID,
+			&change.LookupID,
+			&deploymentID,
+			&instanceConfigID,
+			&version,
+			&change.Change,
+			&change.ChangedAt,
+			&prev,
+			&curr,
+		); err != nil {
+			return nil, err
+		}
+		change.ItemID = itemID
+		change.DeploymentID = deploymentID.String
+		change.InstanceConfigID = instanceConfigID.String
+		if version.Valid {
+			v := uint64(version.Int64)
+			change.ConfigVersion = &v
+		}
+		if prev.Valid {
+			change.Previous = []byte(prev.String)
+		}
+		if curr.Valid {
+			change.Configuration = []byte(curr.String)
+		}
+		list = append(list, change)
+	}
+	return list, rows.Err()
+}
+
+// EnqueueFeedback implements Store
+func (s *postgresStore) EnqueueFeedback(id, result string) error {
+	_, err := s.enqueueFb.Exec(id, result)
+	return err
+}
+
+// DueFeedback implements Store
+func (s *postgresStore) DueFeedback() ([]FeedbackItem, error) {
+	var (
+		err  error
+		rows *sql.Rows
+		due  []FeedbackItem
+	)
+
+	if rows, err = s.dueFb.Query(); err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		fb := FeedbackItem{}
+		if err = rows.Scan(
+			&fb.DeploymentID,
+			&fb.Result,
+			&fb.QueuedAt,
+			&fb.Attempts,
+		); err != nil {
+			return nil, err
+		}
+		due = append(due, fb)
+	}
+	return due, rows.Err()
+}
+
+// AckFeedback implements Store
+func (s *postgresStore) AckFeedback(id string, queuedAt time.Time) error {
+	_, err := s.ackFb.Exec(id, queuedAt)
+	return err
+}
+
+// RetryFeedback implements Store
+func (s *postgresStore) RetryFeedback(id string, queuedAt time.Time,
+	delay time.Duration, lastError string) error {
+	_, err := s.retryFb.Exec(id, queuedAt, int64(delay/time.Second),
+		lastError)
+	return err
+}
+
+// Feedback implements Store
+func (s *postgresStore) Feedback() ([]FeedbackItem, error) {
+	var (
+		err  error
+		rows *sql.Rows
+		list []FeedbackItem
+	)
+
+	if rows, err = s.listFb.Query(); err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		fb := FeedbackItem{}
+		if err = rows.Scan(
+			&fb.DeploymentID,
+			&fb.Result,
+			&fb.QueuedAt,
+			&fb.Attempts,
+			&fb.NextAttempt,
+			&fb.LastError,
+		); err != nil {
+			return nil, err
+		}
+		list = append(list, fb)
+	}
+	return list, rows.Err()
+}
+
+// Close implements Store
+func (s *postgresStore) Close() {
+	for _, st := range []*sql.Stmt{
+		s.checkItem, s.updateItem, s.checkLookup, s.insertLookup,
+		s.insertItem, s.deleteItem, s.deleteLookup, s.getLookup,
+		s.itemCount, s.getConfig, s.getItems, s.retrieve,
+		s.enqueueFb, s.dueFb, s.ackFb, s.retryFb, s.listFb,
+		s.touchLookup, s.addHistory, s.getHistory, s.getRevision,
+		s.retrieveBulk, s.retrieveHost, s.getChanges, s.curRevision,
+	} {
+		if st != nil {
+			st.Close()
+		}
+	}
+}
+
+// strings runs st and returns the single string column of all rows
+func (s *postgresStore) strings(st *sql.Stmt, args ...interface{}) ([]string, error) {
+	var (
+		err   error
+		rows  *sql.Rows
+		value string
+		list  []string
+	)
+
+	if rows, err = st.Query(args...); err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err = rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		list = append(list, value)
+	}
+	return list, rows.Err()
+}
+
+// configurations runs st and returns the stored configurations. The
+// statement returns the deleted flag if withDeleted is set.
+func (s *postgresStore) configurations(st *sql.Stmt, withDeleted bool,
+	args ...interface{}) ([]StoredConfiguration, error) {
+	var (
+		err  error
+		rows *sql.Rows
+		list []StoredConfiguration
+	)
+
+	if rows, err = st.Query(args...); err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			c      StoredConfiguration
+			config sql.NullString
+		)
+		if withDeleted {
+			err = rows.Scan(&c.LookupID, &c.Revision, &c.Deleted, &config)
+		} else {
+			err = rows.Scan(&c.LookupID, &c.Revision, &config)
+		}
+		if err != nil {
+			return nil, err
+		}
+		c.Configuration = config.String
+		list = append(list, c)
+	}
+	return list, rows.Err()
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
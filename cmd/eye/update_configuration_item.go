@@ -77,18 +77,21 @@ func UpdateConfigurationItem(w http.ResponseWriter, r *http.Request, params http
 		return
 	}
 
-	if err = updateItem(item, lookupID); err != nil {
+	if err = updateItem(item, lookupID, details); err != nil {
 		dispatchInternalServerError(&w, err.Error())
 		return
 	}
 	dispatchNoContent(&w)
 }
 
-func updateItem(item *ConfigurationItem, lookupID string) error {
+// updateItem replaces item, which is now stored under lookupID.
+// details is the deployment the update originates from and recorded
+// in the item history.
+func updateItem(item *ConfigurationItem, lookupID string, details *proto.Deployment) error {
 	var (
-		itemID string
-		err    error
-		jsonb  []byte
+		oldLookupID, previous string
+		err                   error
+		jsonb                 []byte
 	)
 
 	if jsonb, err = json.Marshal(item); err != nil {
@@ -97,19 +100,25 @@ func updateItem(item *ConfigurationItem, lookupID string) error {
 
 	// since this was an explicit update request, non-existence is a
 	// hard error
-	if err = Eye.run.checkItem.QueryRow(item.ConfigurationItemID.String()).Scan(&itemID); err != nil {
+	if oldLookupID, err = Eye.run.store.ItemLookup(item.ConfigurationItemID.String()); err != nil {
 		return err
 	}
-	if itemID != item.ConfigurationItemID.String() {
-		panic(`Database corrupted`)
+	if previous, err = Eye.run.store.ItemConfiguration(item.ConfigurationItemID.String()); err != nil {
+		return err
 	}
 
-	_, err = Eye.run.updateItem.Exec(
+	if err = Eye.run.store.UpdateItem(
 		item.ConfigurationItemID.String(),
 		lookupID,
 		jsonb,
-	)
-	return err
+	); err != nil {
+		return err
+	}
+	if err = recordHistory(item.ConfigurationItemID.String(), lookupID,
+		historyUpdated, details, []byte(previous), jsonb); err != nil {
+		return err
+	}
+	return touchLookup(oldLookupID, lookupID)
 }
 
 // vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -39,26 +39,71 @@ import (
 	"github.com/satori/go.uuid"
 )
 
-func CalculateLookupID(id uint64, metric string) string {
-	asset := strconv.FormatUint(id, 10)
+// CalculateLookupID returns the lookupID for metric on the host with
+// host identifier hostID
+func CalculateLookupID(hostID, metric string) string {
 	hash := sha256.New()
-	hash.Write([]byte(asset))
+	hash.Write([]byte(hostID))
 	hash.Write([]byte(metric))
 
 	return hex.EncodeToString(hash.Sum(nil))
 }
 
+// ItemObject returns the host identifier and the name of the object
+// details is deployed on. With the asset lookup scheme, nodes with an
+// asset ID are identified by it, all other objects by their UUID.
+func ItemObject(details *proto.Deployment) (string, string, error) {
+	id, name := deploymentObject(details)
+	if id == `` {
+		return ``, ``, fmt.Errorf("Deployment without %s object",
+			details.ObjectType)
+	}
+	if details.Node != nil && details.Node.AssetID != 0 &&
+		Eye.LookupScheme != LookupSchemeUUID {
+		return strconv.FormatUint(details.Node.AssetID, 10), name, nil
+	}
+	return id, name, nil
+}
+
+// deploymentObject returns the id and name of the node, cluster or
+// group details is deployed on
+func deploymentObject(details *proto.Deployment) (string, string) {
+	switch details.ObjectType {
+	case `cluster`:
+		if details.Cluster != nil {
+			return details.Cluster.ID, details.Cluster.Name
+		}
+	case `group`:
+		if details.Group != nil {
+			return details.Group.ID, details.Group.Name
+		}
+	default:
+		if details.Node != nil {
+			return details.Node.ID, details.Node.Name
+		}
+	}
+	return ``, ``
+}
+
 func Itemize(details *proto.Deployment) (string, *ConfigurationItem, error) {
 	var (
-		fqdn, dnsZone string
-		err           error
+		key, hostID, object string
+		err                 error
 	)
-	lookupID := CalculateLookupID(details.Node.AssetID, details.Metric.Path)
+	if hostID, object, err = ItemObject(details); err != nil {
+		return ``, nil, err
+	}
+	rule := Eye.run.rules.lookup(details.Metric.Path)
+	if key, err = rule.key(details); err != nil {
+		return ``, nil, err
+	}
+	lookupID := CalculateLookupID(hostID, key)
 
 	item := &ConfigurationItem{
-		Metric:   details.Metric.Path,
-		Interval: details.CheckConfig.Interval,
-		HostID:   strconv.FormatUint(details.Node.AssetID, 10),
+		Metric:     key,
+		Interval:   details.CheckConfig.Interval,
+		HostID:     hostID,
+		ObjectType: details.ObjectType,
 		Metadata: ConfigurationMetaData{
 			Monitoring: details.Monitoring.Name,
 			Team:       details.Team.Name,
@@ -69,53 +114,20 @@ func Itemize(details *proto.Deployment) (string, *ConfigurationItem, error) {
 		return "", nil, err
 	}
 
-	switch item.Metric {
-	case `disk.write.per.second`:
-		fallthrough
-	case `disk.read.per.second`:
-		fallthrough
-	case `disk.free`:
-		fallthrough
-	case `disk.usage.percent`:
-		mpt := GetServiceAttributeValue(details, `filesystem`)
-		if mpt == `` {
-			return ``, nil, fmt.Errorf(`Disk metric is missing filesystem service attribute`)
-		}
-		item.Metric = fmt.Sprintf("%s:%s", item.Metric, mpt)
-		// recalculate lookupID
-		lookupID = CalculateLookupID(details.Node.AssetID, item.Metric)
-	}
-
 	// set oncall duty if available
 	if details.Oncall != nil && details.Oncall.ID != "" {
 		item.Oncall = fmt.Sprintf("%s (%s)", details.Oncall.Name, details.Oncall.Number)
 	}
 
-	// construct item.Metadata.Targethost with help of system properties
-	if details.Properties != nil {
-		for _, prop := range *details.Properties {
-			switch prop.Name {
-			case "fqdn":
-				fqdn = prop.Value
-			case "dns_zone":
-				dnsZone = prop.Value
-			}
-		}
-	}
-	switch {
-	case len(fqdn) > 0:
-		item.Metadata.Targethost = fqdn
-	case len(dnsZone) > 0:
-		item.Metadata.Targethost = fmt.Sprintf("%s.%s", details.Node.Name, dnsZone)
-	default:
-		item.Metadata.Targethost = details.Node.Name
-	}
+	// construct item.Metadata.Targethost according to the
+	// itemization rule
+	item.Metadata.Targethost = rule.targethost(details)
 
 	// construct item.Metadata.Source
 	if details.Service != nil && details.Service.Name != `` {
 		item.Metadata.Source = fmt.Sprintf("%s, %s", details.Service.Name, details.CheckConfig.Name)
 	} else {
-		item.Metadata.Source = fmt.Sprintf("System (%s), %s", details.Node.Name, details.CheckConfig.Name)
+		item.Metadata.Source = fmt.Sprintf("System (%s), %s", object, details.CheckConfig.Name)
 	}
 
 	// slurp all thresholds
@@ -170,12 +182,25 @@ func dispatchNoContent(w *http.ResponseWriter) {
 	(*w).Write(nil)
 }
 
+// 304
+func dispatchNotModified(w *http.ResponseWriter, etag string) {
+	(*w).Header().Set("ETag", etag)
+	(*w).WriteHeader(http.StatusNotModified)
+	(*w).Write(nil)
+}
+
 // 400
 func dispatchBadRequest(w *http.ResponseWriter, err string) {
 	http.Error(*w, err, http.StatusBadRequest)
 	log.Println(err)
 }
 
+// 401
+func dispatchUnauthorized(w *http.ResponseWriter, err string) {
+	http.Error(*w, err, http.StatusUnauthorized)
+	log.Println(err)
+}
+
 // 404
 func dispatchNotFound(w *http.ResponseWriter) {
 	http.Error(*w, "No items found", http.StatusNotFound)
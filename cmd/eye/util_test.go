@@ -0,0 +1,170 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package main
+
+import (
+	"testing"
+
+	"github.com/mjolnir42/soma/lib/proto"
+)
+
+func TestItemize(t *testing.T) {
+	soma, _ := newFakeSoma()
+	defer soma.close()
+
+	instanceID := `a4f0e3c2-1d5b-4e6f-8a7b-9c0d1e2f3a4b`
+	tests := []struct {
+		name       string
+		scheme     string
+		mutate     func(*proto.Deployment)
+		wantErr    bool
+		hostID     string
+		metric     string
+		targethost string
+		source     string
+	}{
+		{
+			name:       `node by asset id`,
+			mutate:     func(d *proto.Deployment) {},
+			hostID:     `42`,
+			metric:     `cpu.usage.percent`,
+			targethost: `testnode`,
+			source:     `System (testnode), cpu`,
+		},
+		{
+			name:       `node by uuid`,
+			scheme:     LookupSchemeUUID,
+			mutate:     func(d *proto.Deployment) {},
+			hostID:     `4c5a8f1e-6b0d-4f8e-9d3c-2a1b0c9d8e7f`,
+			metric:     `cpu.usage.percent`,
+			targethost: `testnode`,
+			source:     `System (testnode), cpu`,
+		},
+		{
+			name: `node with fqdn and service`,
+			mutate: func(d *proto.Deployment) {
+				d.Properties = &[]proto.PropertySystem{
+					{Name: `fqdn`, Value: `testnode.example.org`},
+				}
+				d.Service = &proto.PropertyService{Name: `webserver`}
+			},
+			hostID:     `42`,
+			metric:     `cpu.usage.percent`,
+			targethost: `testnode.example.org`,
+			source:     `webserver, cpu`,
+		},
+		{
+			name: `cluster`,
+			mutate: func(d *proto.Deployment) {
+				d.ObjectType = `cluster`
+				d.Node = nil
+				d.Cluster = &proto.Cluster{
+					ID:   `7e6d5c4b-3a29-4817-a6f5-e4d3c2b1a098`,
+					Name: `testcluster`,
+				}
+			},
+			hostID:     `7e6d5c4b-3a29-4817-a6f5-e4d3c2b1a098`,
+			metric:     `cpu.usage.percent`,
+			targethost: `testcluster`,
+			source:     `System (testcluster), cpu`,
+		},
+		{
+			name: `filesystem metric`,
+			mutate: func(d *proto.Deployment) {
+				d.Metric.Path = `disk.free`
+				d.Service = &proto.PropertyService{
+					Name: `filesystem`,
+					Attributes: []proto.ServiceAttribute{
+						{Name: `filesystem`, Value: `/var`},
+					},
+				}
+			},
+			hostID:     `42`,
+			metric:     `disk.free:/var`,
+			targethost: `testnode`,
+			source:     `filesystem, cpu`,
+		},
+		{
+			name: `filesystem metric without attribute`,
+			mutate: func(d *proto.Deployment) {
+				d.Metric.Path = `disk.free`
+			},
+			wantErr: true,
+		},
+		{
+			name: `missing object`,
+			mutate: func(d *proto.Deployment) {
+				d.Node = nil
+			},
+			wantErr: true,
+		},
+		{
+			name: `invalid instance id`,
+			mutate: func(d *proto.Deployment) {
+				d.CheckInstance.InstanceID = `not-a-uuid`
+			},
+			wantErr: true,
+		},
+		{
+			name: `validation failure`,
+			mutate: func(d *proto.Deployment) {
+				d.Team.Name = ``
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		Eye.LookupScheme = tt.scheme
+		d := testDeployment(instanceID, `rollout`)
+		tt.mutate(&d)
+
+		lookupID, item, err := Itemize(&d)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s: expected error, got item %v", tt.name, item)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", tt.name, err.Error())
+			continue
+		}
+		if item.ConfigurationItemID.String() != instanceID {
+			t.Errorf("%s: item id %s, expected %s", tt.name,
+				item.ConfigurationItemID.String(), instanceID)
+		}
+		if item.HostID != tt.hostID {
+			t.Errorf("%s: host id %s, expected %s", tt.name,
+				item.HostID, tt.hostID)
+		}
+		if item.Metric != tt.metric {
+			t.Errorf("%s: metric %s, expected %s", tt.name,
+				item.Metric, tt.metric)
+		}
+		if lookupID != CalculateLookupID(tt.hostID, tt.metric) {
+			t.Errorf("%s: wrong lookup id %s", tt.name, lookupID)
+		}
+		if item.Metadata.Targethost != tt.targethost {
+			t.Errorf("%s: targethost %s, expected %s", tt.name,
+				item.Metadata.Targethost, tt.targethost)
+		}
+		if item.Metadata.Source != tt.source {
+			t.Errorf("%s: source %s, expected %s", tt.name,
+				item.Metadata.Source, tt.source)
+		}
+		if len(item.Thresholds) != 1 || item.Thresholds[0].Value != 90 {
+			t.Errorf("%s: wrong thresholds %v", tt.name, item.Thresholds)
+		}
+	}
+	Eye.LookupScheme = ``
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
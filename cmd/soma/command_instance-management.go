@@ -10,9 +10,12 @@ package main
 
 import (
 	"fmt"
+	"net/url"
+	"strconv"
 
 	"github.com/codegangsta/cli"
 	"github.com/mjolnir42/soma/internal/adm"
+	"github.com/mjolnir42/soma/internal/cmpl"
 )
 
 func registerInstanceMgmt(app cli.App) *cli.App {
@@ -33,9 +36,10 @@ func registerInstanceMgmt(app cli.App) *cli.App {
 						Action: runtime(cmdInstanceMgmtShow),
 					},
 					{
-						Name:   `versions`,
-						Usage:  `Show version history of a check instance`,
-						Action: runtime(cmdInstanceMgmtVersion),
+						Name:         `versions`,
+						Usage:        `Show version history of a check instance, or the changes between two versions`,
+						Action:       runtime(cmdInstanceMgmtVersion),
+						BashComplete: cmpl.InstanceMgmtVersions,
 					},
 				},
 			},
@@ -65,16 +69,65 @@ func cmdInstanceMgmtShow(c *cli.Context) error {
 	return adm.Perform(`get`, path, `show`, nil, c)
 }
 
+// cmdInstanceMgmtVersion function
+// soma instance-mgmt versions ${instanceID} [ diff ${version}|latest ] \
+//      [ against ${version} ]
 func cmdInstanceMgmtVersion(c *cli.Context) error {
-	if err := adm.VerifySingleArgument(c); err != nil {
-		return err
+	var err error
+	opts := map[string][]string{}
+	multipleAllowed := []string{}
+	uniqueOptions := []string{`diff`, `against`}
+	mandatoryOptions := []string{}
+
+	if c.NArg() == 0 {
+		return fmt.Errorf(`Syntax error, missing instance ID`)
 	}
 	if !adm.IsUUID(c.Args().First()) {
 		return fmt.Errorf("Argument is not a UUID: %s",
 			c.Args().First())
 	}
 
-	path := fmt.Sprintf("/instance/%s/versions", c.Args().First())
+	if err = adm.ParseVariadicArguments(
+		opts,
+		multipleAllowed,
+		uniqueOptions,
+		mandatoryOptions,
+		c.Args().Tail(),
+	); err != nil {
+		return err
+	}
+
+	_, diff := opts[`diff`]
+	_, against := opts[`against`]
+	if !diff && !against {
+		path := fmt.Sprintf("/instance/%s/versions", c.Args().First())
+		return adm.Perform(`get`, path, `show`, nil, c)
+	}
+
+	query := url.Values{}
+	if diff && opts[`diff`][0] != `latest` {
+		var version uint64
+		if err = adm.ValidateLBoundUint64(
+			opts[`diff`][0], &version, 1,
+		); err != nil {
+			return err
+		}
+		query.Set(`version`, strconv.FormatUint(version, 10))
+	}
+	if against {
+		var version uint64
+		if err = adm.ValidateLBoundUint64(
+			opts[`against`][0], &version, 1,
+		); err != nil {
+			return err
+		}
+		query.Set(`against`, strconv.FormatUint(version, 10))
+	}
+
+	path := fmt.Sprintf("/deployment/id/%s/diff", c.Args().First())
+	if len(query) > 0 {
+		path = path + `?` + query.Encode()
+	}
 	return adm.Perform(`get`, path, `show`, nil, c)
 }
 
@@ -0,0 +1,203 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package main // import "github.com/mjolnir42/soma/cmd/soma"
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/codegangsta/cli"
+	"github.com/mjolnir42/soma/internal/adm"
+	"github.com/mjolnir42/soma/internal/cmpl"
+	"github.com/mjolnir42/soma/internal/help"
+	"github.com/mjolnir42/soma/lib/proto"
+)
+
+func registerMaintenance(app cli.App) *cli.App {
+	app.Commands = append(app.Commands,
+		[]cli.Command{
+			{
+				Name:        `maintenance`,
+				Usage:       `SUBCOMMANDS for maintenance windows`,
+				Description: help.Text(`maintenance::`),
+				Subcommands: []cli.Command{
+					{
+						Name:         `create`,
+						Usage:        `Create a new maintenance window`,
+						Description:  help.Text(`maintenance::create`),
+						Action:       runtime(maintenanceCreate),
+						BashComplete: cmpl.MaintenanceCreate,
+					},
+					{
+						Name:        `cancel`,
+						Usage:       `Cancel a maintenance window`,
+						Description: help.Text(`maintenance::cancel`),
+						Action:      runtime(maintenanceCancel),
+					},
+					{
+						Name:        `list`,
+						Usage:       `List all current and upcoming maintenance windows`,
+						Description: help.Text(`maintenance::list`),
+						Action:      runtime(maintenanceList),
+					},
+					{
+						Name:        `show`,
+						Usage:       `Show details about a maintenance window`,
+						Description: help.Text(`maintenance::show`),
+						Action:      runtime(maintenanceShow),
+					},
+				},
+			},
+		}...,
+	)
+	return &app
+}
+
+// maintenanceCreate function
+// soma maintenance create ${description} \
+//      repository|bucket|environment|monitoring ${target} \
+//      from ${start} \
+//      until ${end} \
+//      [hold-jobs ${bool}]
+func maintenanceCreate(c *cli.Context) error {
+	opts := map[string][]string{}
+	multipleAllowed := []string{}
+	uniqueOptions := []string{
+		proto.MaintenanceScopeRepository,
+		proto.MaintenanceScopeBucket,
+		proto.MaintenanceScopeEnvironment,
+		proto.MaintenanceScopeMonitoring,
+		`from`,
+		`until`,
+		`hold-jobs`,
+	}
+	mandatoryOptions := []string{`from`, `until`}
+
+	if err := adm.ParseVariadicArguments(
+		opts,
+		multipleAllowed,
+		uniqueOptions,
+		mandatoryOptions,
+		c.Args().Tail(),
+	); err != nil {
+		return err
+	}
+
+	req := proto.NewMaintenanceWindowRequest()
+	req.MaintenanceWindow.Description = c.Args().First()
+
+	var err error
+	for _, scope := range []string{
+		proto.MaintenanceScopeRepository,
+		proto.MaintenanceScopeBucket,
+		proto.MaintenanceScopeEnvironment,
+		proto.MaintenanceScopeMonitoring,
+	} {
+		if _, ok := opts[scope]; !ok {
+			continue
+		}
+		if req.MaintenanceWindow.Scope != `` {
+			return fmt.Errorf("Maintenance window can only have"+
+				" one scope, found %s and %s",
+				req.MaintenanceWindow.Scope, scope)
+		}
+		req.MaintenanceWindow.Scope = scope
+
+		switch scope {
+		case proto.MaintenanceScopeRepository:
+			if req.MaintenanceWindow.RepositoryID, err = adm.LookupRepoID(
+				opts[scope][0]); err != nil {
+				return err
+			}
+		case proto.MaintenanceScopeBucket:
+			if req.MaintenanceWindow.BucketID, err = adm.LookupBucketID(
+				opts[scope][0]); err != nil {
+				return err
+			}
+		case proto.MaintenanceScopeEnvironment:
+			if err = adm.ValidateEnvironment(opts[scope][0]); err != nil {
+				return err
+			}
+			req.MaintenanceWindow.Environment = opts[scope][0]
+		case proto.MaintenanceScopeMonitoring:
+			if req.MaintenanceWindow.MonitoringID, err = adm.LookupMonitoringID(
+				opts[scope][0]); err != nil {
+				return err
+			}
+		}
+	}
+	if req.MaintenanceWindow.Scope == `` {
+		return fmt.Errorf("Maintenance window requires one of the" +
+			" scopes repository, bucket, environment or monitoring")
+	}
+
+	for _, ts := range []string{`from`, `until`} {
+		if _, err = time.Parse(time.RFC3339, opts[ts][0]); err != nil {
+			return fmt.Errorf("Invalid RFC3339 timestamp for %s: %s",
+				ts, err.Error())
+		}
+	}
+	req.MaintenanceWindow.StartsAt = opts[`from`][0]
+	req.MaintenanceWindow.EndsAt = opts[`until`][0]
+
+	if _, ok := opts[`hold-jobs`]; ok {
+		if err = adm.ValidateBool(opts[`hold-jobs`][0],
+			&req.MaintenanceWindow.HoldJobs); err != nil {
+			return err
+		}
+	}
+
+	return adm.Perform(`postbody`, `/maintenance/`, `command`, req, c)
+}
+
+// maintenanceCancel function
+// soma maintenance cancel ${id}
+func maintenanceCancel(c *cli.Context) error {
+	if err := adm.VerifySingleArgument(c); err != nil {
+		return err
+	}
+
+	if err := adm.ValidateUUID(c.Args().First()); err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf("/maintenance/%s",
+		url.QueryEscape(c.Args().First()))
+	return adm.Perform(`delete`, path, `command`, nil, c)
+}
+
+// maintenanceList function
+// soma maintenance list
+func maintenanceList(c *cli.Context) error {
+	if err := adm.VerifyNoArgument(c); err != nil {
+		return err
+	}
+
+	return adm.Perform(`get`, `/maintenance/`, `list`, nil, c)
+}
+
+// maintenanceShow function
+// soma maintenance show ${id}
+func maintenanceShow(c *cli.Context) error {
+	if err := adm.VerifySingleArgument(c); err != nil {
+		return err
+	}
+
+	if err := adm.ValidateUUID(c.Args().First()); err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf("/maintenance/%s",
+		url.QueryEscape(c.Args().First()))
+	return adm.Perform(`get`, path, `show`, nil, c)
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -40,6 +40,13 @@ func registerMonitoringMgmt(app cli.App) *cli.App {
 						Description: help.Text(`monitoringsystem-mgmt::remove`),
 						Action:      runtime(monitoringMgmtRemove),
 					},
+					{
+						Name:         `secret`,
+						Usage:        `Set the shared secret of a monitoring system`,
+						Description:  help.Text(`monitoringsystem-mgmt::secret`),
+						Action:       runtime(monitoringMgmtSecret),
+						BashComplete: cmpl.MonitoringMgmtSecret,
+					},
 					{
 						Name:        `list`,
 						Usage:       `List monitoring systems`,
@@ -67,12 +74,14 @@ func registerMonitoringMgmt(app cli.App) *cli.App {
 
 // monitoringMgmtAdd function
 // soma monitoringsystem-mgmt add ${name} mode ${mode} contact ${user} \
-//      team ${team} [ callback ${callback} ]
+//      team ${team} [ callback ${callback} ] [ batchsize ${num} ] \
+//      [ flush ${seconds} ] [ secret ${secret} ]
 func monitoringMgmtAdd(c *cli.Context) error {
 	var err error
 	opts := map[string][]string{}
 	multipleAllowed := []string{}
-	uniqueOptions := []string{`mode`, `contact`, `team`, `callback`}
+	uniqueOptions := []string{`mode`, `contact`, `team`, `callback`,
+		`batchsize`, `flush`, `secret`}
 	mandatoryOptions := []string{`mode`, `contact`, `team`}
 
 	if err = adm.ParseVariadicArguments(
@@ -125,6 +134,25 @@ func monitoringMgmtAdd(c *cli.Context) error {
 		}
 		req.Monitoring.Callback = opts[`callback`][0]
 	}
+	if _, ok := opts[`batchsize`]; ok {
+		if err = adm.ValidateLBoundUint64(
+			opts[`batchsize`][0],
+			&req.Monitoring.BatchSize, 0,
+		); err != nil {
+			return err
+		}
+	}
+	if _, ok := opts[`flush`]; ok {
+		if err = adm.ValidateLBoundUint64(
+			opts[`flush`][0],
+			&req.Monitoring.BatchFlush, 0,
+		); err != nil {
+			return err
+		}
+	}
+	if _, ok := opts[`secret`]; ok {
+		req.Monitoring.Secret = opts[`secret`][0]
+	}
 
 	return adm.Perform(`postbody`, `/monitoringsystem/`, `command`, req, c)
 }
@@ -152,4 +180,45 @@ func monitoringMgmtRemove(c *cli.Context) error {
 	return adm.Perform(`delete`, path, `command`, nil, c)
 }
 
+// monitoringMgmtSecret function
+// soma monitoringsystem-mgmt secret ${name} [ value ${secret} ]
+func monitoringMgmtSecret(c *cli.Context) error {
+	var err error
+	opts := map[string][]string{}
+	multipleAllowed := []string{}
+	uniqueOptions := []string{`value`}
+	mandatoryOptions := []string{}
+
+	if err = adm.ParseVariadicArguments(
+		opts,
+		multipleAllowed,
+		uniqueOptions,
+		mandatoryOptions,
+		c.Args().Tail(),
+	); err != nil {
+		return err
+	}
+
+	if err = adm.ValidateNoSlash(c.Args().First()); err != nil {
+		return err
+	}
+
+	monitoringID, err := adm.LookupMonitoringID(c.Args().First())
+	if err != nil {
+		return err
+	}
+
+	req := proto.NewMonitoringRequest()
+	req.Monitoring.ID = monitoringID
+	if _, ok := opts[`value`]; ok {
+		req.Monitoring.Secret = opts[`value`][0]
+	}
+
+	path := fmt.Sprintf(
+		"/monitoringsystem/%s/secret",
+		url.QueryEscape(monitoringID),
+	)
+	return adm.Perform(`patchbody`, path, `command`, req, c)
+}
+
 // vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -58,6 +58,25 @@ func registerRepository(app cli.App) *cli.App {
 						Description: help.Text(`repository-config::list`),
 						Action:      runtime(repositoryConfigList),
 					},
+					{
+						Name:        `rollback`,
+						Usage:       `SUBCOMMANDS for automatic rollback of failed rollouts`,
+						Description: help.Text(`repository-config::rollback`),
+						Subcommands: []cli.Command{
+							{
+								Name:        `disable`,
+								Usage:       `Disable automatic rollback for a repository`,
+								Description: help.Text(`repository-config::rollback`),
+								Action:      runtime(repositoryConfigRollbackDisable),
+							},
+							{
+								Name:        `enable`,
+								Usage:       `Enable automatic rollback for a repository`,
+								Description: help.Text(`repository-config::rollback`),
+								Action:      runtime(repositoryConfigRollbackEnable),
+							},
+						},
+					},
 					{
 						Name:         `show`,
 						Usage:        `Show information about a specific repository`,
@@ -0,0 +1,109 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package main // import "github.com/mjolnir42/soma/cmd/soma"
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/codegangsta/cli"
+	"github.com/mjolnir42/soma/internal/adm"
+	"github.com/mjolnir42/soma/internal/help"
+	"github.com/mjolnir42/soma/lib/proto"
+)
+
+func registerOutbox(app cli.App) *cli.App {
+	app.Commands = append(app.Commands,
+		[]cli.Command{
+			{
+				Name:        `outbox`,
+				Usage:       `SUBCOMMANDS for monitoring system notification outboxes`,
+				Description: help.Text(`outbox::`),
+				Subcommands: []cli.Command{
+					{
+						Name:        `list`,
+						Usage:       `List undelivered notifications of a monitoring system`,
+						Description: help.Text(`outbox::list`),
+						Action:      runtime(outboxList),
+					},
+					{
+						Name:        `replay`,
+						Usage:       `Redeliver all undelivered notifications of a monitoring system`,
+						Description: help.Text(`outbox::replay`),
+						Action:      runtime(outboxReplay),
+					},
+					{
+						Name:        `purge`,
+						Usage:       `Remove all undelivered notifications of a monitoring system`,
+						Description: help.Text(`outbox::purge`),
+						Action:      runtime(outboxPurge),
+					},
+				},
+			},
+		}...,
+	)
+	return &app
+}
+
+// outboxList function
+// soma outbox list ${monitoring}
+func outboxList(c *cli.Context) (err error) {
+	if err = adm.VerifySingleArgument(c); err != nil {
+		return err
+	}
+
+	var id, path string
+	if id, err = adm.LookupMonitoringID(
+		c.Args().First()); err != nil {
+		return err
+	}
+	path = fmt.Sprintf("/monitoringsystem/%s/outbox/",
+		url.QueryEscape(id))
+
+	return adm.Perform(`get`, path, `list`, nil, c)
+}
+
+// outboxReplay function
+// soma outbox replay ${monitoring}
+func outboxReplay(c *cli.Context) (err error) {
+	if err = adm.VerifySingleArgument(c); err != nil {
+		return err
+	}
+
+	req := proto.NewMonitoringRequest()
+	if req.Monitoring.ID, err = adm.LookupMonitoringID(
+		c.Args().First()); err != nil {
+		return err
+	}
+	path := fmt.Sprintf("/monitoringsystem/%s/outbox/replay",
+		url.QueryEscape(req.Monitoring.ID))
+
+	return adm.Perform(`patchbody`, path, `command`, req, c)
+}
+
+// outboxPurge function
+// soma outbox purge ${monitoring}
+func outboxPurge(c *cli.Context) (err error) {
+	if err = adm.VerifySingleArgument(c); err != nil {
+		return err
+	}
+
+	var id, path string
+	if id, err = adm.LookupMonitoringID(
+		c.Args().First()); err != nil {
+		return err
+	}
+	path = fmt.Sprintf("/monitoringsystem/%s/outbox/",
+		url.QueryEscape(id))
+
+	return adm.Perform(`delete`, path, `command`, nil, c)
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
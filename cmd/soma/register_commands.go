@@ -54,11 +54,13 @@ func registerCommands(app cli.App) *cli.App {
 	app = *registerInstances(app)
 	app = *registerJobs(app)
 	app = *registerLevels(app)
+	app = *registerMaintenance(app)
 	app = *registerMetrics(app)
 	app = *registerModes(app)
 	app = *registerMonitoringMgmt(app)
 	app = *registerNodes(app)
 	app = *registerOncall(app)
+	app = *registerOutbox(app)
 	app = *registerPermissions(app)
 	app = *registerPredicates(app)
 	app = *registerProperty(app)
@@ -43,6 +43,10 @@ func registerFlags(app cli.App) *cli.App {
 			Name:  "volatile, o",
 			Usage: "Do not ensure that the BoltDB structure exists",
 		},
+		cli.BoolFlag{
+			Name:  "dry-run, n",
+			Usage: "Compute tree changes without applying them",
+		},
 		cli.BoolFlag{
 			Name:   `doublelogout`,
 			Usage:  `(internal) logout called without actually being logged in`,
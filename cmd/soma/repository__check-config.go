@@ -27,6 +27,13 @@ func registerChecks(app cli.App) *cli.App {
 				Name:  `check-config`,
 				Usage: `SUBCOMMANDS for check configuration management`,
 				Subcommands: []cli.Command{
+					{
+						Name:         `apply`,
+						Usage:        `Apply a check configuration file to its repository`,
+						Description:  help.Text(`check-config::apply`),
+						Action:       runtime(checkConfigApply),
+						BashComplete: cmpl.None,
+					},
 					{
 						Name:         `create`,
 						Usage:        `Create a new check configuration`,
@@ -41,6 +48,20 @@ func registerChecks(app cli.App) *cli.App {
 						Action:       runtime(checkConfigDestroy),
 						BashComplete: cmpl.CheckConfigDestroy,
 					},
+					{
+						Name:         `diff`,
+						Usage:        `Show the changes check-config apply would make`,
+						Description:  help.Text(`check-config::apply`),
+						Action:       runtime(checkConfigDiff),
+						BashComplete: cmpl.None,
+					},
+					{
+						Name:         `export`,
+						Usage:        `Export the check configurations of a repository`,
+						Description:  help.Text(`check-config::export`),
+						Action:       runtime(checkConfigExport),
+						BashComplete: cmpl.DirectIn,
+					},
 					{
 						Name:         `list`,
 						Usage:        `List check configurations in a repository`,
@@ -48,6 +69,55 @@ func registerChecks(app cli.App) *cli.App {
 						Action:       runtime(checkConfigList),
 						BashComplete: cmpl.DirectIn,
 					},
+					{
+						Name:        `override`,
+						Usage:       `SUBCOMMANDS for per-object threshold overrides`,
+						Description: help.Text(`check-config::override`),
+						Subcommands: []cli.Command{
+							{
+								Name:         `remove`,
+								Usage:        `Remove the threshold override of a check configuration from an object`,
+								Description:  help.Text(`check-config::override`),
+								Action:       runtime(checkConfigOverrideRemove),
+								BashComplete: cmpl.CheckConfigOverrideRemove,
+							},
+							{
+								Name:         `set`,
+								Usage:        `Override the thresholds of a check configuration on an object`,
+								Description:  help.Text(`check-config::override`),
+								Action:       runtime(checkConfigOverrideSet),
+								BashComplete: cmpl.CheckConfigOverrideSet,
+							},
+						},
+					},
+					{
+						Name:         `resume`,
+						Usage:        `Resume the halted rollout of a check configuration`,
+						Description:  help.Text(`check-config::resume`),
+						Action:       runtime(checkConfigResume),
+						BashComplete: cmpl.In,
+					},
+					{
+						Name:        `rollback`,
+						Usage:       `SUBCOMMANDS for automatic rollback of failed rollouts`,
+						Description: help.Text(`check-config::rollback`),
+						Subcommands: []cli.Command{
+							{
+								Name:         `disable`,
+								Usage:        `Disable automatic rollback for a check configuration`,
+								Description:  help.Text(`check-config::rollback`),
+								Action:       runtime(checkConfigRollbackDisable),
+								BashComplete: cmpl.In,
+							},
+							{
+								Name:         `enable`,
+								Usage:        `Enable automatic rollback for a check configuration`,
+								Description:  help.Text(`check-config::rollback`),
+								Action:       runtime(checkConfigRollbackEnable),
+								BashComplete: cmpl.In,
+							},
+						},
+					},
 					{
 						Name:         `show`,
 						Usage:        `Show details about a check configuration`,
@@ -55,6 +125,13 @@ func registerChecks(app cli.App) *cli.App {
 						Action:       runtime(checkConfigShow),
 						BashComplete: cmpl.In,
 					},
+					{
+						Name:         `update`,
+						Usage:        `Update interval or thresholds of a check configuration`,
+						Description:  help.Text(`check-config::update`),
+						Action:       runtime(checkConfigUpdate),
+						BashComplete: cmpl.CheckConfigUpdate,
+					},
 				},
 			},
 		}...,
@@ -65,14 +142,11 @@ func registerChecks(app cli.App) *cli.App {
 // checkConfigCreate function
 // soma check-config create ...
 func checkConfigCreate(c *cli.Context) error {
-	var err error
-	var teamID string
 	opts := map[string][]string{}
 	constraints := []proto.CheckConfigConstraint{}
 	thresholds := []proto.CheckConfigThreshold{}
-	req := proto.NewCheckConfigRequest()
 
-	if err = adm.ParseVariadicCheckArguments(
+	if err := adm.ParseVariadicCheckArguments(
 		opts,
 		&constraints,
 		&thresholds,
@@ -80,72 +154,95 @@ func checkConfigCreate(c *cli.Context) error {
 	); err != nil {
 		return err
 	}
+
+	req, err := checkConfigRequest(c.Args().First(), opts,
+		constraints, thresholds)
+	if err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf("/checkconfig/%s/",
+		url.QueryEscape(req.CheckConfig.RepositoryID),
+	)
+	return adm.Perform(`postbody`, path, `check-config::create`, req, c)
+}
+
+// checkConfigRequest builds the request to create the check
+// configuration name from the arguments parsed by
+// adm.ParseVariadicCheckArguments
+func checkConfigRequest(name string, opts map[string][]string,
+	constraints []proto.CheckConfigConstraint,
+	thresholds []proto.CheckConfigThreshold) (proto.Request, error) {
+	var err error
+	var teamID string
+	req := proto.NewCheckConfigRequest()
+
 	if err = adm.ValidateLBoundUint64(opts[`interval`][0],
 		&req.CheckConfig.Interval, 1); err != nil {
-		return err
+		return req, err
 	}
 
-	if err = adm.ValidateRuneCount(c.Args().First(), 256); err != nil {
-		return err
+	if err = adm.ValidateRuneCount(name, 256); err != nil {
+		return req, err
 	}
 
 	if req.CheckConfig.CapabilityID, err = adm.LookupCapabilityID(
 		opts[`with`][0]); err != nil {
-		return err
+		return req, err
 	}
 
 	req.CheckConfig.ObjectType = opts[`on/type`][0]
-	req.CheckConfig.Name = c.Args().First()
+	req.CheckConfig.Name = name
 	if err = adm.ValidateNotUUID(req.CheckConfig.Name); err != nil {
-		return err
+		return req, err
 	}
 
 	switch req.CheckConfig.ObjectType {
 	case `repository`:
 		if req.CheckConfig.RepositoryID, err = adm.LookupRepoID(opts[`on/object`][0]); err != nil {
-			return err
+			return req, err
 		}
 		req.CheckConfig.ObjectID = req.CheckConfig.RepositoryID
 	case `bucket`:
 		if req.CheckConfig.BucketID, err = adm.LookupBucketID(opts[`on/object`][0]); err != nil {
-			return err
+			return req, err
 		}
 		if req.CheckConfig.RepositoryID, err = adm.LookupRepoByBucket(req.CheckConfig.BucketID); err != nil {
-			return err
+			return req, err
 		}
 		req.CheckConfig.ObjectID = req.CheckConfig.BucketID
 	case `node`:
 		if req.CheckConfig.ObjectID, err = adm.LookupNodeID(opts[`on/object`][0]); err != nil {
-			return err
+			return req, err
 		}
 		config := &proto.NodeConfig{}
 		if config, err = adm.LookupNodeConfig(req.CheckConfig.ObjectID); err != nil {
-			return err
+			return req, err
 		}
 		req.CheckConfig.BucketID = config.BucketID
 		req.CheckConfig.RepositoryID = config.RepositoryID
 	case `group`, `cluster`:
 		if req.CheckConfig.BucketID, err = adm.LookupBucketID(opts[`in`][0]); err != nil {
-			return err
+			return req, err
 		}
 		if req.CheckConfig.RepositoryID, err = adm.LookupRepoByBucket(req.CheckConfig.BucketID); err != nil {
-			return err
+			return req, err
 		}
 		if req.CheckConfig.ObjectID, err = adm.LookupCheckObjectID(
 			req.CheckConfig.ObjectType, opts[`on/object`][0],
 			req.CheckConfig.BucketID,
 		); err != nil {
-			return err
+			return req, err
 		}
 	default:
-		return fmt.Errorf("Unknown object entity: %s", req.CheckConfig.ObjectType)
+		return req, fmt.Errorf("Unknown object entity: %s", req.CheckConfig.ObjectType)
 	}
 
 	// optional argument: inheritance
 	if iv, ok := opts[`inheritance`]; ok {
 		if err = adm.ValidateBool(iv[0],
 			&req.CheckConfig.Inheritance); err != nil {
-			return err
+			return req, err
 		}
 	} else {
 		// inheritance defaults to true
@@ -156,7 +253,7 @@ func checkConfigCreate(c *cli.Context) error {
 	if co, ok := opts[`childrenonly`]; ok {
 		if err = adm.ValidateBool(co[0],
 			&req.CheckConfig.ChildrenOnly); err != nil {
-			return err
+			return req, err
 		}
 	} else {
 		// childrenonly defaults to false
@@ -166,20 +263,31 @@ func checkConfigCreate(c *cli.Context) error {
 	// optional argument: extern
 	if ex, ok := opts[`extern`]; ok {
 		if err = adm.ValidateRuneCount(ex[0], 64); err != nil {
-			return err
+			return req, err
 		}
 		req.CheckConfig.ExternalID = ex[0]
 	}
 
+	// optional argument: rollout
+	if _, ok := opts[`rollout`]; ok {
+		if req.CheckConfig.Rollout, err = adm.ValidateRollout(
+			opts[`rollout/wave`][0],
+			opts[`rollout/soak`][0],
+			opts[`rollout/halt`][0],
+		); err != nil {
+			return req, err
+		}
+	}
+
 	if err = adm.LookupTeamByRepo(
 		req.CheckConfig.RepositoryID, &teamID); err != nil {
-		return err
+		return req, err
 	}
 
 	if req.CheckConfig.Thresholds, err = adm.ValidateThresholds(
 		thresholds,
 	); err != nil {
-		return err
+		return req, err
 	}
 
 	if req.CheckConfig.Constraints, err = adm.ValidateCheckConstraints(
@@ -187,13 +295,10 @@ func checkConfigCreate(c *cli.Context) error {
 		teamID,
 		constraints,
 	); err != nil {
-		return err
+		return req, err
 	}
 
-	path := fmt.Sprintf("/checkconfig/%s/",
-		url.QueryEscape(req.CheckConfig.RepositoryID),
-	)
-	return adm.Perform(`postbody`, path, `check-config::create`, req, c)
+	return req, nil
 }
 
 // checkConfigDestroy function
@@ -308,4 +413,268 @@ func checkConfigShow(c *cli.Context) error {
 	return adm.Perform(`get`, path, `check-config::list`, nil, c)
 }
 
+// checkConfigUpdate function
+// soma check-config update ${name} in ${repository} [interval ${interval}] [threshold ...]
+func checkConfigUpdate(c *cli.Context) error {
+	var err error
+	var repoID, checkID string
+	opts := map[string][]string{}
+	thresholds := []proto.CheckConfigThreshold{}
+
+	if err = adm.ParseVariadicCheckUpdateArguments(
+		opts,
+		&thresholds,
+		c.Args().Tail(),
+	); err != nil {
+		return err
+	}
+	if _, ok := opts[`interval`]; !ok && len(thresholds) == 0 {
+		return fmt.Errorf("Syntax error, missing keyword: interval" +
+			" or threshold")
+	}
+
+	if repoID, err = adm.LookupRepoID(opts[`in`][0]); err != nil {
+		return err
+	}
+	if checkID, _, err = adm.LookupCheckConfigID(c.Args().First(),
+		repoID, ``); err != nil {
+		return err
+	}
+
+	// the update replaces interval and thresholds, start from the
+	// current values for the ones that are not changed
+	var current *proto.CheckConfig
+	if current, err = fetchCheckConfig(repoID, checkID); err != nil {
+		return err
+	}
+	req, err := checkConfigUpdateRequest(current, opts, thresholds)
+	if err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf("/checkconfig/%s/%s",
+		url.QueryEscape(repoID),
+		url.QueryEscape(checkID),
+	)
+	return adm.Perform(`putbody`, path, `command`, req, c)
+}
+
+// checkConfigUpdateRequest builds the request to update check
+// configuration current with the arguments parsed by
+// adm.ParseVariadicCheckUpdateArguments
+func checkConfigUpdateRequest(current *proto.CheckConfig,
+	opts map[string][]string,
+	thresholds []proto.CheckConfigThreshold) (proto.Request, error) {
+	var err error
+	req := proto.NewCheckConfigRequest()
+	req.CheckConfig.Interval = current.Interval
+	req.CheckConfig.Thresholds = current.Thresholds
+
+	if iv, ok := opts[`interval`]; ok {
+		if err = adm.ValidateLBoundUint64(iv[0],
+			&req.CheckConfig.Interval, 1); err != nil {
+			return req, err
+		}
+	}
+	if len(thresholds) > 0 {
+		if req.CheckConfig.Thresholds, err = adm.ValidateThresholds(
+			thresholds,
+		); err != nil {
+			return req, err
+		}
+	}
+	return req, nil
+}
+
+// fetchCheckConfig returns the check configuration checkID of the
+// repository
+func fetchCheckConfig(repoID, checkID string) (*proto.CheckConfig,
+	error) {
+	resp, err := adm.GetReq(fmt.Sprintf("/checkconfig/%s/%s",
+		url.QueryEscape(repoID),
+		url.QueryEscape(checkID),
+	))
+	if err != nil {
+		return nil, err
+	}
+	res := proto.Result{}
+	if err = adm.DecodedResponse(resp, &res); err != nil {
+		return nil, err
+	}
+	if res.CheckConfigs == nil || len(*res.CheckConfigs) != 1 {
+		return nil, fmt.Errorf("Check configuration %s not found",
+			checkID)
+	}
+	return &(*res.CheckConfigs)[0], nil
+}
+
+// checkConfigResume function
+// soma check-config resume ${name} in ${repository}
+func checkConfigResume(c *cli.Context) error {
+	opts := map[string][]string{}
+	multipleAllowed := []string{}
+	uniqueOptions := []string{`in`}
+	mandatoryOptions := []string{`in`}
+
+	var err error
+	if err = adm.ParseVariadicArguments(
+		opts,
+		multipleAllowed,
+		uniqueOptions,
+		mandatoryOptions,
+		c.Args().Tail(),
+	); err != nil {
+		return err
+	}
+
+	req := proto.NewCheckConfigRequest()
+	if req.CheckConfig.RepositoryID, err = adm.LookupRepoID(
+		opts[`in`][0]); err != nil {
+		return err
+	}
+	if req.CheckConfig.ID, _, err = adm.LookupCheckConfigID(
+		c.Args().First(), req.CheckConfig.RepositoryID, ``); err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf("/checkconfig/%s/%s/rollout/resume",
+		url.QueryEscape(req.CheckConfig.RepositoryID),
+		url.QueryEscape(req.CheckConfig.ID),
+	)
+	return adm.Perform(`patchbody`, path, `command`, req, c)
+}
+
+// checkConfigRollbackEnable function
+// soma check-config rollback enable ${name} in ${repository}
+func checkConfigRollbackEnable(c *cli.Context) error {
+	return checkConfigRollback(c, `putbody`)
+}
+
+// checkConfigRollbackDisable function
+// soma check-config rollback disable ${name} in ${repository}
+func checkConfigRollbackDisable(c *cli.Context) error {
+	return checkConfigRollback(c, `delete`)
+}
+
+// checkConfigRollback enables or disables the automatic rollback
+// policy of a check configuration
+func checkConfigRollback(c *cli.Context, method string) error {
+	opts := map[string][]string{}
+	multipleAllowed := []string{}
+	uniqueOptions := []string{`in`}
+	mandatoryOptions := []string{`in`}
+
+	var err error
+	if err = adm.ParseVariadicArguments(
+		opts,
+		multipleAllowed,
+		uniqueOptions,
+		mandatoryOptions,
+		c.Args().Tail(),
+	); err != nil {
+		return err
+	}
+
+	req := proto.NewCheckConfigRequest()
+	if req.CheckConfig.RepositoryID, err = adm.LookupRepoID(
+		opts[`in`][0]); err != nil {
+		return err
+	}
+	if req.CheckConfig.ID, _, err = adm.LookupCheckConfigID(
+		c.Args().First(), req.CheckConfig.RepositoryID, ``); err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf("/checkconfig/%s/%s/rollback",
+		url.QueryEscape(req.CheckConfig.RepositoryID),
+		url.QueryEscape(req.CheckConfig.ID),
+	)
+	if method == `delete` {
+		return adm.Perform(method, path, `command`, nil, c)
+	}
+	return adm.Perform(method, path, `command`, req, c)
+}
+
+// checkConfigOverrideSet function
+// soma check-config override set ${name} in ${bucket} on ${type} ${object} threshold ...
+func checkConfigOverrideSet(c *cli.Context) error {
+	return checkConfigOverride(c, `putbody`)
+}
+
+// checkConfigOverrideRemove function
+// soma check-config override remove ${name} in ${bucket} on ${type} ${object}
+func checkConfigOverrideRemove(c *cli.Context) error {
+	return checkConfigOverride(c, `delete`)
+}
+
+// checkConfigOverride sets or removes the threshold override of a
+// check configuration on an object
+func checkConfigOverride(c *cli.Context, method string) error {
+	var err error
+	var bucketID, objectID string
+	opts := map[string][]string{}
+	thresholds := []proto.CheckConfigThreshold{}
+	req := proto.NewCheckConfigRequest()
+
+	if err = adm.ParseVariadicOverrideArguments(
+		opts,
+		&thresholds,
+		c.Args().Tail(),
+	); err != nil {
+		return err
+	}
+
+	switch opts[`on/type`][0] {
+	case proto.EntityRepository:
+		if req.CheckConfig.RepositoryID, err = adm.LookupRepoID(
+			opts[`in`][0]); err != nil {
+			return err
+		}
+	case proto.EntityBucket, proto.EntityGroup, proto.EntityCluster,
+		proto.EntityNode:
+		if bucketID, err = adm.LookupBucketID(opts[`in`][0]); err != nil {
+			return err
+		}
+		if req.CheckConfig.RepositoryID, err = adm.LookupRepoByBucket(
+			bucketID); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("Unknown object entity: %s", opts[`on/type`][0])
+	}
+	if objectID, err = adm.LookupCheckObjectID(
+		opts[`on/type`][0], opts[`on/object`][0], bucketID,
+	); err != nil {
+		return err
+	}
+	if req.CheckConfig.ID, _, err = adm.LookupCheckConfigID(
+		c.Args().First(), req.CheckConfig.RepositoryID, ``); err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf("/checkconfig/%s/%s/override/%s/%s",
+		url.QueryEscape(req.CheckConfig.RepositoryID),
+		url.QueryEscape(req.CheckConfig.ID),
+		url.QueryEscape(opts[`on/type`][0]),
+		url.QueryEscape(objectID),
+	)
+	if method == `delete` {
+		if len(thresholds) > 0 {
+			return fmt.Errorf("Syntax error, thresholds can not be" +
+				" specified when removing an override")
+		}
+		return adm.Perform(method, path, `command`, nil, c)
+	}
+
+	if len(thresholds) == 0 {
+		return fmt.Errorf("Syntax error, missing keyword: threshold")
+	}
+	if req.CheckConfig.Thresholds, err = adm.ValidateThresholds(
+		thresholds,
+	); err != nil {
+		return err
+	}
+	return adm.Perform(method, path, `command`, req, c)
+}
+
 // vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
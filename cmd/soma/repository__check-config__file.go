@@ -0,0 +1,680 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package main // import "github.com/mjolnir42/soma/cmd/soma"
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/codegangsta/cli"
+	"github.com/mjolnir42/soma/internal/adm"
+	"github.com/mjolnir42/soma/lib/proto"
+	"github.com/mjolnir42/soma/lib/threshold"
+)
+
+// checkConfigFile is the declarative description of the check
+// configurations of a repository. It is written by check-config
+// export and read by check-config apply. All objects are referenced
+// by name.
+type checkConfigFile struct {
+	Repository   string            `json:"repository"`
+	CheckConfigs []checkConfigSpec `json:"checkConfigs"`
+}
+
+// checkConfigSpec describes a single check configuration. Bucket is
+// only required for check configurations on groups and clusters.
+type checkConfigSpec struct {
+	Name             string                             `json:"name"`
+	Bucket           string                             `json:"bucket,omitempty"`
+	ObjectType       string                             `json:"objectType"`
+	Object           string                             `json:"object"`
+	Capability       string                             `json:"capability"`
+	Interval         uint64                             `json:"interval"`
+	Inheritance      *bool                              `json:"inheritance,omitempty"`
+	ChildrenOnly     bool                               `json:"childrenOnly,omitempty"`
+	ExternalID       string                             `json:"externalID,omitempty"`
+	Thresholds       []thresholdSpec                    `json:"thresholds"`
+	Constraints      []constraintSpec                   `json:"constraints,omitempty"`
+	ConstraintGroups []proto.CheckConfigConstraintGroup `json:"constraintGroups,omitempty"`
+}
+
+// thresholdSpec describes a threshold like the threshold keyword of
+// check-config create
+type thresholdSpec struct {
+	Predicate string         `json:"predicate"`
+	Level     string         `json:"level"`
+	Value     thresholdValue `json:"value"`
+}
+
+// constraintSpec describes a constraint like the constraint keyword
+// of check-config create
+type constraintSpec struct {
+	Type     string `json:"type"`
+	Key      string `json:"key"`
+	Operator string `json:"operator,omitempty"`
+	Value    string `json:"value"`
+}
+
+// thresholdValue is either an integer or a value template. Integers
+// are written as JSON numbers, templates as strings.
+type thresholdValue string
+
+// MarshalJSON implements json.Marshaler
+func (v thresholdValue) MarshalJSON() ([]byte, error) {
+	if _, err := strconv.ParseInt(string(v), 10, 64); err == nil {
+		return []byte(v), nil
+	}
+	return json.Marshal(string(v))
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (v *thresholdValue) UnmarshalJSON(data []byte) error {
+	var s string
+	if bytes.HasPrefix(data, []byte(`"`)) {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		*v = thresholdValue(s)
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return err
+	}
+	*v = thresholdValue(n.String())
+	return nil
+}
+
+// checkConfigExport function
+// soma check-config export in ${repository}
+func checkConfigExport(c *cli.Context) error {
+	opts := map[string][]string{}
+	multipleAllowed := []string{}
+	uniqueOptions := []string{`in`}
+	mandatoryOptions := []string{`in`}
+
+	var err error
+	if err = adm.ParseVariadicArguments(
+		opts,
+		multipleAllowed,
+		uniqueOptions,
+		mandatoryOptions,
+		adm.AllArguments(c),
+	); err != nil {
+		return err
+	}
+
+	var repoID string
+	file := checkConfigFile{}
+	if repoID, err = adm.LookupRepoID(opts[`in`][0]); err != nil {
+		return err
+	}
+	if err = adm.LookupRepoName(repoID, &file.Repository); err != nil {
+		return err
+	}
+	if file.CheckConfigs, _, err = fetchCheckConfigSpecs(
+		repoID,
+	); err != nil {
+		return err
+	}
+
+	// predicates are written as is, not HTML escaped
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent(``, `    `)
+	return encoder.Encode(&file)
+}
+
+// checkConfigDiff function
+// soma check-config diff ${file}
+func checkConfigDiff(c *cli.Context) error {
+	return checkConfigFileSync(c, false)
+}
+
+// checkConfigApply function
+// soma check-config apply ${file}
+func checkConfigApply(c *cli.Context) error {
+	return checkConfigFileSync(c, true)
+}
+
+// checkConfigFileSync compares the check configurations described in
+// the file given as argument with the check configurations of its
+// repository and prints the differences. If apply is true, the check
+// configurations of the repository are changed to match the file.
+func checkConfigFileSync(c *cli.Context, apply bool) error {
+	var (
+		err      error
+		repoID   string
+		file     *checkConfigFile
+		current  []checkConfigSpec
+		configID map[string]string
+		changes  checkConfigChanges
+	)
+
+	if err = adm.VerifySingleArgument(c); err != nil {
+		return err
+	}
+	if file, err = readCheckConfigFile(c.Args().First()); err != nil {
+		return err
+	}
+	if repoID, err = adm.LookupRepoID(file.Repository); err != nil {
+		return err
+	}
+	if current, configID, err = fetchCheckConfigSpecs(repoID); err != nil {
+		return err
+	}
+
+	have := make(map[string]checkConfigSpec, len(current))
+	for _, spec := range current {
+		have[spec.Name] = spec
+	}
+	want := make(map[string]checkConfigSpec, len(file.CheckConfigs))
+	for _, spec := range file.CheckConfigs {
+		if _, ok := want[spec.Name]; ok {
+			return fmt.Errorf("Check configuration %s is defined"+
+				" more than once", spec.Name)
+		}
+		for i := range spec.Thresholds {
+			if err = adm.LookupLevelName(
+				spec.Thresholds[i].Level,
+				&spec.Thresholds[i].Level,
+			); err != nil {
+				return err
+			}
+		}
+		if err = spec.normalize(); err != nil {
+			return fmt.Errorf("Check configuration %s: %s",
+				spec.Name, err.Error())
+		}
+		want[spec.Name] = spec
+	}
+
+	if changes, err = diffCheckConfigs(have, want); err != nil {
+		return err
+	}
+	if changes.empty() {
+		fmt.Println(`No changes`)
+		return nil
+	}
+	for _, name := range changes.destroy {
+		fmt.Printf("- %s\n", name)
+	}
+	for _, name := range changes.update {
+		fmt.Printf("~ %s (%s)\n", name,
+			strings.Join(changes.changed[name], `, `))
+	}
+	for _, name := range changes.replace {
+		fmt.Printf("-/+ %s (%s)\n", name,
+			strings.Join(changes.changed[name], `, `))
+	}
+	for _, name := range changes.create {
+		fmt.Printf("+ %s\n", name)
+	}
+	if !apply {
+		return nil
+	}
+
+	// build all requests before the first change, so that an invalid
+	// check configuration in the file does not leave the repository
+	// partially applied
+	requests := make(map[string]proto.Request,
+		len(changes.create)+len(changes.update)+len(changes.replace))
+	for _, name := range changes.update {
+		var req proto.Request
+		spec := want[name]
+		if req, err = spec.updateRequest(file.Repository); err != nil {
+			return fmt.Errorf("Check configuration %s: %s",
+				name, err.Error())
+		}
+		requests[name] = req
+	}
+	for _, name := range append(changes.replace, changes.create...) {
+		var req proto.Request
+		spec := want[name]
+		if req, err = spec.request(file.Repository); err != nil {
+			return fmt.Errorf("Check configuration %s: %s",
+				name, err.Error())
+		}
+		if req.CheckConfig.RepositoryID != repoID {
+			return fmt.Errorf("Check configuration %s is not in"+
+				" repository %s", name, file.Repository)
+		}
+		requests[name] = req
+	}
+
+	// new check configurations are created and updatable ones are
+	// updated in place first. Only then are check configurations
+	// replaced, one at a time, and finally destroyed.
+	for _, name := range changes.create {
+		if err = adm.Perform(`postbody`, fmt.Sprintf(
+			"/checkconfig/%s/",
+			url.QueryEscape(repoID),
+		), `check-config::create`, requests[name], c); err != nil {
+			return err
+		}
+	}
+	for _, name := range changes.update {
+		if err = adm.Perform(`putbody`, fmt.Sprintf(
+			"/checkconfig/%s/%s",
+			url.QueryEscape(repoID),
+			url.QueryEscape(configID[name]),
+		), `command`, requests[name], c); err != nil {
+			return err
+		}
+	}
+	for _, name := range changes.replace {
+		if err = adm.Perform(`delete`, fmt.Sprintf(
+			"/checkconfig/%s/%s",
+			url.QueryEscape(repoID),
+			url.QueryEscape(configID[name]),
+		), `check-config::destroy`, nil, c); err != nil {
+			return err
+		}
+		if err = adm.Perform(`postbody`, fmt.Sprintf(
+			"/checkconfig/%s/",
+			url.QueryEscape(repoID),
+		), `check-config::create`, requests[name], c); err != nil {
+			return fmt.Errorf("Check configuration %s was destroyed"+
+				" and could not be created again: %s", name,
+				err.Error())
+		}
+	}
+	for _, name := range changes.destroy {
+		if err = adm.Perform(`delete`, fmt.Sprintf(
+			"/checkconfig/%s/%s",
+			url.QueryEscape(repoID),
+			url.QueryEscape(configID[name]),
+		), `check-config::destroy`, nil, c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkConfigChanges are the changes that make the check
+// configurations of a repository match a check configuration file,
+// each ordered by name
+type checkConfigChanges struct {
+	create  []string
+	update  []string
+	replace []string
+	destroy []string
+	// changed holds the changed fields of updated and replaced
+	// check configurations
+	changed map[string][]string
+}
+
+// empty reports whether there are no changes
+func (c checkConfigChanges) empty() bool {
+	return len(c.create)+len(c.update)+len(c.replace)+
+		len(c.destroy) == 0
+}
+
+// diffCheckConfigs computes the changes from the check configurations
+// have to the check configurations want, both by name. Only interval
+// and thresholds can be updated in place, check configurations with
+// other changed fields must be replaced.
+func diffCheckConfigs(have, want map[string]checkConfigSpec) (
+	checkConfigChanges, error) {
+	changes := checkConfigChanges{
+		changed: map[string][]string{},
+	}
+	for name, spec := range want {
+		if _, ok := have[name]; !ok {
+			changes.create = append(changes.create, name)
+			continue
+		}
+		changed, err := spec.changedFields(have[name])
+		if err != nil {
+			return checkConfigChanges{}, fmt.Errorf(
+				"Check configuration %s: %s", name, err.Error())
+		}
+		switch {
+		case len(changed) == 0:
+			continue
+		case updatable(changed):
+			changes.update = append(changes.update, name)
+		default:
+			changes.replace = append(changes.replace, name)
+		}
+		changes.changed[name] = changed
+	}
+	for name := range have {
+		if _, ok := want[name]; !ok {
+			changes.destroy = append(changes.destroy, name)
+		}
+	}
+	sort.Strings(changes.create)
+	sort.Strings(changes.update)
+	sort.Strings(changes.replace)
+	sort.Strings(changes.destroy)
+	return changes, nil
+}
+
+// readCheckConfigFile reads a check configuration file
+func readCheckConfigFile(fname string) (*checkConfigFile, error) {
+	data, err := ioutil.ReadFile(fname)
+	if err != nil {
+		return nil, err
+	}
+
+	file := &checkConfigFile{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err = decoder.Decode(file); err != nil {
+		return nil, fmt.Errorf("%s: %s", fname, err.Error())
+	}
+	if file.Repository == `` {
+		return nil, fmt.Errorf("%s: repository not set", fname)
+	}
+	return file, nil
+}
+
+// fetchCheckConfigSpecs returns the check configurations of the
+// repository, ordered by name, and their IDs by name
+func fetchCheckConfigSpecs(repoID string) ([]checkConfigSpec,
+	map[string]string, error) {
+	resp, err := adm.GetReq(fmt.Sprintf("/export/checkconfig/%s/",
+		url.QueryEscape(repoID)))
+	if err != nil {
+		return nil, nil, err
+	}
+	res := proto.Result{}
+	if err = adm.DecodedResponse(resp, &res); err != nil {
+		return nil, nil, err
+	}
+
+	specs := []checkConfigSpec{}
+	ids := map[string]string{}
+	if res.CheckConfigs == nil {
+		return specs, ids, nil
+	}
+	for i := range *res.CheckConfigs {
+		var spec checkConfigSpec
+		cfg := &(*res.CheckConfigs)[i]
+		if spec, err = newCheckConfigSpec(cfg); err != nil {
+			return nil, nil, err
+		}
+		specs = append(specs, spec)
+		ids[spec.Name] = cfg.ID
+	}
+	sort.Slice(specs, func(i, j int) bool {
+		return specs[i].Name < specs[j].Name
+	})
+	return specs, ids, nil
+}
+
+// newCheckConfigSpec converts an exported check configuration
+func newCheckConfigSpec(cfg *proto.CheckConfig) (checkConfigSpec, error) {
+	if cfg.Details == nil || cfg.Details.Names == nil {
+		return checkConfigSpec{}, fmt.Errorf("Check configuration"+
+			" %s was exported without names", cfg.ID)
+	}
+	inheritance := cfg.Inheritance
+	spec := checkConfigSpec{
+		Name:             cfg.Name,
+		ObjectType:       cfg.ObjectType,
+		Object:           cfg.Details.Names.Object,
+		Capability:       cfg.Details.Names.Capability,
+		Interval:         cfg.Interval,
+		Inheritance:      &inheritance,
+		ChildrenOnly:     cfg.ChildrenOnly,
+		ExternalID:       cfg.ExternalID,
+		Thresholds:       []thresholdSpec{},
+		ConstraintGroups: cfg.ConstraintGroups,
+	}
+	switch cfg.ObjectType {
+	case proto.EntityGroup, proto.EntityCluster:
+		spec.Bucket = cfg.Details.Names.Bucket
+	}
+
+	for _, thr := range cfg.Thresholds {
+		value := thresholdValue(strconv.FormatInt(thr.Value, 10))
+		if thr.ValueTemplate != `` {
+			value = thresholdValue(thr.ValueTemplate)
+		}
+		spec.Thresholds = append(spec.Thresholds, thresholdSpec{
+			Predicate: thr.Predicate.Symbol,
+			Level:     thr.Level.Name,
+			Value:     value,
+		})
+	}
+
+	for _, cstr := range cfg.Constraints {
+		c := constraintSpec{
+			Type: cstr.ConstraintType,
+		}
+		// @undefined is given in place of the value
+		if cstr.Operator != proto.ConstraintUndefined {
+			c.Operator = cstr.Operator
+		}
+		switch cstr.ConstraintType {
+		case `native`:
+			c.Key, c.Value = cstr.Native.Name, cstr.Native.Value
+		case `system`:
+			c.Key, c.Value = cstr.System.Name, cstr.System.Value
+		case `custom`:
+			c.Key, c.Value = cstr.Custom.Name, cstr.Custom.Value
+		case `attribute`:
+			c.Key, c.Value = cstr.Attribute.Name, cstr.Attribute.Value
+		case `service`:
+			c.Key, c.Value = `name`, cstr.Service.Name
+		case `oncall`:
+			c.Key, c.Value = `name`, cstr.Oncall.Name
+		default:
+			return checkConfigSpec{}, fmt.Errorf("Check configuration"+
+				" %s has unknown constraint type %s", cfg.Name,
+				cstr.ConstraintType)
+		}
+		if cstr.Operator == proto.ConstraintUndefined {
+			c.Value = proto.ConstraintUndefined
+		}
+		spec.Constraints = append(spec.Constraints, c)
+	}
+
+	if err := spec.normalize(); err != nil {
+		return checkConfigSpec{}, err
+	}
+	return spec, nil
+}
+
+// normalize fills in the defaults of s, validates its threshold
+// values and sorts its thresholds and constraints so that specs can
+// be compared. Level names must already be resolved.
+func (s *checkConfigSpec) normalize() error {
+	if s.Name == `` {
+		return fmt.Errorf(`name not set`)
+	}
+	if s.Inheritance == nil {
+		// inheritance defaults to true
+		inheritance := true
+		s.Inheritance = &inheritance
+	}
+	if s.ExternalID == `none` {
+		s.ExternalID = ``
+	}
+	for _, thr := range s.Thresholds {
+		if threshold.IsTemplate(string(thr.Value)) {
+			if _, err := threshold.ParseTemplate(
+				string(thr.Value),
+			); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, err := strconv.ParseInt(
+			string(thr.Value), 10, 64,
+		); err != nil {
+			return fmt.Errorf("threshold value not numeric: %s",
+				thr.Value)
+		}
+	}
+	sort.SliceStable(s.Thresholds, func(i, j int) bool {
+		return s.Thresholds[i].Level < s.Thresholds[j].Level
+	})
+	sort.SliceStable(s.Constraints, func(i, j int) bool {
+		a, b := s.Constraints[i], s.Constraints[j]
+		switch {
+		case a.Type != b.Type:
+			return a.Type < b.Type
+		case a.Key != b.Key:
+			return a.Key < b.Key
+		case a.Operator != b.Operator:
+			return a.Operator < b.Operator
+		}
+		return a.Value < b.Value
+	})
+	return nil
+}
+
+// changedFields returns the names of the fields that differ between
+// s and o, in the order of the file format
+func (s checkConfigSpec) changedFields(o checkConfigSpec) ([]string,
+	error) {
+	a, err := s.fields()
+	if err != nil {
+		return nil, err
+	}
+	b, err := o.fields()
+	if err != nil {
+		return nil, err
+	}
+
+	changed := []string{}
+	for _, field := range []string{
+		`bucket`, `objectType`, `object`, `capability`,
+		`interval`, `inheritance`, `childrenOnly`, `externalID`,
+		`thresholds`, `constraints`, `constraintGroups`,
+	} {
+		if !bytes.Equal(a[field], b[field]) {
+			changed = append(changed, field)
+		}
+	}
+	return changed, nil
+}
+
+// fields returns the JSON encoding of the fields of s by name
+func (s checkConfigSpec) fields() (map[string]json.RawMessage, error) {
+	var fields map[string]json.RawMessage
+	data, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// updatable reports whether a check configuration with the changed
+// fields can be updated in place
+func updatable(changed []string) bool {
+	for _, field := range changed {
+		switch field {
+		case `interval`, `thresholds`:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// request builds the request to create the check configuration
+// described by s in the repository
+func (s *checkConfigSpec) request(repository string) (proto.Request,
+	error) {
+	// the in keyword is only evaluated for groups and clusters
+	in := s.Bucket
+	if in == `` {
+		in = repository
+	}
+	args := []string{
+		`in`, in,
+		`on`, s.ObjectType, s.Object,
+		`with`, s.Capability,
+		`interval`, strconv.FormatUint(s.Interval, 10),
+		`inheritance`, strconv.FormatBool(*s.Inheritance),
+		`childrenonly`, strconv.FormatBool(s.ChildrenOnly),
+	}
+	if s.ExternalID != `` {
+		args = append(args, `extern`, s.ExternalID)
+	}
+	args = append(args, s.thresholdArgs()...)
+	for _, cstr := range s.Constraints {
+		args = append(args, `constraint`, cstr.Type, cstr.Key)
+		if cstr.Operator != `` {
+			args = append(args, `operator`, cstr.Operator)
+		}
+		args = append(args, cstr.Value)
+	}
+
+	opts := map[string][]string{}
+	constraints := []proto.CheckConfigConstraint{}
+	thresholds := []proto.CheckConfigThreshold{}
+	if err := adm.ParseVariadicCheckArguments(
+		opts,
+		&constraints,
+		&thresholds,
+		args,
+	); err != nil {
+		return proto.Request{}, err
+	}
+	req, err := checkConfigRequest(s.Name, opts, constraints,
+		thresholds)
+	if err != nil {
+		return req, err
+	}
+	req.CheckConfig.ConstraintGroups = s.ConstraintGroups
+	return req, nil
+}
+
+// updateRequest builds the request to update the interval and the
+// thresholds of the check configuration described by s
+func (s *checkConfigSpec) updateRequest(repository string) (proto.Request,
+	error) {
+	args := []string{
+		`in`, repository,
+		`interval`, strconv.FormatUint(s.Interval, 10),
+	}
+	args = append(args, s.thresholdArgs()...)
+
+	opts := map[string][]string{}
+	thresholds := []proto.CheckConfigThreshold{}
+	if err := adm.ParseVariadicCheckUpdateArguments(
+		opts,
+		&thresholds,
+		args,
+	); err != nil {
+		return proto.Request{}, err
+	}
+	return checkConfigUpdateRequest(&proto.CheckConfig{}, opts,
+		thresholds)
+}
+
+// thresholdArgs returns the thresholds of s as command arguments
+func (s *checkConfigSpec) thresholdArgs() []string {
+	args := []string{}
+	for _, thr := range s.Thresholds {
+		args = append(args, `threshold`,
+			`predicate`, thr.Predicate,
+			`level`, thr.Level,
+			`value`, string(thr.Value),
+		)
+	}
+	return args
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
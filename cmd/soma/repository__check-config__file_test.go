@@ -0,0 +1,200 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package main // import "github.com/mjolnir42/soma/cmd/soma"
+
+import (
+	"reflect"
+	"testing"
+)
+
+func testCheckConfigSpec(name string, modify func(*checkConfigSpec)) checkConfigSpec {
+	spec := checkConfigSpec{
+		Name:       name,
+		Bucket:     `bucket`,
+		ObjectType: `group`,
+		Object:     `group`,
+		Capability: `monitoring.external.metric`,
+		Interval:   60,
+		Thresholds: []thresholdSpec{
+			{Predicate: `>=`, Level: `warning`, Value: `80`},
+		},
+		Constraints: []constraintSpec{
+			{Type: `system`, Key: `fqdn`, Operator: `!~`, Value: `\.lab$`},
+		},
+	}
+	if modify != nil {
+		modify(&spec)
+	}
+	if err := spec.normalize(); err != nil {
+		panic(err)
+	}
+	return spec
+}
+
+func TestDiffCheckConfigs(t *testing.T) {
+	tests := []struct {
+		name     string
+		have     []checkConfigSpec
+		want     []checkConfigSpec
+		expected checkConfigChanges
+	}{
+		{
+			name: `unchanged`,
+			have: []checkConfigSpec{testCheckConfigSpec(`a`, nil)},
+			want: []checkConfigSpec{testCheckConfigSpec(`a`, func(s *checkConfigSpec) {
+				// inheritance defaults to true
+				s.Inheritance = nil
+			})},
+			expected: checkConfigChanges{},
+		},
+		{
+			name: `update interval and thresholds`,
+			have: []checkConfigSpec{testCheckConfigSpec(`a`, nil)},
+			want: []checkConfigSpec{testCheckConfigSpec(`a`, func(s *checkConfigSpec) {
+				s.Interval = 300
+				s.Thresholds = append(s.Thresholds, thresholdSpec{
+					Predicate: `>=`,
+					Level:     `critical`,
+					Value:     `95%@attribute:max_connections`,
+				})
+			})},
+			expected: checkConfigChanges{
+				update: []string{`a`},
+				changed: map[string][]string{
+					`a`: {`interval`, `thresholds`},
+				},
+			},
+		},
+		{
+			name: `replace changed constraint`,
+			have: []checkConfigSpec{testCheckConfigSpec(`a`, nil)},
+			want: []checkConfigSpec{testCheckConfigSpec(`a`, func(s *checkConfigSpec) {
+				s.Interval = 300
+				s.Constraints[0].Operator = `=~`
+			})},
+			expected: checkConfigChanges{
+				replace: []string{`a`},
+				changed: map[string][]string{
+					`a`: {`interval`, `constraints`},
+				},
+			},
+		},
+		{
+			name: `replace moved check configuration`,
+			have: []checkConfigSpec{testCheckConfigSpec(`a`, nil)},
+			want: []checkConfigSpec{testCheckConfigSpec(`a`, func(s *checkConfigSpec) {
+				s.Bucket = ``
+				s.ObjectType = `bucket`
+				s.Object = `bucket`
+			})},
+			expected: checkConfigChanges{
+				replace: []string{`a`},
+				changed: map[string][]string{
+					`a`: {`bucket`, `objectType`, `object`},
+				},
+			},
+		},
+		{
+			name: `create`,
+			have: []checkConfigSpec{testCheckConfigSpec(`b`, nil)},
+			want: []checkConfigSpec{
+				testCheckConfigSpec(`c`, nil),
+				testCheckConfigSpec(`b`, nil),
+				testCheckConfigSpec(`a`, nil),
+			},
+			expected: checkConfigChanges{
+				create: []string{`a`, `c`},
+			},
+		},
+		{
+			name: `destroy`,
+			have: []checkConfigSpec{
+				testCheckConfigSpec(`c`, nil),
+				testCheckConfigSpec(`b`, nil),
+				testCheckConfigSpec(`a`, nil),
+			},
+			want: []checkConfigSpec{testCheckConfigSpec(`b`, nil)},
+			expected: checkConfigChanges{
+				destroy: []string{`a`, `c`},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		have := map[string]checkConfigSpec{}
+		for _, spec := range test.have {
+			have[spec.Name] = spec
+		}
+		want := map[string]checkConfigSpec{}
+		for _, spec := range test.want {
+			want[spec.Name] = spec
+		}
+		if test.expected.changed == nil {
+			test.expected.changed = map[string][]string{}
+		}
+
+		changes, err := diffCheckConfigs(have, want)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test.name, err.Error())
+			continue
+		}
+		if !reflect.DeepEqual(changes, test.expected) {
+			t.Errorf("%s: expected %+v, got %+v", test.name,
+				test.expected, changes)
+		}
+		if changes.empty() != (test.name == `unchanged`) {
+			t.Errorf("%s: empty() reported %t", test.name,
+				changes.empty())
+		}
+	}
+}
+
+func TestCheckConfigSpecNormalize(t *testing.T) {
+	spec := checkConfigSpec{
+		Name:       `a`,
+		ExternalID: `none`,
+		Thresholds: []thresholdSpec{
+			{Predicate: `>=`, Level: `warning`, Value: `80`},
+			{Predicate: `>=`, Level: `critical`, Value: `95`},
+		},
+		Constraints: []constraintSpec{
+			{Type: `system`, Key: `fqdn`, Value: `node1`},
+			{Type: `custom`, Key: `rack`, Operator: `@in`, Value: `r1,r2`},
+		},
+	}
+	if err := spec.normalize(); err != nil {
+		t.Fatal(err)
+	}
+	if spec.Inheritance == nil || !*spec.Inheritance {
+		t.Errorf("Inheritance does not default to true")
+	}
+	if spec.ExternalID != `` {
+		t.Errorf("External ID none was not cleared")
+	}
+	if spec.Thresholds[0].Level != `critical` {
+		t.Errorf("Thresholds are not sorted: %+v", spec.Thresholds)
+	}
+	if spec.Constraints[0].Type != `custom` {
+		t.Errorf("Constraints are not sorted: %+v", spec.Constraints)
+	}
+
+	for _, value := range []thresholdValue{`eighty`, `80%@unknown:x`} {
+		invalid := checkConfigSpec{
+			Name: `a`,
+			Thresholds: []thresholdSpec{
+				{Predicate: `>=`, Level: `warning`, Value: value},
+			},
+		}
+		if err := invalid.normalize(); err == nil {
+			t.Errorf("Threshold value %s was accepted", value)
+		}
+	}
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
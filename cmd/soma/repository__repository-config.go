@@ -119,4 +119,41 @@ func repositoryConfigTree(c *cli.Context) error {
 	return adm.Perform(`get`, path, `tree`, nil, c)
 }
 
+// repositoryConfigRollbackEnable function
+// soma repository rollback enable ${repository}
+func repositoryConfigRollbackEnable(c *cli.Context) error {
+	if err := adm.VerifySingleArgument(c); err != nil {
+		return err
+	}
+	repositoryID, err := adm.LookupRepoID(c.Args().First())
+	if err != nil {
+		return err
+	}
+
+	req := proto.NewRepositoryRequest()
+	req.Repository.ID = repositoryID
+
+	path := fmt.Sprintf("/repository/%s/rollback", url.QueryEscape(
+		repositoryID,
+	))
+	return adm.Perform(`putbody`, path, `command`, req, c)
+}
+
+// repositoryConfigRollbackDisable function
+// soma repository rollback disable ${repository}
+func repositoryConfigRollbackDisable(c *cli.Context) error {
+	if err := adm.VerifySingleArgument(c); err != nil {
+		return err
+	}
+	repositoryID, err := adm.LookupRepoID(c.Args().First())
+	if err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf("/repository/%s/rollback", url.QueryEscape(
+		repositoryID,
+	))
+	return adm.Perform(`delete`, path, `command`, nil, c)
+}
+
 // vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -56,6 +56,11 @@ func initCommon(c *cli.Context) {
 		}).SetRootCertificate(Cfg.Run.CertPath)
 	}
 
+	// request tree changes to only be computed, not applied
+	if c.GlobalBool(`dry-run`) {
+		Client = Client.SetQueryParam(`dryRun`, `true`)
+	}
+
 	/*
 		// check configured API
 		if resp, err = Client.R().Head(`/`); err != nil {
@@ -251,6 +251,11 @@ func main() {
 	rst = rest.New(super.IsAuthorized, hm, &SomaCfg, reqLog, errLog)
 
 	go rst.Run()
+	if SomaCfg.Metrics.Port != `` {
+		go rst.RunMetrics()
+		appLog.Printf("Exporting metrics on %s:%s%s", SomaCfg.Metrics.Listen,
+			SomaCfg.Metrics.Port, SomaCfg.Metrics.Path)
+	}
 
 	// signal handler for shutdown
 	sigChanShutdown := make(chan os.Signal, 1)
@@ -19,6 +19,9 @@ func commandCleanupChecks(done chan bool, printOnly bool) {
 		`DELETE FROM constraints_service_attribute;`,
 		`DELETE FROM constraints_service_property;`,
 		`DELETE FROM constraints_system_property;`,
+		`DELETE FROM check_configuration_constraint_groups;`,
+		`DELETE FROM threshold_override_thresholds;`,
+		`DELETE FROM threshold_overrides;`,
 		`DELETE FROM check_configurations;`,
 	}
 
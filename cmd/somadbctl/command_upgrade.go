@@ -44,6 +44,16 @@ var UpgradeVersions = map[string]map[int]func(int, string, bool) int{
 		201811120001: upgradeSomaTo201811120002,
 		201811120002: upgradeSomaTo201811150001,
 		201811150001: upgradeSomaTo201901300001,
+		201901300001: upgradeSomaTo201902010001,
+		201902010001: upgradeSomaTo201902010002,
+		201902010002: upgradeSomaTo201902010003,
+		201902010003: upgradeSomaTo201902010004,
+		201902010004: upgradeSomaTo201902010005,
+		201902010005: upgradeSomaTo201902010006,
+		201902010006: upgradeSomaTo201902010007,
+		201902010007: upgradeSomaTo201902010008,
+		201902010008: upgradeSomaTo201902010009,
+		201902010009: upgradeSomaTo201902010010,
 	},
 	`root`: map[int]func(int, string, bool) int{
 		000000000001: installRoot201605150001,
@@ -1123,6 +1133,161 @@ func upgradeSomaTo201901300001(curr int, tool string, printOnly bool) int {
 	return 201901300001
 }
 
+func upgradeSomaTo201902010001(curr int, tool string, printOnly bool) int {
+	if curr != 201901300001 {
+		return 0
+	}
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS soma.notification_outbox ( notification_id uuid PRIMARY KEY, monitoring_id uuid NOT NULL REFERENCES soma.monitoring_systems ( monitoring_id ) ON DELETE CASCADE DEFERRABLE, check_instance_id uuid NOT NULL REFERENCES soma.check_instances ( check_instance_id ) ON DELETE CASCADE DEFERRABLE, delivery_state varchar(32) NOT NULL DEFAULT 'pending', attempts integer NOT NULL DEFAULT 0, last_error text NULL, created_at timestamptz(3) NOT NULL DEFAULT NOW(), next_attempt_at timestamptz(3) NOT NULL DEFAULT NOW(), delivered_at timestamptz(3) NULL, CHECK ( delivery_state IN ( 'pending', 'delivered', 'dead' ) ), CHECK ( attempts >= 0 ), CHECK ( EXTRACT( TIMEZONE FROM created_at ) = '0' ), CHECK ( EXTRACT( TIMEZONE FROM next_attempt_at ) = '0' ), CHECK ( EXTRACT( TIMEZONE FROM delivered_at ) = '0' ));`,
+		`CREATE UNIQUE INDEX _notification_outbox_undelivered ON soma.notification_outbox ( monitoring_id, check_instance_id ) WHERE delivery_state != 'delivered';`,
+	}
+	stmts = append(stmts,
+		fmt.Sprintf("INSERT INTO public.schema_versions (schema, version, description) VALUES ('soma', 201902010001, 'Upgrade - somadbctl %s');", tool),
+	)
+	executeUpgrades(stmts, printOnly)
+	return 201902010001
+}
+
+func upgradeSomaTo201902010002(curr int, tool string, printOnly bool) int {
+	if curr != 201902010001 {
+		return 0
+	}
+	stmts := []string{
+		`ALTER TABLE soma.monitoring_systems ADD COLUMN monitoring_batch_size integer NOT NULL DEFAULT 0;`,
+		`ALTER TABLE soma.monitoring_systems ADD COLUMN monitoring_batch_flush integer NOT NULL DEFAULT 0;`,
+		`ALTER TABLE soma.monitoring_systems ADD CHECK ( monitoring_batch_size >= 0 );`,
+		`ALTER TABLE soma.monitoring_systems ADD CHECK ( monitoring_batch_flush >= 0 );`,
+	}
+	stmts = append(stmts,
+		fmt.Sprintf("INSERT INTO public.schema_versions (schema, version, description) VALUES ('soma', 201902010002, 'Upgrade - somadbctl %s');", tool),
+	)
+	executeUpgrades(stmts, printOnly)
+	return 201902010002
+}
+
+func upgradeSomaTo201902010003(curr int, tool string, printOnly bool) int {
+	if curr != 201902010002 {
+		return 0
+	}
+	stmts := []string{
+		`ALTER TABLE soma.monitoring_systems ADD COLUMN monitoring_secret text NULL;`,
+	}
+	stmts = append(stmts,
+		fmt.Sprintf("INSERT INTO public.schema_versions (schema, version, description) VALUES ('soma', 201902010003, 'Upgrade - somadbctl %s');", tool),
+	)
+	executeUpgrades(stmts, printOnly)
+	return 201902010003
+}
+
+func upgradeSomaTo201902010004(curr int, tool string, printOnly bool) int {
+	if curr != 201902010003 {
+		return 0
+	}
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS soma.maintenance_windows ( maintenance_window_id uuid PRIMARY KEY, scope varchar(32) NOT NULL, repository_id uuid NULL REFERENCES soma.repository ( id ) ON DELETE CASCADE DEFERRABLE, bucket_id uuid NULL REFERENCES soma.buckets ( bucket_id ) ON DELETE CASCADE DEFERRABLE, environment varchar(32) NULL REFERENCES soma.environments ( environment ) ON DELETE CASCADE DEFERRABLE, monitoring_id uuid NULL REFERENCES soma.monitoring_systems ( monitoring_id ) ON DELETE CASCADE DEFERRABLE, description text NOT NULL DEFAULT '', starts_at timestamptz(3) NOT NULL, ends_at timestamptz(3) NOT NULL, hold_jobs boolean NOT NULL DEFAULT 'no', cancelled_at timestamptz(3) NULL, created_by uuid NOT NULL REFERENCES inventory.user ( id ) DEFERRABLE, created_at timestamptz(3) NOT NULL DEFAULT NOW(), CHECK ( scope IN ( 'repository', 'bucket', 'environment', 'monitoring' ) ), CHECK ( scope != 'repository' OR repository_id IS NOT NULL ), CHECK ( scope != 'bucket' OR bucket_id IS NOT NULL ), CHECK ( scope != 'environment' OR environment IS NOT NULL ), CHECK ( scope != 'monitoring' OR monitoring_id IS NOT NULL ), CHECK ( num_nonnulls( repository_id, bucket_id, environment, monitoring_id ) = 1 ), CHECK ( NOT hold_jobs OR scope = 'repository' ), CHECK ( starts_at < ends_at ), CHECK ( EXTRACT( TIMEZONE FROM starts_at ) = '0' ), CHECK ( EXTRACT( TIMEZONE FROM ends_at ) = '0' ), CHECK ( EXTRACT( TIMEZONE FROM cancelled_at ) = '0' ), CHECK ( EXTRACT( TIMEZONE FROM created_at ) = '0' ));`,
+	}
+	stmts = append(stmts,
+		fmt.Sprintf("INSERT INTO public.schema_versions (schema, version, description) VALUES ('soma', 201902010004, 'Upgrade - somadbctl %s');", tool),
+	)
+	executeUpgrades(stmts, printOnly)
+	return 201902010004
+}
+
+func upgradeSomaTo201902010005(curr int, tool string, printOnly bool) int {
+	if curr != 201902010004 {
+		return 0
+	}
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS soma.check_configuration_rollout ( configuration_id uuid PRIMARY KEY REFERENCES soma.check_configurations ( configuration_id ) ON DELETE CASCADE DEFERRABLE, wave_size integer NULL, wave_percent integer NULL, soak_seconds integer NOT NULL DEFAULT 0, failure_threshold integer NOT NULL DEFAULT 1, wave integer NOT NULL DEFAULT 0, wave_released_at timestamptz(3) NULL, rollout_started_at timestamptz(3) NULL, halted_at timestamptz(3) NULL, CHECK ( num_nonnulls( wave_size, wave_percent ) = 1 ), CHECK ( wave_size IS NULL OR wave_size > 0 ), CHECK ( wave_percent IS NULL OR ( wave_percent > 0 AND wave_percent <= 100 ) ), CHECK ( soak_seconds >= 0 ), CHECK ( failure_threshold > 0 ), CHECK ( wave >= 0 ), CHECK ( EXTRACT( TIMEZONE FROM wave_released_at ) = '0' ), CHECK ( EXTRACT( TIMEZONE FROM rollout_started_at ) = '0' ), CHECK ( EXTRACT( TIMEZONE FROM halted_at ) = '0' ));`,
+	}
+	stmts = append(stmts,
+		fmt.Sprintf("INSERT INTO public.schema_versions (schema, version, description) VALUES ('soma', 201902010005, 'Upgrade - somadbctl %s');", tool),
+	)
+	executeUpgrades(stmts, printOnly)
+	return 201902010005
+}
+
+func upgradeSomaTo201902010006(curr int, tool string, printOnly bool) int {
+	if curr != 201902010005 {
+		return 0
+	}
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS soma.rollback_policies ( rollback_policy_id uuid PRIMARY KEY, repository_id uuid NOT NULL REFERENCES soma.repository ( id ) ON DELETE CASCADE DEFERRABLE, configuration_id uuid NULL REFERENCES soma.check_configurations ( configuration_id ) ON DELETE CASCADE DEFERRABLE, created_by uuid NOT NULL REFERENCES inventory.user ( id ) DEFERRABLE, created_at timestamptz(3) NOT NULL DEFAULT NOW(), FOREIGN KEY ( configuration_id, repository_id ) REFERENCES soma.check_configurations ( configuration_id, repository_id ) ON DELETE CASCADE DEFERRABLE, CHECK ( EXTRACT( TIMEZONE FROM created_at ) = '0' ));`,
+		`CREATE UNIQUE INDEX _unique_repository_rollback_policy ON soma.rollback_policies ( repository_id ) WHERE configuration_id IS NULL;`,
+		`CREATE UNIQUE INDEX _unique_configuration_rollback_policy ON soma.rollback_policies ( configuration_id ) WHERE configuration_id IS NOT NULL;`,
+		`CREATE TABLE IF NOT EXISTS soma.check_instance_rollbacks ( rollback_id uuid PRIMARY KEY, check_instance_id uuid NOT NULL REFERENCES soma.check_instances ( check_instance_id ) ON DELETE CASCADE DEFERRABLE, failed_instance_config_id uuid NOT NULL REFERENCES soma.check_instance_configurations ( check_instance_config_id ) ON DELETE CASCADE DEFERRABLE, restored_instance_config_id uuid NOT NULL REFERENCES soma.check_instance_configurations ( check_instance_config_id ) ON DELETE CASCADE DEFERRABLE, created_at timestamptz(3) NOT NULL DEFAULT NOW(), CHECK ( failed_instance_config_id != restored_instance_config_id ), CHECK ( EXTRACT( TIMEZONE FROM created_at ) = '0' ));`,
+		`CREATE INDEX _check_instance_rollbacks ON soma.check_instance_rollbacks ( check_instance_id, created_at );`,
+	}
+	stmts = append(stmts,
+		fmt.Sprintf("INSERT INTO public.schema_versions (schema, version, description) VALUES ('soma', 201902010006, 'Upgrade - somadbctl %s');", tool),
+	)
+	executeUpgrades(stmts, printOnly)
+	return 201902010006
+}
+
+func upgradeSomaTo201902010007(curr int, tool string, printOnly bool) int {
+	if curr != 201902010006 {
+		return 0
+	}
+	stmts := []string{
+		`ALTER TABLE soma.constraints_custom_property ADD COLUMN IF NOT EXISTS operator varchar(16) NOT NULL DEFAULT '==';`,
+		`ALTER TABLE soma.constraints_system_property ADD COLUMN IF NOT EXISTS operator varchar(16) NOT NULL DEFAULT '==';`,
+		`ALTER TABLE soma.constraints_native_property ADD COLUMN IF NOT EXISTS operator varchar(16) NOT NULL DEFAULT '==';`,
+		`ALTER TABLE soma.constraints_service_property ADD COLUMN IF NOT EXISTS operator varchar(16) NOT NULL DEFAULT '==';`,
+		`ALTER TABLE soma.constraints_service_attribute ADD COLUMN IF NOT EXISTS operator varchar(16) NOT NULL DEFAULT '==';`,
+	}
+	stmts = append(stmts,
+		fmt.Sprintf("INSERT INTO public.schema_versions (schema, version, description) VALUES ('soma', 201902010007, 'Upgrade - somadbctl %s');", tool),
+	)
+	executeUpgrades(stmts, printOnly)
+	return 201902010007
+}
+
+func upgradeSomaTo201902010008(curr int, tool string, printOnly bool) int {
+	if curr != 201902010007 {
+		return 0
+	}
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS soma.check_configuration_constraint_groups ( configuration_id uuid PRIMARY KEY REFERENCES soma.check_configurations ( configuration_id ) ON DELETE CASCADE DEFERRABLE, groups jsonb NOT NULL, CHECK ( jsonb_typeof( groups ) = 'array' ));`,
+	}
+	stmts = append(stmts,
+		fmt.Sprintf("INSERT INTO public.schema_versions (schema, version, description) VALUES ('soma', 201902010008, 'Upgrade - somadbctl %s');", tool),
+	)
+	executeUpgrades(stmts, printOnly)
+	return 201902010008
+}
+
+func upgradeSomaTo201902010009(curr int, tool string, printOnly bool) int {
+	if curr != 201902010008 {
+		return 0
+	}
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS soma.threshold_overrides ( override_id uuid PRIMARY KEY, configuration_id uuid NOT NULL, repository_id uuid NOT NULL REFERENCES soma.repository ( id ) DEFERRABLE, object_id uuid NOT NULL, object_type varchar(64) NOT NULL REFERENCES soma.object_types ( object_type ) DEFERRABLE, deleted boolean NOT NULL DEFAULT 'no', FOREIGN KEY ( configuration_id, repository_id ) REFERENCES soma.check_configurations ( configuration_id, repository_id ) ON DELETE CASCADE DEFERRABLE, CHECK ( object_type IN ( 'repository', 'bucket', 'group', 'cluster', 'node' ) ));`,
+		`CREATE UNIQUE INDEX _unique_active_threshold_override ON soma.threshold_overrides ( configuration_id, object_id ) WHERE NOT deleted;`,
+		`CREATE TABLE IF NOT EXISTS soma.threshold_override_thresholds ( override_id uuid NOT NULL REFERENCES soma.threshold_overrides ( override_id ) ON DELETE CASCADE DEFERRABLE, predicate varchar(4) NOT NULL REFERENCES soma.configuration_predicates ( predicate ) DEFERRABLE, threshold varchar(128) NOT NULL, notification_level varchar(16) NOT NULL REFERENCES soma.notification_levels ( level_name ) DEFERRABLE);`,
+		`ALTER TABLE soma.check_instance_configurations ADD COLUMN threshold_override_id uuid NULL REFERENCES soma.threshold_overrides ( override_id ) DEFERRABLE;`,
+	}
+	stmts = append(stmts,
+		fmt.Sprintf("INSERT INTO public.schema_versions (schema, version, description) VALUES ('soma', 201902010009, 'Upgrade - somadbctl %s');", tool),
+	)
+	executeUpgrades(stmts, printOnly)
+	return 201902010009
+}
+
+func upgradeSomaTo201902010010(curr int, tool string, printOnly bool) int {
+	if curr != 201902010009 {
+		return 0
+	}
+	stmts := []string{
+		`ALTER TABLE soma.check_instance_configurations ADD COLUMN threshold_values jsonb NULL;`,
+	}
+	stmts = append(stmts,
+		fmt.Sprintf("INSERT INTO public.schema_versions (schema, version, description) VALUES ('soma', 201902010010, 'Upgrade - somadbctl %s');", tool),
+	)
+	executeUpgrades(stmts, printOnly)
+	return 201902010010
+}
+
 func installRoot201605150001(curr int, tool string, printOnly bool) int {
 	if curr != 000000000001 {
 		return 0
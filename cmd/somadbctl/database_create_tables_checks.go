@@ -140,6 +140,7 @@ create table if not exists soma.constraints_custom_property (
     custom_property_id          uuid            NOT NULL REFERENCES soma.custom_properties ( custom_property_id ) DEFERRABLE,
     repository_id               uuid            NOT NULL REFERENCES soma.repository (id) DEFERRABLE,
     property_value              text            NOT NULL,
+    operator                    varchar(16)     NOT NULL DEFAULT '==',
     -- ensure this custom property is defined for this repository
     FOREIGN KEY ( repository_id, custom_property_id ) REFERENCES soma.custom_properties ( repository_id, custom_property_id ) DEFERRABLE,
     -- ensure the configuration_id is for the repository the custom property is defined in
@@ -152,7 +153,8 @@ create table if not exists soma.constraints_custom_property (
 create table if not exists soma.constraints_system_property (
     configuration_id            uuid            NOT NULL REFERENCES soma.check_configurations ( configuration_id ) DEFERRABLE,
     system_property             varchar(128)    NOT NULL REFERENCES soma.system_properties ( system_property ) DEFERRABLE,
-    property_value              text            NOT NULL
+    property_value              text            NOT NULL,
+    operator                    varchar(16)     NOT NULL DEFAULT '=='
 );`
 	queries[idx] = "createTableCheckConstraintsSystemProperty"
 	idx++
@@ -161,7 +163,8 @@ create table if not exists soma.constraints_system_property (
 create table if not exists soma.constraints_native_property (
     configuration_id            uuid            NOT NULL REFERENCES soma.check_configurations ( configuration_id ) DEFERRABLE,
     native_property             varchar(128)    NOT NULL REFERENCES soma.native_properties ( native_property ) DEFERRABLE,
-    property_value              text            NOT NULL
+    property_value              text            NOT NULL,
+    operator                    varchar(16)     NOT NULL DEFAULT '=='
 );`
 	queries[idx] = "createTableCheckConstraintsNativeProperty"
 	idx++
@@ -170,7 +173,8 @@ create table if not exists soma.constraints_native_property (
 create table if not exists soma.constraints_service_property (
     configuration_id            uuid            NOT NULL REFERENCES soma.check_configurations ( configuration_id ) DEFERRABLE,
     team_id                     uuid            NOT NULL,
-    name                        varchar(128)    NOT NULL
+    name                        varchar(128)    NOT NULL,
+    operator                    varchar(16)     NOT NULL DEFAULT '=='
 );`
 	queries[idx] = "createTableCheckConstraintsServiceProperty"
 	idx++
@@ -179,7 +183,8 @@ create table if not exists soma.constraints_service_property (
 create table if not exists soma.constraints_service_attribute (
     configuration_id            uuid            NOT NULL REFERENCES soma.check_configurations ( configuration_id ) DEFERRABLE,
     attribute                   varchar(128)    NOT NULL REFERENCES soma.attribute ( attribute ) DEFERRABLE,
-    value                       varchar(512)
+    value                       varchar(512),
+    operator                    varchar(16)     NOT NULL DEFAULT '=='
 );`
 	queries[idx] = "createTableCheckConstraintsServiceAttributes"
 	idx++
@@ -190,6 +195,98 @@ create table if not exists soma.constraints_oncall_property (
     oncall_duty_id              uuid            NOT NULL REFERENCES inventory.oncall_team ( id ) DEFERRABLE
 );`
 	queries[idx] = "createTableCheckConstraintsOncallProperty"
+	idx++
+
+	queryMap["createTableCheckConfigurationRollout"] = `
+create table if not exists soma.check_configuration_rollout (
+    configuration_id            uuid            PRIMARY KEY REFERENCES soma.check_configurations ( configuration_id ) ON DELETE CASCADE DEFERRABLE,
+    wave_size                   integer         NULL,
+    wave_percent                integer         NULL,
+    soak_seconds                integer         NOT NULL DEFAULT 0,
+    failure_threshold           integer         NOT NULL DEFAULT 1,
+    wave                        integer         NOT NULL DEFAULT 0,
+    wave_released_at            timestamptz(3)  NULL,
+    rollout_started_at          timestamptz(3)  NULL,
+    halted_at                   timestamptz(3)  NULL,
+    CHECK ( num_nonnulls( wave_size, wave_percent ) = 1 ),
+    CHECK ( wave_size IS NULL OR wave_size > 0 ),
+    CHECK ( wave_percent IS NULL OR ( wave_percent > 0 AND wave_percent <= 100 ) ),
+    CHECK ( soak_seconds >= 0 ),
+    CHECK ( failure_threshold > 0 ),
+    CHECK ( wave >= 0 ),
+    CHECK ( EXTRACT( TIMEZONE FROM wave_released_at ) = '0' ),
+    CHECK ( EXTRACT( TIMEZONE FROM rollout_started_at ) = '0' ),
+    CHECK ( EXTRACT( TIMEZONE FROM halted_at ) = '0' )
+);`
+	queries[idx] = "createTableCheckConfigurationRollout"
+	idx++
+
+	queryMap["createTableCheckConfigurationConstraintGroups"] = `
+create table if not exists soma.check_configuration_constraint_groups (
+    configuration_id            uuid            PRIMARY KEY REFERENCES soma.check_configurations ( configuration_id ) ON DELETE CASCADE DEFERRABLE,
+    groups                      jsonb           NOT NULL,
+    CHECK ( jsonb_typeof( groups ) = 'array' )
+);`
+	queries[idx] = "createTableCheckConfigurationConstraintGroups"
+	idx++
+
+	queryMap["createTableRollbackPolicies"] = `
+create table if not exists soma.rollback_policies (
+    rollback_policy_id          uuid            PRIMARY KEY,
+    repository_id               uuid            NOT NULL REFERENCES soma.repository ( id ) ON DELETE CASCADE DEFERRABLE,
+    configuration_id            uuid            NULL REFERENCES soma.check_configurations ( configuration_id ) ON DELETE CASCADE DEFERRABLE,
+    created_by                  uuid            NOT NULL REFERENCES inventory.user ( id ) DEFERRABLE,
+    created_at                  timestamptz(3)  NOT NULL DEFAULT NOW(),
+    -- ensure the configuration_id is for the repository the policy is defined in
+    FOREIGN KEY ( configuration_id, repository_id ) REFERENCES soma.check_configurations ( configuration_id, repository_id ) ON DELETE CASCADE DEFERRABLE,
+    CHECK ( EXTRACT( TIMEZONE FROM created_at ) = '0' )
+);`
+	queries[idx] = "createTableRollbackPolicies"
+	idx++
+
+	queryMap[`createUniqueIndexRepositoryRollbackPolicy`] = `
+create unique index _unique_repository_rollback_policy
+    on soma.rollback_policies ( repository_id )
+    where configuration_id IS NULL;`
+	queries[idx] = `createUniqueIndexRepositoryRollbackPolicy`
+	idx++
+
+	queryMap[`createUniqueIndexConfigurationRollbackPolicy`] = `
+create unique index _unique_configuration_rollback_policy
+    on soma.rollback_policies ( configuration_id )
+    where configuration_id IS NOT NULL;`
+	queries[idx] = `createUniqueIndexConfigurationRollbackPolicy`
+	idx++
+
+	queryMap["createTableThresholdOverrides"] = `
+create table if not exists soma.threshold_overrides (
+    override_id                 uuid            PRIMARY KEY,
+    configuration_id            uuid            NOT NULL,
+    repository_id               uuid            NOT NULL REFERENCES soma.repository ( id ) DEFERRABLE,
+    object_id                   uuid            NOT NULL,
+    object_type                 varchar(64)     NOT NULL REFERENCES soma.object_types ( object_type ) DEFERRABLE,
+    deleted                     boolean         NOT NULL DEFAULT 'no',
+    FOREIGN KEY ( configuration_id, repository_id ) REFERENCES soma.check_configurations ( configuration_id, repository_id ) ON DELETE CASCADE DEFERRABLE,
+    CHECK ( object_type IN ( 'repository', 'bucket', 'group', 'cluster', 'node' ) )
+);`
+	queries[idx] = "createTableThresholdOverrides"
+	idx++
+
+	queryMap[`createUniqueIndexActiveThresholdOverride`] = `
+create unique index _unique_active_threshold_override
+    on soma.threshold_overrides ( configuration_id, object_id )
+    where NOT deleted;`
+	queries[idx] = `createUniqueIndexActiveThresholdOverride`
+	idx++
+
+	queryMap["createTableThresholdOverrideThresholds"] = `
+create table if not exists soma.threshold_override_thresholds (
+    override_id                 uuid            NOT NULL REFERENCES soma.threshold_overrides ( override_id ) ON DELETE CASCADE DEFERRABLE,
+    predicate                   varchar(4)      NOT NULL REFERENCES soma.configuration_predicates ( predicate ) DEFERRABLE,
+    threshold                   varchar(128)    NOT NULL,
+    notification_level          varchar(16)     NOT NULL REFERENCES soma.notification_levels ( level_name ) DEFERRABLE
+);`
+	queries[idx] = "createTableThresholdOverrideThresholds"
 
 	performDatabaseTask(printOnly, verbose, queries, queryMap)
 }
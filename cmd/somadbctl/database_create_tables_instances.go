@@ -58,6 +58,8 @@ create table if not exists soma.check_instance_configurations (
     next_status                 varchar(32)     NOT NULL REFERENCES soma.check_instance_status ( status ) DEFERRABLE,
     awaiting_deletion           boolean         NOT NULL DEFAULT 'no',
     deployment_details          jsonb           NOT NULL,
+    threshold_override_id       uuid            NULL REFERENCES soma.threshold_overrides ( override_id ) DEFERRABLE,
+    threshold_values            jsonb           NULL,
     CHECK ( status != 'none' ),
     CHECK ( status = 'awaiting_computation' OR monitoring_id IS NOT NULL )
 );`
@@ -116,6 +118,88 @@ create index _config_dependencies
     blocking_instance_config_id
 );`
 	queries[idx] = `createIndexConfigurationDependencies`
+	idx++
+
+	queryMap["createTableNotificationOutbox"] = `
+create table if not exists soma.notification_outbox (
+    notification_id             uuid            PRIMARY KEY,
+    monitoring_id               uuid            NOT NULL REFERENCES soma.monitoring_systems ( monitoring_id ) ON DELETE CASCADE DEFERRABLE,
+    check_instance_id           uuid            NOT NULL REFERENCES soma.check_instances ( check_instance_id ) ON DELETE CASCADE DEFERRABLE,
+    delivery_state              varchar(32)     NOT NULL DEFAULT 'pending',
+    attempts                    integer         NOT NULL DEFAULT 0,
+    last_error                  text            NULL,
+    created_at                  timestamptz(3)  NOT NULL DEFAULT NOW(),
+    next_attempt_at             timestamptz(3)  NOT NULL DEFAULT NOW(),
+    delivered_at                timestamptz(3)  NULL,
+    CHECK ( delivery_state IN ( 'pending', 'delivered', 'dead' ) ),
+    CHECK ( attempts >= 0 ),
+    CHECK ( EXTRACT( TIMEZONE FROM created_at ) = '0' ),
+    CHECK ( EXTRACT( TIMEZONE FROM next_attempt_at ) = '0' ),
+    CHECK ( EXTRACT( TIMEZONE FROM delivered_at ) = '0' )
+);`
+	queries[idx] = "createTableNotificationOutbox"
+	idx++
+
+	queryMap[`createIndexNotificationOutboxUndelivered`] = `
+create unique index _notification_outbox_undelivered
+    on soma.notification_outbox (
+    monitoring_id,
+    check_instance_id
+) where delivery_state != 'delivered';`
+	queries[idx] = `createIndexNotificationOutboxUndelivered`
+	idx++
+
+	queryMap[`createTableMaintenanceWindows`] = `
+create table if not exists soma.maintenance_windows (
+    maintenance_window_id       uuid            PRIMARY KEY,
+    scope                       varchar(32)     NOT NULL,
+    repository_id               uuid            NULL REFERENCES soma.repository ( id ) ON DELETE CASCADE DEFERRABLE,
+    bucket_id                   uuid            NULL REFERENCES soma.buckets ( bucket_id ) ON DELETE CASCADE DEFERRABLE,
+    environment                 varchar(32)     NULL REFERENCES soma.environments ( environment ) ON DELETE CASCADE DEFERRABLE,
+    monitoring_id               uuid            NULL REFERENCES soma.monitoring_systems ( monitoring_id ) ON DELETE CASCADE DEFERRABLE,
+    description                 text            NOT NULL DEFAULT '',
+    starts_at                   timestamptz(3)  NOT NULL,
+    ends_at                     timestamptz(3)  NOT NULL,
+    hold_jobs                   boolean         NOT NULL DEFAULT 'no',
+    cancelled_at                timestamptz(3)  NULL,
+    created_by                  uuid            NOT NULL REFERENCES inventory.user ( id ) DEFERRABLE,
+    created_at                  timestamptz(3)  NOT NULL DEFAULT NOW(),
+    CHECK ( scope IN ( 'repository', 'bucket', 'environment', 'monitoring' ) ),
+    CHECK ( scope != 'repository' OR repository_id IS NOT NULL ),
+    CHECK ( scope != 'bucket' OR bucket_id IS NOT NULL ),
+    CHECK ( scope != 'environment' OR environment IS NOT NULL ),
+    CHECK ( scope != 'monitoring' OR monitoring_id IS NOT NULL ),
+    CHECK ( num_nonnulls( repository_id, bucket_id, environment, monitoring_id ) = 1 ),
+    CHECK ( NOT hold_jobs OR scope = 'repository' ),
+    CHECK ( starts_at < ends_at ),
+    CHECK ( EXTRACT( TIMEZONE FROM starts_at ) = '0' ),
+    CHECK ( EXTRACT( TIMEZONE FROM ends_at ) = '0' ),
+    CHECK ( EXTRACT( TIMEZONE FROM cancelled_at ) = '0' ),
+    CHECK ( EXTRACT( TIMEZONE FROM created_at ) = '0' )
+);`
+	queries[idx] = `createTableMaintenanceWindows`
+	idx++
+
+	queryMap[`createTableCheckInstanceRollbacks`] = `
+create table if not exists soma.check_instance_rollbacks (
+    rollback_id                 uuid            PRIMARY KEY,
+    check_instance_id           uuid            NOT NULL REFERENCES soma.check_instances ( check_instance_id ) ON DELETE CASCADE DEFERRABLE,
+    failed_instance_config_id   uuid            NOT NULL REFERENCES soma.check_instance_configurations ( check_instance_config_id ) ON DELETE CASCADE DEFERRABLE,
+    restored_instance_config_id uuid            NOT NULL REFERENCES soma.check_instance_configurations ( check_instance_config_id ) ON DELETE CASCADE DEFERRABLE,
+    created_at                  timestamptz(3)  NOT NULL DEFAULT NOW(),
+    CHECK ( failed_instance_config_id != restored_instance_config_id ),
+    CHECK ( EXTRACT( TIMEZONE FROM created_at ) = '0' )
+);`
+	queries[idx] = `createTableCheckInstanceRollbacks`
+	idx++
+
+	queryMap[`createIndexCheckInstanceRollbacks`] = `
+create index _check_instance_rollbacks
+    on soma.check_instance_rollbacks (
+    check_instance_id,
+    created_at
+);`
+	queries[idx] = `createIndexCheckInstanceRollbacks`
 
 	performDatabaseTask(printOnly, verbose, queries, queryMap)
 }
@@ -57,7 +57,12 @@ create table if not exists soma.monitoring_systems (
     monitoring_contact          uuid            NOT NULL REFERENCES inventory.user ( id ) DEFERRABLE,
     monitoring_owner_team       uuid            NOT NULL REFERENCES inventory.team ( id ) DEFERRABLE,
     monitoring_callback_uri     text,
-    UNIQUE ( monitoring_id, monitoring_system_mode )
+    monitoring_batch_size       integer         NOT NULL DEFAULT 0,
+    monitoring_batch_flush      integer         NOT NULL DEFAULT 0,
+    monitoring_secret           text            NULL,
+    UNIQUE ( monitoring_id, monitoring_system_mode ),
+    CHECK ( monitoring_batch_size >= 0 ),
+    CHECK ( monitoring_batch_flush >= 0 )
 );`
 	queries[idx] = "createTableMonitoringSystems"
 	idx++
@@ -14,7 +14,9 @@ import (
 	"strings"
 
 	"github.com/codegangsta/cli"
+	"github.com/mjolnir42/soma/lib/constraint"
 	"github.com/mjolnir42/soma/lib/proto"
+	"github.com/mjolnir42/soma/lib/threshold"
 )
 
 // ParseVariadicArguments parses split up argument lists of
@@ -130,7 +132,8 @@ func ParseVariadicCheckArguments(
 		`interval`,
 		`inheritance`,
 		`childrenonly`,
-		`extern`}
+		`extern`,
+		`rollout`}
 	required := []string{
 		`in`,
 		`on`,
@@ -200,17 +203,46 @@ argloop:
 						` constraint specification`)
 					goto abort
 				}
+				// the operator between key and value is optional and
+				// given with the keyword operator
+				count := 3
+				if len(args[pos+1:]) >= 5 && args[pos+3] == `operator` {
+					count = 5
+				}
 				constr := proto.CheckConfigConstraint{}
 				if err := parseConstraintChain(
 					&constr,
-					args[pos+1:pos+4],
+					args[pos+1:pos+1+count],
 				); err != nil {
 					errors = append(errors, err.Error())
 					goto abort
 				}
 				*constraints = append(*constraints, constr)
 				skip = true
-				skipcount = 3
+				skipcount = count
+				continue argloop
+
+			case `rollout`:
+				// argument is the start of a rollout policy
+				// specification. check we have enough arguments left
+				if len(args[pos+1:]) < 6 {
+					errors = append(errors, `Syntax error, incomplete`+
+						` rollout specification`)
+					goto abort
+				}
+				if err := parseRolloutChain(
+					result,
+					args[pos+1:pos+7],
+				); err != nil {
+					errors = append(errors, err.Error())
+					goto abort
+				}
+				// set for unique checks
+				result[val] = append(result[val], fmt.Sprintf(
+					"%s::%s::%s", args[pos+2], args[pos+4],
+					args[pos+6]))
+				skip = true
+				skipcount = 6
 				continue argloop
 
 			case `on`:
@@ -275,6 +307,127 @@ abort:
 	return nil
 }
 
+// ParseVariadicOverrideArguments is a version of
+// ParseVariadicArguments for threshold overrides of check
+// configurations, which accepts the keywords in, on and threshold.
+func ParseVariadicOverrideArguments(
+	result map[string][]string,
+	thresholds *[]proto.CheckConfigThreshold,
+	args []string,
+) error {
+	return parseVariadicThresholdArguments(
+		result,
+		thresholds,
+		[]string{`in`, `on`, `threshold`},
+		[]string{`in`, `on`},
+		args,
+	)
+}
+
+// ParseVariadicCheckUpdateArguments is a version of
+// ParseVariadicArguments for updates of check configurations, which
+// accepts the keywords in, interval and threshold.
+func ParseVariadicCheckUpdateArguments(
+	result map[string][]string,
+	thresholds *[]proto.CheckConfigThreshold,
+	args []string,
+) error {
+	return parseVariadicThresholdArguments(
+		result,
+		thresholds,
+		[]string{`in`, `interval`, `threshold`},
+		[]string{`in`},
+		args,
+	)
+}
+
+// parseVariadicThresholdArguments parses args for the keywords in
+// keys. The keyword threshold may be given multiple times, all
+// other keywords at most once. The keywords in mandatory must be
+// given.
+func parseVariadicThresholdArguments(
+	result map[string][]string,
+	thresholds *[]proto.CheckConfigThreshold,
+	keys []string,
+	mandatory []string,
+	args []string,
+) error {
+	errors := []string{}
+
+	for pos := 0; pos < len(args); pos++ {
+		val := args[pos]
+		if !sliceContainsString(val, keys) {
+			errors = append(errors, fmt.Sprintf("Syntax error, erroneus"+
+				" argument: %s", val))
+			continue
+		}
+		if len(args[pos+1:]) < 1 {
+			errors = append(errors, `Syntax error, incomplete`+
+				` key/value specification (too few items left`+
+				` to parse)`,
+			)
+			break
+		}
+		if err := checkStringNotAKeyword(args[pos+1], keys); err != nil {
+			errors = append(errors, err.Error())
+			break
+		}
+
+		switch val {
+		case `threshold`:
+			if len(args[pos+1:]) < 6 {
+				errors = append(errors, `Syntax error, incomplete`+
+					`threshold specification`)
+				goto abort
+			}
+			thr := proto.CheckConfigThreshold{}
+			if err := parseThresholdChain(
+				&thr,
+				args[pos+1:pos+7],
+			); err != nil {
+				errors = append(errors, err.Error())
+				goto abort
+			}
+			*thresholds = append(*thresholds, thr)
+			pos += 6
+		case `on`:
+			if len(args[pos+1:]) < 2 {
+				errors = append(errors, `Syntax error, incomplete`+
+					` object specification`)
+				goto abort
+			}
+			result[`on/type`] = append(result[`on/type`], args[pos+1])
+			result[`on/object`] = append(result[`on/object`], args[pos+2])
+			result[val] = append(result[val], fmt.Sprintf(
+				"%s::%s", args[pos+1], args[pos+2]))
+			pos += 2
+		default:
+			result[val] = append(result[val], args[pos+1])
+			pos++
+		}
+	}
+
+	for _, key := range mandatory {
+		if _, ok := result[key]; !ok {
+			errors = append(errors, fmt.Sprintf("Syntax error,"+
+				" missing keyword: %s", key))
+		}
+	}
+	for _, key := range keys {
+		if sl, ok := result[key]; ok && len(sl) > 1 {
+			errors = append(errors, fmt.Sprintf("Syntax error,"+
+				" keyword must only be provided once: %s", key))
+		}
+	}
+
+abort:
+	if len(errors) > 0 {
+		return fmt.Errorf(combineStrings(errors...))
+	}
+
+	return nil
+}
+
 // ParseVariadicTriples is a variant of ParseVariadicArguments where
 // every keyword is followed by two values
 func ParseVariadicTriples(
@@ -451,6 +604,15 @@ func parseThresholdChain(result *proto.CheckConfigThreshold,
 	var err error
 	result.Predicate.Symbol = tParse[`predicate`][0]
 	result.Level.Name = tParse[`level`][0]
+	if threshold.IsTemplate(tParse[`value`][0]) {
+		if _, err = threshold.ParseTemplate(
+			tParse[`value`][0],
+		); err != nil {
+			return fmt.Errorf("Syntax error, %s", err.Error())
+		}
+		result.ValueTemplate = tParse[`value`][0]
+		return nil
+	}
 	if result.Value, err = strconv.ParseInt(
 		tParse[`value`][0], 10, 64,
 	); err != nil {
@@ -460,10 +622,43 @@ func parseThresholdChain(result *proto.CheckConfigThreshold,
 	return nil
 }
 
+// parseRolloutChain parses the rollout policy specification given
+// to ParseVariadicCheckArguments into result
+func parseRolloutChain(result map[string][]string, args []string) error {
+	rParse := make(map[string][]string)
+	if err := ParseVariadicArguments(
+		rParse,
+		[]string{},
+		[]string{`wave`, `soak`, `halt`},
+		[]string{`wave`, `soak`, `halt`},
+		args,
+	); err != nil {
+		return err
+	}
+	for _, key := range []string{`wave`, `soak`, `halt`} {
+		result[`rollout/`+key] = append(result[`rollout/`+key],
+			rParse[key][0])
+	}
+	return nil
+}
+
 // parseConstraintChain parses a single constraint specification
-// given to ParseVariadicCheckArguments
+// given to ParseVariadicCheckArguments. The specification is either
+// <type> <key> <value> or <type> <key> operator <operator> <value>.
 func parseConstraintChain(result *proto.CheckConfigConstraint,
 	args []string) error {
+	if len(args) == 5 {
+		result.Operator = args[3]
+		args = []string{args[0], args[1], args[4]}
+	} else if args[2] == proto.ConstraintUndefined {
+		result.Operator = proto.ConstraintUndefined
+	}
+	if err := constraint.Validate(
+		result.EffectiveOperator(), args[2],
+	); err != nil {
+		return err
+	}
+
 	result.ConstraintType = args[0]
 	switch result.ConstraintType {
 	case `service`:
@@ -477,6 +672,10 @@ func parseConstraintChain(result *proto.CheckConfigConstraint,
 		}
 
 	case `oncall`:
+		if result.EffectiveOperator() != proto.ConstraintEqual {
+			return fmt.Errorf("Syntax error, oncall constraints do not"+
+				" support operator %s", result.Operator)
+		}
 		result.Oncall = &proto.PropertyOncall{}
 		switch args[1] {
 		case `id`:
@@ -0,0 +1,141 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package adm // import "github.com/mjolnir42/soma/internal/adm"
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mjolnir42/soma/lib/proto"
+)
+
+func TestParseVariadicCheckArgumentsConstraint(t *testing.T) {
+	base := []string{`in`, `bucket`, `on`, `group`, `group`, `with`,
+		`capability`, `interval`, `60`}
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected []proto.CheckConfigConstraint
+		fails    bool
+	}{
+		{
+			name: `without operator`,
+			args: []string{`constraint`, `system`, `fqdn`, `node1`},
+			expected: []proto.CheckConfigConstraint{{
+				ConstraintType: `system`,
+				System: &proto.PropertySystem{
+					Name:  `fqdn`,
+					Value: `node1`,
+				},
+			}},
+		},
+		{
+			name: `with operator`,
+			args: []string{`constraint`, `system`, `fqdn`, `operator`,
+				`!~`, `\.lab$`},
+			expected: []proto.CheckConfigConstraint{{
+				ConstraintType: `system`,
+				Operator:       `!~`,
+				System: &proto.PropertySystem{
+					Name:  `fqdn`,
+					Value: `\.lab$`,
+				},
+			}},
+		},
+		{
+			name: `operator symbol as value`,
+			args: []string{`constraint`, `custom`, `cmp`, `>=`,
+				`constraint`, `system`, `cpu_cores`, `operator`, `>=`,
+				`8`},
+			expected: []proto.CheckConfigConstraint{
+				{
+					ConstraintType: `custom`,
+					Custom: &proto.PropertyCustom{
+						Name:  `cmp`,
+						Value: `>=`,
+					},
+				},
+				{
+					ConstraintType: `system`,
+					Operator:       `>=`,
+					System: &proto.PropertySystem{
+						Name:  `cpu_cores`,
+						Value: `8`,
+					},
+				},
+			},
+		},
+		{
+			name: `keyword as value`,
+			args: []string{`constraint`, `custom`, `role`, `operator`,
+				`==`, `operator`},
+			expected: []proto.CheckConfigConstraint{{
+				ConstraintType: `custom`,
+				Operator:       `==`,
+				Custom: &proto.PropertyCustom{
+					Name:  `role`,
+					Value: `operator`,
+				},
+			}},
+		},
+		{
+			name: `undefined`,
+			args: []string{`constraint`, `system`, `maintenance`,
+				`@undefined`},
+			expected: []proto.CheckConfigConstraint{{
+				ConstraintType: `system`,
+				Operator:       `@undefined`,
+				System: &proto.PropertySystem{
+					Name:  `maintenance`,
+					Value: `@undefined`,
+				},
+			}},
+		},
+		{
+			name: `unsupported operator`,
+			args: []string{`constraint`, `system`, `fqdn`, `operator`,
+				`=`, `node1`},
+			fails: true,
+		},
+		{
+			name: `invalid value`,
+			args: []string{`constraint`, `system`, `cpu_cores`,
+				`operator`, `>=`, `eight`},
+			fails: true,
+		},
+	}
+
+	for _, test := range tests {
+		opts := map[string][]string{}
+		constraints := []proto.CheckConfigConstraint{}
+		thresholds := []proto.CheckConfigThreshold{}
+		err := ParseVariadicCheckArguments(opts, &constraints,
+			&thresholds, append(append([]string{}, base...),
+				test.args...))
+		if test.fails {
+			if err == nil {
+				t.Errorf("%s: expected an error", test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test.name,
+				err.Error())
+			continue
+		}
+		if !reflect.DeepEqual(constraints, test.expected) {
+			t.Errorf("%s: expected %+v, got %+v", test.name,
+				test.expected, constraints)
+		}
+	}
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
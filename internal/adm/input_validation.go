@@ -383,20 +383,25 @@ func ValidateCheckConstraints(repoID, teamID string,
 			}
 			valid = append(valid, proto.CheckConfigConstraint{
 				ConstraintType: prop.ConstraintType,
+				Operator:       prop.Operator,
 				Oncall:         &oncall,
 			})
 
 		case `service`:
 			service := proto.PropertyService{}
 			var err error
-			if service.ID, err = LookupServicePropertyID(
-				prop.Service.Name, teamID); err != nil {
-				return nil, err
+			// only an equality constraint names a single service
+			if prop.EffectiveOperator() == proto.ConstraintEqual {
+				if service.ID, err = LookupServicePropertyID(
+					prop.Service.Name, teamID); err != nil {
+					return nil, err
+				}
 			}
 			service.Name = prop.Service.Name
 			service.TeamID = teamID
 			valid = append(valid, proto.CheckConfigConstraint{
 				ConstraintType: prop.ConstraintType,
+				Operator:       prop.Operator,
 				Service:        &service,
 			})
 
@@ -411,6 +416,7 @@ func ValidateCheckConstraints(repoID, teamID string,
 			custom.Value = prop.Custom.Value
 			valid = append(valid, proto.CheckConfigConstraint{
 				ConstraintType: prop.ConstraintType,
+				Operator:       prop.Operator,
 				Custom:         &custom,
 			})
 		}
@@ -441,9 +447,10 @@ func ValidateThresholds(thresholds []proto.CheckConfigThreshold) (
 			}
 		}
 		t := proto.CheckConfigThreshold{
-			Value:     thr.Value,
-			Predicate: proto.Predicate{},
-			Level:     proto.Level{},
+			Value:         thr.Value,
+			ValueTemplate: thr.ValueTemplate,
+			Predicate:     proto.Predicate{},
+			Level:         proto.Level{},
 		}
 		if err = LookupLevelName(
 			thr.Level.Name,
@@ -460,6 +467,45 @@ func ValidateThresholds(thresholds []proto.CheckConfigThreshold) (
 	return valid, nil
 }
 
+// ValidateRollout tests the rollout policy specification and
+// returns it. The wave size is either a count of check instances or
+// a percentage suffixed with %, soak is the number of seconds
+// between waves and halt the number of failures that halt the
+// rollout.
+func ValidateRollout(wave, soak, halt string) (
+	*proto.CheckConfigRollout, error) {
+	var err error
+	rollout := &proto.CheckConfigRollout{}
+
+	if strings.HasSuffix(wave, `%`) {
+		if err = ValidateLBoundUint64(
+			strings.TrimSuffix(wave, `%`),
+			&rollout.WavePercent, 1,
+		); err != nil {
+			return nil, err
+		}
+		if rollout.WavePercent > 100 {
+			return nil, fmt.Errorf("Error, wave percentage %d"+
+				" exceeds 100", rollout.WavePercent)
+		}
+	} else if err = ValidateLBoundUint64(
+		wave, &rollout.WaveSize, 1,
+	); err != nil {
+		return nil, err
+	}
+	if err = ValidateLBoundUint64(
+		soak, &rollout.SoakTime, 0,
+	); err != nil {
+		return nil, err
+	}
+	if err = ValidateLBoundUint64(
+		halt, &rollout.FailureThreshold, 1,
+	); err != nil {
+		return nil, err
+	}
+	return rollout, nil
+}
+
 // VerifyPermissionTarget verifies that the string target is something
 // that permissions can be granted to. Valid values are user, admin,
 // tool and team.
@@ -8,8 +8,9 @@ import (
 
 // I'm sorry as well.
 func CheckConfigCreate(c *cli.Context) {
-	topArgs := []string{`in`, `on`, `with`, `interval`, `inheritance`, `childrenonly`, `extern`, `threshold`, `constraint`}
+	topArgs := []string{`in`, `on`, `with`, `interval`, `inheritance`, `childrenonly`, `extern`, `rollout`, `threshold`, `constraint`}
 	thrArgs := []string{`predicate`, `level`, `value`}
+	rolArgs := []string{`wave`, `soak`, `halt`}
 	ctrArgs := []string{`service`, `oncall`, `attribute`, `system`, `native`, `custom`}
 	onArgs := []string{`repository`, `bucket`, `group`, `cluster`, `node`}
 
@@ -27,6 +28,7 @@ func CheckConfigCreate(c *cli.Context) {
 	subON := false
 	subTHRESHOLD := false
 	subCONSTRAINT := false
+	subROLLOUT := false
 
 	hasIN := false
 	hasON := false
@@ -35,11 +37,16 @@ func CheckConfigCreate(c *cli.Context) {
 	hasINHERITANCE := false
 	hasCHILDRENONLY := false
 	hasEXTERN := false
+	hasROLLOUT := false
 
 	hasTHRPredicate := false
 	hasTHRLevel := false
 	hasTHRValue := false
 
+	hasROLWave := false
+	hasROLSoak := false
+	hasROLHalt := false
+
 	hasCTRService := false
 	hasCTROncall := false
 	hasCTRAttribute := false
@@ -48,6 +55,7 @@ func CheckConfigCreate(c *cli.Context) {
 	hasCTRCustom := false
 	hasCTRSelectedService := false
 	hasCTRSelectedOncall := false
+	hasCTRKey := false
 
 	for _, t := range c.Args().Tail() {
 		if skipNext > 0 {
@@ -81,6 +89,26 @@ func CheckConfigCreate(c *cli.Context) {
 				}
 			}
 		}
+		if subROLLOUT {
+			if hasROLWave && hasROLSoak && hasROLHalt {
+				subROLLOUT = false
+			} else {
+				switch t {
+				case `wave`:
+					skipNext = 1
+					hasROLWave = true
+					continue
+				case `soak`:
+					skipNext = 1
+					hasROLSoak = true
+					continue
+				case `halt`:
+					skipNext = 1
+					hasROLHalt = true
+					continue
+				}
+			}
+		}
 		if subCONSTRAINT {
 			if hasCTRSelectedService {
 				skipNext = 1
@@ -92,6 +120,15 @@ func CheckConfigCreate(c *cli.Context) {
 				hasCTRSelectedOncall = false
 				continue
 			}
+			// the key is followed by the value, or by the keyword
+			// operator with the operator and the value
+			if hasCTRKey {
+				hasCTRKey = false
+				if t == `operator` {
+					skipNext = 2
+				}
+				continue
+			}
 			if hasCTRService || hasCTROncall || hasCTRAttribute || hasCTRSystem || hasCTRNative || hasCTRCustom {
 				subCONSTRAINT = false
 				hasCTRService = false
@@ -113,19 +150,23 @@ func CheckConfigCreate(c *cli.Context) {
 					hasCTROncall = true
 					continue
 				case `attribute`:
-					skipNext = 2
+					skipNext = 1
+					hasCTRKey = true
 					hasCTRAttribute = true
 					continue
 				case `system`:
-					skipNext = 2
+					skipNext = 1
+					hasCTRKey = true
 					hasCTRSystem = true
 					continue
 				case `native`:
-					skipNext = 2
+					skipNext = 1
+					hasCTRKey = true
 					hasCTRNative = true
 					continue
 				case `custom`:
-					skipNext = 2
+					skipNext = 1
+					hasCTRKey = true
 					hasCTRCustom = true
 					continue
 				}
@@ -161,6 +202,10 @@ func CheckConfigCreate(c *cli.Context) {
 			skipNext = 1
 			hasEXTERN = true
 			continue
+		case `rollout`:
+			hasROLLOUT = true
+			subROLLOUT = true
+			continue
 		case `threshold`:
 			subTHRESHOLD = true
 			continue
@@ -169,8 +214,8 @@ func CheckConfigCreate(c *cli.Context) {
 			continue
 		}
 	}
-	// skipNext not yet consumed
-	if skipNext > 0 {
+	// skipNext not yet consumed, or the constraint value is missing
+	if skipNext > 0 || hasCTRKey {
 		return
 	}
 	// in subchain: ON
@@ -193,6 +238,28 @@ func CheckConfigCreate(c *cli.Context) {
 			return
 		}
 	}
+	// in subchain: ROLLOUT
+	if subROLLOUT {
+		if !(hasROLWave && hasROLSoak && hasROLHalt) {
+			for _, t := range rolArgs {
+				switch t {
+				case `wave`:
+					if !hasROLWave {
+						fmt.Println(t)
+					}
+				case `soak`:
+					if !hasROLSoak {
+						fmt.Println(t)
+					}
+				case `halt`:
+					if !hasROLHalt {
+						fmt.Println(t)
+					}
+				}
+			}
+			return
+		}
+	}
 	// in subchain: THRESHOLD
 	if subTHRESHOLD {
 		if !(hasTHRPredicate && hasTHRLevel && hasTHRValue) {
@@ -246,6 +313,158 @@ func CheckConfigCreate(c *cli.Context) {
 			if !hasEXTERN {
 				fmt.Println(t)
 			}
+		case `rollout`:
+			if !hasROLLOUT {
+				fmt.Println(t)
+			}
+		default:
+			fmt.Println(t)
+		}
+	}
+}
+
+// CheckConfigOverrideSet completes the arguments of
+// soma check-config override set
+func CheckConfigOverrideSet(c *cli.Context) {
+	checkConfigOverride(c, []string{`in`, `on`, `threshold`})
+}
+
+// CheckConfigOverrideRemove completes the arguments of
+// soma check-config override remove
+func CheckConfigOverrideRemove(c *cli.Context) {
+	checkConfigOverride(c, []string{`in`, `on`})
+}
+
+// CheckConfigUpdate completes the arguments of
+// soma check-config update
+func CheckConfigUpdate(c *cli.Context) {
+	checkConfigOverride(c, []string{`in`, `interval`, `threshold`})
+}
+
+func checkConfigOverride(c *cli.Context, topArgs []string) {
+	thrArgs := []string{`predicate`, `level`, `value`}
+	onArgs := []string{`repository`, `bucket`, `group`, `cluster`, `node`}
+
+	if c.NArg() == 0 {
+		return
+	}
+
+	if c.NArg() == 1 {
+		for _, t := range topArgs {
+			fmt.Println(t)
+		}
+	}
+
+	skipNext := 0
+	subON := false
+	subTHRESHOLD := false
+
+	hasIN := false
+	hasON := false
+	hasINTERVAL := false
+
+	hasTHRPredicate := false
+	hasTHRLevel := false
+	hasTHRValue := false
+
+	for _, t := range c.Args().Tail() {
+		if skipNext > 0 {
+			skipNext--
+			continue
+		}
+		if subON {
+			skipNext = 1
+			subON = false
+		}
+		if subTHRESHOLD {
+			if hasTHRPredicate && hasTHRLevel && hasTHRValue {
+				subTHRESHOLD = false
+				hasTHRPredicate = false
+				hasTHRLevel = false
+				hasTHRValue = false
+			} else {
+				switch t {
+				case `predicate`:
+					skipNext = 1
+					hasTHRPredicate = true
+					continue
+				case `level`:
+					skipNext = 1
+					hasTHRLevel = true
+					continue
+				case `value`:
+					skipNext = 1
+					hasTHRValue = true
+					continue
+				}
+			}
+		}
+		switch t {
+		case `in`:
+			skipNext = 1
+			hasIN = true
+			continue
+		case `on`:
+			hasON = true
+			subON = true
+			continue
+		case `interval`:
+			skipNext = 1
+			hasINTERVAL = true
+			continue
+		case `threshold`:
+			subTHRESHOLD = true
+			continue
+		}
+	}
+	// skipNext not yet consumed
+	if skipNext > 0 {
+		return
+	}
+	// in subchain: ON
+	if subON {
+		for _, t := range onArgs {
+			fmt.Println(t)
+		}
+		return
+	}
+	// in subchain: THRESHOLD
+	if subTHRESHOLD {
+		if !(hasTHRPredicate && hasTHRLevel && hasTHRValue) {
+			for _, t := range thrArgs {
+				switch t {
+				case `predicate`:
+					if !hasTHRPredicate {
+						fmt.Println(t)
+					}
+				case `level`:
+					if !hasTHRLevel {
+						fmt.Println(t)
+					}
+				case `value`:
+					if !hasTHRValue {
+						fmt.Println(t)
+					}
+				}
+			}
+			return
+		}
+	}
+	// not in any subchain
+	for _, t := range topArgs {
+		switch t {
+		case `in`:
+			if !hasIN {
+				fmt.Println(t)
+			}
+		case `on`:
+			if !hasON {
+				fmt.Println(t)
+			}
+		case `interval`:
+			if !hasINTERVAL {
+				fmt.Println(t)
+			}
 		default:
 			fmt.Println(t)
 		}
@@ -0,0 +1,9 @@
+package cmpl
+
+import "github.com/codegangsta/cli"
+
+func InstanceMgmtVersions(c *cli.Context) {
+	Generic(c, []string{`diff`, `against`})
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -0,0 +1,10 @@
+package cmpl
+
+import "github.com/codegangsta/cli"
+
+func MaintenanceCreate(c *cli.Context) {
+	Generic(c, []string{`repository`, `bucket`, `environment`,
+		`monitoring`, `from`, `until`, `hold-jobs`})
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
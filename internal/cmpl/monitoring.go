@@ -3,7 +3,11 @@ package cmpl
 import "github.com/codegangsta/cli"
 
 func MonitoringMgmtAdd(c *cli.Context) {
-	Generic(c, []string{`mode`, `contact`, `team`, `callback`})
+	Generic(c, []string{`mode`, `contact`, `team`, `callback`, `batchsize`, `flush`, `secret`})
+}
+
+func MonitoringMgmtSecret(c *cli.Context) {
+	Generic(c, []string{`value`})
 }
 
 // vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
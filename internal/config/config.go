@@ -30,6 +30,9 @@ type Config struct {
 	PokePath      string     `json:"notify.path.element"`
 	PokeBatchSize uint64     `json:"notify.batch.size,string"`
 	PokeTimeout   uint64     `json:"notify.timeout.ms,string"`
+	PokeRetries   uint64     `json:"notify.retry.limit,string"`
+	PokeBackoff   uint64     `json:"notify.backoff.base.seconds,string"`
+	PokeBackoffMx uint64     `json:"notify.backoff.max.seconds,string"`
 	Observer      bool       `json:"observer,string"`
 	ObserverRepo  string     `json:"-"`
 	NoPoke        bool       `json:"no.poke,string"`
@@ -42,6 +45,7 @@ type Config struct {
 	Version       string     `json:"version"`
 	Database      DbConfig   `json:"database"`
 	Daemon        Daemon     `json:"daemon"`
+	Metrics       Metrics    `json:"metrics"`
 	Auth          AuthConfig `json:"authentication"`
 	Ldap          LdapConfig `json:"ldap"`
 }
@@ -67,6 +71,17 @@ type Daemon struct {
 	Key    string   `json:"key.file"`
 }
 
+// Metrics configures the listener that exports the runtime metrics.
+// The listener is disabled if no port is configured and uses the TLS
+// settings of the daemon.
+type Metrics struct {
+	Listen          string `json:"listen"`
+	Port            string `json:"port"`
+	Path            string `json:"path"`
+	Unauthenticated bool   `json:"unauthenticated,string"`
+	CollectInterval uint64 `json:"collect.interval.seconds,string"`
+}
+
 // AuthConfig stores authentication settings for SOMA
 type AuthConfig struct {
 	KexExpirySeconds     uint64 `json:"kex.expiry,string"`
@@ -151,7 +166,7 @@ func (c *Config) ReadConfigFile(fname string) error {
 	}
 
 	if c.PokeBatchSize != 0 {
-		log.Println(`Configuration value notify.batch.size is deprecated and no longer has any effect.`)
+		log.Println(`Configuration value notify.batch.size is deprecated and no longer has any effect, batching is configured per monitoring system.`)
 	}
 
 	if c.PokeTimeout == 0 {
@@ -159,6 +174,21 @@ func (c *Config) ReadConfigFile(fname string) error {
 		c.PokeTimeout = 1000
 	}
 
+	if c.PokeRetries == 0 {
+		log.Println(`Setting default value for notify.retry.limit: 10`)
+		c.PokeRetries = 10
+	}
+
+	if c.PokeBackoff == 0 {
+		log.Println(`Setting default value for notify.backoff.base.seconds: 2`)
+		c.PokeBackoff = 2
+	}
+
+	if c.PokeBackoffMx == 0 {
+		log.Println(`Setting default value for notify.backoff.max.seconds: 900`)
+		c.PokeBackoffMx = 900
+	}
+
 	if c.PokePath == `` {
 		c.PokePath = `/deployment/id`
 		log.Printf("Setting default value for notify.path.element: %s",
@@ -174,6 +204,19 @@ func (c *Config) ReadConfigFile(fname string) error {
 		c.ShutdownDelay = 5
 	}
 
+	if c.Metrics.Path == `` {
+		c.Metrics.Path = `/metrics`
+	}
+
+	if c.Metrics.CollectInterval == 0 {
+		log.Println(`Setting default value for metrics.collect.interval.seconds: 30`)
+		c.Metrics.CollectInterval = 30
+	}
+
+	if c.Metrics.Port != `` && c.Metrics.Unauthenticated {
+		log.Println(`Metrics export configured without authentication`)
+	}
+
 	switch c.LogLevel {
 	case `debug`, `info`, `warn`, `error`, `fatal`, `panic`:
 	default:
@@ -86,6 +86,18 @@ func (h *Map) Range() map[string]Handler {
 	return h.hmap
 }
 
+// QueueDepth returns the number of requests currently queued in the
+// Intake() channel of each handler
+func (h *Map) QueueDepth() map[string]int {
+	h.RLock()
+	defer h.RUnlock()
+	depth := make(map[string]int, len(h.hmap))
+	for name, hdl := range h.hmap {
+		depth[name] = len(hdl.Intake())
+	}
+	return depth
+}
+
 // Register calls register() for each handler
 func (h *Map) Register(n string, c *sql.DB, l []*logrus.Logger) {
 	h.Lock()
@@ -41,6 +41,7 @@ const (
 	SectionJobStatusMgmt    = `job-status-mgmt`
 	SectionJobTypeMgmt      = `job-type-mgmt`
 	SectionLevel            = `level`
+	SectionMaintenance      = `maintenance`
 	SectionMetric           = `metric`
 	SectionMode             = `mode`
 	SectionMonitoringMgmt   = `monitoringsystem-mgmt`
@@ -128,6 +129,7 @@ const (
 	SectionCapability  = `capability`
 	SectionDeployment  = `deployment`
 	SectionMonitoring  = `monitoringsystem`
+	SectionOutbox      = `outbox`
 )
 
 // Actions for the various permission sections
@@ -137,10 +139,13 @@ const (
 	ActionAssemble        = `assemble`
 	ActionAssign          = `assign`
 	ActionAudit           = `audit`
+	ActionCancel          = `cancel`
 	ActionCreate          = `create`
 	ActionDeclare         = `declare`
+	ActionDiff            = `diff`
 	ActionDelete          = `delete`
 	ActionDestroy         = `destroy`
+	ActionExport          = `export`
 	ActionFailed          = `failed`
 	ActionFilter          = `filter`
 	ActionGet             = `get`
@@ -151,6 +156,9 @@ const (
 	ActionMemberAssign    = `member-assign`
 	ActionMemberList      = `member-list`
 	ActionMemberUnassign  = `member-unassign`
+	ActionMetrics         = `metrics`
+	ActionOverrideRemove  = `override-remove`
+	ActionOverrideSet     = `override-set`
 	ActionPending         = `pending`
 	ActionPropertyCreate  = `property-create`
 	ActionPropertyDestroy = `property-destroy`
@@ -161,9 +169,13 @@ const (
 	ActionRepoRebuild     = `rebuild-repository`
 	ActionRepoRestart     = `restart-repository`
 	ActionRepoStop        = `stop-repository`
+	ActionReplay          = `replay`
 	ActionRepossess       = `repossess`
+	ActionResume          = `resume`
 	ActionRetry           = `retry`
 	ActionRevoke          = `revoke`
+	ActionRollbackDisable = `rollback-disable`
+	ActionRollbackEnable  = `rollback-enable`
 	ActionSearch          = `search`
 	ActionSearchAll       = `search/all`
 	ActionSearchByList    = `search/list`
@@ -172,6 +184,7 @@ const (
 	ActionShow            = `show`
 	ActionShowConfig      = `show-config`
 	ActionShutdown        = `shutdown`
+	ActionStream          = `stream`
 	ActionSuccess         = `success`
 	ActionSummary         = `summary`
 	ActionSync            = `sync`
@@ -24,12 +24,14 @@ type Request struct {
 	RemoteAddr    string
 	AuthUser      string
 	RequestURI    string
-	Reply         chan Result `json:"-"`
+	Reply         chan Result     `json:"-"`
+	Done          <-chan struct{} `json:"-"`
 	JobID         uuid.UUID
 	Search        Filter
 	Update        UpdateData
 	Flag          Flags
 	DeploymentIDs []string
+	MonAuth       MonitoringAuth
 
 	Super *Supervisor
 	Cache *Request
@@ -54,6 +56,7 @@ type Request struct {
 	JobStatus   proto.JobStatus
 	JobType     proto.JobType
 	Level       proto.Level
+	Maintenance proto.MaintenanceWindow
 	Metric      proto.Metric
 	Mode        proto.Mode
 	Monitoring  proto.Monitoring
@@ -90,28 +93,41 @@ func New(r *http.Request, params httprouter.Params) Request {
 	}
 }
 
+// MonitoringAuth holds the authentication data a monitoring system
+// supplied with its request
+type MonitoringAuth struct {
+	Method    string
+	Path      string
+	Timestamp string
+	Signature string
+	Token     string
+	Body      []byte
+}
+
 type Filter struct {
-	IsDetailed bool
-	ActionObj  proto.Action
-	Bucket     proto.BucketFilter
-	Cluster    proto.Cluster
-	Grant      proto.Grant
-	Group      proto.Group
-	Job        proto.JobFilter
-	JobResult  proto.JobResult
-	JobStatus  proto.JobStatus
-	JobType    proto.JobType
-	Level      proto.Level
-	Monitoring proto.Monitoring
-	Node       proto.Node
-	Oncall     proto.Oncall
-	Permission proto.Permission
-	Property   proto.Property
-	Repository proto.RepositoryFilter
-	SectionObj proto.Section
-	Server     proto.Server
-	Team       proto.Team
-	User       proto.User
+	IsDetailed      bool
+	ActionObj       proto.Action
+	Bucket          proto.BucketFilter
+	Deployment      proto.DeploymentFilter
+	DeploymentEvent proto.DeploymentEventFilter
+	Cluster         proto.Cluster
+	Grant           proto.Grant
+	Group           proto.Group
+	Job             proto.JobFilter
+	JobResult       proto.JobResult
+	JobStatus       proto.JobStatus
+	JobType         proto.JobType
+	Level           proto.Level
+	Monitoring      proto.Monitoring
+	Node            proto.Node
+	Oncall          proto.Oncall
+	Permission      proto.Permission
+	Property        proto.Property
+	Repository      proto.RepositoryFilter
+	SectionObj      proto.Section
+	Server          proto.Server
+	Team            proto.Team
+	User            proto.User
 }
 
 type UpdateData struct {
@@ -135,6 +151,7 @@ type Flags struct {
 	Unscoped     bool
 	Rebuild      bool
 	RebuildLevel string
+	DryRun       bool
 }
 
 func CacheUpdateFromRequest(rq *Request) Request {
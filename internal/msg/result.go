@@ -26,49 +26,53 @@ type Result struct {
 
 	Super Supervisor
 
-	ActionObj      []proto.Action
-	Admin          []proto.Admin
-	Attribute      []proto.Attribute
-	Bucket         []proto.Bucket
-	Capability     []proto.Capability
-	Category       []proto.Category
-	CheckConfig    []proto.CheckConfig
-	Cluster        []proto.Cluster
-	Datacenter     []proto.Datacenter
-	Deployment     []proto.Deployment
-	Entity         []proto.Entity
-	Environment    []proto.Environment
-	Grant          []proto.Grant
-	Group          []proto.Group
-	HostDeployment []proto.HostDeployment
-	Instance       []proto.Instance
-	Job            []proto.Job
-	JobResult      []proto.JobResult
-	JobStatus      []proto.JobStatus
-	JobType        []proto.JobType
-	Level          []proto.Level
-	Metric         []proto.Metric
-	Mode           []proto.Mode
-	Monitoring     []proto.Monitoring
-	Node           []proto.Node
-	Oncall         []proto.Oncall
-	Permission     []proto.Permission
-	Predicate      []proto.Predicate
-	Property       []proto.Property
-	Provider       []proto.Provider
-	Repository     []proto.Repository
-	SectionObj     []proto.Section
-	Server         []proto.Server
-	State          []proto.State
-	Status         []proto.Status
-	System         []proto.System
-	Team           []proto.Team
-	Tree           proto.Tree
-	Unit           []proto.Unit
-	User           []proto.User
-	Validity       []proto.Validity
-	View           []proto.View
-	Workflow       []proto.Workflow
+	ActionObj         []proto.Action
+	Admin             []proto.Admin
+	Attribute         []proto.Attribute
+	Bucket            []proto.Bucket
+	Capability        []proto.Capability
+	Category          []proto.Category
+	CheckConfig       []proto.CheckConfig
+	Cluster           []proto.Cluster
+	Datacenter        []proto.Datacenter
+	Deployment        []proto.Deployment
+	DeploymentEvent   []proto.DeploymentEvent
+	Entity            []proto.Entity
+	Environment       []proto.Environment
+	Grant             []proto.Grant
+	Group             []proto.Group
+	HostDeployment    []proto.HostDeployment
+	Instance          []proto.Instance
+	Job               []proto.Job
+	JobResult         []proto.JobResult
+	JobStatus         []proto.JobStatus
+	JobType           []proto.JobType
+	Level             []proto.Level
+	MaintenanceWindow []proto.MaintenanceWindow
+	Metric            []proto.Metric
+	Mode              []proto.Mode
+	Monitoring        []proto.Monitoring
+	Node              []proto.Node
+	Notification      []proto.Notification
+	Oncall            []proto.Oncall
+	Permission        []proto.Permission
+	Predicate         []proto.Predicate
+	Property          []proto.Property
+	Provider          []proto.Provider
+	Repository        []proto.Repository
+	SectionObj        []proto.Section
+	Server            []proto.Server
+	State             []proto.State
+	Status            []proto.Status
+	System            []proto.System
+	Team              []proto.Team
+	Tree              proto.Tree
+	TreeAction        []proto.TreeAction
+	Unit              []proto.Unit
+	User              []proto.User
+	Validity          []proto.Validity
+	View              []proto.View
+	Workflow          []proto.Workflow
 }
 
 func FromRequest(rq *Request) Result {
@@ -179,6 +183,8 @@ func (r *Result) Clear(s string) {
 		r.Job = []proto.Job{}
 	case `level`:
 		r.Level = []proto.Level{}
+	case SectionMaintenance:
+		r.MaintenanceWindow = []proto.MaintenanceWindow{}
 	case `metric`:
 		r.Metric = []proto.Metric{}
 	case `mode`:
@@ -189,6 +195,8 @@ func (r *Result) Clear(s string) {
 		r.Node = []proto.Node{}
 	case `oncall`:
 		r.Oncall = []proto.Oncall{}
+	case SectionOutbox:
+		r.Notification = []proto.Notification{}
 	case `permission`:
 		r.Permission = []proto.Permission{}
 	case `predicate`:
@@ -162,7 +162,8 @@ permloop:
 		} else {
 			switch q.Section {
 			// per-monitoring scope
-			case msg.SectionMonitoring, msg.SectionCapability, msg.SectionDeployment:
+			case msg.SectionMonitoring, msg.SectionCapability, msg.SectionDeployment,
+				msg.SectionOutbox:
 				objID = q.Monitoring.ID
 			// per-team scope
 			case msg.SectionPropertyService:
@@ -187,7 +188,8 @@ permloop:
 
 		// check authorization
 		switch q.Section {
-		case msg.SectionMonitoring, msg.SectionCapability, msg.SectionDeployment:
+		case msg.SectionMonitoring, msg.SectionCapability, msg.SectionDeployment,
+			msg.SectionOutbox:
 			// per-monitoring sections
 			if c.grantMonitoring.assess(subjectType, subjectID,
 				category, objID, permID, any) {
@@ -40,6 +40,10 @@ func (x *Rest) HostDeploymentFetch(w http.ResponseWriter, r *http.Request,
 		x.replyBadRequest(&w, &request, err)
 		return
 	}
+	if err = monitoringAuth(r, &request.MonAuth); err != nil {
+		x.replyBadRequest(&w, &request, err)
+		return
+	}
 
 	request.Monitoring.ID = params.ByName(`monitoringID`)
 	request.Node.AssetID = assetID
@@ -75,6 +79,10 @@ func (x *Rest) HostDeploymentAssemble(w http.ResponseWriter, r *http.Request,
 		x.replyBadRequest(&w, &request, err)
 		return
 	}
+	if err = monitoringAuth(r, &request.MonAuth); err != nil {
+		x.replyBadRequest(&w, &request, err)
+		return
+	}
 
 	cReq := proto.NewHostDeploymentRequest()
 	if err = decodeJSONBody(r, &cReq); err != nil {
@@ -0,0 +1,140 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package rest // import "github.com/mjolnir42/soma/internal/rest"
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mjolnir42/soma/internal/msg"
+	"github.com/mjolnir42/soma/lib/proto"
+)
+
+// MaintenanceList function
+func (x *Rest) MaintenanceList(w http.ResponseWriter, r *http.Request,
+	params httprouter.Params) {
+	defer panicCatcher(w)
+
+	request := msg.New(r, params)
+	request.Section = msg.SectionMaintenance
+	request.Action = msg.ActionList
+
+	if !x.isAuthorized(&request) {
+		x.replyForbidden(&w, &request, nil)
+		return
+	}
+
+	x.handlerMap.MustLookup(&request).Intake() <- request
+	result := <-request.Reply
+	x.send(&w, &result)
+}
+
+// MaintenanceShow function
+func (x *Rest) MaintenanceShow(w http.ResponseWriter, r *http.Request,
+	params httprouter.Params) {
+	defer panicCatcher(w)
+
+	request := msg.New(r, params)
+	request.Section = msg.SectionMaintenance
+	request.Action = msg.ActionShow
+
+	if err := checkStringIsUUID(params.ByName(`maintenanceID`)); err != nil {
+		x.replyBadRequest(&w, &request, err)
+		return
+	}
+	request.Maintenance.ID = params.ByName(`maintenanceID`)
+
+	if !x.isAuthorized(&request) {
+		x.replyForbidden(&w, &request, nil)
+		return
+	}
+
+	x.handlerMap.MustLookup(&request).Intake() <- request
+	result := <-request.Reply
+	x.send(&w, &result)
+}
+
+// MaintenanceAdd function
+func (x *Rest) MaintenanceAdd(w http.ResponseWriter, r *http.Request,
+	params httprouter.Params) {
+	defer panicCatcher(w)
+
+	request := msg.New(r, params)
+	request.Section = msg.SectionMaintenance
+	request.Action = msg.ActionAdd
+
+	cReq := proto.NewMaintenanceWindowRequest()
+	if err := decodeJSONBody(r, &cReq); err != nil {
+		x.replyBadRequest(&w, &request, err)
+		return
+	}
+
+	for _, id := range []string{
+		cReq.MaintenanceWindow.RepositoryID,
+		cReq.MaintenanceWindow.BucketID,
+		cReq.MaintenanceWindow.MonitoringID,
+	} {
+		if id == `` {
+			continue
+		}
+		if err := checkStringIsUUID(id); err != nil {
+			x.replyBadRequest(&w, &request, err)
+			return
+		}
+	}
+
+	request.Maintenance = proto.MaintenanceWindow{
+		Scope:        cReq.MaintenanceWindow.Scope,
+		RepositoryID: cReq.MaintenanceWindow.RepositoryID,
+		BucketID:     cReq.MaintenanceWindow.BucketID,
+		Environment:  cReq.MaintenanceWindow.Environment,
+		MonitoringID: cReq.MaintenanceWindow.MonitoringID,
+		Description:  cReq.MaintenanceWindow.Description,
+		StartsAt:     cReq.MaintenanceWindow.StartsAt,
+		EndsAt:       cReq.MaintenanceWindow.EndsAt,
+		HoldJobs:     cReq.MaintenanceWindow.HoldJobs,
+	}
+
+	if !x.isAuthorized(&request) {
+		x.replyForbidden(&w, &request, nil)
+		return
+	}
+
+	x.handlerMap.MustLookup(&request).Intake() <- request
+	result := <-request.Reply
+	x.send(&w, &result)
+}
+
+// MaintenanceCancel function
+func (x *Rest) MaintenanceCancel(w http.ResponseWriter, r *http.Request,
+	params httprouter.Params) {
+	defer panicCatcher(w)
+
+	request := msg.New(r, params)
+	request.Section = msg.SectionMaintenance
+	request.Action = msg.ActionCancel
+
+	if err := checkStringIsUUID(params.ByName(`maintenanceID`)); err != nil {
+		x.replyBadRequest(&w, &request, err)
+		return
+	}
+	request.Maintenance.ID = params.ByName(`maintenanceID`)
+
+	if !x.isAuthorized(&request) {
+		x.replyForbidden(&w, &request, nil)
+		return
+	}
+
+	x.handlerMap.MustLookup(&request).Intake() <- request
+	result := <-request.Reply
+	x.send(&w, &result)
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
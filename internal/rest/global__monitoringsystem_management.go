@@ -96,6 +96,9 @@ func (x *Rest) MonitoringMgmtAdd(w http.ResponseWriter, r *http.Request,
 	request.Monitoring.Contact = cReq.Monitoring.Contact
 	request.Monitoring.TeamID = cReq.Monitoring.TeamID
 	request.Monitoring.Callback = cReq.Monitoring.Callback
+	request.Monitoring.BatchSize = cReq.Monitoring.BatchSize
+	request.Monitoring.BatchFlush = cReq.Monitoring.BatchFlush
+	request.Monitoring.Secret = cReq.Monitoring.Secret
 
 	if !x.isAuthorized(&request) {
 		x.replyForbidden(&w, &request, nil)
@@ -127,4 +130,36 @@ func (x *Rest) MonitoringMgmtRemove(w http.ResponseWriter, r *http.Request,
 	x.send(&w, &result)
 }
 
+// MonitoringMgmtSecret function
+func (x *Rest) MonitoringMgmtSecret(w http.ResponseWriter, r *http.Request,
+	params httprouter.Params) {
+	defer panicCatcher(w)
+
+	request := msg.New(r, params)
+	request.Section = msg.SectionMonitoringMgmt
+	request.Action = msg.ActionUpdate
+
+	if err := checkStringIsUUID(params.ByName(`monitoringID`)); err != nil {
+		x.replyBadRequest(&w, &request, err)
+		return
+	}
+
+	cReq := proto.NewMonitoringRequest()
+	if err := decodeJSONBody(r, &cReq); err != nil {
+		x.replyBadRequest(&w, &request, err)
+		return
+	}
+	request.Monitoring.ID = params.ByName(`monitoringID`)
+	request.Monitoring.Secret = cReq.Monitoring.Secret
+
+	if !x.isAuthorized(&request) {
+		x.replyForbidden(&w, &request, nil)
+		return
+	}
+
+	x.handlerMap.MustLookup(&request).Intake() <- request
+	result := <-request.Reply
+	x.send(&w, &result)
+}
+
 // vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
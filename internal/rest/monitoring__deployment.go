@@ -11,6 +11,7 @@ package rest // import "github.com/mjolnir42/soma/internal/rest"
 import (
 	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/julienschmidt/httprouter"
 	"github.com/mjolnir42/soma/internal/msg"
@@ -31,6 +32,19 @@ func (x *Rest) DeploymentShow(w http.ResponseWriter, r *http.Request,
 	}
 	request.Deployment.ID = params.ByName(`deploymentID`)
 
+	if params.ByName(`monitoringID`) != `` {
+		if err := checkStringIsUUID(params.ByName(`monitoringID`)); err != nil {
+			x.replyBadRequest(&w, &request, err)
+			return
+		}
+		request.Monitoring.ID = params.ByName(`monitoringID`)
+	}
+
+	if err := monitoringAuth(r, &request.MonAuth); err != nil {
+		x.replyBadRequest(&w, &request, err)
+		return
+	}
+
 	// BUG	if !x.isAuthorized(&request) {
 	// BUG		x.replyForbidden(&w, &request, nil)
 	// BUG		return
@@ -72,6 +86,11 @@ func (x *Rest) DeploymentUpdate(w http.ResponseWriter, r *http.Request,
 		request.Monitoring.ID = params.ByName(`monitoringID`)
 	}
 
+	if err := monitoringAuth(r, &request.MonAuth); err != nil {
+		x.replyBadRequest(&w, &request, err)
+		return
+	}
+
 	// BUG	if !x.isAuthorized(&request) {
 	// BUG		x.replyForbidden(&w, &request, nil)
 	// BUG		return
@@ -97,6 +116,32 @@ func (x *Rest) DeploymentList(w http.ResponseWriter, r *http.Request,
 	}
 	request.Monitoring.ID = params.ByName(`monitoringID`)
 
+	// optional paging of the deployment list, as query parameters
+	// ?active=<bool>&limit=<count>&offset=<count>
+	var err error
+	for param, count := range map[string]*uint64{
+		`limit`:  &request.Search.Deployment.Limit,
+		`offset`: &request.Search.Deployment.Offset,
+	} {
+		if val := r.URL.Query().Get(param); val != `` {
+			if *count, err = strconv.ParseUint(val, 10, 63); err != nil {
+				x.replyBadRequest(&w, &request, err)
+				return
+			}
+		}
+	}
+	if val := r.URL.Query().Get(`active`); val != `` {
+		if request.Search.Deployment.IncludeActive, err = strconv.ParseBool(val); err != nil {
+			x.replyBadRequest(&w, &request, err)
+			return
+		}
+	}
+
+	if err := monitoringAuth(r, &request.MonAuth); err != nil {
+		x.replyBadRequest(&w, &request, err)
+		return
+	}
+
 	// BUG	if !x.isAuthorized(&request) {
 	// BUG		x.replyForbidden(&w, &request, nil)
 	// BUG		return
@@ -122,6 +167,11 @@ func (x *Rest) DeploymentPending(w http.ResponseWriter, r *http.Request,
 	}
 	request.Monitoring.ID = params.ByName(`monitoringID`)
 
+	if err := monitoringAuth(r, &request.MonAuth); err != nil {
+		x.replyBadRequest(&w, &request, err)
+		return
+	}
+
 	// BUG	if !x.isAuthorized(&request) {
 	// BUG		x.replyForbidden(&w, &request, nil)
 	// BUG		return
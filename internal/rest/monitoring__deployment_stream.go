@@ -0,0 +1,132 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package rest // import "github.com/mjolnir42/soma/internal/rest"
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mjolnir42/soma/internal/msg"
+	"github.com/mjolnir42/soma/lib/proto"
+)
+
+// streamBufferLength is the number of events buffered per client. A
+// client that falls further behind is disconnected.
+const streamBufferLength = 256
+
+// DeploymentStream function
+func (x *Rest) DeploymentStream(w http.ResponseWriter, r *http.Request,
+	params httprouter.Params) {
+	defer panicCatcher(w)
+
+	request := msg.New(r, params)
+	request.Section = msg.SectionDeployment
+	request.Action = msg.ActionStream
+
+	filter := proto.DeploymentEventFilter{
+		MonitoringID: params.ByName(`monitoringID`),
+		RepositoryID: r.URL.Query().Get(`repository`),
+		Status:       r.URL.Query()[`state`],
+	}
+	if filter.MonitoringID == `` {
+		filter.MonitoringID = r.URL.Query().Get(`monitoring`)
+	}
+	for _, id := range []string{
+		filter.MonitoringID,
+		filter.RepositoryID,
+	} {
+		if id == `` {
+			continue
+		}
+		if err := checkStringIsUUID(id); err != nil {
+			x.replyBadRequest(&w, &request, err)
+			return
+		}
+	}
+	request.Search.DeploymentEvent = filter
+	request.Monitoring.ID = filter.MonitoringID
+
+	if !x.isAuthorized(&request) {
+		x.replyForbidden(&w, &request, nil)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		x.replyServerError(&w, &request,
+			fmt.Errorf(`Streaming is not supported by the connection`))
+		return
+	}
+
+	jsonLines := r.URL.Query().Get(`format`) == `jsonl` ||
+		strings.Contains(r.Header.Get(`Accept`), `application/x-ndjson`)
+
+	// the handler keeps sending on the reply channel until the
+	// client disconnects
+	request.Reply = make(chan msg.Result, streamBufferLength)
+	request.Done = r.Context().Done()
+	x.handlerMap.MustLookup(&request).Intake() <- request
+
+	if jsonLines {
+		w.Header().Set(`Content-Type`, `application/x-ndjson`)
+	} else {
+		w.Header().Set(`Content-Type`, `text/event-stream`)
+	}
+	w.Header().Set(`Cache-Control`, `no-cache`)
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepalive := time.NewTicker(30 * time.Second)
+	defer keepalive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepalive.C:
+			if jsonLines {
+				continue
+			}
+			// SSE comment lines keep proxies from closing the idle
+			// connection
+			fmt.Fprint(w, ":keepalive\n\n")
+			flusher.Flush()
+		case result, ok := <-request.Reply:
+			if !ok {
+				// the handler disconnected the client
+				return
+			}
+			for i := range result.DeploymentEvent {
+				bjson, err := json.Marshal(&result.DeploymentEvent[i])
+				if err != nil {
+					x.errLog.WithField(`RequestID`, request.ID.String()).
+						Errorln(err)
+					continue
+				}
+				if jsonLines {
+					fmt.Fprintf(w, "%s\n", bjson)
+				} else {
+					fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n",
+						result.DeploymentEvent[i].ID,
+						result.DeploymentEvent[i].Status,
+						bjson,
+					)
+				}
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
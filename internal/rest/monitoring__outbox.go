@@ -0,0 +1,94 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package rest // import "github.com/mjolnir42/soma/internal/rest"
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mjolnir42/soma/internal/msg"
+)
+
+// OutboxList function
+func (x *Rest) OutboxList(w http.ResponseWriter, r *http.Request,
+	params httprouter.Params) {
+	defer panicCatcher(w)
+
+	request := msg.New(r, params)
+	request.Section = msg.SectionOutbox
+	request.Action = msg.ActionList
+
+	if err := checkStringIsUUID(params.ByName(`monitoringID`)); err != nil {
+		x.replyBadRequest(&w, &request, err)
+		return
+	}
+	request.Monitoring.ID = params.ByName(`monitoringID`)
+
+	if !x.isAuthorized(&request) {
+		x.replyForbidden(&w, &request, nil)
+		return
+	}
+
+	x.handlerMap.MustLookup(&request).Intake() <- request
+	result := <-request.Reply
+	x.send(&w, &result)
+}
+
+// OutboxReplay function
+func (x *Rest) OutboxReplay(w http.ResponseWriter, r *http.Request,
+	params httprouter.Params) {
+	defer panicCatcher(w)
+
+	request := msg.New(r, params)
+	request.Section = msg.SectionOutbox
+	request.Action = msg.ActionReplay
+
+	if err := checkStringIsUUID(params.ByName(`monitoringID`)); err != nil {
+		x.replyBadRequest(&w, &request, err)
+		return
+	}
+	request.Monitoring.ID = params.ByName(`monitoringID`)
+
+	if !x.isAuthorized(&request) {
+		x.replyForbidden(&w, &request, nil)
+		return
+	}
+
+	x.handlerMap.MustLookup(&request).Intake() <- request
+	result := <-request.Reply
+	x.send(&w, &result)
+}
+
+// OutboxPurge function
+func (x *Rest) OutboxPurge(w http.ResponseWriter, r *http.Request,
+	params httprouter.Params) {
+	defer panicCatcher(w)
+
+	request := msg.New(r, params)
+	request.Section = msg.SectionOutbox
+	request.Action = msg.ActionPurge
+
+	if err := checkStringIsUUID(params.ByName(`monitoringID`)); err != nil {
+		x.replyBadRequest(&w, &request, err)
+		return
+	}
+	request.Monitoring.ID = params.ByName(`monitoringID`)
+
+	if !x.isAuthorized(&request) {
+		x.replyForbidden(&w, &request, nil)
+		return
+	}
+
+	x.handlerMap.MustLookup(&request).Intake() <- request
+	result := <-request.Reply
+	x.send(&w, &result)
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -154,6 +154,7 @@ func (x *Rest) BucketCreate(w http.ResponseWriter, r *http.Request,
 		return
 	}
 
+	request.Flag.DryRun = requestDryRun(r, cReq.Flags)
 	x.handlerMap.MustLookup(&request).Intake() <- request
 	result := <-request.Reply
 	x.send(&w, &result)
@@ -176,6 +177,7 @@ func (x *Rest) BucketDestroy(w http.ResponseWriter, r *http.Request,
 		return
 	}
 
+	request.Flag.DryRun = requestDryRun(r, nil)
 	x.handlerMap.MustLookup(&request).Intake() <- request
 	result := <-request.Reply
 	x.send(&w, &result)
@@ -268,6 +270,7 @@ func (x *Rest) BucketPropertyCreate(w http.ResponseWriter, r *http.Request,
 		return
 	}
 
+	request.Flag.DryRun = requestDryRun(r, cReq.Flags)
 	x.handlerMap.MustLookup(&request).Intake() <- request
 	result := <-request.Reply
 	x.send(&w, &result)
@@ -302,6 +305,7 @@ func (x *Rest) BucketPropertyDestroy(w http.ResponseWriter, r *http.Request,
 		return
 	}
 
+	request.Flag.DryRun = requestDryRun(r, nil)
 	x.handlerMap.MustLookup(&request).Intake() <- request
 	result := <-request.Reply
 	x.send(&w, &result)
@@ -80,6 +80,33 @@ func (x *Rest) CheckConfigSearch(w http.ResponseWriter, r *http.Request,
 	x.send(&w, &result)
 }
 
+// CheckConfigExport function
+func (x *Rest) CheckConfigExport(w http.ResponseWriter, r *http.Request,
+	params httprouter.Params) {
+	defer panicCatcher(w)
+
+	request := msg.New(r, params)
+	request.Section = msg.SectionCheckConfig
+	request.Action = msg.ActionExport
+
+	if err := checkStringIsUUID(params.ByName(`repositoryID`)); err != nil {
+		x.replyBadRequest(&w, &request, err)
+		return
+	}
+	request.CheckConfig = proto.CheckConfig{
+		RepositoryID: params.ByName(`repositoryID`),
+	}
+
+	if !x.isAuthorized(&request) {
+		x.replyForbidden(&w, &request, nil)
+		return
+	}
+
+	x.handlerMap.MustLookup(&request).Intake() <- request
+	result := <-request.Reply
+	x.send(&w, &result)
+}
+
 // CheckConfigShow function
 func (x *Rest) CheckConfigShow(w http.ResponseWriter, r *http.Request,
 	params httprouter.Params) {
@@ -118,6 +145,16 @@ func (x *Rest) CheckConfigCreate(w http.ResponseWriter, r *http.Request,
 		return
 	}
 	request.CheckConfig = cReq.CheckConfig.Clone()
+	if err := checkConstraintOperators(&request.CheckConfig); err != nil {
+		x.replyBadRequest(&w, &request, err)
+		return
+	}
+	if err := checkThresholdTemplates(
+		request.CheckConfig.Thresholds,
+	); err != nil {
+		x.replyBadRequest(&w, &request, err)
+		return
+	}
 
 	if !x.isAuthorized(&request) {
 		x.replyForbidden(&w, &request, nil)
@@ -132,6 +169,7 @@ func (x *Rest) CheckConfigCreate(w http.ResponseWriter, r *http.Request,
 		return
 	}
 
+	request.Flag.DryRun = requestDryRun(r, cReq.Flags)
 	x.handlerMap.MustLookup(&request).Intake() <- request
 	result := <-request.Reply
 	x.send(&w, &result)
@@ -163,6 +201,223 @@ func (x *Rest) CheckConfigDestroy(w http.ResponseWriter, r *http.Request,
 		return
 	}
 
+	request.Flag.DryRun = requestDryRun(r, nil)
+	x.handlerMap.MustLookup(&request).Intake() <- request
+	result := <-request.Reply
+	x.send(&w, &result)
+}
+
+// CheckConfigUpdate function
+func (x *Rest) CheckConfigUpdate(w http.ResponseWriter, r *http.Request,
+	params httprouter.Params) {
+	defer panicCatcher(w)
+
+	request := msg.New(r, params)
+	request.Section = msg.SectionMonitoring
+	request.Action = msg.ActionUse
+
+	for _, id := range []string{
+		params.ByName(`repositoryID`),
+		params.ByName(`checkID`),
+	} {
+		if err := checkStringIsUUID(id); err != nil {
+			x.replyBadRequest(&w, &request, err)
+			return
+		}
+	}
+
+	cReq := proto.NewCheckConfigRequest()
+	if err := decodeJSONBody(r, &cReq); err != nil {
+		x.replyBadRequest(&w, &request, err)
+		return
+	}
+	// only the interval and the thresholds of a check configuration
+	// can be updated
+	request.CheckConfig = proto.CheckConfig{
+		ID:           params.ByName(`checkID`),
+		RepositoryID: params.ByName(`repositoryID`),
+		Interval:     cReq.CheckConfig.Interval,
+		Thresholds: make([]proto.CheckConfigThreshold,
+			len(cReq.CheckConfig.Thresholds)),
+	}
+	copy(request.CheckConfig.Thresholds, cReq.CheckConfig.Thresholds)
+	if err := checkThresholdTemplates(
+		request.CheckConfig.Thresholds,
+	); err != nil {
+		x.replyBadRequest(&w, &request, err)
+		return
+	}
+
+	if !x.isAuthorized(&request) {
+		x.replyForbidden(&w, &request, nil)
+		return
+	}
+
+	request.Section = msg.SectionCheckConfig
+	request.Action = msg.ActionUpdate
+
+	if !x.isAuthorized(&request) {
+		x.replyForbidden(&w, &request, nil)
+		return
+	}
+
+	request.Flag.DryRun = requestDryRun(r, cReq.Flags)
+	x.handlerMap.MustLookup(&request).Intake() <- request
+	result := <-request.Reply
+	x.send(&w, &result)
+}
+
+// CheckConfigRolloutResume function
+func (x *Rest) CheckConfigRolloutResume(w http.ResponseWriter, r *http.Request,
+	params httprouter.Params) {
+	defer panicCatcher(w)
+
+	request := msg.New(r, params)
+	request.Section = msg.SectionCheckConfig
+	request.Action = msg.ActionResume
+
+	for _, id := range []string{
+		params.ByName(`repositoryID`),
+		params.ByName(`checkID`),
+	} {
+		if err := checkStringIsUUID(id); err != nil {
+			x.replyBadRequest(&w, &request, err)
+			return
+		}
+	}
+	request.CheckConfig = proto.CheckConfig{
+		ID:           params.ByName(`checkID`),
+		RepositoryID: params.ByName(`repositoryID`),
+	}
+
+	if !x.isAuthorized(&request) {
+		x.replyForbidden(&w, &request, nil)
+		return
+	}
+
+	x.handlerMap.MustLookup(&request).Intake() <- request
+	result := <-request.Reply
+	x.send(&w, &result)
+}
+
+// CheckConfigRollbackEnable function
+func (x *Rest) CheckConfigRollbackEnable(w http.ResponseWriter, r *http.Request,
+	params httprouter.Params) {
+	x.checkConfigRollback(w, r, params, msg.ActionRollbackEnable)
+}
+
+// CheckConfigRollbackDisable function
+func (x *Rest) CheckConfigRollbackDisable(w http.ResponseWriter, r *http.Request,
+	params httprouter.Params) {
+	x.checkConfigRollback(w, r, params, msg.ActionRollbackDisable)
+}
+
+// checkConfigRollback enables or disables the automatic rollback
+// policy of a check configuration
+func (x *Rest) checkConfigRollback(w http.ResponseWriter, r *http.Request,
+	params httprouter.Params, action string) {
+	defer panicCatcher(w)
+
+	request := msg.New(r, params)
+	request.Section = msg.SectionCheckConfig
+	request.Action = action
+
+	for _, id := range []string{
+		params.ByName(`repositoryID`),
+		params.ByName(`checkID`),
+	} {
+		if err := checkStringIsUUID(id); err != nil {
+			x.replyBadRequest(&w, &request, err)
+			return
+		}
+	}
+	request.CheckConfig = proto.CheckConfig{
+		ID:           params.ByName(`checkID`),
+		RepositoryID: params.ByName(`repositoryID`),
+	}
+
+	if !x.isAuthorized(&request) {
+		x.replyForbidden(&w, &request, nil)
+		return
+	}
+
+	x.handlerMap.MustLookup(&request).Intake() <- request
+	result := <-request.Reply
+	x.send(&w, &result)
+}
+
+// CheckConfigOverrideSet function
+func (x *Rest) CheckConfigOverrideSet(w http.ResponseWriter, r *http.Request,
+	params httprouter.Params) {
+	x.checkConfigOverride(w, r, params, msg.ActionOverrideSet)
+}
+
+// CheckConfigOverrideRemove function
+func (x *Rest) CheckConfigOverrideRemove(w http.ResponseWriter, r *http.Request,
+	params httprouter.Params) {
+	x.checkConfigOverride(w, r, params, msg.ActionOverrideRemove)
+}
+
+// checkConfigOverride sets or removes the threshold override of a
+// check configuration on a tree object
+func (x *Rest) checkConfigOverride(w http.ResponseWriter, r *http.Request,
+	params httprouter.Params, action string) {
+	defer panicCatcher(w)
+
+	request := msg.New(r, params)
+	request.Section = msg.SectionCheckConfig
+	request.Action = action
+
+	for _, id := range []string{
+		params.ByName(`repositoryID`),
+		params.ByName(`checkID`),
+		params.ByName(`objectID`),
+	} {
+		if err := checkStringIsUUID(id); err != nil {
+			x.replyBadRequest(&w, &request, err)
+			return
+		}
+	}
+	switch params.ByName(`objectType`) {
+	case proto.EntityRepository, proto.EntityBucket, proto.EntityGroup,
+		proto.EntityCluster, proto.EntityNode:
+	default:
+		x.replyBadRequest(&w, &request, fmt.Errorf(
+			"Invalid override object type: %s",
+			params.ByName(`objectType`)))
+		return
+	}
+
+	override := proto.CheckConfigThresholdOverride{
+		ObjectID:   params.ByName(`objectID`),
+		ObjectType: params.ByName(`objectType`),
+	}
+	cReq := proto.NewCheckConfigRequest()
+	if action == msg.ActionOverrideSet {
+		if err := decodeJSONBody(r, &cReq); err != nil {
+			x.replyBadRequest(&w, &request, err)
+			return
+		}
+		override.Thresholds = make([]proto.CheckConfigThreshold,
+			len(cReq.CheckConfig.Thresholds))
+		copy(override.Thresholds, cReq.CheckConfig.Thresholds)
+		if err := checkThresholdTemplates(override.Thresholds); err != nil {
+			x.replyBadRequest(&w, &request, err)
+			return
+		}
+	}
+	request.CheckConfig = proto.CheckConfig{
+		ID:                 params.ByName(`checkID`),
+		RepositoryID:       params.ByName(`repositoryID`),
+		ThresholdOverrides: []proto.CheckConfigThresholdOverride{override},
+	}
+
+	if !x.isAuthorized(&request) {
+		x.replyForbidden(&w, &request, nil)
+		return
+	}
+
+	request.Flag.DryRun = requestDryRun(r, cReq.Flags)
 	x.handlerMap.MustLookup(&request).Intake() <- request
 	result := <-request.Reply
 	x.send(&w, &result)
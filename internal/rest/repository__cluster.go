@@ -157,6 +157,7 @@ func (x *Rest) ClusterCreate(w http.ResponseWriter, r *http.Request,
 		return
 	}
 
+	request.Flag.DryRun = requestDryRun(r, cReq.Flags)
 	x.handlerMap.MustLookup(&request).Intake() <- request
 	result := <-request.Reply
 	x.send(&w, &result)
@@ -181,6 +182,7 @@ func (x *Rest) ClusterDestroy(w http.ResponseWriter, r *http.Request,
 		return
 	}
 
+	request.Flag.DryRun = requestDryRun(r, nil)
 	x.handlerMap.MustLookup(&request).Intake() <- request
 	result := <-request.Reply
 	x.send(&w, &result)
@@ -233,6 +235,7 @@ func (x *Rest) ClusterMemberAssign(w http.ResponseWriter, r *http.Request,
 		return
 	}
 
+	request.Flag.DryRun = requestDryRun(r, cReq.Flags)
 	x.handlerMap.MustLookup(&request).Intake() <- request
 	result := <-request.Reply
 	x.send(&w, &result)
@@ -266,6 +269,7 @@ func (x *Rest) ClusterMemberUnassign(w http.ResponseWriter, r *http.Request,
 		return
 	}
 
+	request.Flag.DryRun = requestDryRun(r, nil)
 	x.handlerMap.MustLookup(&request).Intake() <- request
 	result := <-request.Reply
 	x.send(&w, &result)
@@ -318,6 +322,7 @@ func (x *Rest) ClusterPropertyCreate(w http.ResponseWriter, r *http.Request,
 		return
 	}
 
+	request.Flag.DryRun = requestDryRun(r, cReq.Flags)
 	x.handlerMap.MustLookup(&request).Intake() <- request
 	result := <-request.Reply
 	x.send(&w, &result)
@@ -354,6 +359,7 @@ func (x *Rest) ClusterPropertyDestroy(w http.ResponseWriter, r *http.Request,
 		return
 	}
 
+	request.Flag.DryRun = requestDryRun(r, nil)
 	x.handlerMap.MustLookup(&request).Intake() <- request
 	result := <-request.Reply
 	x.send(&w, &result)
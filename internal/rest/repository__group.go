@@ -160,6 +160,7 @@ func (x *Rest) GroupCreate(w http.ResponseWriter, r *http.Request,
 		return
 	}
 
+	request.Flag.DryRun = requestDryRun(r, cReq.Flags)
 	x.handlerMap.MustLookup(&request).Intake() <- request
 	result := <-request.Reply
 	x.send(&w, &result)
@@ -184,6 +185,7 @@ func (x *Rest) GroupDestroy(w http.ResponseWriter, r *http.Request,
 		return
 	}
 
+	request.Flag.DryRun = requestDryRun(r, nil)
 	x.handlerMap.MustLookup(&request).Intake() <- request
 	result := <-request.Reply
 	x.send(&w, &result)
@@ -262,6 +264,7 @@ func (x *Rest) GroupMemberAssign(w http.ResponseWriter, r *http.Request,
 		return
 	}
 
+	request.Flag.DryRun = requestDryRun(r, cReq.Flags)
 	x.handlerMap.MustLookup(&request).Intake() <- request
 	result := <-request.Reply
 	x.send(&w, &result)
@@ -305,6 +308,7 @@ func (x *Rest) GroupMemberUnassign(w http.ResponseWriter, r *http.Request,
 		return
 	}
 
+	request.Flag.DryRun = requestDryRun(r, nil)
 	x.handlerMap.MustLookup(&request).Intake() <- request
 	result := <-request.Reply
 	x.send(&w, &result)
@@ -351,6 +355,7 @@ func (x *Rest) GroupPropertyCreate(w http.ResponseWriter, r *http.Request,
 		return
 	}
 
+	request.Flag.DryRun = requestDryRun(r, cReq.Flags)
 	x.handlerMap.MustLookup(&request).Intake() <- request
 	result := <-request.Reply
 	x.send(&w, &result)
@@ -384,6 +389,7 @@ func (x *Rest) GroupPropertyDestroy(w http.ResponseWriter, r *http.Request,
 		return
 	}
 
+	request.Flag.DryRun = requestDryRun(r, nil)
 	x.handlerMap.MustLookup(&request).Intake() <- request
 	result := <-request.Reply
 	x.send(&w, &result)
@@ -10,9 +10,11 @@ package rest // import "github.com/mjolnir42/soma/internal/rest"
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/julienschmidt/httprouter"
 	"github.com/mjolnir42/soma/internal/msg"
+	"github.com/mjolnir42/soma/lib/proto"
 )
 
 // InstanceShow returns information about a check instance
@@ -60,6 +62,49 @@ func (x *Rest) InstanceVersions(w http.ResponseWriter, r *http.Request,
 	x.send(&w, &result)
 }
 
+// InstanceDiff returns the changes between two versions of a check
+// instance's configuration
+func (x *Rest) InstanceDiff(w http.ResponseWriter, r *http.Request,
+	params httprouter.Params) {
+	defer panicCatcher(w)
+
+	var err error
+
+	request := msg.New(r, params)
+	request.Section = msg.SectionInstance
+	request.Action = msg.ActionDiff
+	request.Instance.Diff = &proto.DeploymentDiff{}
+
+	if err = checkStringIsUUID(params.ByName(`deploymentID`)); err != nil {
+		x.replyBadRequest(&w, &request, err)
+		return
+	}
+	request.Instance.ID = params.ByName(`deploymentID`)
+
+	// optional versions to compare, as query parameters
+	// ?version=<version>&against=<version>
+	for param, version := range map[string]*uint64{
+		`version`: &request.Instance.Diff.Version,
+		`against`: &request.Instance.Diff.Against,
+	} {
+		if val := r.URL.Query().Get(param); val != `` {
+			if *version, err = strconv.ParseUint(val, 10, 31); err != nil {
+				x.replyBadRequest(&w, &request, err)
+				return
+			}
+		}
+	}
+
+	if !x.isAuthorized(&request) {
+		x.replyForbidden(&w, &request, nil)
+		return
+	}
+
+	x.handlerMap.MustLookup(&request).Intake() <- request
+	result := <-request.Reply
+	x.send(&w, &result)
+}
+
 // InstanceList returns the list of instances in the subtree
 // below the queried object.
 // Currently only supports repositories and buckets as target.
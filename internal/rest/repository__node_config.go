@@ -53,6 +53,7 @@ func (x *Rest) NodeConfigAssign(w http.ResponseWriter,
 		return
 	}
 
+	request.Flag.DryRun = requestDryRun(r, cReq.Flags)
 	x.handlerMap.MustLookup(&request).Intake() <- request
 	result := <-request.Reply
 	x.send(&w, &result)
@@ -89,6 +90,7 @@ func (x *Rest) NodeConfigUnassign(w http.ResponseWriter,
 		return
 	}
 
+	request.Flag.DryRun = requestDryRun(r, nil)
 	x.handlerMap.MustLookup(&request).Intake() <- request
 	result := <-request.Reply
 	x.send(&w, &result)
@@ -140,6 +142,7 @@ func (x *Rest) NodeConfigPropertyCreate(w http.ResponseWriter,
 		return
 	}
 
+	request.Flag.DryRun = requestDryRun(r, cReq.Flags)
 	x.handlerMap.MustLookup(&request).Intake() <- request
 	result := <-request.Reply
 	x.send(&w, &result)
@@ -200,6 +203,7 @@ func (x *Rest) NodeConfigPropertyDestroy(w http.ResponseWriter,
 		return
 	}
 
+	request.Flag.DryRun = requestDryRun(r, cReq.Flags)
 	x.handlerMap.MustLookup(&request).Intake() <- request
 	result := <-request.Reply
 	x.send(&w, &result)
@@ -163,6 +163,7 @@ func (x *Rest) RepositoryConfigPropertyCreate(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	request.Flag.DryRun = requestDryRun(r, cReq.Flags)
 	x.handlerMap.MustLookup(&request).Intake() <- request
 	result := <-request.Reply
 	x.send(&w, &result)
@@ -192,6 +193,7 @@ func (x *Rest) RepositoryConfigPropertyDestroy(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	request.Flag.DryRun = requestDryRun(r, nil)
 	x.handlerMap.MustLookup(&request).Intake() <- request
 	result := <-request.Reply
 	x.send(&w, &result)
@@ -245,6 +247,45 @@ func (x *Rest) RepositoryConfigPropertyUpdate(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	request.Flag.DryRun = requestDryRun(r, cReq.Flags)
+	x.handlerMap.MustLookup(&request).Intake() <- request
+	result := <-request.Reply
+	x.send(&w, &result)
+}
+
+// RepositoryConfigRollbackEnable function
+func (x *Rest) RepositoryConfigRollbackEnable(w http.ResponseWriter, r *http.Request,
+	params httprouter.Params) {
+	x.repositoryConfigRollback(w, r, params, msg.ActionRollbackEnable)
+}
+
+// RepositoryConfigRollbackDisable function
+func (x *Rest) RepositoryConfigRollbackDisable(w http.ResponseWriter, r *http.Request,
+	params httprouter.Params) {
+	x.repositoryConfigRollback(w, r, params, msg.ActionRollbackDisable)
+}
+
+// repositoryConfigRollback enables or disables the automatic
+// rollback policy of a repository
+func (x *Rest) repositoryConfigRollback(w http.ResponseWriter, r *http.Request,
+	params httprouter.Params, action string) {
+	defer panicCatcher(w)
+
+	request := msg.New(r, params)
+	request.Section = msg.SectionRepositoryConfig
+	request.Action = action
+
+	if err := checkStringIsUUID(params.ByName(`repositoryID`)); err != nil {
+		x.replyBadRequest(&w, &request, err)
+		return
+	}
+	request.Repository.ID = params.ByName(`repositoryID`)
+
+	if !x.isAuthorized(&request) {
+		x.replyForbidden(&w, &request, nil)
+		return
+	}
+
 	x.handlerMap.MustLookup(&request).Intake() <- request
 	result := <-request.Reply
 	x.send(&w, &result)
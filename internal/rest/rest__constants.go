@@ -19,6 +19,7 @@ const (
 	rtRepositoryPropertyID       = `/repository/:repositoryID/property/:propertyType/:sourceID`
 	rtRepositoryPropertyMgmt     = `/repository/:repositoryID/property-mgmt/:propertyType/`
 	rtRepositoryPropertyMgmtID   = `/repository/:repositoryID/property-mgmt/:propertyType/:propertyID`
+	rtRepositoryRollback         = `/repository/:repositoryID/rollback`
 	rtRepositoryTree             = `/repository/:repositoryID/tree`
 	rtGlobalBucket               = `/bucket/`
 	rtBucket                     = `/repository/:repositoryID/bucket/`
@@ -95,10 +96,19 @@ const (
 	rtDeploymentIDAction         = `/monitoringsystem/:monitoringID/deployment/id/:deploymentID/:action`
 	rtDeploymentState            = `/monitoringsystem/:monitoringID/deployment/state/`
 	rtDeploymentStateID          = `/monitoringsystem/:monitoringID/deployment/state/:state`
+	rtDeploymentStream           = `/monitoringsystem/:monitoringID/deployment/stream`
 	rtAliasDeploymentID          = `/deployment/id/:deploymentID`
 	rtAliasDeploymentIDAction    = `/deployment/id/:deploymentID/:action`
+	rtAliasDeploymentIDDiff      = `/deployment/id/:deploymentID/diff`
+	rtAliasDeploymentStream      = `/deployment/stream`
 	rtCompatDeploymentID         = `/deployments/id/:deploymentID`
 	rtCompatDeploymentIDAction   = `/deployments/id/:deploymentID/:action`
+	rtOutbox                     = `/monitoringsystem/:monitoringID/outbox/`
+	rtOutboxReplay               = `/monitoringsystem/:monitoringID/outbox/replay`
+	rtMonitoringSecret           = `/monitoringsystem/:monitoringID/secret`
+	rtCheckConfigRolloutResume   = `/checkconfig/:repositoryID/:checkID/rollout/resume`
+	rtCheckConfigRollback        = `/checkconfig/:repositoryID/:checkID/rollback`
+	rtCheckConfigOverride        = `/checkconfig/:repositoryID/:checkID/override/:objectType/:objectID`
 	rtOncallMember               = `/oncall/:oncallID/member/`
 	rtOncallMemberID             = `/oncall/:oncallID/member/:userID`
 	rtJob                        = `/job/`
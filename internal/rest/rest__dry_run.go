@@ -0,0 +1,31 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package rest // import "github.com/mjolnir42/soma/internal/rest"
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/mjolnir42/soma/lib/proto"
+)
+
+// requestDryRun returns true if the client requested that a tree
+// changing request is only processed as dry run. This is either
+// requested via the request flags, or via the dryRun query parameter
+// for requests without body.
+func requestDryRun(r *http.Request, flags *proto.Flags) bool {
+	if flags != nil && flags.DryRun {
+		return true
+	}
+	dryRun, _ := strconv.ParseBool(r.URL.Query().Get(`dryRun`))
+	return dryRun
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -0,0 +1,83 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package rest // import "github.com/mjolnir42/soma/internal/rest"
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mjolnir42/soma/internal/msg"
+)
+
+// RunMetrics is the event server for the metrics export. It listens
+// on the separately configured metrics address.
+func (x *Rest) RunMetrics() {
+	router := httprouter.New()
+	if x.conf.Metrics.Unauthenticated {
+		router.GET(x.conf.Metrics.Path, x.Unauthenticated(x.MetricsExport))
+	} else {
+		router.GET(x.conf.Metrics.Path, x.Authenticated(x.MetricsExport))
+	}
+
+	addr := fmt.Sprintf("%s:%s", x.conf.Metrics.Listen, x.conf.Metrics.Port)
+	if x.conf.Daemon.TLS {
+		x.errLog.Fatal(http.ListenAndServeTLS(
+			addr,
+			x.conf.Daemon.Cert,
+			x.conf.Daemon.Key,
+			router,
+		))
+	} else {
+		x.errLog.Fatal(http.ListenAndServe(addr, router))
+	}
+}
+
+// MetricsExport function
+func (x *Rest) MetricsExport(w http.ResponseWriter, r *http.Request,
+	params httprouter.Params) {
+	defer panicCatcher(w)
+
+	if !x.conf.Metrics.Unauthenticated {
+		request := msg.New(r, params)
+		request.Section = msg.SectionSystem
+		request.Action = msg.ActionMetrics
+
+		if !x.isAuthorized(&request) {
+			x.replyForbidden(&w, &request, nil)
+			return
+		}
+	}
+
+	registries := make([]string, 0, len(Metrics))
+	for name := range Metrics {
+		registries = append(registries, name)
+	}
+	sort.Strings(registries)
+
+	exp := newPromExporter()
+	for _, name := range registries {
+		exp.add(name, Metrics[name])
+	}
+
+	buf := &bytes.Buffer{}
+	if err := exp.write(buf); err != nil {
+		x.hardServerError(&w)
+		return
+	}
+
+	w.Header().Set(`Content-Type`, `text/plain; version=0.0.4; charset=utf-8`)
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -0,0 +1,55 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package rest // import "github.com/mjolnir42/soma/internal/rest"
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/mjolnir42/soma/internal/msg"
+	"github.com/mjolnir42/soma/lib/auth"
+)
+
+// maxMonitoringAuthBody is the maximum request body size in bytes
+// that is read for signature verification
+const maxMonitoringAuthBody = 1 << 20
+
+// monitoringAuth extracts the authentication data a monitoring system
+// supplied with request r into a. The request body is read for
+// signature verification and replaced so that it can be decoded
+// afterwards.
+func monitoringAuth(r *http.Request, a *msg.MonitoringAuth) error {
+	var (
+		err  error
+		body []byte
+	)
+
+	if r.Body != nil {
+		if body, err = ioutil.ReadAll(
+			io.LimitReader(r.Body, maxMonitoringAuthBody),
+		); err != nil {
+			return err
+		}
+		r.Body.Close()
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	a.Method = r.Method
+	a.Path = r.URL.Path
+	a.Timestamp = r.Header.Get(auth.HeaderMonitoringTimestamp)
+	a.Signature = r.Header.Get(auth.HeaderMonitoringSignature)
+	a.Token = r.Header.Get(auth.HeaderMonitoringToken)
+	a.Body = body
+	return nil
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -0,0 +1,170 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package rest // import "github.com/mjolnir42/soma/internal/rest"
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	metrics "github.com/rcrowley/go-metrics"
+)
+
+// promQuantiles are the quantiles exported for histograms and timers
+var promQuantiles = []float64{0.5, 0.75, 0.95, 0.99, 0.999}
+
+// promFamily is a metric family in the Prometheus text format
+type promFamily struct {
+	kind    string
+	samples []string
+}
+
+// promExporter renders go-metrics registries in the Prometheus text
+// exposition format. Metric names may carry a label set in the form
+// name{label="value"}, which is exported as labels of the metric.
+// Label values must already be escaped for the text format. Metric
+// names are prefixed with the name of their registry.
+type promExporter struct {
+	families map[string]*promFamily
+}
+
+// newPromExporter returns a new promExporter
+func newPromExporter() *promExporter {
+	return &promExporter{
+		families: make(map[string]*promFamily),
+	}
+}
+
+// add renders all metrics of registry r, which is named registry
+func (p *promExporter) add(registry string, r metrics.Registry) {
+	r.Each(func(name string, i interface{}) {
+		base, labels := promSplitName(registry + `.` +
+			strings.TrimLeft(name, `.`))
+
+		switch metric := i.(type) {
+		case metrics.Counter:
+			// go-metrics counters can be decremented
+			p.sample(base, `untyped`, ``, labels,
+				float64(metric.Count()))
+		case metrics.Gauge:
+			p.sample(base, `gauge`, ``, labels,
+				float64(metric.Value()))
+		case metrics.GaugeFloat64:
+			p.sample(base, `gauge`, ``, labels, metric.Value())
+		case metrics.Meter:
+			m := metric.Snapshot()
+			p.sample(base+`_total`, `counter`, ``, labels,
+				float64(m.Count()))
+			p.sample(base+`_rate1`, `gauge`, ``, labels, m.Rate1())
+			p.sample(base+`_rate5`, `gauge`, ``, labels, m.Rate5())
+			p.sample(base+`_rate15`, `gauge`, ``, labels, m.Rate15())
+			p.sample(base+`_rate_mean`, `gauge`, ``, labels,
+				m.RateMean())
+		case metrics.Histogram:
+			h := metric.Snapshot()
+			p.summary(base, labels, h.Percentiles(promQuantiles),
+				float64(h.Sum()), h.Count(), 1)
+		case metrics.Timer:
+			t := metric.Snapshot()
+			p.summary(base+`_seconds`, labels,
+				t.Percentiles(promQuantiles), float64(t.Sum()),
+				t.Count(), float64(time.Second))
+		}
+	})
+}
+
+// summary renders a summary sample set. All values are divided by
+// scale.
+func (p *promExporter) summary(family, labels string, quantiles []float64,
+	sum float64, count int64, scale float64) {
+	for i := range quantiles {
+		p.sample(family, `summary`, ``, promJoinLabels(labels,
+			fmt.Sprintf(`quantile="%s"`, strconv.FormatFloat(
+				promQuantiles[i], 'g', -1, 64))),
+			quantiles[i]/scale)
+	}
+	p.sample(family, `summary`, `_sum`, labels, sum/scale)
+	p.sample(family, `summary`, `_count`, labels, float64(count))
+}
+
+// sample records a sample of metric family, whose sample name is the
+// family name with suffix appended
+func (p *promExporter) sample(family, kind, suffix, labels string,
+	value float64) {
+	if _, ok := p.families[family]; !ok {
+		p.families[family] = &promFamily{kind: kind}
+	}
+	if labels != `` {
+		labels = `{` + labels + `}`
+	}
+	p.families[family].samples = append(p.families[family].samples,
+		fmt.Sprintf("%s%s%s %s", family, suffix, labels,
+			strconv.FormatFloat(value, 'g', -1, 64)))
+}
+
+// write outputs all recorded metric families sorted by name
+func (p *promExporter) write(w io.Writer) (err error) {
+	names := make([]string, 0, len(p.families))
+	for name := range p.families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if _, err = fmt.Fprintf(w, "# TYPE %s %s\n", name,
+			p.families[name].kind); err != nil {
+			return
+		}
+		sort.Strings(p.families[name].samples)
+		for _, s := range p.families[name].samples {
+			if _, err = fmt.Fprintln(w, s); err != nil {
+				return
+			}
+		}
+	}
+	return
+}
+
+// promSplitName splits a registry name into a valid Prometheus metric
+// name and its label set
+func promSplitName(name string) (base, labels string) {
+	if i := strings.Index(name, `{`); i >= 0 && strings.HasSuffix(name, `}`) {
+		labels = name[i+1 : len(name)-1]
+		name = name[:i]
+	}
+	base = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z',
+			r >= '0' && r <= '9', r == '_', r == ':':
+			return r
+		}
+		return '_'
+	}, strings.Trim(name, `.`))
+	if base == `` || (base[0] >= '0' && base[0] <= '9') {
+		base = `_` + base
+	}
+	return
+}
+
+// promJoinLabels joins label sets, skipping empty ones
+func promJoinLabels(labels ...string) string {
+	set := make([]string, 0, len(labels))
+	for _, l := range labels {
+		if l != `` {
+			set = append(set, l)
+		}
+	}
+	return strings.Join(set, `,`)
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -0,0 +1,85 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package rest // import "github.com/mjolnir42/soma/internal/rest"
+
+import (
+	"bytes"
+	"testing"
+
+	metrics "github.com/rcrowley/go-metrics"
+)
+
+func TestPromExporter(t *testing.T) {
+	soma := metrics.NewRegistry()
+	metrics.GetOrRegisterCounter(`.requests`, soma).Inc(3)
+	metrics.GetOrRegisterGaugeFloat64(
+		`.handler.queue.depth{handler="job_r"}`, soma).Update(2)
+	metrics.GetOrRegisterGaugeFloat64(
+		`.handler.queue.depth{handler="a\"b\\c\nd"}`, soma).Update(1)
+	h := metrics.GetOrRegisterHistogram(`.batch.size`, soma,
+		metrics.NewUniformSample(16))
+	for _, v := range []int64{1, 2, 3, 4} {
+		h.Update(v)
+	}
+
+	golang := metrics.NewRegistry()
+	metrics.GetOrRegisterGauge(`requests`, golang).Update(7)
+
+	exp := newPromExporter()
+	exp.add(`golang`, golang)
+	exp.add(`soma`, soma)
+
+	buf := &bytes.Buffer{}
+	if err := exp.write(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `# TYPE golang_requests gauge
+golang_requests 7
+# TYPE soma_batch_size summary
+soma_batch_size_count 4
+soma_batch_size_sum 10
+soma_batch_size{quantile="0.5"} 2.5
+soma_batch_size{quantile="0.75"} 3.75
+soma_batch_size{quantile="0.95"} 4
+soma_batch_size{quantile="0.99"} 4
+soma_batch_size{quantile="0.999"} 4
+# TYPE soma_handler_queue_depth gauge
+soma_handler_queue_depth{handler="a\"b\\c\nd"} 1
+soma_handler_queue_depth{handler="job_r"} 2
+# TYPE soma_requests untyped
+soma_requests 3
+`
+	if buf.String() != expected {
+		t.Errorf("Unexpected output:\n%s\nexpected:\n%s",
+			buf.String(), expected)
+	}
+}
+
+func TestPromSplitName(t *testing.T) {
+	tests := []struct {
+		name, base, labels string
+	}{
+		{`soma.handler.queue.depth`, `soma_handler_queue_depth`, ``},
+		{`soma.outbox.backlog{monitoring="a{b}"}`, `soma_outbox_backlog`,
+			`monitoring="a{b}"`},
+		{`.1st-metric.`, `_1st_metric`, ``},
+	}
+
+	for _, test := range tests {
+		base, labels := promSplitName(test.name)
+		if base != test.base || labels != test.labels {
+			t.Errorf("%s: expected %s %s, got %s %s", test.name,
+				test.base, test.labels, base, labels)
+		}
+	}
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
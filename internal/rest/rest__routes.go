@@ -36,12 +36,16 @@ func (x *Rest) setupRouter() *httprouter.Router {
 	router.GET(`/entity/`, x.Authenticated(x.EntityList))
 	router.GET(`/environment/:environment`, x.Authenticated(x.EnvironmentShow))
 	router.GET(`/environment/`, x.Authenticated(x.EnvironmentList))
+	router.GET(`/export/checkconfig/:repositoryID/`, x.Authenticated(x.CheckConfigExport))
 	router.GET(`/hostdeployment/:monitoringID/:assetID`, x.Unauthenticated(x.HostDeploymentFetch))
 	router.GET(`/instance/:instanceID/versions`, x.Authenticated(x.InstanceVersions))
+	router.GET(rtAliasDeploymentIDDiff, x.Authenticated(x.InstanceDiff))
 	router.GET(`/instance/:instanceID`, x.Authenticated(x.ScopeSelectInstanceShow))
 	router.GET(`/instance/`, x.Authenticated(x.ScopeSelectInstanceList))
 	router.GET(`/level/:level`, x.Authenticated(x.LevelShow))
 	router.GET(`/level/`, x.Authenticated(x.LevelList))
+	router.GET(`/maintenance/:maintenanceID`, x.Authenticated(x.MaintenanceShow))
+	router.GET(`/maintenance/`, x.Authenticated(x.MaintenanceList))
 	router.GET(`/metric/:metric`, x.Authenticated(x.MetricShow))
 	router.GET(`/metric/`, x.Authenticated(x.MetricList))
 	router.GET(`/mode/:mode`, x.Authenticated(x.ModeShow))
@@ -115,6 +119,7 @@ func (x *Rest) setupRouter() *httprouter.Router {
 	router.GET(rtNodeInstanceVersions, x.Authenticated(x.InstanceVersions))
 	router.GET(rtNodeTree, x.Authenticated(x.NodeConfigTree))
 	router.GET(rtOncallMember, x.Authenticated(x.OncallMemberList))
+	router.GET(rtOutbox, x.Authenticated(x.OutboxList))
 	router.GET(rtPermission, x.Authenticated(x.PermissionList))
 	router.GET(rtPermissionID, x.Authenticated(x.PermissionShow))
 	router.GET(rtPropertyMgmt, x.Authenticated(x.PropertyMgmtList))
@@ -174,6 +179,7 @@ func (x *Rest) setupRouter() *httprouter.Router {
 			router.DELETE(`/entity/:entity`, x.Authenticated(x.EntityRemove))
 			router.DELETE(`/environment/:environment`, x.Authenticated(x.EnvironmentRemove))
 			router.DELETE(`/level/:level`, x.Authenticated(x.LevelRemove))
+			router.DELETE(`/maintenance/:maintenanceID`, x.Authenticated(x.MaintenanceCancel))
 			router.DELETE(`/metric/:metric`, x.Authenticated(x.MetricRemove))
 			router.DELETE(`/mode/:mode`, x.Authenticated(x.ModeRemove))
 			router.DELETE(`/monitoringsystem/:monitoringID`, x.Authenticated(x.MonitoringMgmtRemove))
@@ -195,6 +201,8 @@ func (x *Rest) setupRouter() *httprouter.Router {
 			router.DELETE(rtBucketID, x.Authenticated(x.BucketDestroy))
 			router.DELETE(rtBucketMemberID, x.Authenticated(x.BucketMemberUnassign))
 			router.DELETE(rtBucketPropertyID, x.Authenticated(x.BucketPropertyDestroy))
+			router.DELETE(rtCheckConfigOverride, x.Authenticated(x.CheckConfigOverrideRemove))
+			router.DELETE(rtCheckConfigRollback, x.Authenticated(x.CheckConfigRollbackDisable))
 			router.DELETE(rtClusterID, x.Authenticated(x.ClusterDestroy))
 			router.DELETE(rtClusterMemberID, x.Authenticated(x.ClusterMemberUnassign))
 			router.DELETE(rtClusterPropertyID, x.Authenticated(x.ClusterPropertyDestroy))
@@ -209,30 +217,38 @@ func (x *Rest) setupRouter() *httprouter.Router {
 			router.DELETE(rtNodePropertyID, x.Authenticated(x.NodeConfigPropertyDestroy))
 			router.DELETE(rtNodeUnassign, x.Authenticated(x.NodeConfigUnassign))
 			router.DELETE(rtOncallMemberID, x.Authenticated(x.OncallMemberUnassign))
+			router.DELETE(rtOutbox, x.Authenticated(x.OutboxPurge))
 			router.DELETE(rtPermissionID, x.Authenticated(x.PermissionRemove))
 			router.DELETE(rtPropertyMgmtID, x.Authenticated(x.PropertyMgmtRemove))
 			router.DELETE(rtRepositoryPropertyID, x.Authenticated(x.RepositoryConfigPropertyDestroy))
 			router.DELETE(rtRepositoryPropertyMgmtID, x.Authenticated(x.PropertyMgmtCustomRemove))
+			router.DELETE(rtRepositoryRollback, x.Authenticated(x.RepositoryConfigRollbackDisable))
 			router.DELETE(rtRightID, x.Authenticated(x.RightRevoke))
 			router.DELETE(rtTeamPropertyMgmtID, x.Authenticated(x.PropertyMgmtServiceRemove))
 			router.DELETE(rtTeamRepositoryID, x.Authenticated(x.RepositoryDestroy))
 			router.GET(rtAliasDeploymentID, x.Unauthenticated(x.DeploymentShow))
+			router.GET(rtAliasDeploymentStream, x.Authenticated(x.DeploymentStream))
 			router.GET(rtCompatDeploymentID, x.Unauthenticated(x.DeploymentShow))
 			router.GET(rtDeployment, x.Unauthenticated(x.DeploymentList))
 			router.GET(rtDeploymentID, x.Unauthenticated(x.DeploymentShow))
 			router.GET(rtDeploymentState, x.Unauthenticated(x.DeploymentPending))
 			router.GET(rtDeploymentStateID, x.Unauthenticated(x.DeploymentFilter))
+			router.GET(rtDeploymentStream, x.Authenticated(x.DeploymentStream))
 			router.GET(rtJobEntryWaitID, x.Authenticated(x.ScopeSelectJobWait))
 			router.GET(rtTeamRepositoryIDAudit, x.Authenticated(x.RepositoryAudit))
 			router.PATCH(`/accounts/password/:kexID`, x.Unauthenticated(x.SupervisorPasswordChange))
+			router.PATCH(`/checkconfig/:repositoryID/:checkID`, x.Authenticated(x.CheckConfigUpdate))
 			router.PATCH(`/oncall/:oncallID`, x.Authenticated(x.OncallUpdate))
 			router.PATCH(`/workflow/retry`, x.Authenticated(x.WorkflowRetry))
 			router.PATCH(`/workflow/set/:instanceconfigID`, x.Authenticated(x.WorkflowSet))
+			router.PATCH(rtCheckConfigRolloutResume, x.Authenticated(x.CheckConfigRolloutResume))
 			router.PATCH(rtAliasDeploymentIDAction, x.Unauthenticated(x.DeploymentUpdate))
 			router.PATCH(rtCompatDeploymentIDAction, x.Unauthenticated(x.DeploymentUpdate))
 			router.PATCH(rtClusterID, x.Authenticated(x.ClusterRename))
 			router.PATCH(rtDeploymentIDAction, x.Unauthenticated(x.DeploymentUpdate))
 			router.PATCH(rtOncallMember, x.Authenticated(x.OncallMemberAssign))
+			router.PATCH(rtMonitoringSecret, x.Authenticated(x.MonitoringMgmtSecret))
+			router.PATCH(rtOutboxReplay, x.Authenticated(x.OutboxReplay))
 			router.PATCH(rtPermissionID, x.Authenticated(x.PermissionEdit))
 			router.PATCH(rtTeamRepositoryIDName, x.Authenticated(x.RepositoryRename))
 			router.PATCH(rtTeamRepositoryIDOwner, x.Authenticated(x.RepositoryRepossess))
@@ -247,6 +263,7 @@ func (x *Rest) setupRouter() *httprouter.Router {
 			router.POST(`/environment/`, x.Authenticated(x.EnvironmentAdd))
 			router.POST(`/kex/`, x.Unauthenticated(x.SupervisorKex))
 			router.POST(`/level/`, x.Authenticated(x.LevelAdd))
+			router.POST(`/maintenance/`, x.Authenticated(x.MaintenanceAdd))
 			router.POST(`/metric/`, x.Authenticated(x.MetricAdd))
 			router.POST(`/mode/`, x.Authenticated(x.ModeAdd))
 			router.POST(`/monitoringsystem/`, x.Authenticated(x.MonitoringMgmtAdd))
@@ -288,6 +305,7 @@ func (x *Rest) setupRouter() *httprouter.Router {
 			router.PUT(`/accounts/activate/user/:kexID`, x.Unauthenticated(x.SupervisorActivateUser))
 			router.PUT(`/accounts/activate/admin/:kexID`, x.Unauthenticated(x.SupervisorActivateAdmin))
 			router.PUT(`/accounts/password/:kexID`, x.Unauthenticated(x.SupervisorPasswordReset))
+			router.PUT(`/checkconfig/:repositoryID/:checkID`, x.Authenticated(x.CheckConfigUpdate))
 			router.PUT(`/datacenter/:datacenter`, x.Authenticated(x.DatacenterRename))
 			router.PUT(`/entity/:entity`, x.Authenticated(x.EntityRename))
 			router.PUT(`/environment/:environment`, x.Authenticated(x.EnvironmentRename))
@@ -299,12 +317,15 @@ func (x *Rest) setupRouter() *httprouter.Router {
 			router.PUT(`/user/:userID`, x.Authenticated(x.UserMgmtUpdate))
 			router.PUT(`/view/:view`, x.Authenticated(x.ViewRename))
 			router.PUT(rtBucketPropertyID, x.Authenticated(x.BucketPropertyUpdate))
+			router.PUT(rtCheckConfigOverride, x.Authenticated(x.CheckConfigOverrideSet))
+			router.PUT(rtCheckConfigRollback, x.Authenticated(x.CheckConfigRollbackEnable))
 			router.PUT(rtClusterPropertyID, x.Authenticated(x.ClusterPropertyUpdate))
 			router.PUT(rtGroupPropertyID, x.Authenticated(x.GroupPropertyUpdate))
 			router.PUT(rtNodeConfig, x.Authenticated(x.NodeConfigAssign))
 			router.PUT(rtNodeID, x.Authenticated(x.NodeMgmtUpdate))
 			router.PUT(rtNodePropertyID, x.Authenticated(x.NodeConfigPropertyUpdate))
 			router.PUT(rtRepositoryPropertyID, x.Authenticated(x.RepositoryConfigPropertyUpdate))
+			router.PUT(rtRepositoryRollback, x.Authenticated(x.RepositoryConfigRollbackEnable))
 		}
 	}
 	return router
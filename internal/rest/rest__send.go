@@ -99,6 +99,10 @@ func (x *Rest) send(w *http.ResponseWriter, r *msg.Result) {
 	case msg.SectionLevel:
 		result = proto.NewLevelResult()
 		*result.Levels = append(*result.Levels, r.Level...)
+	case msg.SectionMaintenance:
+		result = proto.NewMaintenanceWindowResult()
+		*result.MaintenanceWindows = append(*result.MaintenanceWindows,
+			r.MaintenanceWindow...)
 	case msg.SectionMetric:
 		result = proto.NewMetricResult()
 		*result.Metrics = append(*result.Metrics, r.Metric...)
@@ -108,6 +112,9 @@ func (x *Rest) send(w *http.ResponseWriter, r *msg.Result) {
 	case msg.SectionOncall:
 		result = proto.NewOncallResult()
 		*result.Oncalls = append(*result.Oncalls, r.Oncall...)
+	case msg.SectionOutbox:
+		result = proto.NewNotificationResult()
+		*result.Notifications = append(*result.Notifications, r.Notification...)
 	case msg.SectionPermission:
 		result = proto.NewPermissionResult()
 		*result.Permissions = append(*result.Permissions, r.Permission...)
@@ -464,6 +471,11 @@ func (x *Rest) send(w *http.ResponseWriter, r *msg.Result) {
 	}
 	result.RequestID = r.ID.String()
 
+	// tree actions computed by dry run requests
+	if r.TreeAction != nil {
+		result.TreeActions = &r.TreeAction
+	}
+
 	logEntry = logEntry.WithField(`Code`, r.Code)
 
 	switch r.Code {
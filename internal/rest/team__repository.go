@@ -33,6 +33,7 @@ func (x *Rest) RepositoryDestroy(w http.ResponseWriter, r *http.Request,
 		return
 	}
 
+	request.Flag.DryRun = requestDryRun(r, nil)
 	x.handlerMap.MustLookup(&request).Intake() <- request
 	result := <-request.Reply
 	x.send(&w, &result)
@@ -88,6 +89,7 @@ func (x *Rest) RepositoryRename(w http.ResponseWriter, r *http.Request,
 		return
 	}
 
+	request.Flag.DryRun = requestDryRun(r, cReq.Flags)
 	x.handlerMap.MustLookup(&request).Intake() <- request
 	result := <-request.Reply
 	x.send(&w, &result)
@@ -116,6 +118,7 @@ func (x *Rest) RepositoryRepossess(w http.ResponseWriter, r *http.Request,
 		return
 	}
 
+	request.Flag.DryRun = requestDryRun(r, cReq.Flags)
 	x.handlerMap.MustLookup(&request).Intake() <- request
 	result := <-request.Reply
 	x.send(&w, &result)
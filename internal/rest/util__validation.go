@@ -9,6 +9,12 @@
 package rest // import "github.com/mjolnir42/soma/internal/rest"
 
 import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/mjolnir42/soma/internal/msg"
+	"github.com/mjolnir42/soma/lib/constraint"
+	"github.com/mjolnir42/soma/lib/proto"
 	"github.com/satori/go.uuid"
 )
 
@@ -19,4 +25,122 @@ func checkStringIsUUID(str string) error {
 	return nil
 }
 
+// maxThresholdLength is the size of the database column that stores
+// threshold values and their value templates
+const maxThresholdLength = 128
+
+// checkThresholdTemplates verifies that the value templates of
+// thresholds fit into the database
+func checkThresholdTemplates(thresholds []proto.CheckConfigThreshold) error {
+	for _, thr := range thresholds {
+		if utf8.RuneCountInString(thr.ValueTemplate) > maxThresholdLength {
+			return fmt.Errorf("Threshold value template for level %s"+
+				" exceeds %d characters", thr.Level.Name,
+				maxThresholdLength)
+		}
+	}
+	return nil
+}
+
+// checkConstraintOperators verifies that all constraints of cfg use
+// a supported operator with a valid value, and that all constraint
+// groups are well formed
+func checkConstraintOperators(cfg *proto.CheckConfig) error {
+	for i := range cfg.Constraints {
+		if err := checkConstraintOperator(&cfg.Constraints[i]); err != nil {
+			return err
+		}
+	}
+	for i := range cfg.ConstraintGroups {
+		if err := checkConstraintGroup(&cfg.ConstraintGroups[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkConstraintOperator verifies the operator of constraint c
+func checkConstraintOperator(c *proto.CheckConfigConstraint) error {
+	var value string
+	switch {
+	case c.ConstraintType == msg.ConstraintOncall:
+		if c.EffectiveOperator() != proto.ConstraintEqual {
+			return fmt.Errorf("Oncall constraints only support"+
+				" operator %s", proto.ConstraintEqual)
+		}
+		return nil
+	case c.ConstraintType == msg.ConstraintService && c.Service != nil:
+		value = c.Service.Name
+	case c.ConstraintType == msg.ConstraintAttribute && c.Attribute != nil:
+		value = c.Attribute.Value
+	case c.ConstraintType == msg.ConstraintCustom && c.Custom != nil:
+		value = c.Custom.Value
+	case c.ConstraintType == msg.ConstraintSystem && c.System != nil:
+		value = c.System.Value
+	case c.ConstraintType == msg.ConstraintNative && c.Native != nil:
+		value = c.Native.Value
+	}
+	return constraint.Validate(c.EffectiveOperator(), value)
+}
+
+// checkConstraintGroup verifies the operator and members of grp and
+// all its nested groups
+func checkConstraintGroup(grp *proto.CheckConfigConstraintGroup) error {
+	switch grp.Operator {
+	case proto.ConstraintGroupAnd, proto.ConstraintGroupOr,
+		proto.ConstraintGroupNot:
+	default:
+		return fmt.Errorf("Invalid constraint group operator: %s",
+			grp.Operator)
+	}
+	if len(grp.Constraints)+len(grp.Groups) == 0 {
+		return fmt.Errorf("Empty constraint group: %s", grp.Operator)
+	}
+	for i := range grp.Constraints {
+		c := &grp.Constraints[i]
+		switch c.ConstraintType {
+		case msg.ConstraintAttribute:
+			return fmt.Errorf("Attribute constraints are not" +
+				" supported in constraint groups")
+		case msg.ConstraintCustom:
+			if c.Custom == nil {
+				return fmt.Errorf("Incomplete custom constraint" +
+					" in constraint group")
+			}
+		case msg.ConstraintSystem:
+			if c.System == nil {
+				return fmt.Errorf("Incomplete system constraint" +
+					" in constraint group")
+			}
+		case msg.ConstraintNative:
+			if c.Native == nil {
+				return fmt.Errorf("Incomplete native constraint" +
+					" in constraint group")
+			}
+		case msg.ConstraintService:
+			if c.Service == nil {
+				return fmt.Errorf("Incomplete service constraint" +
+					" in constraint group")
+			}
+		case msg.ConstraintOncall:
+			if c.Oncall == nil {
+				return fmt.Errorf("Incomplete oncall constraint" +
+					" in constraint group")
+			}
+		default:
+			return fmt.Errorf("Invalid constraint type in"+
+				" constraint group: %s", c.ConstraintType)
+		}
+		if err := checkConstraintOperator(c); err != nil {
+			return err
+		}
+	}
+	for i := range grp.Groups {
+		if err := checkConstraintGroup(&grp.Groups[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -2,7 +2,7 @@ package soma
 
 import (
 	"database/sql"
-	"strconv"
+	"encoding/json"
 
 	"github.com/mjolnir42/soma/internal/stmt"
 	"github.com/mjolnir42/soma/lib/proto"
@@ -39,6 +39,8 @@ func exportCheckConfigObjectTX(tx *sql.Tx, objectID string) (
 		`cstrService`:   stmt.CheckConfigShowConstrService,
 		`cstrAttribute`: stmt.CheckConfigShowConstrAttribute,
 		`cstrOncall`:    stmt.CheckConfigShowConstrOncall,
+		`cstrGroups`:    stmt.CheckConfigShowConstrGroups,
+		`overrides`:     stmt.CheckConfigShowThresholdOverrides,
 		`instance`:      stmt.CheckConfigObjectInstanceInfo,
 	} {
 		if txMap[name], err = tx.Prepare(statement); err != nil {
@@ -57,44 +59,127 @@ func exportCheckConfigObjectTX(tx *sql.Tx, objectID string) (
 			return nil, err
 		}
 
-		if checkConfig, err = exportCheckConfig(
-			txMap[`base`],
+		if checkConfig, err = exportCheckConfigTX(
+			txMap,
 			checkConfigID,
 		); err != nil {
 			rows.Close()
 			return nil, err
 		}
-		if checkConfig.Thresholds, err = exportCheckConfigThresholds(
-			txMap[`threshold`],
+		if checkConfig == nil {
+			continue
+		}
+		if instances, err = exportCheckInstancesForObject(
+			txMap[`instance`],
 			checkConfigID,
+			objectID,
 		); err != nil {
 			rows.Close()
 			return nil, err
 		}
-		if checkConfig.Constraints, err = exportCheckConfigConstraints(
-			txMap[`cstrCustom`],
-			txMap[`cstrSystem`],
-			txMap[`cstrNative`],
-			txMap[`cstrService`],
-			txMap[`cstrAttribute`],
-			txMap[`cstrOncall`],
-			checkConfigID,
+		if len(instances) > 0 {
+			checkConfig.Details = &proto.CheckConfigDetails{
+				Instances: instances,
+			}
+		}
+		checkconfigs = append(checkconfigs, *checkConfig)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	tx.Commit()
+
+	return &checkconfigs, nil
+}
+
+// exportCheckConfigRepositoryTX returns all check configurations of
+// the repository with the names of the objects they reference
+func exportCheckConfigRepositoryTX(tx *sql.Tx, repositoryID string) (
+	*[]proto.CheckConfig, error) {
+
+	var (
+		err                               error
+		checkconfigs                      []proto.CheckConfig
+		checkConfigID, repoID, configName string
+		repoName, objectName, capability  string
+		bucketIDOrNull, bucketNameOrNull  sql.NullString
+		checkConfig                       *proto.CheckConfig
+		txMap                             map[string]*sql.Stmt
+		rows                              *sql.Rows
+		names                             *proto.CheckConfigNames
+	)
+
+	// declare this tx as deferrable read-only
+	if _, err = tx.Exec(stmt.ReadOnlyTransaction); err != nil {
+		return nil, err
+	}
+
+	txMap = make(map[string]*sql.Stmt)
+	checkconfigs = make([]proto.CheckConfig, 0)
+
+	for name, statement := range map[string]string{
+		`configs`:       stmt.CheckConfigList,
+		`names`:         stmt.CheckConfigExportNames,
+		`base`:          stmt.CheckConfigShowBase,
+		`threshold`:     stmt.CheckConfigShowThreshold,
+		`cstrCustom`:    stmt.CheckConfigShowConstrCustom,
+		`cstrSystem`:    stmt.CheckConfigShowConstrSystem,
+		`cstrNative`:    stmt.CheckConfigShowConstrNative,
+		`cstrService`:   stmt.CheckConfigShowConstrService,
+		`cstrAttribute`: stmt.CheckConfigShowConstrAttribute,
+		`cstrOncall`:    stmt.CheckConfigShowConstrOncall,
+		`cstrGroups`:    stmt.CheckConfigShowConstrGroups,
+		`overrides`:     stmt.CheckConfigShowThresholdOverrides,
+	} {
+		if txMap[name], err = tx.Prepare(statement); err != nil {
+			return nil, err
+		}
+	}
+
+	if rows, err = txMap[`configs`].Query(repositoryID); err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		if err = rows.Scan(
+			&checkConfigID,
+			&repoID,
+			&bucketIDOrNull,
+			&configName,
 		); err != nil {
 			rows.Close()
 			return nil, err
 		}
-		if instances, err = exportCheckInstancesForObject(
-			txMap[`instance`],
+
+		if checkConfig, err = exportCheckConfigTX(
+			txMap,
 			checkConfigID,
-			objectID,
 		); err != nil {
 			rows.Close()
 			return nil, err
 		}
-		if len(instances) > 0 {
-			checkConfig.Details = &proto.CheckConfigDetails{
-				Instances: instances,
-			}
+		if checkConfig == nil {
+			continue
+		}
+
+		if err = txMap[`names`].QueryRow(checkConfigID).Scan(
+			&repoName,
+			&bucketNameOrNull,
+			&objectName,
+			&capability,
+		); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		names = &proto.CheckConfigNames{
+			Repository: repoName,
+			Object:     objectName,
+			Capability: capability,
+		}
+		if bucketNameOrNull.Valid {
+			names.Bucket = bucketNameOrNull.String
+		}
+		checkConfig.Details = &proto.CheckConfigDetails{
+			Names: names,
 		}
 		checkconfigs = append(checkconfigs, *checkConfig)
 	}
@@ -106,6 +191,55 @@ func exportCheckConfigObjectTX(tx *sql.Tx, objectID string) (
 	return &checkconfigs, nil
 }
 
+// exportCheckConfigTX returns the check configuration with its
+// thresholds, constraints and threshold overrides. It expects txMap
+// to contain the prepared statements of exportCheckConfigObjectTX.
+func exportCheckConfigTX(txMap map[string]*sql.Stmt,
+	checkConfigID string) (*proto.CheckConfig, error) {
+
+	var (
+		err         error
+		checkConfig *proto.CheckConfig
+	)
+
+	if checkConfig, err = exportCheckConfig(
+		txMap[`base`],
+		checkConfigID,
+	); err != nil || checkConfig == nil {
+		return nil, err
+	}
+	if checkConfig.Thresholds, err = exportCheckConfigThresholds(
+		txMap[`threshold`],
+		checkConfigID,
+	); err != nil {
+		return nil, err
+	}
+	if checkConfig.Constraints, err = exportCheckConfigConstraints(
+		txMap[`cstrCustom`],
+		txMap[`cstrSystem`],
+		txMap[`cstrNative`],
+		txMap[`cstrService`],
+		txMap[`cstrAttribute`],
+		txMap[`cstrOncall`],
+		checkConfigID,
+	); err != nil {
+		return nil, err
+	}
+	if checkConfig.ConstraintGroups, err = exportCheckConfigConstraintGroups(
+		txMap[`cstrGroups`],
+		checkConfigID,
+	); err != nil {
+		return nil, err
+	}
+	if checkConfig.ThresholdOverrides, err = exportCheckConfigThresholdOverrides(
+		txMap[`overrides`],
+		checkConfigID,
+	); err != nil {
+		return nil, err
+	}
+	return checkConfig, nil
+}
+
 // expects stmt.CheckConfigShowBase as prepared statement
 func exportCheckConfig(prepStmt *sql.Stmt, queryID string) (
 	*proto.CheckConfig, error) {
@@ -171,7 +305,7 @@ func exportCheckConfigThresholds(prepStmt *sql.Stmt, queryID string) (
 		err                                       error
 		rows                                      *sql.Rows
 		checkConfigID, predicateSymbol, threshold string
-		levelName, levelShortName                 string
+		levelName, levelShortName, valueTemplate  string
 		levelNumeric, thresholdValue              int64
 		thresholds                                []proto.CheckConfigThreshold
 	)
@@ -192,7 +326,7 @@ func exportCheckConfigThresholds(prepStmt *sql.Stmt, queryID string) (
 			rows.Close()
 			return nil, err
 		}
-		thresholdValue, _ = strconv.ParseInt(threshold, 10, 64)
+		thresholdValue, valueTemplate, _ = splitThresholdValue(threshold)
 
 		thr := proto.CheckConfigThreshold{
 			Predicate: proto.Predicate{
@@ -203,7 +337,8 @@ func exportCheckConfigThresholds(prepStmt *sql.Stmt, queryID string) (
 				ShortName: levelShortName,
 				Numeric:   uint16(levelNumeric),
 			},
-			Value: thresholdValue,
+			Value:         thresholdValue,
+			ValueTemplate: valueTemplate,
 		}
 		thresholds = append(thresholds, thr)
 	}
@@ -214,6 +349,71 @@ func exportCheckConfigThresholds(prepStmt *sql.Stmt, queryID string) (
 	return thresholds, nil
 }
 
+// expects stmt.CheckConfigShowThresholdOverrides as prepared statement
+func exportCheckConfigThresholdOverrides(prepStmt *sql.Stmt,
+	queryID string) ([]proto.CheckConfigThresholdOverride, error) {
+
+	var (
+		err                              error
+		rows                             *sql.Rows
+		overrideID, objectID, objectType string
+		predicateSymbol, threshold       string
+		levelName, levelShortName        string
+		valueTemplate                    string
+		levelNumeric, thresholdValue     int64
+		overrides                        []proto.CheckConfigThresholdOverride
+	)
+
+	if rows, err = prepStmt.Query(queryID); err != nil {
+		return nil, err
+	}
+
+	for rows.Next() {
+		if err = rows.Scan(
+			&overrideID,
+			&objectID,
+			&objectType,
+			&predicateSymbol,
+			&threshold,
+			&levelName,
+			&levelShortName,
+			&levelNumeric,
+		); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		thresholdValue, valueTemplate, _ = splitThresholdValue(threshold)
+
+		// rows are ordered by override
+		if len(overrides) == 0 || overrides[len(overrides)-1].ID != overrideID {
+			overrides = append(overrides, proto.CheckConfigThresholdOverride{
+				ID:         overrideID,
+				ObjectID:   objectID,
+				ObjectType: objectType,
+				Thresholds: []proto.CheckConfigThreshold{},
+			})
+		}
+		last := &overrides[len(overrides)-1]
+		last.Thresholds = append(last.Thresholds, proto.CheckConfigThreshold{
+			Predicate: proto.Predicate{
+				Symbol: predicateSymbol,
+			},
+			Level: proto.Level{
+				Name:      levelName,
+				ShortName: levelShortName,
+				Numeric:   uint16(levelNumeric),
+			},
+			Value:         thresholdValue,
+			ValueTemplate: valueTemplate,
+		})
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return overrides, nil
+}
+
 // expects in that order:
 // - stmt.CheckConfigShowConstrCustom
 // - stmt.CheckConfigShowConstrSystem
@@ -272,6 +472,7 @@ func exportCheckConfigCustomConstraints(prepStmt *sql.Stmt,
 		checkConfigID, propertyID, repositoryID string
 		name, value                             string
 		rows                                    *sql.Rows
+		operator                                string
 		err                                     error
 		constraints                             []proto.CheckConfigConstraint
 	)
@@ -287,12 +488,14 @@ func exportCheckConfigCustomConstraints(prepStmt *sql.Stmt,
 			&repositoryID,
 			&value,
 			&name,
+			&operator,
 		); err != nil {
 			rows.Close()
 			return nil, err
 		}
 		cstr := proto.CheckConfigConstraint{
 			ConstraintType: `custom`,
+			Operator:       operator,
 			Custom: &proto.PropertyCustom{
 				ID:           propertyID,
 				RepositoryID: repositoryID,
@@ -316,6 +519,7 @@ func exportCheckConfigSystemConstraints(prepStmt *sql.Stmt,
 	var (
 		checkConfigID, name, value string
 		rows                       *sql.Rows
+		operator                   string
 		err                        error
 		constraints                []proto.CheckConfigConstraint
 	)
@@ -329,12 +533,14 @@ func exportCheckConfigSystemConstraints(prepStmt *sql.Stmt,
 			&checkConfigID,
 			&name,
 			&value,
+			&operator,
 		); err != nil {
 			rows.Close()
 			return nil, err
 		}
 		cstr := proto.CheckConfigConstraint{
 			ConstraintType: `system`,
+			Operator:       operator,
 			System: &proto.PropertySystem{
 				Name:  name,
 				Value: value,
@@ -356,6 +562,7 @@ func exportCheckConfigNativeConstraints(prepStmt *sql.Stmt,
 	var (
 		checkConfigID, name, value string
 		rows                       *sql.Rows
+		operator                   string
 		err                        error
 		constraints                []proto.CheckConfigConstraint
 	)
@@ -369,12 +576,14 @@ func exportCheckConfigNativeConstraints(prepStmt *sql.Stmt,
 			&checkConfigID,
 			&name,
 			&value,
+			&operator,
 		); err != nil {
 			rows.Close()
 			return nil, err
 		}
 		cstr := proto.CheckConfigConstraint{
 			ConstraintType: `native`,
+			Operator:       operator,
 			Native: &proto.PropertyNative{
 				Name:  name,
 				Value: value,
@@ -396,6 +605,7 @@ func exportCheckConfigServiceConstraints(prepStmt *sql.Stmt,
 	var (
 		checkConfigID, name, teamID string
 		rows                        *sql.Rows
+		operator                    string
 		err                         error
 		constraints                 []proto.CheckConfigConstraint
 	)
@@ -409,12 +619,14 @@ func exportCheckConfigServiceConstraints(prepStmt *sql.Stmt,
 			&checkConfigID,
 			&teamID,
 			&name,
+			&operator,
 		); err != nil {
 			rows.Close()
 			return nil, err
 		}
 		cstr := proto.CheckConfigConstraint{
 			ConstraintType: `service`,
+			Operator:       operator,
 			Service: &proto.PropertyService{
 				Name:   name,
 				TeamID: teamID,
@@ -436,6 +648,7 @@ func exportCheckConfigAttributeConstraints(prepStmt *sql.Stmt,
 	var (
 		checkConfigID, name, value string
 		rows                       *sql.Rows
+		operator                   string
 		err                        error
 		constraints                []proto.CheckConfigConstraint
 	)
@@ -449,12 +662,14 @@ func exportCheckConfigAttributeConstraints(prepStmt *sql.Stmt,
 			&checkConfigID,
 			&name,
 			&value,
+			&operator,
 		); err != nil {
 			rows.Close()
 			return nil, err
 		}
 		cstr := proto.CheckConfigConstraint{
 			ConstraintType: `attribute`,
+			Operator:       operator,
 			Attribute: &proto.ServiceAttribute{
 				Name:  name,
 				Value: value,
@@ -511,6 +726,30 @@ func exportCheckConfigOncallConstraints(prepStmt *sql.Stmt,
 	return constraints, nil
 }
 
+// expects stmt.CheckConfigShowConstrGroups as prepared statement
+func exportCheckConfigConstraintGroups(prepStmt *sql.Stmt,
+	queryID string) ([]proto.CheckConfigConstraintGroup, error) {
+
+	var (
+		err    error
+		raw    string
+		groups []proto.CheckConfigConstraintGroup
+	)
+
+	if err = prepStmt.QueryRow(queryID).Scan(
+		&raw,
+	); err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal([]byte(raw), &groups); err != nil {
+		return nil, err
+	}
+
+	return groups, nil
+}
+
 // expects stmt.CheckConfigInstanceInfo as prepared statement
 func exportCheckInstancesForConfig(prepStmt *sql.Stmt,
 	queryID string) ([]proto.CheckInstanceInfo, error) {
@@ -520,6 +759,8 @@ func exportCheckInstancesForConfig(prepStmt *sql.Stmt,
 		rows                             *sql.Rows
 		instanceID, objectID, objectType string
 		currentStatus, nextStatus        string
+		overrideID, overrideObjectID     sql.NullString
+		overrideObjectType               sql.NullString
 		instances                        []proto.CheckInstanceInfo
 	)
 
@@ -534,6 +775,9 @@ func exportCheckInstancesForConfig(prepStmt *sql.Stmt,
 			&objectType,
 			&currentStatus,
 			&nextStatus,
+			&overrideID,
+			&overrideObjectID,
+			&overrideObjectType,
 		); err != nil {
 			rows.Close()
 			return nil, err
@@ -545,6 +789,13 @@ func exportCheckInstancesForConfig(prepStmt *sql.Stmt,
 			CurrentStatus: currentStatus,
 			NextStatus:    nextStatus,
 		}
+		if overrideID.Valid {
+			info.ThresholdSource = &proto.CheckThresholdSource{
+				OverrideID: overrideID.String,
+				ObjectID:   overrideObjectID.String,
+				ObjectType: overrideObjectType.String,
+			}
+		}
 		instances = append(instances, info)
 	}
 	if err = rows.Err(); err != nil {
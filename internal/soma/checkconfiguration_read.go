@@ -10,10 +10,11 @@ package soma
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
-	"strconv"
 
 	"github.com/Sirupsen/logrus"
+	"github.com/lib/pq"
 	"github.com/mjolnir42/soma/internal/handler"
 	"github.com/mjolnir42/soma/internal/msg"
 	"github.com/mjolnir42/soma/internal/stmt"
@@ -37,6 +38,9 @@ type CheckConfigurationRead struct {
 	stmtShowConstraintAttribute *sql.Stmt
 	stmtShowConstraintOncall    *sql.Stmt
 	stmtShowInstanceInfo        *sql.Stmt
+	stmtShowRollout             *sql.Stmt
+	stmtShowConstraintGroups    *sql.Stmt
+	stmtShowOverrides           *sql.Stmt
 	appLog                      *logrus.Logger
 	reqLog                      *logrus.Logger
 	errLog                      *logrus.Logger
@@ -68,6 +72,7 @@ func (r *CheckConfigurationRead) RegisterRequests(hmap *handler.Map) {
 		msg.ActionList,
 		msg.ActionShow,
 		msg.ActionSearch,
+		msg.ActionExport,
 	} {
 		hmap.Request(msg.SectionCheckConfig, action, r.handlerName)
 	}
@@ -88,16 +93,19 @@ func (r *CheckConfigurationRead) Run() {
 	var err error
 
 	for statement, prepStmt := range map[string]**sql.Stmt{
-		stmt.CheckConfigList:                &r.stmtList,
-		stmt.CheckConfigShowBase:            &r.stmtShow,
-		stmt.CheckConfigShowThreshold:       &r.stmtShowThreshold,
-		stmt.CheckConfigShowConstrCustom:    &r.stmtShowConstraintCustom,
-		stmt.CheckConfigShowConstrSystem:    &r.stmtShowConstraintSystem,
-		stmt.CheckConfigShowConstrNative:    &r.stmtShowConstraintNative,
-		stmt.CheckConfigShowConstrService:   &r.stmtShowConstraintService,
-		stmt.CheckConfigShowConstrAttribute: &r.stmtShowConstraintAttribute,
-		stmt.CheckConfigShowConstrOncall:    &r.stmtShowConstraintOncall,
-		stmt.CheckConfigInstanceInfo:        &r.stmtShowInstanceInfo,
+		stmt.CheckConfigList:                   &r.stmtList,
+		stmt.CheckConfigShowBase:               &r.stmtShow,
+		stmt.CheckConfigShowThreshold:          &r.stmtShowThreshold,
+		stmt.CheckConfigShowConstrCustom:       &r.stmtShowConstraintCustom,
+		stmt.CheckConfigShowConstrSystem:       &r.stmtShowConstraintSystem,
+		stmt.CheckConfigShowConstrNative:       &r.stmtShowConstraintNative,
+		stmt.CheckConfigShowConstrService:      &r.stmtShowConstraintService,
+		stmt.CheckConfigShowConstrAttribute:    &r.stmtShowConstraintAttribute,
+		stmt.CheckConfigShowConstrOncall:       &r.stmtShowConstraintOncall,
+		stmt.CheckConfigInstanceInfo:           &r.stmtShowInstanceInfo,
+		stmt.CheckConfigShowRollout:            &r.stmtShowRollout,
+		stmt.CheckConfigShowConstrGroups:       &r.stmtShowConstraintGroups,
+		stmt.CheckConfigShowThresholdOverrides: &r.stmtShowOverrides,
 	} {
 		if *prepStmt, err = r.conn.Prepare(statement); err != nil {
 			r.errLog.Fatal(`checkconfig`, err, stmt.Name(statement))
@@ -131,6 +139,8 @@ func (r *CheckConfigurationRead) process(q *msg.Request) {
 	case msg.ActionSearch:
 		// XXX BUG x.search(q, &result)
 		r.list(q, &result)
+	case msg.ActionExport:
+		r.export(q, &result)
 	default:
 		result.UnknownRequest(q)
 	}
@@ -186,6 +196,31 @@ func (r *CheckConfigurationRead) list(q *msg.Request, mr *msg.Result) {
 	mr.OK()
 }
 
+// export returns all check configurations of a repository with
+// the names of the objects they reference
+func (r *CheckConfigurationRead) export(q *msg.Request, mr *msg.Result) {
+	var (
+		tx           *sql.Tx
+		checkConfigs *[]proto.CheckConfig
+		err          error
+	)
+
+	if tx, err = r.conn.Begin(); err != nil {
+		mr.ServerError(err, q.Section)
+		return
+	}
+	if checkConfigs, err = exportCheckConfigRepositoryTX(
+		tx,
+		q.CheckConfig.RepositoryID,
+	); err != nil {
+		tx.Rollback()
+		mr.ServerError(err, q.Section)
+		return
+	}
+	mr.CheckConfig = append(mr.CheckConfig, *checkConfigs...)
+	mr.OK()
+}
+
 // show returns details for a check configuration
 func (r *CheckConfigurationRead) show(q *msg.Request, mr *msg.Result) {
 	var (
@@ -252,10 +287,25 @@ func (r *CheckConfigurationRead) show(q *msg.Request, mr *msg.Result) {
 		goto fail
 	}
 
+	if err = r.constraintGroups(&checkConfig); err != nil {
+		goto fail
+	}
+
+	if checkConfig.ThresholdOverrides, err = exportCheckConfigThresholdOverrides(
+		r.stmtShowOverrides,
+		checkConfig.ID,
+	); err != nil {
+		goto fail
+	}
+
 	if err = r.instances(&checkConfig); err != nil {
 		goto fail
 	}
 
+	if err = r.rollout(&checkConfig); err != nil {
+		goto fail
+	}
+
 	mr.CheckConfig = append(mr.CheckConfig, checkConfig)
 	mr.OK()
 	return
@@ -268,7 +318,7 @@ fail:
 func (r *CheckConfigurationRead) thresholds(cnf *proto.CheckConfig) error {
 	var (
 		predicate, threshold, lvlName, lvlShort string
-		configID                                string
+		configID, valueTemplate                 string
 		lvlNumeric, value                       int64
 		err                                     error
 		rows                                    *sql.Rows
@@ -298,8 +348,8 @@ func (r *CheckConfigurationRead) thresholds(cnf *proto.CheckConfig) error {
 			return err
 		}
 
-		if value, err = strconv.ParseInt(
-			threshold, 10, 64,
+		if value, valueTemplate, err = splitThresholdValue(
+			threshold,
 		); err != nil {
 			return err
 		}
@@ -313,7 +363,8 @@ func (r *CheckConfigurationRead) thresholds(cnf *proto.CheckConfig) error {
 				ShortName: lvlShort,
 				Numeric:   uint16(lvlNumeric),
 			},
-			Value: value,
+			Value:         value,
+			ValueTemplate: valueTemplate,
 		}
 
 		cnf.Thresholds = append(cnf.Thresholds, thr)
@@ -373,12 +424,34 @@ func (r *CheckConfigurationRead) constraints(cnf *proto.CheckConfig) error {
 	return nil
 }
 
+// constraintGroups adds the nested constraint groups to a check
+// configuration
+func (r *CheckConfigurationRead) constraintGroups(cnf *proto.CheckConfig) error {
+	var (
+		err error
+		raw string
+	)
+
+	if err = r.stmtShowConstraintGroups.QueryRow(
+		cnf.ID,
+	).Scan(
+		&raw,
+	); err == sql.ErrNoRows {
+		// check configuration has no constraint groups
+		return nil
+	} else if err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(raw), &cnf.ConstraintGroups)
+}
+
 // constraintCustom adds constraints on custom properties to
 // a check configuration
 func (r *CheckConfigurationRead) constraintCustom(cnf *proto.CheckConfig) error {
 	var (
 		configID, propertyID, repoID, property, value string
 		rows                                          *sql.Rows
+		operator                                      string
 		err                                           error
 	)
 
@@ -396,12 +469,14 @@ func (r *CheckConfigurationRead) constraintCustom(cnf *proto.CheckConfig) error
 			&repoID,
 			&value,
 			&property,
+			&operator,
 		); err != nil {
 			return err
 		}
 
 		constraint := proto.CheckConfigConstraint{
 			ConstraintType: `custom`,
+			Operator:       operator,
 			Custom: &proto.PropertyCustom{
 				ID:           propertyID,
 				RepositoryID: repoID,
@@ -423,6 +498,7 @@ func (r *CheckConfigurationRead) constraintSystem(cnf *proto.CheckConfig) error
 	var (
 		configID, property, value string
 		rows                      *sql.Rows
+		operator                  string
 		err                       error
 	)
 
@@ -438,12 +514,14 @@ func (r *CheckConfigurationRead) constraintSystem(cnf *proto.CheckConfig) error
 			&configID,
 			&property,
 			&value,
+			&operator,
 		); err != nil {
 			return err
 		}
 
 		constraint := proto.CheckConfigConstraint{
 			ConstraintType: `system`,
+			Operator:       operator,
 			System: &proto.PropertySystem{
 				Name:  property,
 				Value: value,
@@ -463,6 +541,7 @@ func (r *CheckConfigurationRead) constraintNative(cnf *proto.CheckConfig) error
 	var (
 		configID, property, value string
 		rows                      *sql.Rows
+		operator                  string
 		err                       error
 	)
 
@@ -478,12 +557,14 @@ func (r *CheckConfigurationRead) constraintNative(cnf *proto.CheckConfig) error
 			&configID,
 			&property,
 			&value,
+			&operator,
 		); err != nil {
 			return err
 		}
 
 		constraint := proto.CheckConfigConstraint{
 			ConstraintType: `native`,
+			Operator:       operator,
 			Native: &proto.PropertyNative{
 				Name:  property,
 				Value: value,
@@ -501,6 +582,7 @@ func (r *CheckConfigurationRead) constraintService(cnf *proto.CheckConfig) error
 	var (
 		configID, teamID, svcName string
 		rows                      *sql.Rows
+		operator                  string
 		err                       error
 	)
 
@@ -516,12 +598,14 @@ func (r *CheckConfigurationRead) constraintService(cnf *proto.CheckConfig) error
 			&configID,
 			&teamID,
 			&svcName,
+			&operator,
 		); err != nil {
 			return err
 		}
 
 		constraint := proto.CheckConfigConstraint{
 			ConstraintType: `service`,
+			Operator:       operator,
 			Service: &proto.PropertyService{
 				Name:   svcName,
 				TeamID: teamID,
@@ -541,6 +625,7 @@ func (r *CheckConfigurationRead) constraintAttribute(cnf *proto.CheckConfig) err
 	var (
 		configID, attribute, value string
 		rows                       *sql.Rows
+		operator                   string
 		err                        error
 	)
 
@@ -556,12 +641,14 @@ func (r *CheckConfigurationRead) constraintAttribute(cnf *proto.CheckConfig) err
 			&configID,
 			&attribute,
 			&value,
+			&operator,
 		); err != nil {
 			return err
 		}
 
 		constraint := proto.CheckConfigConstraint{
 			ConstraintType: `attribute`,
+			Operator:       operator,
 			Attribute: &proto.ServiceAttribute{
 				Name:  attribute,
 				Value: value,
@@ -618,6 +705,8 @@ func (r *CheckConfigurationRead) instances(cnf *proto.CheckConfig) error {
 		rows                             *sql.Rows
 		instanceID, objectID, objectType string
 		currentStatus, nextStatus        string
+		overrideID, overrideObjectID     sql.NullString
+		overrideObjectType               sql.NullString
 		err                              error
 	)
 
@@ -637,6 +726,9 @@ func (r *CheckConfigurationRead) instances(cnf *proto.CheckConfig) error {
 			&objectType,
 			&currentStatus,
 			&nextStatus,
+			&overrideID,
+			&overrideObjectID,
+			&overrideObjectType,
 		); err != nil {
 			return err
 		}
@@ -648,6 +740,13 @@ func (r *CheckConfigurationRead) instances(cnf *proto.CheckConfig) error {
 			CurrentStatus: currentStatus,
 			NextStatus:    nextStatus,
 		}
+		if overrideID.Valid {
+			instance.ThresholdSource = &proto.CheckThresholdSource{
+				OverrideID: overrideID.String,
+				ObjectID:   overrideObjectID.String,
+				ObjectType: overrideObjectType.String,
+			}
+		}
 		instances = append(instances, instance)
 	}
 	if err = rows.Err(); err != nil {
@@ -662,6 +761,48 @@ func (r *CheckConfigurationRead) instances(cnf *proto.CheckConfig) error {
 	return nil
 }
 
+// rollout adds the staged rollout policy and its current state
+// to a check configuration
+func (r *CheckConfigurationRead) rollout(cnf *proto.CheckConfig) error {
+	var (
+		err                           error
+		waveSize, wavePercent         sql.NullInt64
+		soak, threshold, wave, failed int64
+		haltedAt                      pq.NullTime
+	)
+
+	if err = r.stmtShowRollout.QueryRow(
+		cnf.ID,
+	).Scan(
+		&waveSize,
+		&wavePercent,
+		&soak,
+		&threshold,
+		&wave,
+		&haltedAt,
+		&failed,
+	); err == sql.ErrNoRows {
+		// check configuration has no rollout policy
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	cnf.Rollout = &proto.CheckConfigRollout{
+		WaveSize:         uint64(waveSize.Int64),
+		WavePercent:      uint64(wavePercent.Int64),
+		SoakTime:         uint64(soak),
+		FailureThreshold: uint64(threshold),
+		Wave:             uint64(wave),
+		Failures:         uint64(failed),
+		IsHalted:         haltedAt.Valid,
+	}
+	if haltedAt.Valid {
+		cnf.Rollout.HaltedAt = haltedAt.Time.UTC().Format(msg.RFC3339Milli)
+	}
+	return nil
+}
+
 // ShutdownNow signals the handler to shut down
 func (r *CheckConfigurationRead) ShutdownNow() {
 	close(r.Shutdown)
@@ -0,0 +1,137 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package soma
+
+import (
+	"database/sql"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/mjolnir42/soma/internal/handler"
+	"github.com/mjolnir42/soma/internal/msg"
+	"github.com/mjolnir42/soma/internal/stmt"
+)
+
+// CheckConfigurationWrite handles write requests for check
+// configurations that do not modify the repository tree
+type CheckConfigurationWrite struct {
+	Input       chan msg.Request
+	Shutdown    chan struct{}
+	handlerName string
+	conn        *sql.DB
+	stmtResume  *sql.Stmt
+	appLog      *logrus.Logger
+	reqLog      *logrus.Logger
+	errLog      *logrus.Logger
+}
+
+// newCheckConfigurationWrite returns a new CheckConfigurationWrite
+// handler with input buffer of length
+func newCheckConfigurationWrite(length int) (string, *CheckConfigurationWrite) {
+	w := &CheckConfigurationWrite{}
+	w.handlerName = generateHandlerName() + `_w`
+	w.Input = make(chan msg.Request, length)
+	w.Shutdown = make(chan struct{})
+	return w.handlerName, w
+}
+
+// Register initializes resources provided by the Soma app
+func (w *CheckConfigurationWrite) Register(c *sql.DB, l ...*logrus.Logger) {
+	w.conn = c
+	w.appLog = l[0]
+	w.reqLog = l[1]
+	w.errLog = l[2]
+}
+
+// RegisterRequests links the handler inside the handlermap to the requests
+// it processes
+func (w *CheckConfigurationWrite) RegisterRequests(hmap *handler.Map) {
+	for _, action := range []string{
+		msg.ActionResume,
+	} {
+		hmap.Request(msg.SectionCheckConfig, action, w.handlerName)
+	}
+}
+
+// Intake exposes the Input channel as part of the handler interface
+func (w *CheckConfigurationWrite) Intake() chan msg.Request {
+	return w.Input
+}
+
+// PriorityIntake aliases Intake as part of the handler interface
+func (w *CheckConfigurationWrite) PriorityIntake() chan msg.Request {
+	return w.Intake()
+}
+
+// Run is the event loop for CheckConfigurationWrite
+func (w *CheckConfigurationWrite) Run() {
+	var err error
+
+	for statement, prepStmt := range map[string]**sql.Stmt{
+		stmt.RolloutResume: &w.stmtResume,
+	} {
+		if *prepStmt, err = w.conn.Prepare(statement); err != nil {
+			w.errLog.Fatal(`checkconfig_w`, err, stmt.Name(statement))
+		}
+		defer (*prepStmt).Close()
+	}
+
+runloop:
+	for {
+		select {
+		case <-w.Shutdown:
+			break runloop
+		case req := <-w.Input:
+			w.process(&req)
+		}
+	}
+}
+
+// process is the request dispatcher
+func (w *CheckConfigurationWrite) process(q *msg.Request) {
+	result := msg.FromRequest(q)
+	logRequest(w.reqLog, q)
+
+	switch q.Action {
+	case msg.ActionResume:
+		w.resume(q, &result)
+	default:
+		result.UnknownRequest(q)
+	}
+
+	q.Reply <- result
+}
+
+// resume continues the halted staged rollout of a check
+// configuration with the next wave
+func (w *CheckConfigurationWrite) resume(q *msg.Request, mr *msg.Result) {
+	var (
+		err error
+		res sql.Result
+	)
+
+	if res, err = w.stmtResume.Exec(
+		q.CheckConfig.ID,
+		q.CheckConfig.RepositoryID,
+	); err != nil {
+		mr.ServerError(err, q.Section)
+		return
+	}
+	if mr.RowCnt(res.RowsAffected()) {
+		mr.CheckConfig = append(mr.CheckConfig, q.CheckConfig)
+		mr.OK()
+	}
+}
+
+// ShutdownNow signals the handler to shut down
+func (w *CheckConfigurationWrite) ShutdownNow() {
+	close(w.Shutdown)
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
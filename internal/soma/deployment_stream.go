@@ -0,0 +1,241 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package soma
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/mjolnir42/soma/internal/handler"
+	"github.com/mjolnir42/soma/internal/msg"
+	"github.com/mjolnir42/soma/internal/stmt"
+	"github.com/mjolnir42/soma/lib/proto"
+	uuid "github.com/satori/go.uuid"
+)
+
+// DeploymentStream handles requests to subscribe to the state
+// transitions of check instance configurations. Transitions are
+// published by TreeKeeper, LifeCycle and DeploymentWrite.
+type DeploymentStream struct {
+	Input       chan msg.Request
+	Shutdown    chan struct{}
+	Notify      chan deploymentTransition
+	conn        *sql.DB
+	stmtDetails *sql.Stmt
+	subscribers []streamSpec
+	appLog      *logrus.Logger
+	reqLog      *logrus.Logger
+	errLog      *logrus.Logger
+}
+
+// deploymentTransition is a state transition of a check instance
+// configuration as published by the handlers that perform it
+type deploymentTransition struct {
+	InstanceConfigID string
+	Status           string
+	NextStatus       string
+	Source           string
+	At               time.Time
+}
+
+// streamSpec identifies a client that subscribed to the stream
+type streamSpec struct {
+	Filter proto.DeploymentEventFilter
+	Reply  chan msg.Result
+	Done   <-chan struct{}
+}
+
+// newDeploymentStream returns a new DeploymentStream handler with
+// input and notify buffers of length
+func newDeploymentStream(length int) (d *DeploymentStream) {
+	d = &DeploymentStream{}
+	d.Input = make(chan msg.Request, length)
+	d.Notify = make(chan deploymentTransition, length)
+	d.Shutdown = make(chan struct{})
+	d.subscribers = []streamSpec{}
+	return
+}
+
+// Register initializes resources provided by the Soma app
+func (d *DeploymentStream) Register(c *sql.DB, l ...*logrus.Logger) {
+	d.conn = c
+	d.appLog = l[0]
+	d.reqLog = l[1]
+	d.errLog = l[2]
+}
+
+// RegisterRequests links the handler inside the handlermap to the requests
+// it processes
+func (d *DeploymentStream) RegisterRequests(hmap *handler.Map) {
+	hmap.Request(msg.SectionDeployment, msg.ActionStream, `deployment_stream`)
+}
+
+// Intake exposes the Input channel as part of the handler interface
+func (d *DeploymentStream) Intake() chan msg.Request {
+	return d.Input
+}
+
+// PriorityIntake aliases Intake as part of the handler interface
+func (d *DeploymentStream) PriorityIntake() chan msg.Request {
+	return d.Intake()
+}
+
+// Run is the event loop for DeploymentStream
+func (d *DeploymentStream) Run() {
+	var err error
+
+	if d.stmtDetails, err = d.conn.Prepare(
+		stmt.DeploymentEventDetails,
+	); err != nil {
+		d.errLog.Fatal(`deployment_stream`, err,
+			stmt.Name(stmt.DeploymentEventDetails))
+	}
+	defer d.stmtDetails.Close()
+
+	tock := time.NewTicker(30 * time.Second)
+	defer tock.Stop()
+
+runloop:
+	for {
+		select {
+		case <-d.Shutdown:
+			// disconnect all subscribed clients
+			for i := range d.subscribers {
+				close(d.subscribers[i].Reply)
+			}
+			d.subscribers = nil
+			break runloop
+		case t := <-d.Notify:
+			d.publish(t)
+		case rq := <-d.Input:
+			logRequest(d.reqLog, &rq)
+			d.subscribers = append(d.subscribers, streamSpec{
+				Filter: rq.Search.DeploymentEvent,
+				Reply:  rq.Reply,
+				Done:   rq.Done,
+			})
+		case <-tock.C:
+			// remove subscriptions of clients that have disconnected
+			d.prune(func(s *streamSpec) bool {
+				select {
+				case <-s.Done:
+					return false
+				default:
+				}
+				return true
+			})
+		}
+	}
+}
+
+// publish sends the event for transition t to all subscribed clients
+// whose filter it matches
+func (d *DeploymentStream) publish(t deploymentTransition) {
+	var (
+		err     error
+		version int64
+	)
+
+	if len(d.subscribers) == 0 {
+		return
+	}
+
+	ev := proto.DeploymentEvent{
+		ID:               uuid.Must(uuid.NewV4()).String(),
+		InstanceConfigID: t.InstanceConfigID,
+		Status:           t.Status,
+		NextStatus:       t.NextStatus,
+		Source:           t.Source,
+		Timestamp:        t.At.Format(msg.RFC3339Milli),
+	}
+	if err = d.stmtDetails.QueryRow(
+		t.InstanceConfigID,
+	).Scan(
+		&ev.CheckInstanceID,
+		&version,
+		&ev.MonitoringID,
+		&ev.RepositoryID,
+	); err != nil {
+		// the configuration can already have been deleted
+		if err != sql.ErrNoRows {
+			d.errLog.Println(`DeploymentStream.publish()`, err)
+		}
+		return
+	}
+	ev.Version = uint64(version)
+
+	result := msg.Result{
+		Section:         msg.SectionDeployment,
+		Action:          msg.ActionStream,
+		DeploymentEvent: []proto.DeploymentEvent{ev},
+	}
+	result.OK()
+
+	d.prune(func(s *streamSpec) bool {
+		select {
+		case <-s.Done:
+			return false
+		default:
+		}
+		if !s.Filter.Match(&ev) {
+			return true
+		}
+		select {
+		case s.Reply <- result:
+			return true
+		default:
+			// the client does not keep up with the stream
+			return false
+		}
+	})
+}
+
+// prune removes all subscribers for which keep returns false and
+// disconnects them
+func (d *DeploymentStream) prune(keep func(*streamSpec) bool) {
+	kept := d.subscribers[:0]
+	for i := range d.subscribers {
+		if keep(&d.subscribers[i]) {
+			kept = append(kept, d.subscribers[i])
+			continue
+		}
+		close(d.subscribers[i].Reply)
+	}
+	d.subscribers = kept
+}
+
+// ShutdownNow signals the handler to shutdown
+func (d *DeploymentStream) ShutdownNow() {
+	close(d.Shutdown)
+}
+
+// publishTransition notifies the DeploymentStream handler about a
+// state transition of a check instance configuration. Notifications
+// are dropped if the handler is not running or can not keep up,
+// they must never block the handler performing the transition.
+func publishTransition(s *Soma, source, instanceConfigID, status, nextStatus string) {
+	h, ok := s.handlerMap.Get(`deployment_stream`).(*DeploymentStream)
+	if !ok || h == nil {
+		return
+	}
+	select {
+	case h.Notify <- deploymentTransition{
+		InstanceConfigID: instanceConfigID,
+		Status:           status,
+		NextStatus:       nextStatus,
+		Source:           source,
+		At:               time.Now().UTC(),
+	}:
+	default:
+	}
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -33,9 +33,15 @@ type DeploymentWrite struct {
 	stmtActivate             *sql.Stmt
 	stmtList                 *sql.Stmt
 	stmtAll                  *sql.Stmt
+	stmtPage                 *sql.Stmt
 	stmtClearFlag            *sql.Stmt
 	stmtDeprovision          *sql.Stmt
 	stmtDeprovisionForUpdate *sql.Stmt
+	stmtSecret               *sql.Stmt
+	stmtSecretByID           *sql.Stmt
+	stmtRollbackPolicy       *sql.Stmt
+	stmtRollbackTarget       *sql.Stmt
+	soma                     *Soma
 	appLog                   *logrus.Logger
 	reqLog                   *logrus.Logger
 	errLog                   *logrus.Logger
@@ -43,11 +49,12 @@ type DeploymentWrite struct {
 
 // newDeploymentWrite return a new DeploymentWrite handler with
 // input buffer of length
-func newDeploymentWrite(length int) (string, *DeploymentWrite) {
+func newDeploymentWrite(length int, s *Soma) (string, *DeploymentWrite) {
 	w := &DeploymentWrite{}
 	w.handlerName = generateHandlerName() + `_w`
 	w.Input = make(chan msg.Request, length)
 	w.Shutdown = make(chan struct{})
+	w.soma = s
 	return w.handlerName, w
 }
 
@@ -88,15 +95,20 @@ func (w *DeploymentWrite) Run() {
 	var err error
 
 	for statement, prepStmt := range map[string]**sql.Stmt{
-		stmt.DeploymentGet:              &w.stmtGet,
-		stmt.DeploymentUpdate:           &w.stmtSetStatusUpdate,
-		stmt.DeploymentStatus:           &w.stmtGetStatus,
-		stmt.DeploymentActivate:         &w.stmtActivate,
-		stmt.DeploymentList:             &w.stmtList,
-		stmt.DeploymentListAll:          &w.stmtAll,
-		stmt.DeploymentClearFlag:        &w.stmtClearFlag,
-		stmt.DeploymentDeprovision:      &w.stmtDeprovision,
-		stmt.DeploymentDeprovisionStyle: &w.stmtDeprovisionForUpdate,
+		stmt.DeploymentGet:                  &w.stmtGet,
+		stmt.DeploymentUpdate:               &w.stmtSetStatusUpdate,
+		stmt.DeploymentStatus:               &w.stmtGetStatus,
+		stmt.DeploymentActivate:             &w.stmtActivate,
+		stmt.DeploymentList:                 &w.stmtList,
+		stmt.DeploymentListAll:              &w.stmtAll,
+		stmt.DeploymentListPage:             &w.stmtPage,
+		stmt.DeploymentClearFlag:            &w.stmtClearFlag,
+		stmt.DeploymentDeprovision:          &w.stmtDeprovision,
+		stmt.DeploymentDeprovisionStyle:     &w.stmtDeprovisionForUpdate,
+		stmt.DeploymentMonitoringSecret:     &w.stmtSecret,
+		stmt.DeploymentMonitoringSecretByID: &w.stmtSecretByID,
+		stmt.RollbackPolicyApplies:          &w.stmtRollbackPolicy,
+		stmt.RollbackTarget:                 &w.stmtRollbackTarget,
 	} {
 		if *prepStmt, err = w.conn.Prepare(statement); err != nil {
 			w.errLog.Fatal(`deployment`, err, stmt.Name(statement))
@@ -120,6 +132,11 @@ func (w *DeploymentWrite) process(q *msg.Request) {
 	result := msg.FromRequest(q)
 	logRequest(w.reqLog, q)
 
+	if !w.authenticate(q, &result) {
+		q.Reply <- result
+		return
+	}
+
 	switch q.Action {
 	case msg.ActionShow:
 		w.show(q, &result)
@@ -242,6 +259,8 @@ func (w *DeploymentWrite) show(q *msg.Request, mr *msg.Result) {
 		}
 		if mr.RowCnt(res.RowsAffected()) {
 			mr.Deployment = append(mr.Deployment, depl)
+			publishTransition(w.soma, proto.EventSourceDeployment,
+				instanceConfigID, newCurrentStatus, newNextStatus)
 		}
 	} else {
 		mr.Deployment = append(mr.Deployment, depl)
@@ -318,13 +337,18 @@ func (w *DeploymentWrite) success(q *msg.Request, mr *msg.Result) {
 			ID:   q.Deployment.ID,
 			Task: task,
 		})
+		publishTransition(w.soma, proto.EventSourceDeployment,
+			instanceConfigID, next, proto.DeploymentNone)
 	}
 }
 
-// failed marks a rollout as failed
+// failed marks a rollout as failed. If an automatic rollback policy
+// applies to the check instance, the last active version is queued
+// for rollout again.
 func (w *DeploymentWrite) failed(q *msg.Request, mr *msg.Result) {
 	var (
 		instanceConfigID, status, next, task string
+		newStatus                            string
 		err                                  error
 		res                                  sql.Result
 	)
@@ -345,8 +369,9 @@ func (w *DeploymentWrite) failed(q *msg.Request, mr *msg.Result) {
 
 	switch status {
 	case proto.DeploymentRolloutInProgress:
+		newStatus = proto.DeploymentRolloutFailed
 		if res, err = w.stmtSetStatusUpdate.Exec(
-			proto.DeploymentRolloutFailed,
+			newStatus,
 			proto.DeploymentNone,
 			instanceConfigID,
 		); err != nil {
@@ -362,8 +387,9 @@ func (w *DeploymentWrite) failed(q *msg.Request, mr *msg.Result) {
 			return
 		}
 
+		newStatus = proto.DeploymentDeprovisionFailed
 		if res, err = w.stmtSetStatusUpdate.Exec(
-			proto.DeploymentDeprovisionFailed,
+			newStatus,
 			proto.DeploymentNone,
 			instanceConfigID,
 		); err != nil {
@@ -387,6 +413,11 @@ func (w *DeploymentWrite) failed(q *msg.Request, mr *msg.Result) {
 			ID:   q.Deployment.ID,
 			Task: task,
 		})
+		publishTransition(w.soma, proto.EventSourceDeployment,
+			instanceConfigID, newStatus, proto.DeploymentNone)
+		if status == proto.DeploymentRolloutInProgress {
+			w.rollback(q.Deployment.ID, instanceConfigID)
+		}
 	}
 }
 
@@ -437,6 +468,11 @@ func (w *DeploymentWrite) list(q *msg.Request, mr *msg.Result) {
 		all        *sql.Rows
 	)
 
+	if q.Search.Deployment.IncludeActive || q.Search.Deployment.Limit > 0 {
+		w.listPage(q, mr)
+		return
+	}
+
 	if all, err = w.stmtAll.Query(
 		q.Monitoring.ID,
 	); err != nil {
@@ -467,6 +503,55 @@ func (w *DeploymentWrite) list(q *msg.Request, mr *msg.Result) {
 	mr.OK()
 }
 
+// listPage returns one page of the deployment IDs for a monitoring
+// system together with their current state. Update flags are left
+// untouched, the page is read by resynchronisations and not in
+// response to a notification.
+func (w *DeploymentWrite) listPage(q *msg.Request, mr *msg.Result) {
+	var (
+		instanceID, state string
+		err               error
+		limit             sql.NullInt64
+		page              *sql.Rows
+	)
+
+	if q.Search.Deployment.Limit > 0 {
+		limit.Int64 = int64(q.Search.Deployment.Limit)
+		limit.Valid = true
+	}
+
+	if page, err = w.stmtPage.Query(
+		q.Monitoring.ID,
+		q.Search.Deployment.IncludeActive,
+		limit,
+		int64(q.Search.Deployment.Offset),
+	); err != nil {
+		mr.ServerError(err, q.Section)
+		return
+	}
+	defer page.Close()
+
+	for page.Next() {
+		if err = page.Scan(
+			&instanceID,
+			&state,
+		); err != nil {
+			mr.ServerError(err, q.Section)
+			return
+		}
+
+		mr.Deployment = append(mr.Deployment, proto.Deployment{
+			ID:    instanceID,
+			State: state,
+		})
+	}
+	if err = page.Err(); err != nil {
+		mr.ServerError(err, q.Section)
+		return
+	}
+	mr.OK()
+}
+
 // ShutdownNow signals the handler to shut down
 func (w *DeploymentWrite) ShutdownNow() {
 	close(w.Shutdown)
@@ -0,0 +1,72 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package soma
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/mjolnir42/soma/internal/msg"
+)
+
+// authenticate verifies that request q was sent by the monitoring
+// system the requested deployment belongs to. If it returns false,
+// the reason has been set in mr.
+func (w *DeploymentWrite) authenticate(q *msg.Request, mr *msg.Result) bool {
+	var (
+		err          error
+		monitoringID string
+		secret       sql.NullString
+	)
+
+	switch q.Action {
+	case msg.ActionShow, msg.ActionSuccess, msg.ActionFailed:
+		err = w.stmtSecret.QueryRow(
+			q.Deployment.ID,
+		).Scan(
+			&monitoringID,
+			&secret,
+		)
+	default:
+		err = w.stmtSecretByID.QueryRow(
+			q.Monitoring.ID,
+		).Scan(
+			&monitoringID,
+			&secret,
+		)
+	}
+	if err == sql.ErrNoRows {
+		mr.NotFound(err, q.Section)
+		return false
+	} else if err != nil {
+		mr.ServerError(err, q.Section)
+		return false
+	}
+
+	// deployments requested via the monitoring system specific
+	// routes must belong to that monitoring system
+	if q.Monitoring.ID != `` && q.Monitoring.ID != monitoringID {
+		mr.NotFound(fmt.Errorf(
+			"Deployment %s does not belong to monitoring system %s",
+			q.Deployment.ID, q.Monitoring.ID,
+		), q.Section)
+		return false
+	}
+
+	if !authenticateMonitoring(&q.MonAuth, secret) {
+		mr.Unauthorized(fmt.Errorf(
+			`Invalid or missing monitoring system authentication`,
+		), q.Section)
+		return false
+	}
+	return true
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -0,0 +1,141 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package soma
+
+import (
+	"database/sql"
+
+	"github.com/mjolnir42/soma/internal/stmt"
+	"github.com/mjolnir42/soma/lib/proto"
+	uuid "github.com/satori/go.uuid"
+)
+
+// rollback restores the last active version of check instance
+// instanceID after the rollout of failedID has failed, if an
+// automatic rollback policy applies to the check instance. A version
+// that has already been deprovisioned, or is being deprovisioned, is
+// queued for rollout again. A version that is still deployed is made
+// current again and a pending deprovisioning is cancelled. The
+// rollback is recorded in the version history of the check instance.
+// Errors are logged, the failed rollout has already been recorded
+// and remains valid.
+func (w *DeploymentWrite) rollback(instanceID, failedID string) {
+	var (
+		err                error
+		enabled            bool
+		restoredID, status string
+		restored, next     string
+		update             bool
+		version            int64
+		tx                 *sql.Tx
+	)
+
+	if err = w.stmtRollbackPolicy.QueryRow(
+		instanceID,
+	).Scan(
+		&enabled,
+	); err != nil {
+		w.errLog.Println(`DeploymentWrite.rollback()`, err)
+		return
+	}
+	if !enabled {
+		return
+	}
+
+	if err = w.stmtRollbackTarget.QueryRow(
+		instanceID,
+		failedID,
+	).Scan(
+		&restoredID,
+		&version,
+		&status,
+	); err == sql.ErrNoRows {
+		// the failed version is the first version of this check
+		// instance, there is nothing to roll back to
+		return
+	} else if err != nil {
+		w.errLog.Println(`DeploymentWrite.rollback()`, err)
+		return
+	}
+
+	switch status {
+	case proto.DeploymentDeprovisioned,
+		proto.DeploymentDeprovisionInProgress:
+		// the monitoring system has removed or is removing the
+		// version, it has to be rolled out again
+		restored = proto.DeploymentAwaitingRollout
+		next = proto.DeploymentRolloutInProgress
+		update = true
+	case proto.DeploymentActive,
+		proto.DeploymentAwaitingDeprovision:
+		// the version is still deployed, any pending
+		// deprovisioning is cancelled
+		restored = proto.DeploymentActive
+		next = proto.DeploymentNone
+		update = false
+	default:
+		w.appLog.Printf("DeploymentWrite: not rolling back check"+
+			" instance %s to version %d in status %s",
+			instanceID, version, status)
+		return
+	}
+
+	// open multi-statement transaction. this ensures the restored
+	// version is only rolled out if the rollback was recorded
+	if tx, err = w.conn.Begin(); err != nil {
+		w.errLog.Println(`DeploymentWrite.rollback()`, err)
+		return
+	}
+
+	if _, err = tx.Exec(
+		stmt.LifecycleUpdateConfig,
+		restored,
+		next,
+		false,
+		restoredID,
+	); err != nil {
+		goto bailout
+	}
+
+	if _, err = tx.Exec(
+		stmt.LifecycleUpdateInstance,
+		update,
+		restoredID,
+		instanceID,
+	); err != nil {
+		goto bailout
+	}
+
+	if _, err = tx.Exec(
+		stmt.RollbackRecord,
+		uuid.Must(uuid.NewV4()).String(),
+		instanceID,
+		failedID,
+		restoredID,
+	); err != nil {
+		goto bailout
+	}
+
+	if err = tx.Commit(); err != nil {
+		goto bailout
+	}
+	publishTransition(w.soma, proto.EventSourceDeployment, restoredID,
+		restored, next)
+	w.appLog.Printf("DeploymentWrite: rolled back check instance %s"+
+		" to version %d after failed rollout of %s",
+		instanceID, version, failedID)
+	return
+
+bailout:
+	w.errLog.Println(`DeploymentWrite.rollback()`, err)
+	tx.Rollback()
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
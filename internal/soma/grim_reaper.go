@@ -178,6 +178,8 @@ func (grim *GrimReaper) process(q *msg.Request) bool {
 		`guidepost`,
 		`lifecycle`,
 		`deployment`,
+		`deployment_stream`,
+		`metrics_collector`,
 	} {
 		grim.soma.handlerMap.Get(h).ShutdownNow()
 		grim.soma.handlerMap.Del(h)
@@ -36,9 +36,11 @@ type GuidePost struct {
 	stmtServiceAttributes     *sql.Stmt
 	stmtCapabilityThresholds  *sql.Stmt
 	stmtCheckDetailsForDelete *sql.Stmt
+	stmtCheckConfigCapability *sql.Stmt
 	stmtBucketForNodeID       *sql.Stmt
 	stmtBucketForClusterID    *sql.Stmt
 	stmtBucketForGroupID      *sql.Stmt
+	stmtServerNameByID        *sql.Stmt
 	appLog                    *logrus.Logger
 	reqLog                    *logrus.Logger
 	errLog                    *logrus.Logger
@@ -106,6 +108,9 @@ func (g *GuidePost) RegisterRequests(hmap *handler.Map) {
 		{Section: msg.SectionCluster, Action: msg.ActionMemberUnassign},
 		{Section: msg.SectionCheckConfig, Action: msg.ActionCreate},
 		{Section: msg.SectionCheckConfig, Action: msg.ActionDestroy},
+		{Section: msg.SectionCheckConfig, Action: msg.ActionOverrideRemove},
+		{Section: msg.SectionCheckConfig, Action: msg.ActionOverrideSet},
+		{Section: msg.SectionCheckConfig, Action: msg.ActionUpdate},
 	} {
 		hmap.Request(request.Section, request.Action, `guidepost`)
 	}
@@ -135,9 +140,11 @@ func (g *GuidePost) Run() {
 		stmt.ServiceAttributes:     &g.stmtServiceAttributes,
 		stmt.CapabilityThresholds:  &g.stmtCapabilityThresholds,
 		stmt.CheckDetailsForDelete: &g.stmtCheckDetailsForDelete,
+		stmt.CheckConfigCapability: &g.stmtCheckConfigCapability,
 		stmt.NodeBucketID:          &g.stmtBucketForNodeID,
 		stmt.ClusterBucketID:       &g.stmtBucketForClusterID,
 		stmt.GroupBucketID:         &g.stmtBucketForGroupID,
+		stmt.ServerNameByID:        &g.stmtServerNameByID,
 	} {
 		if *prepStmt, err = g.conn.Prepare(statement); err != nil {
 			g.errLog.Fatal(`guidepost`, err, stmt.Name(statement))
@@ -209,6 +216,13 @@ func (g *GuidePost) process(q *msg.Request) {
 	keeper = fmt.Sprintf("repository_%s", repoName)
 	handler = g.soma.handlerMap.Get(keeper).(*TreeKeeper)
 
+	// dry run requests are not stored as job, the TreeKeeper replies
+	// directly with the computed tree actions
+	if q.Flag.DryRun {
+		handler.Input <- *q
+		return
+	}
+
 	// store job in database
 	q.JobID = uuid.Must(uuid.NewV4())
 	g.appLog.Infof("Saving job %s (%s::%s) for %s",
@@ -261,7 +275,7 @@ func (g *GuidePost) process(q *msg.Request) {
 	case msg.SectionCluster:
 		result.Cluster = append(result.Cluster,
 			q.Cluster)
-	case msg.SectionNodeConfig:
+	case msg.SectionNodeConfig, msg.SectionNodeMgmt:
 		result.Node = append(result.Node,
 			q.Node)
 	case msg.SectionCheckConfig:
@@ -78,10 +78,20 @@ func (g *GuidePost) extractID(q *msg.Request) (string, string) {
 			return ``, ``
 		}
 		return q.Node.Config.RepositoryID, q.Node.Config.BucketID
+	case msg.SectionNodeMgmt:
+		// node updates are forwarded by NodeWrite for assigned
+		// nodes only
+		if q.Action != msg.ActionUpdate || q.Node.Config == nil {
+			return ``, ``
+		}
+		return ``, q.Node.Config.BucketID
 	case msg.SectionCheckConfig:
 		switch q.Action {
 		case msg.ActionCreate:
 		case msg.ActionDestroy:
+		case msg.ActionOverrideRemove:
+		case msg.ActionOverrideSet:
+		case msg.ActionUpdate:
 		default:
 			return ``, ``
 		}
@@ -25,8 +25,12 @@ func (g *GuidePost) fillReqData(q *msg.Request) (bool, error) {
 		return g.fillServiceAttributes(q)
 	case q.Section == msg.SectionNodeConfig && q.Action == msg.ActionAssign:
 		return g.fillNode(q)
+	case q.Section == msg.SectionNodeMgmt && q.Action == msg.ActionUpdate:
+		return g.fillNodeServer(q)
 	case q.Section == msg.SectionCheckConfig && q.Action == msg.ActionDestroy:
 		return g.fillCheckDeleteInfo(q)
+	case q.Section == msg.SectionCheckConfig && q.Action == msg.ActionUpdate:
+		return g.fillCheckDeleteInfo(q)
 	case q.Section == msg.SectionBucket && q.Action == msg.ActionCreate:
 		return g.fillBucketID(q)
 	case q.Section == msg.SectionGroup && q.Action == msg.ActionCreate:
@@ -37,6 +41,8 @@ func (g *GuidePost) fillReqData(q *msg.Request) (bool, error) {
 		return g.fillPropertyDeleteInfo(q)
 	case q.Section == msg.SectionCheckConfig && q.Action == msg.ActionCreate:
 		return g.fillCheckConfigID(q)
+	case q.Section == msg.SectionCheckConfig && q.Action == msg.ActionOverrideSet:
+		return g.fillThresholdOverrideID(q)
 	default:
 		return false, nil
 	}
@@ -48,6 +54,12 @@ func (g *GuidePost) fillCheckConfigID(q *msg.Request) (bool, error) {
 	return false, nil
 }
 
+// generate the ID of a threshold override
+func (g *GuidePost) fillThresholdOverrideID(q *msg.Request) (bool, error) {
+	q.CheckConfig.ThresholdOverrides[0].ID = uuid.Must(uuid.NewV4()).String()
+	return false, nil
+}
+
 // generate BucketID
 func (g *GuidePost) fillBucketID(q *msg.Request) (bool, error) {
 	q.Bucket.ID = uuid.Must(uuid.NewV4()).String()
@@ -70,10 +82,10 @@ func (g *GuidePost) fillClusterID(q *msg.Request) (bool, error) {
 // submitted values.
 func (g *GuidePost) fillNode(q *msg.Request) (bool, error) {
 	var (
-		err                      error
-		ndName, ndTeam, ndServer string
-		ndAsset                  int64
-		ndOnline, ndDeleted      bool
+		err                                 error
+		ndName, ndTeam, ndServer, ndSrvName string
+		ndAsset                             int64
+		ndOnline, ndDeleted                 bool
 	)
 	if err = g.stmtNodeDetails.QueryRow(q.Node.ID).Scan(
 		&ndAsset,
@@ -82,6 +94,7 @@ func (g *GuidePost) fillNode(q *msg.Request) (bool, error) {
 		&ndServer,
 		&ndOnline,
 		&ndDeleted,
+		&ndSrvName,
 	); err != nil {
 		if err == sql.ErrNoRows {
 			return true, fmt.Errorf("Node not found: %s", q.Node.ID)
@@ -92,11 +105,29 @@ func (g *GuidePost) fillNode(q *msg.Request) (bool, error) {
 	q.Node.Name = ndName
 	q.Node.TeamID = ndTeam
 	q.Node.ServerID = ndServer
+	q.Node.ServerName = ndSrvName
 	q.Node.IsOnline = ndOnline
 	q.Node.IsDeleted = ndDeleted
 	return false, nil
 }
 
+// Populate the name of the server a node is moved to, or of the
+// server that was renamed
+func (g *GuidePost) fillNodeServer(q *msg.Request) (bool, error) {
+	if err := g.stmtServerNameByID.QueryRow(
+		q.Update.Node.ServerID,
+	).Scan(
+		&q.Update.Node.ServerName,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return true, fmt.Errorf("Server not found: %s",
+				q.Update.Node.ServerID)
+		}
+		return false, err
+	}
+	return false, nil
+}
+
 // load authoritative copy of the service attributes from the
 // database. Replaces whatever the client sent in.
 func (g *GuidePost) fillServiceAttributes(q *msg.Request) (bool, error) {
@@ -189,7 +220,7 @@ abort:
 	return false, nil
 }
 
-// if the request is a check deletion, populate required IDs
+// if the request is a check deletion or update, populate required IDs
 func (g *GuidePost) fillCheckDeleteInfo(q *msg.Request) (bool, error) {
 	var delObjID, delObjTyp, delSrcChkID string
 	var err error
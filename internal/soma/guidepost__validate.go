@@ -13,6 +13,8 @@ import (
 	"strings"
 
 	"github.com/mjolnir42/soma/internal/msg"
+	"github.com/mjolnir42/soma/lib/proto"
+	"github.com/mjolnir42/soma/lib/threshold"
 )
 
 func (g *GuidePost) validateRequest(q *msg.Request) (bool, error) {
@@ -23,6 +25,14 @@ func (g *GuidePost) validateRequest(q *msg.Request) (bool, error) {
 			if nf, err := g.validateCheckObjectInBucket(q); err != nil {
 				return nf, err
 			}
+		case msg.ActionOverrideRemove, msg.ActionOverrideSet:
+			if nf, err := g.validateOverrideObject(q); err != nil {
+				return nf, err
+			}
+		case msg.ActionUpdate:
+			if nf, err := g.validateCheckUpdate(q); err != nil {
+				return nf, err
+			}
 		}
 	case msg.SectionNodeConfig:
 		if nf, err := g.validateNodeConfig(q); err != nil {
@@ -47,6 +57,8 @@ func (g *GuidePost) validateRequest(q *msg.Request) (bool, error) {
 		// since repository ids are the routing information,
 		// it is unnecessary to check that the object is where the
 		// routing would point to
+	case msg.SectionNodeMgmt:
+		// routed by the bucket the node is assigned to
 	default:
 		return false, fmt.Errorf("Invalid request type %s", q.Section)
 	}
@@ -62,6 +74,13 @@ func (g *GuidePost) validateRequest(q *msg.Request) (bool, error) {
 	case msg.SectionCheckConfig:
 		switch q.Action {
 		case msg.ActionCreate:
+			if nf, err := g.validateCheckRollout(q); err != nil {
+				return nf, err
+			}
+			return g.validateCheckThresholds(q)
+		case msg.ActionOverrideSet:
+			return g.validateOverrideThresholds(q)
+		case msg.ActionUpdate:
 			return g.validateCheckThresholds(q)
 		}
 	case msg.SectionBucket:
@@ -109,11 +128,21 @@ func (g *GuidePost) validateRequest(q *msg.Request) (bool, error) {
 		case msg.SectionRepository:
 			return false, nil
 		}
+	case msg.ActionOverrideRemove:
+		switch q.Section {
+		case msg.SectionCheckConfig:
+			return false, nil
+		}
 	case msg.ActionRepossess:
 		switch q.Section {
 		case msg.SectionRepository:
 			return false, nil
 		}
+	case msg.ActionUpdate:
+		switch q.Section {
+		case msg.SectionNodeMgmt:
+			return false, nil
+		}
 	}
 	return false, fmt.Errorf("Unimplemented guidepost/%s::%s", q.Section, q.Action)
 }
@@ -386,6 +415,97 @@ func (g *GuidePost) validateCheckObjectInBucket(q *msg.Request) (bool, error) {
 	)
 }
 
+// Verify that the check configuration of a threshold override is
+// active within the repository and that the overridden object is
+// part of the same tree.
+func (g *GuidePost) validateOverrideObject(q *msg.Request) (bool, error) {
+	var err error
+	var bid, capabilityID string
+
+	if len(q.CheckConfig.ThresholdOverrides) != 1 {
+		return false, fmt.Errorf("Expected exactly one threshold"+
+			" override, got %d", len(q.CheckConfig.ThresholdOverrides))
+	}
+	if err = g.stmtCheckConfigCapability.QueryRow(
+		q.CheckConfig.ID,
+		q.CheckConfig.RepositoryID,
+	).Scan(
+		&capabilityID,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return true, fmt.Errorf("Check configuration %s not found",
+				q.CheckConfig.ID)
+		}
+		return false, err
+	}
+	q.CheckConfig.CapabilityID = capabilityID
+
+	override := q.CheckConfig.ThresholdOverrides[0]
+	switch override.ObjectType {
+	case msg.EntityRepository:
+		if q.CheckConfig.RepositoryID != override.ObjectID {
+			return false, fmt.Errorf("Conflicting repository ids: %s, %s",
+				q.CheckConfig.RepositoryID,
+				override.ObjectID,
+			)
+		}
+		return false, nil
+	case msg.EntityBucket:
+		bid = override.ObjectID
+	case msg.EntityGroup:
+		err = g.stmtBucketForGroupID.QueryRow(
+			override.ObjectID,
+		).Scan(&bid)
+	case msg.EntityCluster:
+		err = g.stmtBucketForClusterID.QueryRow(
+			override.ObjectID,
+		).Scan(&bid)
+	case msg.EntityNode:
+		err = g.stmtBucketForNodeID.QueryRow(
+			override.ObjectID,
+		).Scan(&bid)
+	default:
+		return false, fmt.Errorf("Unknown object type: %s",
+			override.ObjectType,
+		)
+	}
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return true, fmt.Errorf("No bucketID for object found")
+		}
+		return false, err
+	}
+	return g.validateBucketInRepository(
+		q.CheckConfig.RepositoryID,
+		bid,
+	)
+}
+
+// Verify that the updated check configuration is active within the
+// repository and that the update has a valid check interval
+func (g *GuidePost) validateCheckUpdate(q *msg.Request) (bool, error) {
+	var capabilityID string
+
+	if err := g.stmtCheckConfigCapability.QueryRow(
+		q.CheckConfig.ID,
+		q.CheckConfig.RepositoryID,
+	).Scan(
+		&capabilityID,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return true, fmt.Errorf("Check configuration %s not found",
+				q.CheckConfig.ID)
+		}
+		return false, err
+	}
+	q.CheckConfig.CapabilityID = capabilityID
+
+	if q.CheckConfig.Interval == 0 {
+		return false, fmt.Errorf("Check interval must not be zero")
+	}
+	return false, nil
+}
+
 // Verify that the bucket is part of the specified repository
 func (g *GuidePost) validateBucketInRepository(
 	repo, bucket string) (bool, error) {
@@ -438,6 +558,73 @@ func (g *GuidePost) validateCheckThresholds(q *msg.Request) (bool, error) {
 		return false, fmt.Errorf("no thresholds for check defined")
 	}
 
+	return false, validateThresholdTemplates(q.CheckConfig.Thresholds)
+}
+
+// check the threshold override to contain fewer thresholds than
+// the limit for the capability of the overridden check configuration
+func (g *GuidePost) validateOverrideThresholds(q *msg.Request) (bool, error) {
+	var thrLimit int
+
+	if err := g.stmtCapabilityThresholds.QueryRow(
+		q.CheckConfig.CapabilityID,
+	).Scan(
+		&thrLimit,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return true, fmt.Errorf(
+				"Capability %s not found",
+				q.CheckConfig.CapabilityID)
+		}
+		return false, err
+	}
+	thresholds := q.CheckConfig.ThresholdOverrides[0].Thresholds
+	if len(thresholds) > thrLimit {
+		return false, fmt.Errorf(
+			"Specified %d thresholds exceed limit of %d for capability",
+			len(thresholds),
+			thrLimit)
+	} else if len(thresholds) == 0 {
+		return false, fmt.Errorf("no thresholds for override defined")
+	}
+
+	return false, validateThresholdTemplates(thresholds)
+}
+
+// check the value templates of thresholds to be well-formed
+func validateThresholdTemplates(thresholds []proto.CheckConfigThreshold) error {
+	for _, thr := range thresholds {
+		if thr.ValueTemplate == `` {
+			continue
+		}
+		if _, err := threshold.ParseTemplate(thr.ValueTemplate); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// check the staged rollout policy of a check configuration
+func (g *GuidePost) validateCheckRollout(q *msg.Request) (bool, error) {
+	if q.CheckConfig.Rollout == nil {
+		return false, nil
+	}
+
+	switch {
+	case q.CheckConfig.Rollout.WaveSize == 0 &&
+		q.CheckConfig.Rollout.WavePercent == 0:
+		return false, fmt.Errorf("Rollout policy requires a wave size")
+	case q.CheckConfig.Rollout.WaveSize != 0 &&
+		q.CheckConfig.Rollout.WavePercent != 0:
+		return false, fmt.Errorf("Rollout policy wave size must be" +
+			" either a count or a percentage")
+	case q.CheckConfig.Rollout.WavePercent > 100:
+		return false, fmt.Errorf("Rollout policy wave percentage %d"+
+			" exceeds 100", q.CheckConfig.Rollout.WavePercent)
+	case q.CheckConfig.Rollout.FailureThreshold == 0:
+		return false, fmt.Errorf("Rollout policy requires a failure" +
+			" threshold of at least 1")
+	}
 	return false, nil
 }
 
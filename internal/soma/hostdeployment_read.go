@@ -10,6 +10,7 @@ package soma
 import (
 	"database/sql"
 	"encoding/json"
+	"fmt"
 	"strings"
 
 	"github.com/Sirupsen/logrus"
@@ -28,6 +29,7 @@ type HostDeploymentRead struct {
 	conn                    *sql.DB
 	stmtInstancesForNode    *sql.Stmt
 	stmtLastInstanceVersion *sql.Stmt
+	stmtSecret              *sql.Stmt
 	appLog                  *logrus.Logger
 	reqLog                  *logrus.Logger
 	errLog                  *logrus.Logger
@@ -77,8 +79,9 @@ func (r *HostDeploymentRead) Run() {
 	var err error
 
 	for statement, prepStmt := range map[string]**sql.Stmt{
-		stmt.DeploymentInstancesForNode:    &r.stmtInstancesForNode,
-		stmt.DeploymentLastInstanceVersion: &r.stmtLastInstanceVersion,
+		stmt.DeploymentInstancesForNode:     &r.stmtInstancesForNode,
+		stmt.DeploymentLastInstanceVersion:  &r.stmtLastInstanceVersion,
+		stmt.DeploymentMonitoringSecretByID: &r.stmtSecret,
 	} {
 		if *prepStmt, err = r.conn.Prepare(statement); err != nil {
 			r.errLog.Fatal(`hostdeployment`, err, stmt.Name(statement))
@@ -104,6 +107,11 @@ func (r *HostDeploymentRead) process(q *msg.Request) {
 	result := msg.FromRequest(q)
 	logRequest(r.reqLog, q)
 
+	if !r.authenticate(q, &result) {
+		q.Reply <- result
+		return
+	}
+
 	switch q.Action {
 	case msg.ActionGet:
 		r.get(q, &result)
@@ -115,6 +123,37 @@ func (r *HostDeploymentRead) process(q *msg.Request) {
 	q.Reply <- result
 }
 
+// authenticate verifies that request q was sent by the monitoring
+// system it is inquiring for
+func (r *HostDeploymentRead) authenticate(q *msg.Request, mr *msg.Result) bool {
+	var (
+		err          error
+		monitoringID string
+		secret       sql.NullString
+	)
+
+	if err = r.stmtSecret.QueryRow(
+		q.Monitoring.ID,
+	).Scan(
+		&monitoringID,
+		&secret,
+	); err == sql.ErrNoRows {
+		mr.NotFound(err, q.Section)
+		return false
+	} else if err != nil {
+		mr.ServerError(err, q.Section)
+		return false
+	}
+
+	if !authenticateMonitoring(&q.MonAuth, secret) {
+		mr.Unauthorized(fmt.Errorf(
+			`Invalid or missing monitoring system authentication`,
+		), q.Section)
+		return false
+	}
+	return true
+}
+
 // get returns all local deployments for a node
 func (r *HostDeploymentRead) get(q *msg.Request, mr *msg.Result) {
 	var (
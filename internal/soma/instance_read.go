@@ -30,6 +30,8 @@ type InstanceRead struct {
 	stmtList     *sql.Stmt
 	stmtShow     *sql.Stmt
 	stmtVersions *sql.Stmt
+	stmtVersion  *sql.Stmt
+	stmtPrevious *sql.Stmt
 	appLog       *logrus.Logger
 	reqLog       *logrus.Logger
 	errLog       *logrus.Logger
@@ -61,6 +63,7 @@ func (r *InstanceRead) RegisterRequests(hmap *handler.Map) {
 		msg.ActionList,
 		msg.ActionShow,
 		msg.ActionVersions,
+		msg.ActionDiff,
 	} {
 		hmap.Request(msg.SectionInstance, action, r.handlerName)
 	}
@@ -81,9 +84,11 @@ func (r *InstanceRead) Run() {
 	var err error
 
 	for statement, prepStmt := range map[string]**sql.Stmt{
-		stmt.InstanceScopedList: &r.stmtList,
-		stmt.InstanceShow:       &r.stmtShow,
-		stmt.InstanceVersions:   &r.stmtVersions,
+		stmt.InstanceScopedList:     &r.stmtList,
+		stmt.InstanceShow:           &r.stmtShow,
+		stmt.InstanceVersions:       &r.stmtVersions,
+		stmt.InstanceConfigVersion:  &r.stmtVersion,
+		stmt.InstanceConfigPrevious: &r.stmtPrevious,
 	} {
 		if *prepStmt, err = r.conn.Prepare(statement); err != nil {
 			r.errLog.Fatal(`instance`, err, stmt.Name(statement))
@@ -116,6 +121,8 @@ func (r *InstanceRead) process(q *msg.Request) {
 		r.show(q, &result)
 	case msg.ActionVersions:
 		r.versions(q, &result)
+	case msg.ActionDiff:
+		r.diff(q, &result)
 	default:
 		result.UnknownRequest(q)
 	}
@@ -284,6 +291,8 @@ func (r *InstanceRead) versions(q *msg.Request, mr *msg.Result) {
 		instanceID, status, nextStatus, instanceConfigID string
 		createdNull, activatedNull, deprovisionedNull    pq.NullTime
 		updatedNull, notifiedNull                        pq.NullTime
+		rolledBackNull, restoredNull                     pq.NullTime
+		rolledBackTo, restoredFrom                       sql.NullInt64
 	)
 
 	if rows, err = r.stmtVersions.Query(
@@ -306,6 +315,10 @@ func (r *InstanceRead) versions(q *msg.Request, mr *msg.Result) {
 			&status,
 			&nextStatus,
 			&isInherited,
+			&rolledBackNull,
+			&rolledBackTo,
+			&restoredNull,
+			&restoredFrom,
 		); err != nil {
 			rows.Close()
 			mr.ServerError(err, q.Section)
@@ -339,6 +352,18 @@ func (r *InstanceRead) versions(q *msg.Request, mr *msg.Result) {
 			inst.Info.NotifiedAt = notifiedNull.Time.Format(
 				msg.RFC3339Milli)
 		}
+		// this version failed its rollout and was rolled back
+		if rolledBackNull.Valid {
+			inst.Info.RolledBackAt = rolledBackNull.Time.Format(
+				msg.RFC3339Milli)
+			inst.Info.RolledBackTo = uint64(rolledBackTo.Int64)
+		}
+		// this version was restored after a failed rollout
+		if restoredNull.Valid {
+			inst.Info.RestoredAt = restoredNull.Time.Format(
+				msg.RFC3339Milli)
+			inst.Info.RestoredFrom = uint64(restoredFrom.Int64)
+		}
 		mr.Instance = append(mr.Instance, inst)
 	}
 	if err = rows.Err(); err != nil {
@@ -348,6 +373,100 @@ func (r *InstanceRead) versions(q *msg.Request, mr *msg.Result) {
 	mr.OK()
 }
 
+// diff returns the changes between two versions of a specific
+// instance. If no version is requested, the most recent version is
+// used. If no version to compare against is requested, the version
+// preceding it is used.
+func (r *InstanceRead) diff(q *msg.Request, mr *msg.Result) {
+	var (
+		err                  error
+		version, against     int64
+		details, prevDetails string
+		nullVersion          sql.NullInt64
+		current, previous    proto.Deployment
+	)
+
+	if q.Instance.Diff == nil {
+		q.Instance.Diff = &proto.DeploymentDiff{}
+	}
+
+	if q.Instance.Diff.Version != 0 {
+		err = r.stmtVersion.QueryRow(
+			q.Instance.ID,
+			int64(q.Instance.Diff.Version),
+		).Scan(
+			&version,
+			&details,
+		)
+	} else {
+		err = r.stmtPrevious.QueryRow(
+			q.Instance.ID,
+			nullVersion,
+		).Scan(
+			&version,
+			&details,
+		)
+	}
+	if err == sql.ErrNoRows {
+		mr.NotFound(err, q.Section)
+		return
+	} else if err != nil {
+		mr.ServerError(err, q.Section)
+		return
+	}
+
+	if q.Instance.Diff.Against != 0 {
+		err = r.stmtVersion.QueryRow(
+			q.Instance.ID,
+			int64(q.Instance.Diff.Against),
+		).Scan(
+			&against,
+			&prevDetails,
+		)
+	} else {
+		nullVersion.Int64 = version
+		nullVersion.Valid = true
+		err = r.stmtPrevious.QueryRow(
+			q.Instance.ID,
+			nullVersion,
+		).Scan(
+			&against,
+			&prevDetails,
+		)
+	}
+	if err == sql.ErrNoRows {
+		mr.NotFound(fmt.Errorf(
+			"No version of instance %s to compare version %d against",
+			q.Instance.ID, version,
+		), q.Section)
+		return
+	} else if err != nil {
+		mr.ServerError(err, q.Section)
+		return
+	}
+
+	// unmarhal JSONB deployment details
+	if err = json.Unmarshal([]byte(details), &current); err != nil {
+		mr.ServerError(err, q.Section)
+		return
+	}
+	if err = json.Unmarshal([]byte(prevDetails), &previous); err != nil {
+		mr.ServerError(err, q.Section)
+		return
+	}
+
+	mr.Instance = append(mr.Instance, proto.Instance{
+		ID:      q.Instance.ID,
+		Version: uint64(version),
+		Diff: &proto.DeploymentDiff{
+			Version: uint64(version),
+			Against: uint64(against),
+			Changes: current.Diff(&previous),
+		},
+	})
+	mr.OK()
+}
+
 // ShutdownNow signals the handler to shut down
 func (r *InstanceRead) ShutdownNow() {
 	close(r.Shutdown)
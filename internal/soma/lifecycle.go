@@ -10,14 +10,19 @@ package soma
 
 import (
 	"database/sql"
-	"math"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
 	"time"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/mjolnir42/soma/internal/handler"
 	"github.com/mjolnir42/soma/internal/msg"
 	"github.com/mjolnir42/soma/internal/stmt"
+	"github.com/mjolnir42/soma/lib/auth"
 	"github.com/mjolnir42/soma/lib/proto"
+	uuid "github.com/satori/go.uuid"
 	"gopkg.in/resty.v0"
 )
 
@@ -34,10 +39,21 @@ type LifeCycle struct {
 	stmtDeadlock      *sql.Stmt
 	stmtReschedule    *sql.Stmt
 	stmtSetNotify     *sql.Stmt
+	stmtEnqueue       *sql.Stmt
+	stmtDueSystems    *sql.Stmt
+	stmtDue           *sql.Stmt
+	stmtDelivered     *sql.Stmt
+	stmtRetry         *sql.Stmt
+	stmtDeadLetter    *sql.Stmt
+	stmtWaveStatus    *sql.Stmt
+	stmtWave          *sql.Stmt
+	stmtWaveReleased  *sql.Stmt
+	stmtWaveHalt      *sql.Stmt
+	stmtWaveComplete  *sql.Stmt
 	appLog            *logrus.Logger
 	reqLog            *logrus.Logger
 	errLog            *logrus.Logger
-	pokers            map[string]chan string
+	pokers            map[string]chan struct{}
 	soma              *Soma
 }
 
@@ -78,7 +94,7 @@ func (lc *LifeCycle) RegisterRequests(hmap *handler.Map) {
 // Run is the loop for LifeCycle
 func (lc *LifeCycle) Run() {
 	var err error
-	lc.pokers = make(map[string]chan string)
+	lc.pokers = make(map[string]chan struct{})
 
 	lc.tick = time.NewTicker(
 		time.Duration(lc.soma.conf.LifeCycleTick) * time.Second,
@@ -93,6 +109,17 @@ func (lc *LifeCycle) Run() {
 		stmt.LifecycleDeadLockResolver:                 &lc.stmtDeadlock,
 		stmt.LifecycleRescheduleDeployments:            &lc.stmtReschedule,
 		stmt.LifecycleSetNotified:                      &lc.stmtSetNotify,
+		stmt.LifecycleOutboxEnqueue:                    &lc.stmtEnqueue,
+		stmt.LifecycleOutboxDueSystems:                 &lc.stmtDueSystems,
+		stmt.LifecycleOutboxDue:                        &lc.stmtDue,
+		stmt.LifecycleOutboxDelivered:                  &lc.stmtDelivered,
+		stmt.LifecycleOutboxRetry:                      &lc.stmtRetry,
+		stmt.LifecycleOutboxDeadLetter:                 &lc.stmtDeadLetter,
+		stmt.RolloutStatus:                             &lc.stmtWaveStatus,
+		stmt.RolloutWave:                               &lc.stmtWave,
+		stmt.RolloutWaveReleased:                       &lc.stmtWaveReleased,
+		stmt.RolloutHalt:                               &lc.stmtWaveHalt,
+		stmt.RolloutComplete:                           &lc.stmtWaveComplete,
 	} {
 		if *prepStmt, err = lc.conn.Prepare(statement); err != nil {
 			lc.errLog.Fatal(`lifecycle`, err, stmt.Name(statement))
@@ -121,6 +148,7 @@ runloop:
 			lc.deadlockResolver()
 			lc.handleDelete()
 			if !lc.soma.conf.NoPoke {
+				lc.waves()
 				lc.poke()
 			}
 		}
@@ -130,11 +158,42 @@ exit:
 
 // ghost deletes configurations that that are still in in
 // awaiting_rollout and have update_available set, ie. they have not
-// yet been sent to the monitoring system
+// yet been sent to the monitoring system. It also expires delivered
+// notifications from the outbox.
 func (lc *LifeCycle) ghost() {
-	lc.conn.Exec(stmt.LifecycleDeleteGhosts)
-	lc.conn.Exec(stmt.LifecycleDeleteFailedRollouts)
-	lc.conn.Exec(stmt.LifecycleDeleteDeprovisioned)
+	for _, statement := range []string{
+		stmt.LifecycleDeleteGhosts,
+		stmt.LifecycleDeleteFailedRollouts,
+		stmt.LifecycleDeleteDeprovisioned,
+	} {
+		lc.discard(statement)
+	}
+	lc.conn.Exec(stmt.LifecycleOutboxCleanup)
+}
+
+// discard executes statement, which moves check instance
+// configurations to awaiting_deletion and returns their IDs
+func (lc *LifeCycle) discard(statement string) {
+	var (
+		rows     *sql.Rows
+		err      error
+		configID string
+	)
+
+	if rows, err = lc.conn.Query(statement); err != nil {
+		lc.errLog.Println(`LifeCycle.discard()`, stmt.Name(statement), err)
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err = rows.Scan(&configID); err != nil {
+			lc.errLog.Println(`LifeCycle.discard()`, err)
+			continue
+		}
+		publishTransition(lc.soma, proto.EventSourceLifeCycle, configID,
+			proto.DeploymentAwaitingDeletion, proto.DeploymentNone)
+	}
 }
 
 // search if there are check instance configurations in status blocked
@@ -147,6 +206,7 @@ func (lc *LifeCycle) discardDeletedBlocked() error {
 		blockedID, blockingID, state string
 		tx                           *sql.Tx
 		deps                         *sql.Rows
+		discarded                    []string
 	)
 
 	if deps, err = lc.stmtDeleteBlocked.Query(); err != nil {
@@ -187,6 +247,7 @@ func (lc *LifeCycle) discardDeletedBlocked() error {
 			tx.Rollback()
 			return err
 		}
+		discarded = append(discarded, blockedID)
 	}
 	if deps.Err() != nil {
 		lc.errLog.Println(err)
@@ -199,6 +260,10 @@ func (lc *LifeCycle) discardDeletedBlocked() error {
 		tx.Rollback()
 		return err
 	}
+	for _, configID := range discarded {
+		publishTransition(lc.soma, proto.EventSourceLifeCycle, configID,
+			proto.DeploymentAwaitingDeletion, proto.DeploymentNone)
+	}
 	return nil
 }
 
@@ -296,6 +361,8 @@ idloop:
 			tx.Rollback()
 			continue idloop
 		}
+		publishTransition(lc.soma, proto.EventSourceLifeCycle, blockedID,
+			next, nextNG)
 	}
 }
 
@@ -337,6 +404,9 @@ func (lc *LifeCycle) deadlockResolver() {
 			chkInstConfigID,
 			chkInstID,
 		)
+		publishTransition(lc.soma, proto.EventSourceLifeCycle,
+			chkInstConfigID, proto.DeploymentAwaitingDeprovision,
+			proto.DeploymentDeprovisionInProgress)
 	}
 }
 
@@ -349,6 +419,7 @@ func (lc *LifeCycle) handleDelete() {
 		err               error
 		instCfgID, instID string
 		tx                *sql.Tx
+		deprovisioned     []string
 	)
 
 	if rows, err = lc.stmtDeleteActive.Query(); err != nil {
@@ -389,6 +460,7 @@ cfgloop:
 			tx.Rollback()
 			return
 		}
+		deprovisioned = append(deprovisioned, instCfgID)
 	}
 	if rows.Err() != nil {
 		lc.errLog.Println(err)
@@ -398,12 +470,20 @@ cfgloop:
 	if err = tx.Commit(); err != nil {
 		lc.errLog.Println(err)
 		tx.Rollback()
+		return
+	}
+	for _, configID := range deprovisioned {
+		publishTransition(lc.soma, proto.EventSourceLifeCycle, configID,
+			proto.DeploymentAwaitingDeprovision,
+			proto.DeploymentDeprovisionInProgress)
 	}
 	return
 }
 
-// poke triggers update notifications to monitoring systems that have
-// a configured callback address
+// poke queues update notifications for monitoring systems that have
+// a configured callback address in the notification outbox and wakes
+// up the delivery goroutines. Deployments within the scope of an
+// active maintenance window are held back until the window ends.
 func (lc *LifeCycle) poke() {
 	var (
 		chkIds                        *sql.Rows
@@ -419,12 +499,14 @@ func (lc *LifeCycle) poke() {
 			// and have not moved along in > 5 minutes
 			if chkIds, err = lc.stmtReschedule.Query(); err != nil {
 				lc.errLog.Println(`LifeCycle.reschedule()`, err)
+				continue
 			}
 		case `poke`:
 			// poke picks up configurations that have update_available
 			// set and have not been notified before
 			if chkIds, err = lc.stmtPoke.Query(); err != nil {
 				lc.errLog.Println(`LifeCycle.poke()`, err)
+				continue
 			}
 		}
 
@@ -438,63 +520,386 @@ func (lc *LifeCycle) poke() {
 				continue
 			}
 
-			// there is no goroutine running for the system yet
-			if _, ok := lc.pokers[monitoringID]; !ok {
-				lc.pokers[monitoringID] = make(chan string, 4096)
-				go lc.pokeSystem(callback, lc.pokers[monitoringID])
+			// the notification is persisted in the outbox, from where
+			// it is delivered independent of the lifecycle tick.
+			// Pending notifications for the same check instance
+			// are not queued twice, a dead notification is re-armed.
+			if _, err = lc.stmtEnqueue.Exec(
+				uuid.Must(uuid.NewV4()).String(),
+				monitoringID,
+				chkID,
+			); err != nil {
+				lc.errLog.Println(`LifeCycle.poke()`, err)
+				continue
 			}
-
-			// if the channel is full we skip the checkid and pick it
-			// up on the next lifecycle tick, otherwise an unresponsive
-			// monitoring system could block the lifecycle system
-			if len(lc.pokers[monitoringID]) < 4095 {
-				lc.pokers[monitoringID] <- chkID
-				if mode == `poke` {
-					// notify has been triggered,
-					// clear update available flag
-					lc.stmtClear.Exec(chkID)
-				}
+			if mode == `poke` {
+				// notify has been queued,
+				// clear update available flag
+				lc.stmtClear.Exec(chkID)
 			}
 		}
 		if err = chkIds.Err(); err != nil {
 			lc.errLog.Println(err)
 		}
+		chkIds.Close()
+	}
+
+	lc.wake()
+}
+
+// wake signals the delivery goroutines of all monitoring systems
+// that have due notifications in the outbox
+func (lc *LifeCycle) wake() {
+	var (
+		rows         *sql.Rows
+		err          error
+		monitoringID string
+	)
+
+	if rows, err = lc.stmtDueSystems.Query(); err != nil {
+		lc.errLog.Println(`LifeCycle.wake()`, err)
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err = rows.Scan(
+			&monitoringID,
+		); err != nil {
+			lc.errLog.Println(`LifeCycle.wake()`, err)
+			continue
+		}
+
+		// there is no goroutine running for the system yet
+		if _, ok := lc.pokers[monitoringID]; !ok {
+			lc.pokers[monitoringID] = make(chan struct{}, 1)
+			go lc.pokeSystem(monitoringID, lc.pokers[monitoringID])
+		}
+
+		// if a wakeup is already pending, the goroutine is still
+		// busy with the previous round and picks up new
+		// notifications on its own. An unresponsive monitoring
+		// system can therefore not block the lifecycle system.
+		select {
+		case lc.pokers[monitoringID] <- struct{}{}:
+		default:
+		}
+	}
+	if err = rows.Err(); err != nil {
+		lc.errLog.Println(`LifeCycle.wake()`, err)
 	}
 }
 
-// pokeSystem sends out the update notifications for check IDs it
-// receives via channel in to the callback URL
-func (lc *LifeCycle) pokeSystem(callback string, in chan string) {
+// pokeSystem delivers the due outbox notifications of monitoringID
+// every time it is woken up via channel wake, or once the flush
+// interval of a held back partial batch has passed
+func (lc *LifeCycle) pokeSystem(monitoringID string, wake chan struct{}) {
+	var wait time.Duration
+
 	client := resty.New()
+	client.SetTimeout(time.Duration(
+		lc.soma.conf.PokeTimeout) * time.Millisecond,
+	)
+	// at most one flush is pending, the timer is re-armed after
+	// every delivery
+	flush := time.NewTimer(0)
+	<-flush.C
+	defer flush.Stop()
+
 	for {
 		select {
-		case chkID := <-in:
-			retries := 0
-		retry:
-			client.SetTimeout(time.Duration(
-				lc.soma.conf.PokeTimeout) * time.Millisecond,
-			)
-			if _, err := client.R().SetBody(
-				proto.PushNotification{
-					UUID: chkID,
-					Path: lc.soma.conf.PokePath,
-				},
-			).Post(callback); err != nil {
-				// with limit 4 this implements retries with 1, 2, 4
-				// and 8 seconds sleeps between them
-				if retries < 4 {
-					timeout := math.Pow(2, float64(retries))
-					time.Sleep(time.Duration(timeout) * time.Second)
-					retries++
-					goto retry
+		case <-lc.Shutdown:
+			return
+		case <-wake:
+			if !flush.Stop() {
+				select {
+				case <-flush.C:
+				default:
 				}
-				lc.errLog.Println(err)
-				continue
 			}
-			lc.appLog.Printf("Poked %s (%s)", callback, chkID)
-			lc.stmtSetNotify.Exec(chkID)
+		case <-flush.C:
+		}
+		if wait = lc.deliver(client, monitoringID); wait > 0 {
+			flush.Reset(wait)
+		}
+	}
+}
+
+// deliver sends out all due notifications of monitoringID, either
+// one by one or batched if the monitoring system has a batch size
+// configured. Partial batches are held back until the flush interval
+// of the monitoring system has passed, the remaining time is
+// returned.
+func (lc *LifeCycle) deliver(client *resty.Client,
+	monitoringID string) time.Duration {
+	var (
+		rows                  *sql.Rows
+		err                   error
+		notifyID, chkID, uri  string
+		attempts              uint64
+		batchSize, batchFlush int64
+		dueAt                 time.Time
+		secret                sql.NullString
+		notifications         []proto.Notification
+		callbacks             map[string]string
+		waiting               map[string]time.Time
+	)
+
+	if rows, err = lc.stmtDue.Query(monitoringID); err != nil {
+		lc.errLog.Println(`LifeCycle.deliver()`, err)
+		return 0
+	}
+
+	notifications = []proto.Notification{}
+	callbacks = map[string]string{}
+	waiting = map[string]time.Time{}
+	for rows.Next() {
+		if err = rows.Scan(
+			&notifyID,
+			&chkID,
+			&uri,
+			&attempts,
+			&dueAt,
+			&batchSize,
+			&batchFlush,
+			&secret,
+		); err != nil {
+			lc.errLog.Println(`LifeCycle.deliver()`, err)
+			rows.Close()
+			return 0
+		}
+		notifications = append(notifications, proto.Notification{
+			ID:              notifyID,
+			MonitoringID:    monitoringID,
+			CheckInstanceID: chkID,
+			Attempts:        attempts,
+		})
+		callbacks[notifyID] = uri
+		waiting[notifyID] = dueAt
+	}
+	if err = rows.Err(); err != nil {
+		lc.errLog.Println(`LifeCycle.deliver()`, err)
+		rows.Close()
+		return 0
+	}
+	rows.Close()
+
+	if batchSize <= 1 {
+		lc.deliverSingle(client, notifications, callbacks, secret)
+		return 0
+	}
+
+	for len(notifications) > 0 {
+		size := int(batchSize)
+		if size > len(notifications) {
+			size = len(notifications)
+		}
+		batch := notifications[:size]
+
+		if int64(size) < batchSize {
+			// partial batch, hold it back until the oldest
+			// notification in it has waited for the flush interval
+			wait := time.Duration(batchFlush)*time.Second -
+				time.Since(waiting[batch[0].ID])
+			if wait > 0 {
+				return wait
+			}
+		}
+
+		if !lc.deliverBatch(client, batch, callbacks[batch[0].ID],
+			secret) {
+			return 0
+		}
+		notifications = notifications[size:]
+	}
+	return 0
+}
+
+// deliverSingle sends out one push notification per notification.
+// Delivery for the monitoring system is stopped for this round on the
+// first failure, preserving the order of the notifications.
+func (lc *LifeCycle) deliverSingle(client *resty.Client,
+	notifications []proto.Notification, callbacks map[string]string,
+	secret sql.NullString) {
+	var (
+		err  error
+		resp *resty.Response
+	)
+
+	for _, n := range notifications {
+		resp, err = lc.post(client, callbacks[n.ID],
+			proto.PushNotification{
+				UUID: n.CheckInstanceID,
+				Path: lc.soma.conf.PokePath,
+			},
+			secret,
+		)
+		if err == nil && resp.StatusCode() > 299 {
+			err = fmt.Errorf("Monitoring system returned %s",
+				resp.Status())
+		}
+
+		if err != nil {
+			lc.backoff(n, err)
+			return
+		}
+
+		lc.appLog.Printf("Poked %s (%s)", callbacks[n.ID], n.CheckInstanceID)
+		lc.delivered(n)
+	}
+}
+
+// deliverBatch sends out a single batched push notification for all
+// notifications in batch. The monitoring system acknowledges every
+// notification individually, or all of them at once by replying
+// without content. deliverBatch returns false if the batch could not
+// be delivered at all.
+func (lc *LifeCycle) deliverBatch(client *resty.Client,
+	batch []proto.Notification, callback string,
+	secret sql.NullString) bool {
+	var (
+		err   error
+		resp  *resty.Response
+		acked map[string]proto.PushNotificationAck
+	)
+
+	body := proto.NewPushNotificationBatch()
+	body.Path = lc.soma.conf.PokePath
+	for _, n := range batch {
+		body.UUIDs = append(body.UUIDs, n.CheckInstanceID)
+	}
+
+	resp, err = lc.post(client, callback, body, secret)
+	if err == nil && resp.StatusCode() > 299 {
+		err = fmt.Errorf("Monitoring system returned %s",
+			resp.Status())
+	}
+
+	reply := proto.NewPushNotificationReply()
+	if err == nil && resp.StatusCode() != http.StatusNoContent {
+		if err = json.Unmarshal(resp.Body(), &reply); err != nil {
+			err = fmt.Errorf("Invalid batch acknowledgement: %s",
+				err.Error())
+		}
+	}
+
+	if err != nil {
+		for _, n := range batch {
+			lc.backoff(n, err)
+		}
+		return false
+	}
+
+	lc.appLog.Printf("Poked %s (batch of %d)", callback, len(batch))
+	if resp.StatusCode() == http.StatusNoContent {
+		for _, n := range batch {
+			lc.delivered(n)
+		}
+		return true
+	}
+
+	acked = map[string]proto.PushNotificationAck{}
+	for _, ack := range reply.Acks {
+		acked[ack.UUID] = ack
+	}
+	for _, n := range batch {
+		ack, ok := acked[n.CheckInstanceID]
+		switch {
+		case !ok:
+			lc.backoff(n, fmt.Errorf(
+				`Notification was not acknowledged`))
+		case !ack.Accepted:
+			lc.backoff(n, fmt.Errorf(
+				"Notification was rejected: %s", ack.Error))
+		default:
+			lc.delivered(n)
+		}
+	}
+	return true
+}
+
+// post sends body to the callback URI of a monitoring system. If the
+// monitoring system has a secret configured, the request is signed
+// with it.
+func (lc *LifeCycle) post(client *resty.Client, callback string,
+	body interface{}, secret sql.NullString) (*resty.Response, error) {
+	var (
+		err       error
+		raw       []byte
+		uri       *url.URL
+		timestamp string
+	)
+
+	if !secret.Valid || secret.String == `` {
+		return client.R().SetBody(body).Post(callback)
+	}
+
+	if raw, err = json.Marshal(body); err != nil {
+		return nil, err
+	}
+	if uri, err = url.Parse(callback); err != nil {
+		return nil, err
+	}
+	timestamp = auth.MonitoringTimestamp()
+
+	return client.R().
+		SetHeader(`Content-Type`, `application/json`).
+		SetHeader(auth.HeaderMonitoringTimestamp, timestamp).
+		SetHeader(auth.HeaderMonitoringSignature, auth.SignMonitoring(
+			[]byte(secret.String),
+			http.MethodPost,
+			uri.Path,
+			timestamp,
+			raw,
+		)).
+		SetBody(raw).
+		Post(callback)
+}
+
+// delivered records the successful delivery of notification n
+func (lc *LifeCycle) delivered(n proto.Notification) {
+	if _, err := lc.stmtDelivered.Exec(n.ID); err != nil {
+		lc.errLog.Println(`LifeCycle.delivered()`, err)
+	}
+	lc.stmtSetNotify.Exec(n.CheckInstanceID)
+}
+
+// backoff reschedules the failed notification n with exponential
+// backoff or moves it to the dead letter state once the configured
+// retry limit has been reached
+func (lc *LifeCycle) backoff(n proto.Notification, reason error) {
+	var err error
+
+	lc.errLog.Printf("LifeCycle: notification %s for %s failed: %s",
+		n.ID, n.CheckInstanceID, reason.Error())
+
+	if n.Attempts+1 >= lc.soma.conf.PokeRetries {
+		lc.errLog.Printf("LifeCycle: notification %s for %s moved to"+
+			" dead letter after %d attempts",
+			n.ID, n.CheckInstanceID, n.Attempts+1)
+		if _, err = lc.stmtDeadLetter.Exec(
+			n.ID,
+			reason.Error(),
+		); err != nil {
+			lc.errLog.Println(`LifeCycle.backoff()`, err)
+		}
+		return
+	}
+
+	// base * 2^attempts, capped at the configured maximum
+	backoff := lc.soma.conf.PokeBackoffMx
+	if n.Attempts < 32 {
+		if b := lc.soma.conf.PokeBackoff << n.Attempts; b < backoff {
+			backoff = b
 		}
 	}
+	if _, err = lc.stmtRetry.Exec(
+		n.ID,
+		reason.Error(),
+		int64(backoff),
+	); err != nil {
+		lc.errLog.Println(`LifeCycle.backoff()`, err)
+	}
 }
 
 // ShutdownNow signals the handler to shut down
@@ -0,0 +1,152 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package soma
+
+import (
+	"database/sql"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+// waves releases check instances of check configurations with a
+// staged rollout policy. These are not picked up by poke, instead
+// the next wave of instances is queued in the notification outbox
+// once all instances of the previous wave have finished their
+// rollout and the soak time has passed. Rollouts that reach their
+// failure threshold are halted until they are resumed.
+func (lc *LifeCycle) waves() {
+	var (
+		rows                                *sql.Rows
+		err                                 error
+		configID                            string
+		waveSize, wavePercent               sql.NullInt64
+		threshold, wave, size               int64
+		pending, outstanding, failed, total int64
+		halted, soaked                      bool
+	)
+
+	if rows, err = lc.stmtWaveStatus.Query(); err != nil {
+		lc.errLog.Println(`LifeCycle.waves()`, err)
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err = rows.Scan(
+			&configID,
+			&waveSize,
+			&wavePercent,
+			&threshold,
+			&wave,
+			&halted,
+			&soaked,
+			&pending,
+			&outstanding,
+			&failed,
+			&total,
+		); err != nil {
+			lc.errLog.Println(`LifeCycle.waves()`, err)
+			continue
+		}
+
+		switch {
+		case halted:
+			// halted rollouts are only continued by a resume request
+			continue
+		case failed >= threshold:
+			if _, err = lc.stmtWaveHalt.Exec(configID); err != nil {
+				lc.errLog.Println(`LifeCycle.waves()`, err)
+				continue
+			}
+			lc.appLog.Printf("LifeCycle: halted rollout of check"+
+				" configuration %s in wave %d after %d failures",
+				configID, wave, failed)
+			continue
+		case outstanding > 0:
+			// the previous wave has not finished its rollout
+			continue
+		case pending == 0:
+			if wave > 0 {
+				lc.stmtWaveComplete.Exec(configID)
+			}
+			continue
+		case !soaked:
+			continue
+		}
+
+		size = waveSize.Int64
+		if wavePercent.Valid {
+			size = (total*wavePercent.Int64 + 99) / 100
+		}
+		if size < 1 {
+			size = 1
+		}
+		lc.release(configID, wave+1, size)
+	}
+	if err = rows.Err(); err != nil {
+		lc.errLog.Println(`LifeCycle.waves()`, err)
+	}
+}
+
+// release queues up to size check instances of the check
+// configuration as the next rollout wave
+func (lc *LifeCycle) release(configID string, wave, size int64) {
+	var (
+		rows                *sql.Rows
+		err                 error
+		chkID, monitoringID string
+		released            int
+	)
+
+	if rows, err = lc.stmtWave.Query(configID, size); err != nil {
+		lc.errLog.Println(`LifeCycle.release()`, err)
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err = rows.Scan(
+			&chkID,
+			&monitoringID,
+		); err != nil {
+			lc.errLog.Println(`LifeCycle.release()`, err)
+			continue
+		}
+
+		if _, err = lc.stmtEnqueue.Exec(
+			uuid.Must(uuid.NewV4()).String(),
+			monitoringID,
+			chkID,
+		); err != nil {
+			lc.errLog.Println(`LifeCycle.release()`, err)
+			continue
+		}
+		lc.stmtClear.Exec(chkID)
+		released++
+	}
+	if err = rows.Err(); err != nil {
+		lc.errLog.Println(`LifeCycle.release()`, err)
+	}
+
+	// instances can be held back by maintenance windows, in which
+	// case no wave has been released
+	if released == 0 {
+		return
+	}
+	if _, err = lc.stmtWaveReleased.Exec(configID); err != nil {
+		lc.errLog.Println(`LifeCycle.release()`, err)
+		return
+	}
+	lc.appLog.Printf("LifeCycle: released wave %d of check"+
+		" configuration %s with %d check instances",
+		wave, configID, released)
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -0,0 +1,234 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package soma // import "github.com/mjolnir42/soma/internal/soma"
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/lib/pq"
+	"github.com/mjolnir42/soma/internal/handler"
+	"github.com/mjolnir42/soma/internal/msg"
+	"github.com/mjolnir42/soma/internal/stmt"
+	"github.com/mjolnir42/soma/lib/proto"
+)
+
+// MaintenanceRead handles read requests for maintenance windows
+type MaintenanceRead struct {
+	Input       chan msg.Request
+	Shutdown    chan struct{}
+	handlerName string
+	conn        *sql.DB
+	stmtList    *sql.Stmt
+	stmtShow    *sql.Stmt
+	appLog      *logrus.Logger
+	reqLog      *logrus.Logger
+	errLog      *logrus.Logger
+}
+
+// newMaintenanceRead return a new MaintenanceRead handler with input
+// buffer of length
+func newMaintenanceRead(length int) (string, *MaintenanceRead) {
+	r := &MaintenanceRead{}
+	r.handlerName = generateHandlerName() + `_r`
+	r.Input = make(chan msg.Request, length)
+	r.Shutdown = make(chan struct{})
+	return r.handlerName, r
+}
+
+// Register initializes resources provided by the Soma app
+func (r *MaintenanceRead) Register(c *sql.DB, l ...*logrus.Logger) {
+	r.conn = c
+	r.appLog = l[0]
+	r.reqLog = l[1]
+	r.errLog = l[2]
+}
+
+// RegisterRequests links the handler inside the handlermap to the requests
+// it processes
+func (r *MaintenanceRead) RegisterRequests(hmap *handler.Map) {
+	for _, action := range []string{
+		msg.ActionList,
+		msg.ActionShow,
+	} {
+		hmap.Request(msg.SectionMaintenance, action, r.handlerName)
+	}
+}
+
+// Intake exposes the Input channel as part of the handler interface
+func (r *MaintenanceRead) Intake() chan msg.Request {
+	return r.Input
+}
+
+// PriorityIntake aliases Intake as part of the handler interface
+func (r *MaintenanceRead) PriorityIntake() chan msg.Request {
+	return r.Intake()
+}
+
+// Run is the event loop for MaintenanceRead
+func (r *MaintenanceRead) Run() {
+	var err error
+
+	for statement, prepStmt := range map[string]**sql.Stmt{
+		stmt.MaintenanceList: &r.stmtList,
+		stmt.MaintenanceShow: &r.stmtShow,
+	} {
+		if *prepStmt, err = r.conn.Prepare(statement); err != nil {
+			r.errLog.Fatal(`maintenance_r`, err, stmt.Name(statement))
+		}
+		defer (*prepStmt).Close()
+	}
+
+runloop:
+	for {
+		select {
+		case <-r.Shutdown:
+			break runloop
+		case req := <-r.Input:
+			go func() {
+				r.process(&req)
+			}()
+		}
+	}
+}
+
+// process is the request dispatcher
+func (r *MaintenanceRead) process(q *msg.Request) {
+	result := msg.FromRequest(q)
+	logRequest(r.reqLog, q)
+
+	switch q.Action {
+	case msg.ActionList:
+		r.list(q, &result)
+	case msg.ActionShow:
+		r.show(q, &result)
+	default:
+		result.UnknownRequest(q)
+	}
+
+	q.Reply <- result
+}
+
+// list returns all maintenance windows that have not yet ended
+func (r *MaintenanceRead) list(q *msg.Request, mr *msg.Result) {
+	var (
+		err    error
+		rows   *sql.Rows
+		window proto.MaintenanceWindow
+	)
+
+	if rows, err = r.stmtList.Query(); err != nil {
+		mr.ServerError(err, q.Section)
+		return
+	}
+
+	for rows.Next() {
+		if window, err = scanMaintenanceWindow(rows); err != nil {
+			rows.Close()
+			mr.ServerError(err, q.Section)
+			return
+		}
+		mr.MaintenanceWindow = append(mr.MaintenanceWindow, window)
+	}
+	if err = rows.Err(); err != nil {
+		mr.ServerError(err, q.Section)
+		return
+	}
+	mr.OK()
+}
+
+// show returns the details of a specific maintenance window
+func (r *MaintenanceRead) show(q *msg.Request, mr *msg.Result) {
+	var (
+		err    error
+		window proto.MaintenanceWindow
+	)
+
+	if window, err = scanMaintenanceWindow(r.stmtShow.QueryRow(
+		q.Maintenance.ID,
+	)); err == sql.ErrNoRows {
+		mr.NotFound(err, q.Section)
+		return
+	} else if err != nil {
+		mr.ServerError(err, q.Section)
+		return
+	}
+	mr.MaintenanceWindow = append(mr.MaintenanceWindow, window)
+	mr.OK()
+}
+
+// ShutdownNow signals the handler to shut down
+func (r *MaintenanceRead) ShutdownNow() {
+	close(r.Shutdown)
+}
+
+// scanMaintenanceWindow reads a maintenance window from the row s,
+// which must be the result of stmt.MaintenanceList or
+// stmt.MaintenanceShow
+func scanMaintenanceWindow(s interface {
+	Scan(...interface{}) error
+}) (proto.MaintenanceWindow, error) {
+	var (
+		err                                  error
+		windowID, scope, description, author string
+		repoID, bucketID, env, monitoringID  sql.NullString
+		startsAt, endsAt, createdAt          time.Time
+		holdJobs                             bool
+		cancelledAt                          pq.NullTime
+	)
+
+	if err = s.Scan(
+		&windowID,
+		&scope,
+		&repoID,
+		&bucketID,
+		&env,
+		&monitoringID,
+		&description,
+		&startsAt,
+		&endsAt,
+		&holdJobs,
+		&cancelledAt,
+		&author,
+		&createdAt,
+	); err != nil {
+		return proto.MaintenanceWindow{}, err
+	}
+
+	now := time.Now().UTC()
+	window := proto.MaintenanceWindow{
+		ID:           windowID,
+		Scope:        scope,
+		RepositoryID: repoID.String,
+		BucketID:     bucketID.String,
+		Environment:  env.String,
+		MonitoringID: monitoringID.String,
+		Description:  description,
+		StartsAt:     startsAt.UTC().Format(msg.RFC3339Milli),
+		EndsAt:       endsAt.UTC().Format(msg.RFC3339Milli),
+		HoldJobs:     holdJobs,
+		IsActive: !cancelledAt.Valid &&
+			!now.Before(startsAt) && now.Before(endsAt),
+		IsCancelled: cancelledAt.Valid,
+		Details: &proto.MaintenanceWindowDetails{
+			DetailsCreation: proto.DetailsCreation{
+				CreatedAt: createdAt.UTC().Format(msg.RFC3339Milli),
+				CreatedBy: author,
+			},
+		},
+	}
+	if cancelledAt.Valid {
+		window.CancelledAt = cancelledAt.Time.UTC().Format(msg.RFC3339Milli)
+	}
+	return window, nil
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -0,0 +1,234 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package soma // import "github.com/mjolnir42/soma/internal/soma"
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/mjolnir42/soma/internal/handler"
+	"github.com/mjolnir42/soma/internal/msg"
+	"github.com/mjolnir42/soma/internal/stmt"
+	"github.com/mjolnir42/soma/lib/proto"
+	uuid "github.com/satori/go.uuid"
+)
+
+// MaintenanceWrite handles write requests for maintenance windows
+type MaintenanceWrite struct {
+	Input       chan msg.Request
+	Shutdown    chan struct{}
+	handlerName string
+	conn        *sql.DB
+	stmtAdd     *sql.Stmt
+	stmtCancel  *sql.Stmt
+	appLog      *logrus.Logger
+	reqLog      *logrus.Logger
+	errLog      *logrus.Logger
+}
+
+// newMaintenanceWrite return a new MaintenanceWrite handler with
+// input buffer of length
+func newMaintenanceWrite(length int) (string, *MaintenanceWrite) {
+	w := &MaintenanceWrite{}
+	w.handlerName = generateHandlerName() + `_w`
+	w.Input = make(chan msg.Request, length)
+	w.Shutdown = make(chan struct{})
+	return w.handlerName, w
+}
+
+// Register initializes resources provided by the Soma app
+func (w *MaintenanceWrite) Register(c *sql.DB, l ...*logrus.Logger) {
+	w.conn = c
+	w.appLog = l[0]
+	w.reqLog = l[1]
+	w.errLog = l[2]
+}
+
+// RegisterRequests links the handler inside the handlermap to the requests
+// it processes
+func (w *MaintenanceWrite) RegisterRequests(hmap *handler.Map) {
+	for _, action := range []string{
+		msg.ActionAdd,
+		msg.ActionCancel,
+	} {
+		hmap.Request(msg.SectionMaintenance, action, w.handlerName)
+	}
+}
+
+// Intake exposes the Input channel as part of the handler interface
+func (w *MaintenanceWrite) Intake() chan msg.Request {
+	return w.Input
+}
+
+// PriorityIntake aliases Intake as part of the handler interface
+func (w *MaintenanceWrite) PriorityIntake() chan msg.Request {
+	return w.Intake()
+}
+
+// Run is the event loop for MaintenanceWrite
+func (w *MaintenanceWrite) Run() {
+	var err error
+
+	for statement, prepStmt := range map[string]**sql.Stmt{
+		stmt.MaintenanceAdd:    &w.stmtAdd,
+		stmt.MaintenanceCancel: &w.stmtCancel,
+	} {
+		if *prepStmt, err = w.conn.Prepare(statement); err != nil {
+			w.errLog.Fatal(`maintenance_w`, err, stmt.Name(statement))
+		}
+		defer (*prepStmt).Close()
+	}
+
+runloop:
+	for {
+		select {
+		case <-w.Shutdown:
+			break runloop
+		case req := <-w.Input:
+			w.process(&req)
+		}
+	}
+}
+
+// process is the request dispatcher
+func (w *MaintenanceWrite) process(q *msg.Request) {
+	result := msg.FromRequest(q)
+	logRequest(w.reqLog, q)
+
+	switch q.Action {
+	case msg.ActionAdd:
+		w.add(q, &result)
+	case msg.ActionCancel:
+		w.cancel(q, &result)
+	default:
+		result.UnknownRequest(q)
+	}
+
+	q.Reply <- result
+}
+
+// add creates a new maintenance window
+func (w *MaintenanceWrite) add(q *msg.Request, mr *msg.Result) {
+	var (
+		err                                 error
+		res                                 sql.Result
+		startsAt, endsAt                    time.Time
+		repoID, bucketID, env, monitoringID sql.NullString
+		target                              string
+	)
+
+	if startsAt, err = time.Parse(
+		time.RFC3339, q.Maintenance.StartsAt,
+	); err != nil {
+		mr.BadRequest(err, q.Section)
+		return
+	}
+	if endsAt, err = time.Parse(
+		time.RFC3339, q.Maintenance.EndsAt,
+	); err != nil {
+		mr.BadRequest(err, q.Section)
+		return
+	}
+	if !endsAt.After(startsAt) {
+		mr.BadRequest(fmt.Errorf(
+			"Maintenance window must end after it starts"), q.Section)
+		return
+	}
+	if !endsAt.After(time.Now()) {
+		mr.BadRequest(fmt.Errorf(
+			"Maintenance window must end in the future"), q.Section)
+		return
+	}
+
+	switch q.Maintenance.Scope {
+	case proto.MaintenanceScopeRepository:
+		target = q.Maintenance.RepositoryID
+		repoID = sql.NullString{String: target, Valid: true}
+	case proto.MaintenanceScopeBucket:
+		target = q.Maintenance.BucketID
+		bucketID = sql.NullString{String: target, Valid: true}
+	case proto.MaintenanceScopeEnvironment:
+		target = q.Maintenance.Environment
+		env = sql.NullString{String: target, Valid: true}
+	case proto.MaintenanceScopeMonitoring:
+		target = q.Maintenance.MonitoringID
+		monitoringID = sql.NullString{String: target, Valid: true}
+	default:
+		mr.BadRequest(fmt.Errorf("Unknown maintenance scope: %s",
+			q.Maintenance.Scope), q.Section)
+		return
+	}
+	if target == `` {
+		mr.BadRequest(fmt.Errorf("Missing target for maintenance"+
+			" scope %s", q.Maintenance.Scope), q.Section)
+		return
+	}
+	// jobs are processed per repository, they can only be held by
+	// windows for an entire repository
+	if q.Maintenance.HoldJobs &&
+		q.Maintenance.Scope != proto.MaintenanceScopeRepository {
+		mr.BadRequest(fmt.Errorf("Jobs can only be held by"+
+			" maintenance windows with scope %s",
+			proto.MaintenanceScopeRepository), q.Section)
+		return
+	}
+
+	q.Maintenance.ID = uuid.Must(uuid.NewV4()).String()
+	if res, err = w.stmtAdd.Exec(
+		q.Maintenance.ID,
+		q.Maintenance.Scope,
+		repoID,
+		bucketID,
+		env,
+		monitoringID,
+		q.Maintenance.Description,
+		startsAt.UTC(),
+		endsAt.UTC(),
+		q.Maintenance.HoldJobs,
+		q.AuthUser,
+	); err != nil {
+		mr.ServerError(err, q.Section)
+		return
+	}
+	if mr.RowCnt(res.RowsAffected()) {
+		q.Maintenance.StartsAt = startsAt.UTC().Format(msg.RFC3339Milli)
+		q.Maintenance.EndsAt = endsAt.UTC().Format(msg.RFC3339Milli)
+		mr.MaintenanceWindow = append(mr.MaintenanceWindow,
+			q.Maintenance)
+	}
+}
+
+// cancel ends a maintenance window immediately
+func (w *MaintenanceWrite) cancel(q *msg.Request, mr *msg.Result) {
+	var (
+		err error
+		res sql.Result
+	)
+
+	if res, err = w.stmtCancel.Exec(
+		q.Maintenance.ID,
+	); err != nil {
+		mr.ServerError(err, q.Section)
+		return
+	}
+	if mr.RowCnt(res.RowsAffected()) {
+		mr.MaintenanceWindow = append(mr.MaintenanceWindow,
+			q.Maintenance)
+	}
+}
+
+// ShutdownNow signals the handler to shut down
+func (w *MaintenanceWrite) ShutdownNow() {
+	close(w.Shutdown)
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -0,0 +1,209 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package soma
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/mjolnir42/soma/internal/handler"
+	"github.com/mjolnir42/soma/internal/msg"
+	"github.com/mjolnir42/soma/internal/stmt"
+	metrics "github.com/rcrowley/go-metrics"
+)
+
+// MetricsCollector periodically updates the gauges in the soma
+// metrics registry that are not updated by the handlers themselves
+type MetricsCollector struct {
+	Shutdown     chan struct{}
+	conn         *sql.DB
+	stmtWorkflow *sql.Stmt
+	stmtOutbox   *sql.Stmt
+	stmtLatency  *sql.Stmt
+	collected    map[string]bool
+	appLog       *logrus.Logger
+	reqLog       *logrus.Logger
+	errLog       *logrus.Logger
+	soma         *Soma
+}
+
+// newMetricsCollector returns a new MetricsCollector handler
+func newMetricsCollector(s *Soma) (m *MetricsCollector) {
+	m = &MetricsCollector{}
+	m.Shutdown = make(chan struct{})
+	m.collected = make(map[string]bool)
+	m.soma = s
+	return
+}
+
+// Register initializes resources provided by the Soma app
+func (m *MetricsCollector) Register(c *sql.DB, l ...*logrus.Logger) {
+	m.conn = c
+	m.appLog = l[0]
+	m.reqLog = l[1]
+	m.errLog = l[2]
+}
+
+// Intake exposes a dummy channel required to fulfull the Handler
+// interface
+func (m *MetricsCollector) Intake() chan msg.Request {
+	c := make(chan msg.Request)
+	return c
+}
+
+// PriorityIntake aliases Intake as part of the handler interface
+func (m *MetricsCollector) PriorityIntake() chan msg.Request {
+	return m.Intake()
+}
+
+// RegisterRequests links the handler inside the handlermap to the requests
+// it processes. For MetricsCollector this is a dummy method to fulfill
+// the handler.Handler interface
+func (m *MetricsCollector) RegisterRequests(hmap *handler.Map) {
+}
+
+// Run is the event loop for MetricsCollector
+func (m *MetricsCollector) Run() {
+	var err error
+
+	for statement, prepStmt := range map[string]**sql.Stmt{
+		stmt.CollectorWorkflowStates: &m.stmtWorkflow,
+		stmt.CollectorOutboxBacklog:  &m.stmtOutbox,
+		stmt.CollectorJobLatency:     &m.stmtLatency,
+	} {
+		if *prepStmt, err = m.conn.Prepare(statement); err != nil {
+			m.errLog.Fatal(`metrics_collector`, err, stmt.Name(statement))
+		}
+		defer (*prepStmt).Close()
+	}
+
+	tick := time.NewTicker(
+		time.Duration(m.soma.conf.Metrics.CollectInterval) * time.Second,
+	)
+	defer tick.Stop()
+
+	m.collect()
+runloop:
+	for {
+		select {
+		case <-m.Shutdown:
+			break runloop
+		case <-tick.C:
+			m.collect()
+		}
+	}
+}
+
+// collect updates all collected gauges. Gauges that were not updated,
+// for example for a handler that has been stopped, are removed.
+func (m *MetricsCollector) collect() {
+	current := make(map[string]bool)
+
+	for name, depth := range m.soma.handlerMap.QueueDepth() {
+		m.gauge(current, labeledMetric(`.handler.queue.depth`,
+			`handler`, name), float64(depth))
+	}
+
+	for _, query := range []struct {
+		stmt   *sql.Stmt
+		metric string
+		label  string
+		args   []interface{}
+	}{
+		{m.stmtWorkflow, `.workflow.instances`, `status`, nil},
+		{m.stmtOutbox, `.outbox.backlog`, `monitoring`, nil},
+		{m.stmtLatency, `.job.latency.seconds`, `repository`,
+			[]interface{}{int64(m.soma.conf.Metrics.CollectInterval)}},
+	} {
+		if err := m.query(current, query.stmt, query.metric,
+			query.label, query.args...); err != nil {
+			m.errLog.Println(`MetricsCollector.collect()`, err)
+			// keep the previous values of this query
+			m.keep(current, query.metric)
+		}
+	}
+
+	for name := range m.collected {
+		if !current[name] {
+			Metrics[`soma`].Unregister(name)
+		}
+	}
+	m.collected = current
+}
+
+// query runs statement s and updates the gauge metric labeled by the
+// first result column with the value of the second result column
+func (m *MetricsCollector) query(current map[string]bool, s *sql.Stmt,
+	metric, label string, args ...interface{}) error {
+	var (
+		err   error
+		rows  *sql.Rows
+		key   string
+		value float64
+	)
+
+	if rows, err = s.Query(args...); err != nil {
+		return err
+	}
+
+	for rows.Next() {
+		if err = rows.Scan(
+			&key,
+			&value,
+		); err != nil {
+			rows.Close()
+			return err
+		}
+		m.gauge(current, labeledMetric(metric, label, key), value)
+	}
+	return rows.Err()
+}
+
+// gauge sets the value of gauge name and records it as collected
+func (m *MetricsCollector) gauge(current map[string]bool, name string,
+	value float64) {
+	metrics.GetOrRegisterGaugeFloat64(name, Metrics[`soma`]).Update(value)
+	current[name] = true
+}
+
+// keep records all previously collected gauges of metric as
+// collected
+func (m *MetricsCollector) keep(current map[string]bool, metric string) {
+	for name := range m.collected {
+		if strings.HasPrefix(name, metric+`{`) {
+			current[name] = true
+		}
+	}
+}
+
+// ShutdownNow signals the handler to shutdown
+func (m *MetricsCollector) ShutdownNow() {
+	close(m.Shutdown)
+}
+
+// promLabelEscaper escapes label values for the Prometheus text
+// format
+var promLabelEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	`"`, `\"`,
+	"\n", `\n`,
+)
+
+// labeledMetric returns the name of metric with a label attached.
+// The metrics exporter renders these as labeled metrics.
+func labeledMetric(metric, label, value string) string {
+	return fmt.Sprintf("%s{%s=\"%s\"}", metric, label,
+		promLabelEscaper.Replace(value))
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -0,0 +1,33 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package soma
+
+import "testing"
+
+func TestLabeledMetric(t *testing.T) {
+	tests := []struct {
+		value, expected string
+	}{
+		{`job_r`, `.handler.queue.depth{handler="job_r"}`},
+		{`a"b`, `.handler.queue.depth{handler="a\"b"}`},
+		{`a\b`, `.handler.queue.depth{handler="a\\b"}`},
+		{"a\nb", `.handler.queue.depth{handler="a\nb"}`},
+		{"ä\tb", ".handler.queue.depth{handler=\"ä\tb\"}"},
+	}
+
+	for _, test := range tests {
+		if name := labeledMetric(`.handler.queue.depth`, `handler`,
+			test.value); name != test.expected {
+			t.Errorf("Expected %s, got %s", test.expected, name)
+		}
+	}
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
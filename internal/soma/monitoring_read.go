@@ -213,6 +213,7 @@ func (r *MonitoringRead) show(q *msg.Request, mr *msg.Result) {
 		contact, teamID          string
 		callbackNull             sql.NullString
 		callback                 string
+		batchSize, batchFlush    int64
 	)
 	if err = r.stmtShow.QueryRow(
 		q.Monitoring.ID,
@@ -223,6 +224,8 @@ func (r *MonitoringRead) show(q *msg.Request, mr *msg.Result) {
 		&contact,
 		&teamID,
 		&callbackNull,
+		&batchSize,
+		&batchFlush,
 	); err == sql.ErrNoRows {
 		mr.NotFound(err, q.Section)
 		return
@@ -235,12 +238,14 @@ func (r *MonitoringRead) show(q *msg.Request, mr *msg.Result) {
 		callback = callbackNull.String
 	}
 	mr.Monitoring = append(mr.Monitoring, proto.Monitoring{
-		ID:       monitoringID,
-		Name:     name,
-		Mode:     mode,
-		Contact:  contact,
-		TeamID:   teamID,
-		Callback: callback,
+		ID:         monitoringID,
+		Name:       name,
+		Mode:       mode,
+		Contact:    contact,
+		TeamID:     teamID,
+		Callback:   callback,
+		BatchSize:  uint64(batchSize),
+		BatchFlush: uint64(batchFlush),
 	})
 	mr.OK()
 }
@@ -9,7 +9,9 @@
 package soma // import "github.com/mjolnir42/soma/internal/soma"
 
 import (
+	"crypto/rand"
 	"database/sql"
+	"encoding/hex"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/mjolnir42/soma/internal/handler"
@@ -26,6 +28,7 @@ type MonitoringWrite struct {
 	conn        *sql.DB
 	stmtCreate  *sql.Stmt
 	stmtDelete  *sql.Stmt
+	stmtSecret  *sql.Stmt
 	appLog      *logrus.Logger
 	reqLog      *logrus.Logger
 	errLog      *logrus.Logger
@@ -55,6 +58,7 @@ func (w *MonitoringWrite) RegisterRequests(hmap *handler.Map) {
 	for _, action := range []string{
 		msg.ActionAdd,
 		msg.ActionRemove,
+		msg.ActionUpdate,
 	} {
 		hmap.Request(msg.SectionMonitoringMgmt, action, w.handlerName)
 	}
@@ -77,6 +81,7 @@ func (w *MonitoringWrite) Run() {
 	for statement, prepStmt := range map[string]**sql.Stmt{
 		stmt.MonitoringSystemAdd:    &w.stmtCreate,
 		stmt.MonitoringSystemRemove: &w.stmtDelete,
+		stmt.MonitoringSystemSecret: &w.stmtSecret,
 	} {
 		if *prepStmt, err = w.conn.Prepare(statement); err != nil {
 			w.errLog.Fatal(`monitoring`, err, stmt.Name(statement))
@@ -105,6 +110,8 @@ func (w *MonitoringWrite) process(q *msg.Request) {
 		w.add(q, &result)
 	case msg.ActionRemove:
 		w.remove(q, &result)
+	case msg.ActionUpdate:
+		w.secret(q, &result)
 	default:
 		result.UnknownRequest(q)
 	}
@@ -118,6 +125,7 @@ func (w *MonitoringWrite) add(q *msg.Request, mr *msg.Result) {
 		err      error
 		res      sql.Result
 		callback sql.NullString
+		secret   sql.NullString
 	)
 
 	q.Monitoring.ID = uuid.Must(uuid.NewV4()).String()
@@ -127,6 +135,12 @@ func (w *MonitoringWrite) add(q *msg.Request, mr *msg.Result) {
 			Valid:  true,
 		}
 	}
+	if q.Monitoring.Secret != `` {
+		secret = sql.NullString{
+			String: q.Monitoring.Secret,
+			Valid:  true,
+		}
+	}
 	if res, err = w.stmtCreate.Exec(
 		q.Monitoring.ID,
 		q.Monitoring.Name,
@@ -134,10 +148,15 @@ func (w *MonitoringWrite) add(q *msg.Request, mr *msg.Result) {
 		q.Monitoring.Contact,
 		q.Monitoring.TeamID,
 		callback,
+		int64(q.Monitoring.BatchSize),
+		int64(q.Monitoring.BatchFlush),
+		secret,
 	); err != nil {
 		mr.ServerError(err, q.Section)
 		return
 	}
+	// the secret was provided by the client and is not sent back
+	q.Monitoring.Secret = ``
 	if mr.RowCnt(res.RowsAffected()) {
 		mr.Monitoring = append(mr.Monitoring, q.Monitoring)
 	}
@@ -161,6 +180,41 @@ func (w *MonitoringWrite) remove(q *msg.Request, mr *msg.Result) {
 	}
 }
 
+// secret sets the shared secret of a monitoring system. If no secret
+// was provided, a random one is generated. The secret is only
+// returned to the client if it was generated by SOMA.
+func (w *MonitoringWrite) secret(q *msg.Request, mr *msg.Result) {
+	var (
+		err       error
+		res       sql.Result
+		generated bool
+	)
+
+	if q.Monitoring.Secret == `` {
+		buf := make([]byte, 32)
+		if _, err = rand.Read(buf); err != nil {
+			mr.ServerError(err, q.Section)
+			return
+		}
+		q.Monitoring.Secret = hex.EncodeToString(buf)
+		generated = true
+	}
+
+	if res, err = w.stmtSecret.Exec(
+		q.Monitoring.ID,
+		q.Monitoring.Secret,
+	); err != nil {
+		mr.ServerError(err, q.Section)
+		return
+	}
+	if !generated {
+		q.Monitoring.Secret = ``
+	}
+	if mr.RowCnt(res.RowsAffected()) {
+		mr.Monitoring = append(mr.Monitoring, q.Monitoring)
+	}
+}
+
 // ShutdownNow signals the handler to shut down
 func (w *MonitoringWrite) ShutdownNow() {
 	close(w.Shutdown)
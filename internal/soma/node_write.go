@@ -15,6 +15,7 @@ import (
 	"github.com/mjolnir42/soma/internal/handler"
 	"github.com/mjolnir42/soma/internal/msg"
 	"github.com/mjolnir42/soma/internal/stmt"
+	"github.com/mjolnir42/soma/lib/proto"
 	uuid "github.com/satori/go.uuid"
 )
 
@@ -28,18 +29,22 @@ type NodeWrite struct {
 	stmtPurge   *sql.Stmt
 	stmtRemove  *sql.Stmt
 	stmtUpdate  *sql.Stmt
+	stmtBucket  *sql.Stmt
+	stmtServer  *sql.Stmt
 	appLog      *logrus.Logger
 	reqLog      *logrus.Logger
 	errLog      *logrus.Logger
+	soma        *Soma
 }
 
 // newNodeWrite return a new NodeWrite handler with input buffer of
 // length
-func newNodeWrite(length int) (string, *NodeWrite) {
+func newNodeWrite(length int, s *Soma) (string, *NodeWrite) {
 	w := &NodeWrite{}
 	w.handlerName = generateHandlerName() + `_w`
 	w.Input = make(chan msg.Request, length)
 	w.Shutdown = make(chan struct{})
+	w.soma = s
 	return w.handlerName, w
 }
 
@@ -79,10 +84,12 @@ func (w *NodeWrite) Run() {
 	var err error
 
 	for statement, prepStmt := range map[string]**sql.Stmt{
-		stmt.NodeAdd:    &w.stmtAdd,
-		stmt.NodeUpdate: &w.stmtUpdate,
-		stmt.NodeRemove: &w.stmtRemove,
-		stmt.NodePurge:  &w.stmtPurge,
+		stmt.NodeAdd:      &w.stmtAdd,
+		stmt.NodeUpdate:   &w.stmtUpdate,
+		stmt.NodeRemove:   &w.stmtRemove,
+		stmt.NodePurge:    &w.stmtPurge,
+		stmt.NodeBucketID: &w.stmtBucket,
+		stmt.NodeServerID: &w.stmtServer,
 	} {
 		if *prepStmt, err = w.conn.Prepare(statement); err != nil {
 			w.errLog.Fatal(`node`, err, stmt.Name(statement))
@@ -169,13 +176,27 @@ func (w *NodeWrite) remove(q *msg.Request, mr *msg.Result) {
 	}
 }
 
-// update refreshes a node
+// update refreshes a node. If the node is moved to a different
+// server, the update is forwarded to the repository of the node.
 func (w *NodeWrite) update(q *msg.Request, mr *msg.Result) {
 	var (
-		err error
-		res sql.Result
+		err      error
+		res      sql.Result
+		serverID string
 	)
 
+	if err = w.stmtServer.QueryRow(
+		q.Node.ID,
+	).Scan(
+		&serverID,
+	); err == sql.ErrNoRows {
+		mr.NotFound(err, q.Section)
+		return
+	} else if err != nil {
+		mr.ServerError(err, q.Section)
+		return
+	}
+
 	if res, err = w.stmtUpdate.Exec(
 		q.Update.Node.AssetID,
 		q.Update.Node.Name,
@@ -190,9 +211,43 @@ func (w *NodeWrite) update(q *msg.Request, mr *msg.Result) {
 	}
 	if mr.RowCnt(res.RowsAffected()) {
 		mr.Node = append(mr.Node, q.Node)
+		prev, _ := uuid.FromString(serverID)
+		curr, _ := uuid.FromString(q.Update.Node.ServerID)
+		if !uuid.Equal(prev, curr) {
+			w.forward(q)
+		}
 	}
 }
 
+// forward hands the update of an assigned node to the GuidePost, so
+// the check instances of the node can be recomputed
+func (w *NodeWrite) forward(q *msg.Request) {
+	var bucketID string
+
+	if err := w.stmtBucket.QueryRow(
+		q.Node.ID,
+	).Scan(
+		&bucketID,
+	); err == sql.ErrNoRows {
+		return
+	} else if err != nil {
+		w.errLog.Printf("Failed to forward update of node %s: %s",
+			q.Node.ID, err.Error())
+		return
+	}
+
+	fwd := *q
+	fwd.Node.Config = &proto.NodeConfig{BucketID: bucketID}
+	fwd.Reply = make(chan msg.Result, 1)
+	go func() {
+		w.soma.handlerMap.Get(`guidepost`).(*GuidePost).Input <- fwd
+		if result := <-fwd.Reply; result.Error != nil {
+			w.errLog.Printf("Failed to forward update of node %s: %v",
+				fwd.Node.ID, result.Error)
+		}
+	}()
+}
+
 // purge removes a node flagged as deleted
 func (w *NodeWrite) purge(q *msg.Request, mr *msg.Result) {
 	var (
@@ -0,0 +1,178 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package soma // import "github.com/mjolnir42/soma/internal/soma"
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/lib/pq"
+	"github.com/mjolnir42/soma/internal/handler"
+	"github.com/mjolnir42/soma/internal/msg"
+	"github.com/mjolnir42/soma/internal/stmt"
+	"github.com/mjolnir42/soma/lib/proto"
+)
+
+// OutboxRead handles read requests for the notification outbox
+type OutboxRead struct {
+	Input       chan msg.Request
+	Shutdown    chan struct{}
+	handlerName string
+	conn        *sql.DB
+	stmtList    *sql.Stmt
+	appLog      *logrus.Logger
+	reqLog      *logrus.Logger
+	errLog      *logrus.Logger
+}
+
+// newOutboxRead return a new OutboxRead handler with input buffer
+// of length
+func newOutboxRead(length int) (string, *OutboxRead) {
+	r := &OutboxRead{}
+	r.handlerName = generateHandlerName() + `_r`
+	r.Input = make(chan msg.Request, length)
+	r.Shutdown = make(chan struct{})
+	return r.handlerName, r
+}
+
+// Register initializes resources provided by the Soma app
+func (r *OutboxRead) Register(c *sql.DB, l ...*logrus.Logger) {
+	r.conn = c
+	r.appLog = l[0]
+	r.reqLog = l[1]
+	r.errLog = l[2]
+}
+
+// RegisterRequests links the handler inside the handlermap to the requests
+// it processes
+func (r *OutboxRead) RegisterRequests(hmap *handler.Map) {
+	for _, action := range []string{
+		msg.ActionList,
+	} {
+		hmap.Request(msg.SectionOutbox, action, r.handlerName)
+	}
+}
+
+// Intake exposes the Input channel as part of the handler interface
+func (r *OutboxRead) Intake() chan msg.Request {
+	return r.Input
+}
+
+// PriorityIntake aliases Intake as part of the handler interface
+func (r *OutboxRead) PriorityIntake() chan msg.Request {
+	return r.Intake()
+}
+
+// Run is the event loop for OutboxRead
+func (r *OutboxRead) Run() {
+	var err error
+
+	for statement, prepStmt := range map[string]**sql.Stmt{
+		stmt.OutboxList: &r.stmtList,
+	} {
+		if *prepStmt, err = r.conn.Prepare(statement); err != nil {
+			r.errLog.Fatal(`outbox_r`, err, stmt.Name(statement))
+		}
+		defer (*prepStmt).Close()
+	}
+
+runloop:
+	for {
+		select {
+		case <-r.Shutdown:
+			break runloop
+		case req := <-r.Input:
+			go func() {
+				r.process(&req)
+			}()
+		}
+	}
+}
+
+// process is the request dispatcher
+func (r *OutboxRead) process(q *msg.Request) {
+	result := msg.FromRequest(q)
+	logRequest(r.reqLog, q)
+
+	switch q.Action {
+	case msg.ActionList:
+		r.list(q, &result)
+	default:
+		result.UnknownRequest(q)
+	}
+
+	q.Reply <- result
+}
+
+// list returns all undelivered notifications of a monitoring system
+func (r *OutboxRead) list(q *msg.Request, mr *msg.Result) {
+	var (
+		err                                  error
+		rows                                 *sql.Rows
+		notifyID, monitoringID, chkID, state string
+		attempts                             int64
+		lastError                            sql.NullString
+		createdAt                            time.Time
+		nextAttemptNull                      pq.NullTime
+	)
+
+	if rows, err = r.stmtList.Query(
+		q.Monitoring.ID,
+	); err != nil {
+		mr.ServerError(err, q.Section)
+		return
+	}
+
+	for rows.Next() {
+		if err = rows.Scan(
+			&notifyID,
+			&monitoringID,
+			&chkID,
+			&state,
+			&attempts,
+			&lastError,
+			&createdAt,
+			&nextAttemptNull,
+		); err != nil {
+			rows.Close()
+			mr.ServerError(err, q.Section)
+			return
+		}
+		notification := proto.Notification{
+			ID:              notifyID,
+			MonitoringID:    monitoringID,
+			CheckInstanceID: chkID,
+			State:           state,
+			Attempts:        uint64(attempts),
+			CreatedAt:       createdAt.UTC().Format(msg.RFC3339Milli),
+		}
+		if lastError.Valid {
+			notification.LastError = lastError.String
+		}
+		if nextAttemptNull.Valid && state == proto.NotificationPending {
+			notification.NextAttemptAt = nextAttemptNull.
+				Time.UTC().Format(msg.RFC3339Milli)
+		}
+		mr.Notification = append(mr.Notification, notification)
+	}
+	if err = rows.Err(); err != nil {
+		mr.ServerError(err, q.Section)
+		return
+	}
+	mr.OK()
+}
+
+// ShutdownNow signals the handler to shut down
+func (r *OutboxRead) ShutdownNow() {
+	close(r.Shutdown)
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
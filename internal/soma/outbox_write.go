@@ -0,0 +1,163 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package soma // import "github.com/mjolnir42/soma/internal/soma"
+
+import (
+	"database/sql"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/mjolnir42/soma/internal/handler"
+	"github.com/mjolnir42/soma/internal/msg"
+	"github.com/mjolnir42/soma/internal/stmt"
+)
+
+// OutboxWrite handles write requests for the notification outbox
+type OutboxWrite struct {
+	Input       chan msg.Request
+	Shutdown    chan struct{}
+	handlerName string
+	conn        *sql.DB
+	stmtReplay  *sql.Stmt
+	stmtPurge   *sql.Stmt
+	appLog      *logrus.Logger
+	reqLog      *logrus.Logger
+	errLog      *logrus.Logger
+}
+
+// newOutboxWrite return a new OutboxWrite handler with input buffer
+// of length
+func newOutboxWrite(length int) (string, *OutboxWrite) {
+	w := &OutboxWrite{}
+	w.handlerName = generateHandlerName() + `_w`
+	w.Input = make(chan msg.Request, length)
+	w.Shutdown = make(chan struct{})
+	return w.handlerName, w
+}
+
+// Register initializes resources provided by the Soma app
+func (w *OutboxWrite) Register(c *sql.DB, l ...*logrus.Logger) {
+	w.conn = c
+	w.appLog = l[0]
+	w.reqLog = l[1]
+	w.errLog = l[2]
+}
+
+// RegisterRequests links the handler inside the handlermap to the requests
+// it processes
+func (w *OutboxWrite) RegisterRequests(hmap *handler.Map) {
+	for _, action := range []string{
+		msg.ActionReplay,
+		msg.ActionPurge,
+	} {
+		hmap.Request(msg.SectionOutbox, action, w.handlerName)
+	}
+}
+
+// Intake exposes the Input channel as part of the handler interface
+func (w *OutboxWrite) Intake() chan msg.Request {
+	return w.Input
+}
+
+// PriorityIntake aliases Intake as part of the handler interface
+func (w *OutboxWrite) PriorityIntake() chan msg.Request {
+	return w.Intake()
+}
+
+// Run is the event loop for OutboxWrite
+func (w *OutboxWrite) Run() {
+	var err error
+
+	for statement, prepStmt := range map[string]**sql.Stmt{
+		stmt.OutboxReplay: &w.stmtReplay,
+		stmt.OutboxPurge:  &w.stmtPurge,
+	} {
+		if *prepStmt, err = w.conn.Prepare(statement); err != nil {
+			w.errLog.Fatal(`outbox_w`, err, stmt.Name(statement))
+		}
+		defer (*prepStmt).Close()
+	}
+
+runloop:
+	for {
+		select {
+		case <-w.Shutdown:
+			break runloop
+		case req := <-w.Input:
+			w.process(&req)
+		}
+	}
+}
+
+// process is the request dispatcher
+func (w *OutboxWrite) process(q *msg.Request) {
+	result := msg.FromRequest(q)
+	logRequest(w.reqLog, q)
+
+	switch q.Action {
+	case msg.ActionReplay:
+		w.replay(q, &result)
+	case msg.ActionPurge:
+		w.purge(q, &result)
+	default:
+		result.UnknownRequest(q)
+	}
+
+	q.Reply <- result
+}
+
+// replay resets all undelivered notifications of a monitoring system,
+// including dead letters, for immediate redelivery
+func (w *OutboxWrite) replay(q *msg.Request, mr *msg.Result) {
+	var (
+		err error
+		res sql.Result
+	)
+
+	if res, err = w.stmtReplay.Exec(
+		q.Monitoring.ID,
+	); err != nil {
+		mr.ServerError(err, q.Section)
+		return
+	}
+	// replaying an empty outbox is not an error
+	if _, err = res.RowsAffected(); err != nil {
+		mr.ServerError(err, q.Section)
+		return
+	}
+	mr.OK()
+}
+
+// purge removes all dead notifications of a monitoring system
+func (w *OutboxWrite) purge(q *msg.Request, mr *msg.Result) {
+	var (
+		err error
+		res sql.Result
+	)
+
+	if res, err = w.stmtPurge.Exec(
+		q.Monitoring.ID,
+	); err != nil {
+		mr.ServerError(err, q.Section)
+		return
+	}
+	// purging an empty outbox is not an error
+	if _, err = res.RowsAffected(); err != nil {
+		mr.ServerError(err, q.Section)
+		return
+	}
+	mr.OK()
+}
+
+// ShutdownNow signals the handler to shut down
+func (w *OutboxWrite) ShutdownNow() {
+	close(w.Shutdown)
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -0,0 +1,228 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package soma
+
+import (
+	"database/sql"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/mjolnir42/soma/internal/handler"
+	"github.com/mjolnir42/soma/internal/msg"
+	"github.com/mjolnir42/soma/internal/stmt"
+	uuid "github.com/satori/go.uuid"
+)
+
+// RollbackWrite handles write requests for automatic rollback
+// policies of repositories and check configurations
+type RollbackWrite struct {
+	Input             chan msg.Request
+	Shutdown          chan struct{}
+	handlerName       string
+	conn              *sql.DB
+	stmtEnableRepo    *sql.Stmt
+	stmtDisableRepo   *sql.Stmt
+	stmtEnableConfig  *sql.Stmt
+	stmtDisableConfig *sql.Stmt
+	appLog            *logrus.Logger
+	reqLog            *logrus.Logger
+	errLog            *logrus.Logger
+}
+
+// newRollbackWrite returns a new RollbackWrite handler with input
+// buffer of length
+func newRollbackWrite(length int) (string, *RollbackWrite) {
+	w := &RollbackWrite{}
+	w.handlerName = generateHandlerName() + `_w`
+	w.Input = make(chan msg.Request, length)
+	w.Shutdown = make(chan struct{})
+	return w.handlerName, w
+}
+
+// Register initializes resources provided by the Soma app
+func (w *RollbackWrite) Register(c *sql.DB, l ...*logrus.Logger) {
+	w.conn = c
+	w.appLog = l[0]
+	w.reqLog = l[1]
+	w.errLog = l[2]
+}
+
+// RegisterRequests links the handler inside the handlermap to the requests
+// it processes
+func (w *RollbackWrite) RegisterRequests(hmap *handler.Map) {
+	for _, section := range []string{
+		msg.SectionCheckConfig,
+		msg.SectionRepositoryConfig,
+	} {
+		for _, action := range []string{
+			msg.ActionRollbackDisable,
+			msg.ActionRollbackEnable,
+		} {
+			hmap.Request(section, action, w.handlerName)
+		}
+	}
+}
+
+// Intake exposes the Input channel as part of the handler interface
+func (w *RollbackWrite) Intake() chan msg.Request {
+	return w.Input
+}
+
+// PriorityIntake aliases Intake as part of the handler interface
+func (w *RollbackWrite) PriorityIntake() chan msg.Request {
+	return w.Intake()
+}
+
+// Run is the event loop for RollbackWrite
+func (w *RollbackWrite) Run() {
+	var err error
+
+	for statement, prepStmt := range map[string]**sql.Stmt{
+		stmt.RollbackPolicyEnableRepository:  &w.stmtEnableRepo,
+		stmt.RollbackPolicyDisableRepository: &w.stmtDisableRepo,
+		stmt.RollbackPolicyEnableConfig:      &w.stmtEnableConfig,
+		stmt.RollbackPolicyDisableConfig:     &w.stmtDisableConfig,
+	} {
+		if *prepStmt, err = w.conn.Prepare(statement); err != nil {
+			w.errLog.Fatal(`rollback_w`, err, stmt.Name(statement))
+		}
+		defer (*prepStmt).Close()
+	}
+
+runloop:
+	for {
+		select {
+		case <-w.Shutdown:
+			break runloop
+		case req := <-w.Input:
+			w.process(&req)
+		}
+	}
+}
+
+// process is the request dispatcher
+func (w *RollbackWrite) process(q *msg.Request) {
+	result := msg.FromRequest(q)
+	logRequest(w.reqLog, q)
+
+	switch q.Section {
+	case msg.SectionRepositoryConfig:
+		switch q.Action {
+		case msg.ActionRollbackEnable:
+			w.enableRepository(q, &result)
+		case msg.ActionRollbackDisable:
+			w.disableRepository(q, &result)
+		default:
+			result.UnknownRequest(q)
+		}
+	case msg.SectionCheckConfig:
+		switch q.Action {
+		case msg.ActionRollbackEnable:
+			w.enableConfig(q, &result)
+		case msg.ActionRollbackDisable:
+			w.disableConfig(q, &result)
+		default:
+			result.UnknownRequest(q)
+		}
+	default:
+		result.UnknownRequest(q)
+	}
+
+	q.Reply <- result
+}
+
+// enableRepository enables automatic rollback of failed rollouts for
+// all check configurations of a repository
+func (w *RollbackWrite) enableRepository(q *msg.Request, mr *msg.Result) {
+	var (
+		err error
+		res sql.Result
+	)
+
+	if res, err = w.stmtEnableRepo.Exec(
+		uuid.Must(uuid.NewV4()).String(),
+		q.Repository.ID,
+		q.AuthUser,
+	); err != nil {
+		mr.ServerError(err, q.Section)
+		return
+	}
+	if mr.RowCnt(res.RowsAffected()) {
+		mr.Repository = append(mr.Repository, q.Repository)
+	}
+}
+
+// disableRepository disables the repository wide automatic rollback
+// policy. Policies for individual check configurations are not
+// affected.
+func (w *RollbackWrite) disableRepository(q *msg.Request, mr *msg.Result) {
+	var (
+		err error
+		res sql.Result
+	)
+
+	if res, err = w.stmtDisableRepo.Exec(
+		q.Repository.ID,
+	); err != nil {
+		mr.ServerError(err, q.Section)
+		return
+	}
+	if mr.RowCnt(res.RowsAffected()) {
+		mr.Repository = append(mr.Repository, q.Repository)
+	}
+}
+
+// enableConfig enables automatic rollback of failed rollouts for a
+// check configuration
+func (w *RollbackWrite) enableConfig(q *msg.Request, mr *msg.Result) {
+	var (
+		err error
+		res sql.Result
+	)
+
+	if res, err = w.stmtEnableConfig.Exec(
+		uuid.Must(uuid.NewV4()).String(),
+		q.CheckConfig.ID,
+		q.CheckConfig.RepositoryID,
+		q.AuthUser,
+	); err != nil {
+		mr.ServerError(err, q.Section)
+		return
+	}
+	if mr.RowCnt(res.RowsAffected()) {
+		mr.CheckConfig = append(mr.CheckConfig, q.CheckConfig)
+	}
+}
+
+// disableConfig disables the automatic rollback policy of a check
+// configuration
+func (w *RollbackWrite) disableConfig(q *msg.Request, mr *msg.Result) {
+	var (
+		err error
+		res sql.Result
+	)
+
+	if res, err = w.stmtDisableConfig.Exec(
+		q.CheckConfig.ID,
+		q.CheckConfig.RepositoryID,
+	); err != nil {
+		mr.ServerError(err, q.Section)
+		return
+	}
+	if mr.RowCnt(res.RowsAffected()) {
+		mr.CheckConfig = append(mr.CheckConfig, q.CheckConfig)
+	}
+}
+
+// ShutdownNow signals the handler to shut down
+func (w *RollbackWrite) ShutdownNow() {
+	close(w.Shutdown)
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -15,6 +15,7 @@ import (
 	"github.com/mjolnir42/soma/internal/handler"
 	"github.com/mjolnir42/soma/internal/msg"
 	"github.com/mjolnir42/soma/internal/stmt"
+	"github.com/mjolnir42/soma/lib/proto"
 	uuid "github.com/satori/go.uuid"
 )
 
@@ -28,18 +29,22 @@ type ServerWrite struct {
 	stmtRemove  *sql.Stmt
 	stmtPurge   *sql.Stmt
 	stmtUpdate  *sql.Stmt
+	stmtName    *sql.Stmt
+	stmtNodes   *sql.Stmt
 	appLog      *logrus.Logger
 	reqLog      *logrus.Logger
 	errLog      *logrus.Logger
+	soma        *Soma
 }
 
 // newServerWrite return a new ServerWrite handler with input buffer of
 // length
-func newServerWrite(length int) (string, *ServerWrite) {
+func newServerWrite(length int, s *Soma) (string, *ServerWrite) {
 	w := &ServerWrite{}
 	w.handlerName = generateHandlerName() + `_w`
 	w.Input = make(chan msg.Request, length)
 	w.Shutdown = make(chan struct{})
+	w.soma = s
 	return w.handlerName, w
 }
 
@@ -80,10 +85,12 @@ func (w *ServerWrite) Run() {
 	var err error
 
 	for statement, prepStmt := range map[string]**sql.Stmt{
-		stmt.AddServers:    &w.stmtAdd,
-		stmt.DeleteServers: &w.stmtRemove,
-		stmt.PurgeServers:  &w.stmtPurge,
-		stmt.UpdateServers: &w.stmtUpdate,
+		stmt.AddServers:          &w.stmtAdd,
+		stmt.DeleteServers:       &w.stmtRemove,
+		stmt.PurgeServers:        &w.stmtPurge,
+		stmt.UpdateServers:       &w.stmtUpdate,
+		stmt.ServerNameByID:      &w.stmtName,
+		stmt.ServerAssignedNodes: &w.stmtNodes,
 	} {
 		if *prepStmt, err = w.conn.Prepare(statement); err != nil {
 			w.errLog.Fatal(`server`, err, stmt.Name(statement))
@@ -215,13 +222,27 @@ func (w *ServerWrite) purge(q *msg.Request, mr *msg.Result) {
 	}
 }
 
-// update refreshes a servers details
+// update refreshes a servers details. If the server is renamed, the
+// update is forwarded to the repositories of the nodes on the server.
 func (w *ServerWrite) update(q *msg.Request, mr *msg.Result) {
 	var (
-		res sql.Result
-		err error
+		res  sql.Result
+		err  error
+		name string
 	)
 
+	if err = w.stmtName.QueryRow(
+		q.Server.ID,
+	).Scan(
+		&name,
+	); err == sql.ErrNoRows {
+		mr.NotFound(err, q.Section)
+		return
+	} else if err != nil {
+		mr.ServerError(err, q.Section)
+		return
+	}
+
 	if res, err = w.stmtUpdate.Exec(
 		q.Server.ID,
 		q.Update.Server.AssetID,
@@ -236,6 +257,72 @@ func (w *ServerWrite) update(q *msg.Request, mr *msg.Result) {
 	}
 	if mr.RowCnt(res.RowsAffected()) {
 		mr.Server = append(mr.Server, q.Server)
+		if name != q.Update.Server.Name {
+			w.forward(q)
+		}
+	}
+}
+
+// forward sends a node update for every node on the server that is
+// assigned to a bucket through the GuidePost, which moves the nodes
+// to the renamed server inside their trees
+func (w *ServerWrite) forward(q *msg.Request) {
+	var (
+		err              error
+		rows             *sql.Rows
+		nodeID, bucketID string
+	)
+
+	if rows, err = w.stmtNodes.Query(
+		q.Server.ID,
+	); err != nil {
+		w.errLog.Printf("Failed to forward update of server %s: %s",
+			q.Server.ID, err.Error())
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err = rows.Scan(
+			&nodeID,
+			&bucketID,
+		); err != nil {
+			w.errLog.Printf("Failed to forward update of server %s: %s",
+				q.Server.ID, err.Error())
+			return
+		}
+
+		fwd := msg.Request{
+			ID:         q.ID,
+			Section:    msg.SectionNodeMgmt,
+			Action:     msg.ActionUpdate,
+			RemoteAddr: q.RemoteAddr,
+			AuthUser:   q.AuthUser,
+			RequestURI: q.RequestURI,
+			Reply:      make(chan msg.Result, 1),
+			Node: proto.Node{
+				ID:     nodeID,
+				Config: &proto.NodeConfig{BucketID: bucketID},
+			},
+			Update: msg.UpdateData{
+				Node: proto.Node{
+					ID:       nodeID,
+					ServerID: q.Server.ID,
+				},
+			},
+		}
+		go func() {
+			w.soma.handlerMap.Get(`guidepost`).(*GuidePost).Input <- fwd
+			if result := <-fwd.Reply; result.Error != nil {
+				w.errLog.Printf("Failed to forward update of server"+
+					" %s to node %s: %v", q.Server.ID,
+					fwd.Node.ID, result.Error)
+			}
+		}()
+	}
+	if err = rows.Err(); err != nil {
+		w.errLog.Printf("Failed to forward update of server %s: %s",
+			q.Server.ID, err.Error())
 	}
 }
 
@@ -40,11 +40,13 @@ func (s *Soma) Start() {
 	s.handlerMap.Add(newJobStatusRead(s.conf.QueueLen))
 	s.handlerMap.Add(newJobTypeRead(s.conf.QueueLen))
 	s.handlerMap.Add(newLevelRead(s.conf.QueueLen))
+	s.handlerMap.Add(newMaintenanceRead(s.conf.QueueLen))
 	s.handlerMap.Add(newMetricRead(s.conf.QueueLen))
 	s.handlerMap.Add(newModeRead(s.conf.QueueLen))
 	s.handlerMap.Add(newMonitoringRead(s.conf.QueueLen))
 	s.handlerMap.Add(newNodeRead(s.conf.QueueLen))
 	s.handlerMap.Add(newOncallRead(s.conf.QueueLen))
+	s.handlerMap.Add(newOutboxRead(s.conf.QueueLen))
 	s.handlerMap.Add(newPredicateRead(s.conf.QueueLen))
 	s.handlerMap.Add(newPropertyRead(s.conf.QueueLen))
 	s.handlerMap.Add(newProviderRead(s.conf.QueueLen))
@@ -59,8 +61,10 @@ func (s *Soma) Start() {
 	s.handlerMap.Add(newValidityRead(s.conf.QueueLen))
 	s.handlerMap.Add(newViewRead(s.conf.QueueLen))
 	s.handlerMap.Add(newWorkflowRead(s.conf.QueueLen))
+	s.handlerMap.Add(`metrics_collector`, newMetricsCollector(s))
 
 	if !s.conf.ReadOnly {
+		s.handlerMap.Add(`deployment_stream`, newDeploymentStream(s.conf.QueueLen))
 		s.handlerMap.Add(`forest_custodian`, newForestCustodian(s.conf.QueueLen, s))
 		s.handlerMap.Add(`guidepost`, newGuidePost(s.conf.QueueLen, s))
 		s.handlerMap.Add(`lifecycle`, newLifeCycle(s))
@@ -69,8 +73,9 @@ func (s *Soma) Start() {
 			s.handlerMap.Add(newAdminWrite(s.conf.QueueLen, s))
 			s.handlerMap.Add(newAttributeWrite(s.conf.QueueLen))
 			s.handlerMap.Add(newCapabilityWrite(s.conf.QueueLen))
+			s.handlerMap.Add(newCheckConfigurationWrite(s.conf.QueueLen))
 			s.handlerMap.Add(newDatacenterWrite(s.conf.QueueLen))
-			s.handlerMap.Add(newDeploymentWrite(s.conf.QueueLen))
+			s.handlerMap.Add(newDeploymentWrite(s.conf.QueueLen, s))
 			s.handlerMap.Add(newEntityWrite(s.conf.QueueLen))
 			s.handlerMap.Add(newEnvironmentWrite(s.conf.QueueLen))
 			s.handlerMap.Add(`job_block`, newJobBlock(s.conf.QueueLen))
@@ -78,15 +83,18 @@ func (s *Soma) Start() {
 			s.handlerMap.Add(newJobStatusWrite(s.conf.QueueLen))
 			s.handlerMap.Add(newJobTypeWrite(s.conf.QueueLen))
 			s.handlerMap.Add(newLevelWrite(s.conf.QueueLen))
+			s.handlerMap.Add(newMaintenanceWrite(s.conf.QueueLen))
 			s.handlerMap.Add(newMetricWrite(s.conf.QueueLen))
 			s.handlerMap.Add(newModeWrite(s.conf.QueueLen))
 			s.handlerMap.Add(newMonitoringWrite(s.conf.QueueLen))
-			s.handlerMap.Add(newNodeWrite(s.conf.QueueLen))
+			s.handlerMap.Add(newNodeWrite(s.conf.QueueLen, s))
 			s.handlerMap.Add(newOncallWrite(s.conf.QueueLen))
+			s.handlerMap.Add(newOutboxWrite(s.conf.QueueLen))
 			s.handlerMap.Add(newPredicateWrite(s.conf.QueueLen))
 			s.handlerMap.Add(newPropertyWrite(s.conf.QueueLen))
 			s.handlerMap.Add(newProviderWrite(s.conf.QueueLen))
-			s.handlerMap.Add(newServerWrite(s.conf.QueueLen))
+			s.handlerMap.Add(newRollbackWrite(s.conf.QueueLen))
+			s.handlerMap.Add(newServerWrite(s.conf.QueueLen, s))
 			s.handlerMap.Add(newStateWrite(s.conf.QueueLen))
 			s.handlerMap.Add(newStatusWrite(s.conf.QueueLen))
 			s.handlerMap.Add(newTeamWrite(s.conf.QueueLen, s))
@@ -7,10 +7,63 @@
 
 package soma
 
-import uuid "github.com/satori/go.uuid"
+import (
+	"database/sql"
+	"strconv"
+
+	"github.com/mjolnir42/soma/internal/msg"
+	"github.com/mjolnir42/soma/lib/auth"
+	"github.com/mjolnir42/soma/lib/proto"
+	"github.com/mjolnir42/soma/lib/threshold"
+	uuid "github.com/satori/go.uuid"
+)
 
 func generateHandlerName() string {
 	return uuid.Must(uuid.NewV4()).String()
 }
 
+// authenticateMonitoring verifies that the request authentication
+// data a was created with the monitoring system secret. Monitoring
+// systems without a configured secret are not authenticated.
+func authenticateMonitoring(a *msg.MonitoringAuth, secret sql.NullString) bool {
+	switch {
+	case !secret.Valid || secret.String == ``:
+		return true
+	case a.Signature != ``:
+		return auth.VerifyMonitoring(
+			[]byte(secret.String),
+			a.Method,
+			a.Path,
+			a.Timestamp,
+			a.Signature,
+			a.Body,
+		)
+	case a.Token != ``:
+		return auth.VerifyMonitoringToken(
+			[]byte(secret.String),
+			a.Token,
+		)
+	}
+	return false
+}
+
+// formatThresholdValue returns the database representation of the
+// value of thr, which is either the value or its value template
+func formatThresholdValue(thr *proto.CheckConfigThreshold) string {
+	if thr.ValueTemplate != `` {
+		return thr.ValueTemplate
+	}
+	return strconv.FormatInt(thr.Value, 10)
+}
+
+// splitThresholdValue splits the database representation s of a
+// threshold value into its value or its value template
+func splitThresholdValue(s string) (int64, string, error) {
+	if threshold.IsTemplate(s) {
+		return 0, s, nil
+	}
+	value, err := strconv.ParseInt(s, 10, 64)
+	return value, ``, err
+}
+
 // vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
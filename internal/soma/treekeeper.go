@@ -51,6 +51,7 @@ type TreeKeeper struct {
 	stmtClusterOncall   *sql.Stmt
 	stmtClusterService  *sql.Stmt
 	stmtClusterSysProp  *sql.Stmt
+	stmtConfigStatus    *sql.Stmt
 	stmtDefaultDC       *sql.Stmt
 	stmtDelDuplicate    *sql.Stmt
 	stmtGetComputed     *sql.Stmt
@@ -60,12 +61,14 @@ type TreeKeeper struct {
 	stmtGroupOncall     *sql.Stmt
 	stmtGroupService    *sql.Stmt
 	stmtGroupSysProp    *sql.Stmt
+	stmtHoldJobs        *sql.Stmt
 	stmtList            *sql.Stmt
 	stmtNode            *sql.Stmt
 	stmtNodeCustProp    *sql.Stmt
 	stmtNodeOncall      *sql.Stmt
 	stmtNodeService     *sql.Stmt
 	stmtNodeSysProp     *sql.Stmt
+	stmtOverride        *sql.Stmt
 	stmtPkgs            *sql.Stmt
 	stmtTeam            *sql.Stmt
 	stmtThreshold       *sql.Stmt
@@ -86,6 +89,7 @@ type TreeKeeper struct {
 		requiresRebuild bool
 		rebuildLevel    string
 	}
+	held []msg.Request
 	soma *Soma
 }
 
@@ -144,6 +148,12 @@ func (tk *TreeKeeper) Run() {
 	c.Inc(1)
 	defer c.Dec(1)
 
+	// ticker to check if held jobs can be released
+	holdTick := time.NewTicker(
+		time.Duration(tk.soma.conf.LifeCycleTick) * time.Second,
+	)
+	defer holdTick.Stop()
+
 	// prepare statements early, some are used in tk.startupLoad()
 	var err error
 	for statement, prepStmt := range map[string]**sql.Stmt{
@@ -172,11 +182,14 @@ func (tk *TreeKeeper) Run() {
 		stmt.TxDeployDetailsNodeService:                &tk.stmtNodeService,
 		stmt.TxDeployDetailsProviders:                  &tk.stmtPkgs,
 		stmt.TxDeployDetailsTeam:                       &tk.stmtTeam,
+		stmt.TxDeployDetailsThresholdOverride:          &tk.stmtOverride,
 		stmt.TxDeployDetailsUpdate:                     &tk.stmtUpdate,
 		stmt.TreekeeperGetComputedDeployments:          &tk.stmtGetComputed,
 		stmt.TreekeeperGetPreviousDeployment:           &tk.stmtGetPrevious,
 		stmt.TreekeeperGetViewFromCapability:           &tk.stmtGetView,
 		stmt.TreekeeperStartJob:                        &tk.stmtStartJob,
+		stmt.TreekeeperUpdateConfigStatus:              &tk.stmtConfigStatus,
+		stmt.MaintenanceHoldJobs:                       &tk.stmtHoldJobs,
 	} {
 		if *prepStmt, err = tk.conn.Prepare(statement); err != nil {
 			tk.treeLog.Println("Error preparing SQL statement: ", err)
@@ -325,17 +338,32 @@ runloop:
 			tk.stop()
 			goto stopsign
 		case req := <-tk.Input:
-			tk.process(&req)
-			tk.soma.handlerMap.Get(`job_block`).(*JobBlock).Notify <- req.JobID.String()
-			if !tk.status.isFrozen {
-				// buildDeploymentDetails and orderDeploymentDetails can
-				// both mark the tree as broken if there was an error
-				// preparing required SQL statements
-				tk.buildDeploymentDetails()
-				if tk.status.isBroken {
-					goto broken
-				}
-				tk.orderDeploymentDetails()
+			if req.Flag.DryRun {
+				tk.dryRun(&req)
+				continue runloop
+			}
+			// jobs are held in order of arrival while a maintenance
+			// window holds the jobs of this repository
+			if len(tk.held) > 0 || tk.isHeld() {
+				tk.appLog.Printf("TK[%s]: holding job %s for maintenance",
+					tk.meta.repoName, req.JobID.String())
+				tk.held = append(tk.held, req)
+				continue runloop
+			}
+			tk.work(&req)
+			if tk.status.isBroken {
+				goto broken
+			}
+		case <-holdTick.C:
+			if len(tk.held) == 0 || tk.isHeld() {
+				continue runloop
+			}
+			tk.appLog.Printf("TK[%s]: releasing %d held jobs",
+				tk.meta.repoName, len(tk.held))
+			for len(tk.held) > 0 {
+				req := tk.held[0]
+				tk.held = tk.held[1:]
+				tk.work(&req)
 				if tk.status.isBroken {
 					goto broken
 				}
@@ -436,61 +464,7 @@ func (tk *TreeKeeper) process(q *msg.Request) {
 
 	tk.tree.Begin()
 
-	// q.Action == `rebuild` will fall through switch
-	switch {
-	// property requests
-	case q.Action == msg.ActionPropertyCreate:
-		tk.addProperty(q)
-	case q.Action == msg.ActionPropertyDestroy:
-		tk.rmProperty(q)
-	case q.Action == msg.ActionPropertyUpdate:
-		tk.updateProperty(q)
-	// check requests
-	case q.Section == msg.SectionCheckConfig && q.Action == msg.ActionCreate:
-		err = tk.addCheck(&q.CheckConfig)
-	case q.Section == msg.SectionCheckConfig && q.Action == msg.ActionDestroy:
-		err = tk.rmCheck(&q.CheckConfig)
-	// tree object: membership requests
-	case q.Action == msg.ActionMemberAssign && q.TargetEntity == msg.EntityNode:
-		tk.treeNode(q)
-	case q.Action == msg.ActionMemberUnassign && q.TargetEntity == msg.EntityNode:
-		tk.treeNode(q)
-	case q.Action == msg.ActionMemberAssign && q.TargetEntity == msg.EntityCluster:
-		tk.treeCluster(q)
-	case q.Action == msg.ActionMemberUnassign && q.TargetEntity == msg.EntityCluster:
-		tk.treeCluster(q)
-	case q.Action == msg.ActionMemberAssign && q.TargetEntity == msg.EntityGroup:
-		tk.treeGroup(q)
-	case q.Action == msg.ActionMemberUnassign && q.TargetEntity == msg.EntityGroup:
-		tk.treeGroup(q)
-	// tree object: create/destroy requests
-	case q.Section == msg.SectionNodeConfig && q.Action == msg.ActionAssign:
-		tk.treeNode(q)
-	case q.Section == msg.SectionNodeConfig && q.Action == msg.ActionUnassign:
-		tk.treeNode(q)
-	case q.Section == msg.SectionCluster && q.Action == msg.ActionCreate:
-		tk.treeCluster(q)
-	case q.Section == msg.SectionCluster && q.Action == msg.ActionDestroy:
-		tk.treeCluster(q)
-	case q.Section == msg.SectionGroup && q.Action == msg.ActionCreate:
-		tk.treeGroup(q)
-	case q.Section == msg.SectionGroup && q.Action == msg.ActionDestroy:
-		tk.treeGroup(q)
-	case q.Section == msg.SectionBucket && q.Action == msg.ActionCreate:
-		tk.treeBucket(q)
-	case q.Section == msg.SectionBucket && q.Action == msg.ActionDestroy:
-		tk.treeBucket(q)
-	case q.Section == msg.SectionRepository && q.Action == msg.ActionDestroy:
-		tk.treeRepository(q)
-	// tree object: rename requests
-	case q.Section == msg.SectionBucket && q.Action == msg.ActionRename:
-		tk.treeBucket(q)
-	case q.Section == msg.SectionRepository && q.Action == msg.ActionRename:
-		tk.treeRepository(q)
-	// tree object: repossession requests
-	case q.Section == msg.SectionRepository && q.Action == msg.ActionRepossess:
-		tk.treeRepository(q)
-	}
+	err = tk.apply(q)
 
 	// check if we accumulated an error in one of the switch cases
 	if err != nil {
@@ -529,6 +503,30 @@ func (tk *TreeKeeper) process(q *msg.Request) {
 		); err != nil {
 			goto bailout
 		}
+	case q.Section == msg.SectionCheckConfig && q.Action == msg.ActionOverrideSet:
+		// replace the threshold override on the object
+		if err = tk.txThresholdOverride(
+			q.CheckConfig,
+			stm,
+		); err != nil {
+			goto bailout
+		}
+	case q.Section == msg.SectionCheckConfig && q.Action == msg.ActionOverrideRemove:
+		// mark the threshold override on the object as deleted
+		if _, err = stm[`DeleteThresholdOverride`].Exec(
+			q.CheckConfig.ID,
+			q.CheckConfig.ThresholdOverrides[0].ObjectID,
+		); err != nil {
+			goto bailout
+		}
+	case q.Section == msg.SectionCheckConfig && q.Action == msg.ActionUpdate:
+		// replace interval and thresholds of the check configuration
+		if err = tk.txCheckConfigUpdate(
+			q.CheckConfig,
+			stm,
+		); err != nil {
+			goto bailout
+		}
 	case q.Section == msg.SectionRepository && q.Action == msg.ActionDestroy:
 		// mark all check configurations deleted if the repository is
 		// being destroyed
@@ -739,6 +737,108 @@ unauthorized:
 	return
 }
 
+// work processes the job q and updates the deployment details of the
+// repository afterwards
+func (tk *TreeKeeper) work(q *msg.Request) {
+	tk.process(q)
+	tk.soma.handlerMap.Get(`job_block`).(*JobBlock).Notify <- q.JobID.String()
+	if tk.status.isFrozen {
+		return
+	}
+	// buildDeploymentDetails and orderDeploymentDetails can both mark
+	// the tree as broken if there was an error preparing required SQL
+	// statements
+	tk.buildDeploymentDetails()
+	if tk.status.isBroken {
+		return
+	}
+	tk.orderDeploymentDetails()
+}
+
+// isHeld returns true if an active maintenance window holds the jobs
+// of the repository. Jobs are not held if this can not be determined.
+func (tk *TreeKeeper) isHeld() bool {
+	var count int
+	if err := tk.stmtHoldJobs.QueryRow(
+		tk.meta.repoID,
+	).Scan(&count); err != nil {
+		tk.treeLog.Printf("Failed to check maintenance windows: %s", err)
+		return false
+	}
+	return count > 0
+}
+
+// apply performs the changes requested by q on the tree. The
+// resulting actions and errors are sent into the tree's action and
+// error channels.
+func (tk *TreeKeeper) apply(q *msg.Request) (err error) {
+	// q.Action == `rebuild` will fall through switch
+	switch {
+	// property requests
+	case q.Action == msg.ActionPropertyCreate:
+		tk.addProperty(q)
+	case q.Action == msg.ActionPropertyDestroy:
+		tk.rmProperty(q)
+	case q.Action == msg.ActionPropertyUpdate:
+		tk.updateProperty(q)
+	// check requests
+	case q.Section == msg.SectionCheckConfig && q.Action == msg.ActionCreate:
+		err = tk.addCheck(&q.CheckConfig)
+	case q.Section == msg.SectionCheckConfig && q.Action == msg.ActionDestroy:
+		err = tk.rmCheck(&q.CheckConfig)
+	case q.Section == msg.SectionCheckConfig && q.Action == msg.ActionOverrideSet:
+		err = tk.setThresholdOverride(&q.CheckConfig)
+	case q.Section == msg.SectionCheckConfig && q.Action == msg.ActionOverrideRemove:
+		err = tk.rmThresholdOverride(&q.CheckConfig)
+	case q.Section == msg.SectionCheckConfig && q.Action == msg.ActionUpdate:
+		err = tk.updateCheck(&q.CheckConfig)
+	// tree object: membership requests
+	case q.Action == msg.ActionMemberAssign && q.TargetEntity == msg.EntityNode:
+		tk.treeNode(q)
+	case q.Action == msg.ActionMemberUnassign && q.TargetEntity == msg.EntityNode:
+		tk.treeNode(q)
+	case q.Action == msg.ActionMemberAssign && q.TargetEntity == msg.EntityCluster:
+		tk.treeCluster(q)
+	case q.Action == msg.ActionMemberUnassign && q.TargetEntity == msg.EntityCluster:
+		tk.treeCluster(q)
+	case q.Action == msg.ActionMemberAssign && q.TargetEntity == msg.EntityGroup:
+		tk.treeGroup(q)
+	case q.Action == msg.ActionMemberUnassign && q.TargetEntity == msg.EntityGroup:
+		tk.treeGroup(q)
+	// tree object: create/destroy requests
+	case q.Section == msg.SectionNodeConfig && q.Action == msg.ActionAssign:
+		tk.treeNode(q)
+	case q.Section == msg.SectionNodeConfig && q.Action == msg.ActionUnassign:
+		tk.treeNode(q)
+	// tree object: update requests
+	case q.Section == msg.SectionNodeMgmt && q.Action == msg.ActionUpdate:
+		tk.treeNode(q)
+	case q.Section == msg.SectionCluster && q.Action == msg.ActionCreate:
+		tk.treeCluster(q)
+	case q.Section == msg.SectionCluster && q.Action == msg.ActionDestroy:
+		tk.treeCluster(q)
+	case q.Section == msg.SectionGroup && q.Action == msg.ActionCreate:
+		tk.treeGroup(q)
+	case q.Section == msg.SectionGroup && q.Action == msg.ActionDestroy:
+		tk.treeGroup(q)
+	case q.Section == msg.SectionBucket && q.Action == msg.ActionCreate:
+		tk.treeBucket(q)
+	case q.Section == msg.SectionBucket && q.Action == msg.ActionDestroy:
+		tk.treeBucket(q)
+	case q.Section == msg.SectionRepository && q.Action == msg.ActionDestroy:
+		tk.treeRepository(q)
+	// tree object: rename requests
+	case q.Section == msg.SectionBucket && q.Action == msg.ActionRename:
+		tk.treeBucket(q)
+	case q.Section == msg.SectionRepository && q.Action == msg.ActionRename:
+		tk.treeRepository(q)
+	// tree object: repossession requests
+	case q.Section == msg.SectionRepository && q.Action == msg.ActionRepossess:
+		tk.treeRepository(q)
+	}
+	return
+}
+
 // ShutdownNow signals the handler to shut down
 func (tk *TreeKeeper) ShutdownNow() {
 	if !tk.isStopped() {
@@ -876,6 +976,13 @@ func (tk *TreeKeeper) startTx() (
 		`CreateCheckConfigurationConstraintCustom`:    stmt.TxCreateCheckConfigurationConstraintCustom,
 		`CreateCheckConfigurationConstraintService`:   stmt.TxCreateCheckConfigurationConstraintService,
 		`CreateCheckConfigurationConstraintAttribute`: stmt.TxCreateCheckConfigurationConstraintAttribute,
+		`CreateCheckConfigurationRollout`:             stmt.TxCreateCheckConfigurationRollout,
+		`CreateCheckConfigurationConstraintGroups`:    stmt.TxCreateCheckConfigurationConstraintGroups,
+		`DeleteCheckConfigurationThresholds`:          stmt.TxDeleteCheckConfigurationThresholds,
+		`UpdateCheckConfigurationInterval`:            stmt.TxUpdateCheckConfigurationInterval,
+		`CreateThresholdOverride`:                     stmt.TxCreateThresholdOverride,
+		`CreateThresholdOverrideThreshold`:            stmt.TxCreateThresholdOverrideThreshold,
+		`DeleteThresholdOverride`:                     stmt.TxMarkThresholdOverrideDeleted,
 	} {
 		if stMap[name], err = tx.Prepare(statement); err != nil {
 			err = fmt.Errorf("tk.Prepare(%s) error: %s",
@@ -31,6 +31,10 @@ func (tk *TreeKeeper) buildDeploymentDetails() {
 deploymentbuilder:
 	for rows.Next() {
 		detail := proto.Deployment{}
+		overrideID := sql.NullString{}
+		overrideObjID := sql.NullString{}
+		overrideObjType := sql.NullString{}
+		thresholdValues := sql.NullString{}
 
 		err = rows.Scan(
 			&instanceCfgID,
@@ -54,7 +58,27 @@ deploymentbuilder:
 			&detail.CheckInstance.InstanceServiceConfig,
 			&detail.CheckInstance.CheckID,
 			&detail.CheckInstance.ConfigID,
+			&overrideID,
+			&overrideObjID,
+			&overrideObjType,
+			&thresholdValues,
 		)
+		if thresholdValues.Valid {
+			if err = json.Unmarshal(
+				[]byte(thresholdValues.String),
+				&detail.CheckInstance.ThresholdValues,
+			); err != nil {
+				tk.treeLog.Println(`tk.stmtCheckInstance.QueryRow().Scan():`, err)
+				break deploymentbuilder
+			}
+		}
+		if overrideID.Valid {
+			detail.CheckInstance.ThresholdSource = &proto.CheckThresholdSource{
+				OverrideID: overrideID.String,
+				ObjectID:   overrideObjID.String,
+				ObjectType: overrideObjType.String,
+			}
+		}
 
 		//
 		detail.Check = &proto.Check{
@@ -97,8 +121,14 @@ deploymentbuilder:
 		)
 
 		//
+		// the thresholds of a threshold override replace the
+		// thresholds of the check configuration
 		detail.CheckConfig.Thresholds = []proto.CheckConfigThreshold{}
-		thresh, err = tk.stmtThreshold.Query(detail.CheckConfig.ID)
+		if detail.CheckInstance.ThresholdSource != nil {
+			thresh, err = tk.stmtOverride.Query(overrideID.String)
+		} else {
+			thresh, err = tk.stmtThreshold.Query(detail.CheckConfig.ID)
+		}
 		if err != nil {
 			// a check config must have 1+ thresholds
 			tk.treeLog.Println(`DANGER WILL ROBINSON!`,
@@ -107,15 +137,17 @@ deploymentbuilder:
 		}
 		defer thresh.Close()
 
+		unresolved := ``
 		for thresh.Next() {
 			thr := proto.CheckConfigThreshold{
 				Predicate: proto.Predicate{},
 				Level:     proto.Level{},
 			}
+			value := ``
 
 			err = thresh.Scan(
 				&thr.Predicate.Symbol,
-				&thr.Value,
+				&value,
 				&thr.Level.Name,
 				&thr.Level.ShortName,
 				&thr.Level.Numeric,
@@ -124,8 +156,44 @@ deploymentbuilder:
 				tk.treeLog.Println(`tk.stmtThreshold.Query().Scan():`, err)
 				break deploymentbuilder
 			}
+			if thr.Value, thr.ValueTemplate, err = splitThresholdValue(
+				value,
+			); err != nil {
+				tk.treeLog.Println(`tk.stmtThreshold.Query().Scan():`, err)
+				break deploymentbuilder
+			}
+			// templated thresholds use the value resolved for
+			// this check instance
+			if thr.ValueTemplate != `` {
+				resolved, ok := detail.CheckInstance.ThresholdValues[thr.Level.Numeric]
+				if !ok {
+					unresolved = thr.ValueTemplate
+					continue
+				}
+				thr.Value = resolved
+			}
 			detail.CheckConfig.Thresholds = append(detail.CheckConfig.Thresholds, thr)
 		}
+		// a check instance configuration without value for one of
+		// its templated thresholds can not be rolled out and is
+		// marked as failed to make it visible in the rollout status
+		if unresolved != `` {
+			tk.treeLog.Printf("Unresolved threshold template %s"+
+				" for check instance configuration %s",
+				unresolved, instanceCfgID)
+			if _, err = tk.stmtConfigStatus.Exec(
+				proto.DeploymentRolloutFailed,
+				proto.DeploymentNone,
+				instanceCfgID,
+			); err != nil {
+				tk.treeLog.Println(`tk.stmtConfigStatus.Exec():`, err)
+				break deploymentbuilder
+			}
+			publishTransition(tk.soma, proto.EventSourceTreeKeeper,
+				instanceCfgID, proto.DeploymentRolloutFailed,
+				proto.DeploymentNone)
+			continue deploymentbuilder
+		}
 
 		// XXX TODO
 		//detail.CheckConfiguration.Constraints = []somaproto.CheckConfigurationConstraint{}
@@ -519,6 +587,9 @@ deploymentbuilder:
 				detail.CheckInstance.InstanceConfigID, err)
 			break deploymentbuilder
 		}
+		publishTransition(tk.soma, proto.EventSourceTreeKeeper,
+			detail.CheckInstance.InstanceConfigID,
+			proto.DeploymentComputed, proto.DeploymentNone)
 	}
 	// mark the tree as broken to prevent further data processing
 	if err != nil {
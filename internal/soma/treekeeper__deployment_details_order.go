@@ -113,6 +113,10 @@ deployments:
 			if err = tx.Commit(); err != nil {
 				goto bailout_noprev
 			}
+			publishTransition(tk.soma, proto.EventSourceTreeKeeper,
+				currentChkInstanceConfigID,
+				proto.DeploymentAwaitingRollout,
+				proto.DeploymentRolloutInProgress)
 			continue deployments
 
 		bailout_noprev:
@@ -196,6 +200,10 @@ deployments:
 		if err = tx.Commit(); err != nil {
 			goto bailout_withprev
 		}
+		publishTransition(tk.soma, proto.EventSourceTreeKeeper,
+			currentChkInstanceConfigID,
+			proto.DeploymentBlocked,
+			proto.DeploymentAwaitingRollout)
 		continue deployments
 
 	bailout_withprev:
@@ -0,0 +1,78 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package soma
+
+import (
+	"fmt"
+	"runtime/debug"
+
+	"github.com/mjolnir42/soma/internal/msg"
+	"github.com/mjolnir42/soma/lib/proto"
+)
+
+// dryRun applies the request q to a snapshot of the tree and replies
+// with the resulting tree actions, without persisting anything.
+// Created or updated check instances in the returned actions are
+// candidates for a rollout. Updates whose deployment details do not
+// change are not rolled out.
+func (tk *TreeKeeper) dryRun(q *msg.Request) {
+	result := msg.FromRequest(q)
+	actions := []proto.TreeAction{}
+	var err error
+
+	tk.tree.Begin()
+
+	defer func() {
+		if r := recover(); r != nil {
+			tk.treeLog.Printf("PANIC during dry run on TreeKeeper.%s,"+
+				" RequestID %s: %s", tk.meta.repoName, q.ID.String(), r)
+			tk.treeLog.Printf("PANIC stacktrace: %s", debug.Stack())
+			result.ServerError(fmt.Errorf("%s", r), q.Section)
+		}
+		// discard all changes done by the dry run
+		tk.tree.Rollback()
+		tk.drain(`action`)
+		tk.drain(`error`)
+		q.Reply <- result
+	}()
+
+	if err = tk.apply(q); err != nil {
+		result.ServerError(err, q.Section)
+		return
+	}
+
+	// recalculate check instances, unless the request destroys the
+	// repository
+	switch {
+	case q.Section == msg.SectionRepository && q.Action == msg.ActionDestroy:
+	default:
+		tk.tree.ComputeCheckInstances()
+	}
+
+	for i := len(tk.errors); i > 0; i-- {
+		e := <-tk.errors
+		if err == nil {
+			err = fmt.Errorf("%s", e.Action)
+		}
+	}
+	if err != nil {
+		result.ServerError(err, q.Section)
+		return
+	}
+
+	for i := len(tk.actions); i > 0; i-- {
+		a := <-tk.actions
+		actions = append(actions, proto.TreeAction(*a))
+	}
+	result.TreeAction = actions
+	result.OK()
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
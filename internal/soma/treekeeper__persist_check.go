@@ -10,8 +10,8 @@ package soma
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
-	"strconv"
 	"time"
 
 	"github.com/mjolnir42/soma/internal/msg"
@@ -56,7 +56,7 @@ threshloop:
 		if _, err = stm[`CreateCheckConfigurationThreshold`].Exec(
 			conf.ID,
 			thr.Predicate.Symbol,
-			strconv.FormatInt(thr.Value, 10),
+			formatThresholdValue(&thr),
 			thr.Level.Name,
 		); err != nil {
 			break threshloop
@@ -74,6 +74,7 @@ constrloop:
 				conf.ID,
 				constr.Native.Name,
 				constr.Native.Value,
+				constr.EffectiveOperator(),
 			); err != nil {
 				break constrloop
 			}
@@ -90,6 +91,7 @@ constrloop:
 				constr.Custom.ID,
 				constr.Custom.RepositoryID,
 				constr.Custom.Value,
+				constr.EffectiveOperator(),
 			); err != nil {
 				break constrloop
 			}
@@ -98,6 +100,7 @@ constrloop:
 				conf.ID,
 				constr.System.Name,
 				constr.System.Value,
+				constr.EffectiveOperator(),
 			); err != nil {
 				break constrloop
 			}
@@ -112,6 +115,7 @@ constrloop:
 				conf.ID,
 				tk.meta.teamID,
 				constr.Service.Name,
+				constr.EffectiveOperator(),
 			); err != nil {
 				break constrloop
 			}
@@ -120,6 +124,7 @@ constrloop:
 				conf.ID,
 				constr.Attribute.Name,
 				constr.Attribute.Value,
+				constr.EffectiveOperator(),
 			); err != nil {
 				break constrloop
 			}
@@ -128,6 +133,105 @@ constrloop:
 	if err != nil {
 		return err
 	}
+
+	if conf.Rollout != nil {
+		// exactly one of wave size and wave percentage is set
+		waveSize := sql.NullInt64{
+			Int64: int64(conf.Rollout.WaveSize),
+			Valid: conf.Rollout.WaveSize > 0,
+		}
+		wavePercent := sql.NullInt64{
+			Int64: int64(conf.Rollout.WavePercent),
+			Valid: conf.Rollout.WavePercent > 0,
+		}
+		if _, err = stm[`CreateCheckConfigurationRollout`].Exec(
+			conf.ID,
+			waveSize,
+			wavePercent,
+			int64(conf.Rollout.SoakTime),
+			int64(conf.Rollout.FailureThreshold),
+		); err != nil {
+			return err
+		}
+	}
+
+	if len(conf.ConstraintGroups) > 0 {
+		var groups []byte
+		if groups, err = json.Marshal(conf.ConstraintGroups); err != nil {
+			return err
+		}
+		if _, err = stm[`CreateCheckConfigurationConstraintGroups`].Exec(
+			conf.ID,
+			string(groups),
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// txCheckConfigUpdate replaces the interval and the thresholds of
+// check configuration conf
+func (tk *TreeKeeper) txCheckConfigUpdate(conf proto.CheckConfig,
+	stm map[string]*sql.Stmt) error {
+	var err error
+
+	if _, err = stm[`UpdateCheckConfigurationInterval`].Exec(
+		conf.ID,
+		int64(conf.Interval),
+	); err != nil {
+		return err
+	}
+	if _, err = stm[`DeleteCheckConfigurationThresholds`].Exec(
+		conf.ID,
+	); err != nil {
+		return err
+	}
+	for _, thr := range conf.Thresholds {
+		if _, err = stm[`CreateCheckConfigurationThreshold`].Exec(
+			conf.ID,
+			thr.Predicate.Symbol,
+			formatThresholdValue(&thr),
+			thr.Level.Name,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// txThresholdOverride replaces the threshold override of check
+// configuration conf on the override's object
+func (tk *TreeKeeper) txThresholdOverride(conf proto.CheckConfig,
+	stm map[string]*sql.Stmt) error {
+	var err error
+	override := conf.ThresholdOverrides[0]
+
+	if _, err = stm[`DeleteThresholdOverride`].Exec(
+		conf.ID,
+		override.ObjectID,
+	); err != nil {
+		return err
+	}
+	if _, err = stm[`CreateThresholdOverride`].Exec(
+		override.ID,
+		conf.ID,
+		conf.RepositoryID,
+		override.ObjectID,
+		override.ObjectType,
+	); err != nil {
+		return err
+	}
+	for _, thr := range override.Thresholds {
+		if _, err = stm[`CreateThresholdOverrideThreshold`].Exec(
+			override.ID,
+			thr.Predicate.Symbol,
+			formatThresholdValue(&thr),
+			thr.Level.Name,
+		); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -222,6 +326,23 @@ func (tk *TreeKeeper) txCheckInstanceCreate(a *tree.Action,
 
 func (tk *TreeKeeper) txCheckInstanceConfigCreate(a *tree.Action,
 	stm map[string]*sql.Stmt) error {
+	thresholdOverride := sql.NullString{String: "", Valid: false}
+	if a.CheckInstance.ThresholdSource != nil {
+		thresholdOverride = sql.NullString{
+			String: a.CheckInstance.ThresholdSource.OverrideID,
+			Valid:  true,
+		}
+	}
+	// resolved threshold templates, NULL for instances without
+	// templated thresholds
+	thresholdValues := sql.NullString{String: "", Valid: false}
+	if len(a.CheckInstance.ThresholdValues) > 0 {
+		j, err := json.Marshal(a.CheckInstance.ThresholdValues)
+		if err != nil {
+			return err
+		}
+		thresholdValues = sql.NullString{String: string(j), Valid: true}
+	}
 	statement := stm[`CreateCheckInstanceConfiguration`]
 	_, err := statement.Exec(
 		a.CheckInstance.InstanceConfigID,
@@ -237,6 +358,8 @@ func (tk *TreeKeeper) txCheckInstanceConfigCreate(a *tree.Action,
 		proto.DeploymentNone,
 		false,
 		`{}`,
+		thresholdOverride,
+		thresholdValues,
 	)
 	return err
 }
@@ -165,6 +165,9 @@ func (tk *TreeKeeper) prepareStartupStatements() map[string]*sql.Stmt {
 		`LoadAttributeCstr`:      stmt.TkStartLoadCheckConstraintAttribute,
 		`LoadServiceCstr`:        stmt.TkStartLoadCheckConstraintService,
 		`LoadSystemCstr`:         stmt.TkStartLoadCheckConstraintSystem,
+		`LoadCstrGroups`:         stmt.TkStartLoadCheckConstraintGroups,
+		`LoadOverrides`:          stmt.TkStartLoadThresholdOverrides,
+		`LoadOverrideThreshold`:  stmt.TkStartLoadThresholdOverrideThresholds,
 		`LoadChecksForType`:      stmt.TkStartLoadChecksForType,
 		`LoadInstances`:          stmt.TkStartLoadCheckInstances,
 		`LoadInstanceCfg`:        stmt.TkStartLoadCheckInstanceConfiguration,
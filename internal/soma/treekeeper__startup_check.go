@@ -4,7 +4,6 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
-	"strconv"
 
 	"github.com/mjolnir42/soma/internal/tree"
 	"github.com/mjolnir42/soma/lib/proto"
@@ -17,6 +16,14 @@ func (tk *TreeKeeper) startupChecks(stMap map[string]*sql.Stmt) {
 	}
 	tk.startLog.Printf("TK[%s]: loading checks", tk.meta.repoName)
 
+	//
+	// threshold overrides are set on the tree before the checks so
+	// that they are in effect when the instances are computed
+	tk.startupThresholdOverrides(stMap)
+	if tk.status.isBroken {
+		return
+	}
+
 	//
 	// load checks for the entire tree, in order from root to leaf.
 	// Afterwards, load all check instances. This does not require
@@ -100,7 +107,7 @@ func (tk *TreeKeeper) startupScopedChecks(typ string, stMap map[string]*sql.Stmt
 		externalID, predicate, threshold, levelName, levelShort      string
 		cstrType, value1, value2, value3, itemID, itemCfgID          string
 		monitoringID, cstrHash, cstrValHash, instSvc, instSvcCfgHash string
-		instSvcCfg, errLocation                                      string
+		instSvcCfg, errLocation, operator, valueTemplate             string
 		levelNumeric, numVal, interval, version                      int64
 		isActive, hasInheritance, isChildrenOnly, isEnabled          bool
 		grOrder                                                      map[string][]string
@@ -221,7 +228,7 @@ func (tk *TreeKeeper) startupScopedChecks(typ string, stMap map[string]*sql.Stmt
 				goto fail
 			}
 			// ignore error since we converted this into the DB from int64
-			numVal, _ = strconv.ParseInt(threshold, 10, 64)
+			numVal, valueTemplate, _ = splitThresholdValue(threshold)
 
 			// save threshold
 			victim.Thresholds = append(victim.Thresholds,
@@ -234,7 +241,8 @@ func (tk *TreeKeeper) startupScopedChecks(typ string, stMap map[string]*sql.Stmt
 						ShortName: levelShort,
 						Numeric:   uint16(levelNumeric),
 					},
-					Value: numVal,
+					Value:         numVal,
+					ValueTemplate: valueTemplate,
 				},
 			)
 		}
@@ -271,7 +279,7 @@ func (tk *TreeKeeper) startupScopedChecks(typ string, stMap map[string]*sql.Stmt
 			// iterate over returned constraints - no rows is valid, as
 			// constraints are not mandatory
 			for cstrRows.Next() {
-				if err = cstrRows.Scan(&value1, &value2, &value3); err != nil {
+				if err = cstrRows.Scan(&value1, &value2, &value3, &operator); err != nil {
 					cstrRows.Close()
 					goto fail
 				}
@@ -280,6 +288,7 @@ func (tk *TreeKeeper) startupScopedChecks(typ string, stMap map[string]*sql.Stmt
 					victim.Constraints = append(victim.Constraints,
 						proto.CheckConfigConstraint{
 							ConstraintType: cstrType,
+							Operator:       operator,
 							Custom: &proto.PropertyCustom{
 								ID:           value1,
 								Name:         value2,
@@ -292,6 +301,7 @@ func (tk *TreeKeeper) startupScopedChecks(typ string, stMap map[string]*sql.Stmt
 					victim.Constraints = append(victim.Constraints,
 						proto.CheckConfigConstraint{
 							ConstraintType: cstrType,
+							Operator:       operator,
 							Native: &proto.PropertyNative{
 								Name:  value1,
 								Value: value2,
@@ -302,6 +312,7 @@ func (tk *TreeKeeper) startupScopedChecks(typ string, stMap map[string]*sql.Stmt
 					victim.Constraints = append(victim.Constraints,
 						proto.CheckConfigConstraint{
 							ConstraintType: cstrType,
+							Operator:       operator,
 							Oncall: &proto.PropertyOncall{
 								ID:     value1,
 								Name:   value2,
@@ -313,6 +324,7 @@ func (tk *TreeKeeper) startupScopedChecks(typ string, stMap map[string]*sql.Stmt
 					victim.Constraints = append(victim.Constraints,
 						proto.CheckConfigConstraint{
 							ConstraintType: cstrType,
+							Operator:       operator,
 							Attribute: &proto.ServiceAttribute{
 								Name:  value1,
 								Value: value2,
@@ -323,6 +335,7 @@ func (tk *TreeKeeper) startupScopedChecks(typ string, stMap map[string]*sql.Stmt
 					victim.Constraints = append(victim.Constraints,
 						proto.CheckConfigConstraint{
 							ConstraintType: cstrType,
+							Operator:       operator,
 							Service: &proto.PropertyService{
 								Name:   value2,
 								TeamID: value1,
@@ -333,6 +346,7 @@ func (tk *TreeKeeper) startupScopedChecks(typ string, stMap map[string]*sql.Stmt
 					victim.Constraints = append(victim.Constraints,
 						proto.CheckConfigConstraint{
 							ConstraintType: cstrType,
+							Operator:       operator,
 							System: &proto.PropertySystem{
 								Name:  value1,
 								Value: value2,
@@ -345,6 +359,10 @@ func (tk *TreeKeeper) startupScopedChecks(typ string, stMap map[string]*sql.Stmt
 				goto fail
 			}
 		}
+		if victim.ConstraintGroups, err = tk.startupConstraintGroups(
+			victim.ID, stMap); err != nil {
+			goto fail
+		}
 		cfgMap[checkID] = victim
 	}
 
@@ -702,7 +720,8 @@ func (tk *TreeKeeper) startupScopedReapplyCheckConfig(typ string, stMap map[stri
 		err                                         error
 		configRows, threshRows, cstrRows            *sql.Rows
 		predicate, threshold, levelName, levelShort string
-		cstrType, value1, value2, value3            string
+		cstrType, value1, value2, value3, operator  string
+		valueTemplate                               string
 		levelNumeric, numVal                        int64
 		treeCheck                                   *tree.Check
 		nullBucketID                                sql.NullString
@@ -759,7 +778,7 @@ func (tk *TreeKeeper) startupScopedReapplyCheckConfig(typ string, stMap map[stri
 				goto fail
 			}
 			// ignore errors, we converted into the DB from int64
-			numVal, _ = strconv.ParseInt(threshold, 10, 64)
+			numVal, valueTemplate, _ = splitThresholdValue(threshold)
 
 			// add threshold to config
 			conf.Thresholds = append(conf.Thresholds,
@@ -772,7 +791,8 @@ func (tk *TreeKeeper) startupScopedReapplyCheckConfig(typ string, stMap map[stri
 						ShortName: levelShort,
 						Numeric:   uint16(levelNumeric),
 					},
-					Value: numVal,
+					Value:         numVal,
+					ValueTemplate: valueTemplate,
 				},
 			)
 		}
@@ -801,7 +821,7 @@ func (tk *TreeKeeper) startupScopedReapplyCheckConfig(typ string, stMap map[stri
 			}
 
 			for cstrRows.Next() {
-				if err = cstrRows.Scan(&value1, &value2, &value3); err != nil {
+				if err = cstrRows.Scan(&value1, &value2, &value3, &operator); err != nil {
 					cstrRows.Close()
 					goto fail
 				}
@@ -810,6 +830,7 @@ func (tk *TreeKeeper) startupScopedReapplyCheckConfig(typ string, stMap map[stri
 					conf.Constraints = append(conf.Constraints,
 						proto.CheckConfigConstraint{
 							ConstraintType: cstrType,
+							Operator:       operator,
 							Custom: &proto.PropertyCustom{
 								ID:           value1,
 								Name:         value2,
@@ -822,6 +843,7 @@ func (tk *TreeKeeper) startupScopedReapplyCheckConfig(typ string, stMap map[stri
 					conf.Constraints = append(conf.Constraints,
 						proto.CheckConfigConstraint{
 							ConstraintType: cstrType,
+							Operator:       operator,
 							Native: &proto.PropertyNative{
 								Name:  value1,
 								Value: value2,
@@ -832,6 +854,7 @@ func (tk *TreeKeeper) startupScopedReapplyCheckConfig(typ string, stMap map[stri
 					conf.Constraints = append(conf.Constraints,
 						proto.CheckConfigConstraint{
 							ConstraintType: cstrType,
+							Operator:       operator,
 							Oncall: &proto.PropertyOncall{
 								ID:     value1,
 								Name:   value2,
@@ -843,6 +866,7 @@ func (tk *TreeKeeper) startupScopedReapplyCheckConfig(typ string, stMap map[stri
 					conf.Constraints = append(conf.Constraints,
 						proto.CheckConfigConstraint{
 							ConstraintType: cstrType,
+							Operator:       operator,
 							Attribute: &proto.ServiceAttribute{
 								Name:  value1,
 								Value: value2,
@@ -853,6 +877,7 @@ func (tk *TreeKeeper) startupScopedReapplyCheckConfig(typ string, stMap map[stri
 					conf.Constraints = append(conf.Constraints,
 						proto.CheckConfigConstraint{
 							ConstraintType: cstrType,
+							Operator:       operator,
 							Service: &proto.PropertyService{
 								Name:   value2,
 								TeamID: value1,
@@ -863,6 +888,7 @@ func (tk *TreeKeeper) startupScopedReapplyCheckConfig(typ string, stMap map[stri
 					conf.Constraints = append(conf.Constraints,
 						proto.CheckConfigConstraint{
 							ConstraintType: cstrType,
+							Operator:       operator,
 							System: &proto.PropertySystem{
 								Name:  value1,
 								Value: value2,
@@ -875,6 +901,11 @@ func (tk *TreeKeeper) startupScopedReapplyCheckConfig(typ string, stMap map[stri
 				goto fail
 			}
 		}
+		//    + constraint groups
+		if conf.ConstraintGroups, err = tk.startupConstraintGroups(
+			conf.ID, stMap); err != nil {
+			goto fail
+		}
 		// 3. convert to treecheck
 		if treeCheck, err = tk.convertCheck(&conf); err == nil {
 			// 4. apply config
@@ -895,6 +926,125 @@ fail:
 	}
 }
 
+// startupConstraintGroups loads the constraint groups of check
+// configuration configID
+func (tk *TreeKeeper) startupConstraintGroups(configID string,
+	stMap map[string]*sql.Stmt) ([]proto.CheckConfigConstraintGroup, error) {
+	var (
+		err    error
+		raw    string
+		groups []proto.CheckConfigConstraintGroup
+	)
+	if err = stMap[`LoadCstrGroups`].QueryRow(configID).Scan(
+		&raw,
+	); err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal([]byte(raw), &groups); err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+// startupThresholdOverrides loads all active threshold overrides of
+// the repository and sets them on the tree
+func (tk *TreeKeeper) startupThresholdOverrides(stMap map[string]*sql.Stmt) {
+	var (
+		err                                  error
+		overrideID, configID, objID, objType string
+		predicate, threshold, valueTemplate  string
+		levelName, levelShort                string
+		levelNumeric                         int64
+		numVal                               int64
+		ovRows, thrRows                      *sql.Rows
+		overrides                            []proto.CheckConfig
+	)
+
+	if ovRows, err = stMap[`LoadOverrides`].Query(
+		tk.meta.repoID,
+	); err != nil {
+		goto fail
+	}
+
+	for ovRows.Next() {
+		if err = ovRows.Scan(
+			&overrideID,
+			&configID,
+			&objID,
+			&objType,
+		); err != nil {
+			ovRows.Close()
+			goto fail
+		}
+		overrides = append(overrides, proto.CheckConfig{
+			ID: configID,
+			ThresholdOverrides: []proto.CheckConfigThresholdOverride{{
+				ID:         overrideID,
+				ObjectID:   objID,
+				ObjectType: objType,
+				Thresholds: []proto.CheckConfigThreshold{},
+			}},
+		})
+	}
+	if err = ovRows.Err(); err != nil {
+		goto fail
+	}
+
+	for i := range overrides {
+		if thrRows, err = stMap[`LoadOverrideThreshold`].Query(
+			overrides[i].ThresholdOverrides[0].ID,
+		); err != nil {
+			goto fail
+		}
+
+		for thrRows.Next() {
+			if err = thrRows.Scan(
+				&predicate,
+				&threshold,
+				&levelName,
+				&levelShort,
+				&levelNumeric,
+			); err != nil {
+				thrRows.Close()
+				goto fail
+			}
+			// ignore error since we converted this into the DB from int64
+			numVal, valueTemplate, _ = splitThresholdValue(threshold)
+
+			overrides[i].ThresholdOverrides[0].Thresholds = append(
+				overrides[i].ThresholdOverrides[0].Thresholds,
+				proto.CheckConfigThreshold{
+					Predicate: proto.Predicate{
+						Symbol: predicate,
+					},
+					Level: proto.Level{
+						Name:      levelName,
+						ShortName: levelShort,
+						Numeric:   uint16(levelNumeric),
+					},
+					Value:         numVal,
+					ValueTemplate: valueTemplate,
+				},
+			)
+		}
+		if err = thrRows.Err(); err != nil {
+			goto fail
+		}
+
+		if err = tk.setThresholdOverride(&overrides[i]); err != nil {
+			goto fail
+		}
+	}
+	return
+
+fail:
+	tk.status.isBroken = true
+	tk.startLog.Printf("TK[%s]: Error loading threshold overrides: %s",
+		tk.meta.repoName, err)
+}
+
 // orderGroups orders the groups in a repository so they can be
 // processed from root to leaf
 func (tk *TreeKeeper) orderGroups(stMap map[string]*sql.Stmt) (map[string][]string, map[string]string, error) {
@@ -355,6 +355,7 @@ func (tk *TreeKeeper) startupNodes(stMap map[string]*sql.Stmt) {
 		err                                          error
 		rows                                         *sql.Rows
 		nodeID, nodeName, teamID, serverID, bucketID string
+		serverName                                   string
 		assetID                                      int
 		nodeOnline, nodeDeleted                      bool
 		clusterID, groupID                           sql.NullString
@@ -384,6 +385,7 @@ nodeloop:
 			&bucketID,
 			&clusterID,
 			&groupID,
+			&serverName,
 		)
 		if err != nil {
 			if err == sql.ErrNoRows {
@@ -395,13 +397,14 @@ nodeloop:
 		}
 
 		node := tree.NewNode(tree.NodeSpec{
-			ID:       nodeID,
-			AssetID:  uint64(assetID),
-			Name:     nodeName,
-			Team:     teamID,
-			ServerID: serverID,
-			Online:   nodeOnline,
-			Deleted:  nodeDeleted,
+			ID:         nodeID,
+			AssetID:    uint64(assetID),
+			Name:       nodeName,
+			Team:       teamID,
+			ServerID:   serverID,
+			ServerName: serverName,
+			Online:     nodeOnline,
+			Deleted:    nodeDeleted,
 		})
 		if clusterID.Valid {
 			node.Attach(tree.AttachRequest{
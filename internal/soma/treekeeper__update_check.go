@@ -9,6 +9,8 @@
 package soma
 
 import (
+	"fmt"
+
 	"github.com/mjolnir42/soma/internal/msg"
 	"github.com/mjolnir42/soma/internal/tree"
 	"github.com/mjolnir42/soma/lib/proto"
@@ -41,6 +43,74 @@ func (tk *TreeKeeper) rmCheck(config *proto.CheckConfig) error {
 	return err
 }
 
+func (tk *TreeKeeper) updateCheck(config *proto.CheckConfig) error {
+	var err error
+	var chk *tree.Check
+	if chk, err = tk.convertCheckForUpdate(config); err == nil {
+		tk.tree.Find(tree.FindRequest{
+			ElementType: config.ObjectType,
+			ElementID:   config.ObjectID,
+		}, true).UpdateCheck(*chk)
+		return nil
+	}
+	return err
+}
+
+func (tk *TreeKeeper) setThresholdOverride(config *proto.CheckConfig) error {
+	if len(config.ThresholdOverrides) != 1 {
+		return fmt.Errorf("Expected 1 threshold override, got %d",
+			len(config.ThresholdOverrides))
+	}
+	override := convertThresholdOverride(config.ID, &config.ThresholdOverrides[0])
+	obj, ok := tk.tree.Find(tree.FindRequest{
+		ElementType: config.ThresholdOverrides[0].ObjectType,
+		ElementID:   config.ThresholdOverrides[0].ObjectID,
+	}, true).(tree.ThresholdOverrider)
+	if !ok {
+		return fmt.Errorf("Object %s does not support threshold overrides",
+			config.ThresholdOverrides[0].ObjectID)
+	}
+	obj.SetThresholdOverride(override)
+	return nil
+}
+
+func (tk *TreeKeeper) rmThresholdOverride(config *proto.CheckConfig) error {
+	if len(config.ThresholdOverrides) != 1 {
+		return fmt.Errorf("Expected 1 threshold override, got %d",
+			len(config.ThresholdOverrides))
+	}
+	override := convertThresholdOverride(config.ID, &config.ThresholdOverrides[0])
+	obj, ok := tk.tree.Find(tree.FindRequest{
+		ElementType: config.ThresholdOverrides[0].ObjectType,
+		ElementID:   config.ThresholdOverrides[0].ObjectID,
+	}, true).(tree.ThresholdOverrider)
+	if !ok {
+		return fmt.Errorf("Object %s does not support threshold overrides",
+			config.ThresholdOverrides[0].ObjectID)
+	}
+	obj.DeleteThresholdOverride(override)
+	return nil
+}
+
+func convertThresholdOverride(configID string, o *proto.CheckConfigThresholdOverride) tree.ThresholdOverride {
+	override := tree.ThresholdOverride{
+		ObjectType: o.ObjectType,
+	}
+	override.ID, _ = uuid.FromString(o.ID)
+	override.ConfigID, _ = uuid.FromString(configID)
+	override.ObjectID, _ = uuid.FromString(o.ObjectID)
+	override.Thresholds = make([]tree.CheckThreshold, len(o.Thresholds))
+	for i, thr := range o.Thresholds {
+		override.Thresholds[i] = tree.CheckThreshold{
+			Predicate: thr.Predicate.Symbol,
+			Level:     uint8(thr.Level.Numeric),
+			Value:     thr.Value,
+			Template:  thr.ValueTemplate,
+		}
+	}
+	return override
+}
+
 func (tk *TreeKeeper) convertCheck(conf *proto.CheckConfig) (*tree.Check, error) {
 	treechk := &tree.Check{
 		ID:            uuid.Nil,
@@ -62,40 +132,67 @@ func (tk *TreeKeeper) convertCheck(conf *proto.CheckConfig) (*tree.Check, error)
 			Predicate: thr.Predicate.Symbol,
 			Level:     uint8(thr.Level.Numeric),
 			Value:     thr.Value,
+			Template:  thr.ValueTemplate,
 		}
 		treechk.Thresholds[i] = nthr
 	}
 
 	treechk.Constraints = make([]tree.CheckConstraint, len(conf.Constraints))
-	for i, constr := range conf.Constraints {
-		ncon := tree.CheckConstraint{
-			Type: constr.ConstraintType,
-		}
-		switch constr.ConstraintType {
-		case msg.ConstraintNative:
-			ncon.Key = constr.Native.Name
-			ncon.Value = constr.Native.Value
-		case msg.ConstraintOncall:
-			ncon.Key = `OncallId`
-			ncon.Value = constr.Oncall.ID
-		case msg.ConstraintCustom:
-			ncon.Key = constr.Custom.ID
-			ncon.Value = constr.Custom.Value
-		case msg.ConstraintSystem:
-			ncon.Key = constr.System.Name
-			ncon.Value = constr.System.Value
-		case msg.ConstraintService:
-			ncon.Key = `id`
-			ncon.Value = constr.Service.ID
-		case msg.ConstraintAttribute:
-			ncon.Key = constr.Attribute.Name
-			ncon.Value = constr.Attribute.Value
-		}
-		treechk.Constraints[i] = ncon
+	for i := range conf.Constraints {
+		treechk.Constraints[i] = convertConstraint(&conf.Constraints[i])
+	}
+
+	treechk.ConstraintGroups = make([]tree.CheckConstraintGroup, len(conf.ConstraintGroups))
+	for i := range conf.ConstraintGroups {
+		treechk.ConstraintGroups[i] = convertConstraintGroup(&conf.ConstraintGroups[i])
 	}
 	return treechk, nil
 }
 
+func convertConstraint(constr *proto.CheckConfigConstraint) tree.CheckConstraint {
+	ncon := tree.CheckConstraint{
+		Type:     constr.ConstraintType,
+		Operator: constr.EffectiveOperator(),
+	}
+	switch constr.ConstraintType {
+	case msg.ConstraintNative:
+		ncon.Key = constr.Native.Name
+		ncon.Value = constr.Native.Value
+	case msg.ConstraintOncall:
+		ncon.Key = `OncallId`
+		ncon.Value = constr.Oncall.ID
+	case msg.ConstraintCustom:
+		ncon.Key = constr.Custom.ID
+		ncon.Value = constr.Custom.Value
+	case msg.ConstraintSystem:
+		ncon.Key = constr.System.Name
+		ncon.Value = constr.System.Value
+	case msg.ConstraintService:
+		// services are matched by name
+		ncon.Key = `name`
+		ncon.Value = constr.Service.Name
+	case msg.ConstraintAttribute:
+		ncon.Key = constr.Attribute.Name
+		ncon.Value = constr.Attribute.Value
+	}
+	return ncon
+}
+
+func convertConstraintGroup(grp *proto.CheckConfigConstraintGroup) tree.CheckConstraintGroup {
+	ngrp := tree.CheckConstraintGroup{
+		Operator: grp.Operator,
+	}
+	ngrp.Constraints = make([]tree.CheckConstraint, len(grp.Constraints))
+	for i := range grp.Constraints {
+		ngrp.Constraints[i] = convertConstraint(&grp.Constraints[i])
+	}
+	ngrp.Groups = make([]tree.CheckConstraintGroup, len(grp.Groups))
+	for i := range grp.Groups {
+		ngrp.Groups[i] = convertConstraintGroup(&grp.Groups[i])
+	}
+	return ngrp
+}
+
 func (tk *TreeKeeper) convertCheckForDelete(conf *proto.CheckConfig) (*tree.Check, error) {
 	var err error
 	treechk := &tree.Check{
@@ -111,4 +208,22 @@ func (tk *TreeKeeper) convertCheckForDelete(conf *proto.CheckConfig) (*tree.Chec
 	return treechk, nil
 }
 
+func (tk *TreeKeeper) convertCheckForUpdate(conf *proto.CheckConfig) (*tree.Check, error) {
+	treechk, err := tk.convertCheckForDelete(conf)
+	if err != nil {
+		return nil, err
+	}
+	treechk.Interval = conf.Interval
+	treechk.Thresholds = make([]tree.CheckThreshold, len(conf.Thresholds))
+	for i, thr := range conf.Thresholds {
+		treechk.Thresholds[i] = tree.CheckThreshold{
+			Predicate: thr.Predicate.Symbol,
+			Level:     uint8(thr.Level.Numeric),
+			Value:     thr.Value,
+			Template:  thr.ValueTemplate,
+		}
+	}
+	return treechk, nil
+}
+
 // vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -162,13 +162,14 @@ func (tk *TreeKeeper) treeNode(q *msg.Request) {
 		switch q.Action {
 		case msg.ActionAssign:
 			tree.NewNode(tree.NodeSpec{
-				ID:       q.Node.ID,
-				AssetID:  q.Node.AssetID,
-				Name:     q.Node.Name,
-				Team:     q.Node.TeamID,
-				ServerID: q.Node.ServerID,
-				Online:   q.Node.IsOnline,
-				Deleted:  q.Node.IsDeleted,
+				ID:         q.Node.ID,
+				AssetID:    q.Node.AssetID,
+				Name:       q.Node.Name,
+				Team:       q.Node.TeamID,
+				ServerID:   q.Node.ServerID,
+				ServerName: q.Node.ServerName,
+				Online:     q.Node.IsOnline,
+				Deleted:    q.Node.IsDeleted,
 			}).Attach(tree.AttachRequest{
 				Root:       tk.tree,
 				ParentType: msg.EntityBucket,
@@ -182,6 +183,18 @@ func (tk *TreeKeeper) treeNode(q *msg.Request) {
 		}
 	}
 
+	if q.Section == msg.SectionNodeMgmt && q.Action == msg.ActionUpdate {
+		if node, ok := tk.tree.Find(tree.FindRequest{
+			ElementType: msg.EntityNode,
+			ElementID:   q.Node.ID,
+		}, true).(*tree.Node); ok {
+			node.SetServer(
+				q.Update.Node.ServerID,
+				q.Update.Node.ServerName,
+			)
+		}
+	}
+
 	if q.Action == msg.ActionMemberUnassign && q.TargetEntity == msg.EntityNode {
 		switch q.Section {
 		case msg.SectionCluster:
@@ -28,6 +28,13 @@ WHERE  scc.configuration_id = $1::uuid
   AND  sc.check_id          = sc.source_check_id
   AND  NOT sc.deleted;`
 
+	CheckConfigCapability = `
+SELECT scc.capability_id
+FROM   soma.check_configurations scc
+WHERE  scc.configuration_id = $1::uuid
+  AND  scc.repository_id    = $2::uuid
+  AND  NOT scc.deleted;`
+
 	CheckConfigList = `
 SELECT configuration_id,
        repository_id,
@@ -73,7 +80,8 @@ SELECT scc.configuration_id,
        sccp.custom_property_id,
        sccp.repository_id,
        sccp.property_value,
-       scp.custom_property
+       scp.custom_property,
+       sccp.operator
 FROM   soma.check_configurations scc
 JOIN   soma.constraints_custom_property sccp
 ON     scc.configuration_id = sccp.configuration_id
@@ -85,7 +93,8 @@ WHERE  scc.configuration_id = $1::uuid;`
 	CheckConfigShowConstrSystem = `
 SELECT scc.configuration_id,
        scsp.system_property,
-       scsp.property_value
+       scsp.property_value,
+       scsp.operator
 FROM   soma.check_configurations scc
 JOIN   soma.constraints_system_property scsp
 ON     scc.configuration_id = scsp.configuration_id
@@ -94,7 +103,8 @@ WHERE  scc.configuration_id = $1::uuid;`
 	CheckConfigShowConstrNative = `
 SELECT scc.configuration_id,
        scnp.native_property,
-       scnp.property_value
+       scnp.property_value,
+       scnp.operator
 FROM   soma.check_configurations scc
 JOIN   soma.constraints_native_property scnp
 ON     scc.configuration_id = scnp.configuration_id
@@ -103,7 +113,8 @@ WHERE  scc.configuration_id = $1::uuid;`
 	CheckConfigShowConstrService = `
 SELECT scc.configuration_id,
        scsvp.team_id,
-       scsvp.name
+       scsvp.name,
+       scsvp.operator
 FROM   soma.check_configurations scc
 JOIN   soma.constraints_service_property scsvp
 ON     scc.configuration_id = scsvp.configuration_id
@@ -112,7 +123,8 @@ WHERE  scc.configuration_id = $1::uuid;`
 	CheckConfigShowConstrAttribute = `
 SELECT scc.configuration_id,
        scsa.attribute,
-       scsa.value
+       scsa.value,
+       scsa.operator
 FROM   soma.check_configurations scc
 JOIN   soma.constraints_service_attribute scsa
 ON     scc.configuration_id = scsa.configuration_id
@@ -130,12 +142,57 @@ JOIN   inventory.oncall_team iot
 ON     scop.oncall_duty_id = iot.id
 WHERE  scc.configuration_id = $1::uuid;`
 
+	CheckConfigShowRollout = `
+SELECT sccr.wave_size,
+       sccr.wave_percent,
+       sccr.soak_seconds,
+       sccr.failure_threshold,
+       sccr.wave,
+       sccr.halted_at,
+       (SELECT COUNT(sci.check_instance_id)
+        FROM   soma.check_instances sci
+        JOIN   soma.check_instance_configurations scic
+          ON   sci.current_instance_config_id = scic.check_instance_config_id
+        WHERE  sci.check_configuration_id = sccr.configuration_id
+          AND  NOT sci.deleted
+          AND  scic.status = '` + proto.DeploymentRolloutFailed + `'::varchar
+          AND  sccr.rollout_started_at IS NOT NULL
+          AND  scic.status_last_updated_at >= sccr.rollout_started_at)
+FROM   soma.check_configuration_rollout sccr
+WHERE  sccr.configuration_id = $1::uuid;`
+
+	CheckConfigShowConstrGroups = `
+SELECT scccg.groups
+FROM   soma.check_configuration_constraint_groups scccg
+WHERE  scccg.configuration_id = $1::uuid;`
+
+	CheckConfigShowThresholdOverrides = `
+SELECT sto.override_id,
+       sto.object_id,
+       sto.object_type,
+       stot.predicate,
+       stot.threshold,
+       stot.notification_level,
+       snl.level_shortname,
+       snl.level_numeric
+FROM   soma.threshold_overrides sto
+JOIN   soma.threshold_override_thresholds stot
+  ON   sto.override_id = stot.override_id
+JOIN   soma.notification_levels snl
+  ON   stot.notification_level = snl.level_name
+WHERE  sto.configuration_id = $1::uuid
+  AND  NOT sto.deleted
+ORDER  BY sto.override_id;`
+
 	CheckConfigInstanceInfo = `
 SELECT sci.check_instance_id,
        sc.object_id,
        sc.object_type,
        scic.status,
-       scic.next_status
+       scic.next_status,
+       scic.threshold_override_id,
+       sto.object_id,
+       sto.object_type
 FROM   soma.check_configurations scc
 JOIN   soma.check_instances sci
   ON   scc.configuration_id = sci.check_configuration_id
@@ -143,6 +200,8 @@ JOIN   soma.checks sc
   ON   sci.check_id = sc.check_id
 JOIN   soma.check_instance_configurations scic
   ON   sci.current_instance_config_id = scic.check_instance_config_id
+LEFT   JOIN soma.threshold_overrides sto
+  ON   scic.threshold_override_id = sto.override_id
 WHERE  scc.configuration_id = $1::uuid
   AND  scic.status != '` + proto.DeploymentAwaitingDeletion + `'::varchar;`
 
@@ -167,9 +226,41 @@ WHERE  scc.configuration_id = $1::uuid
 SELECT sc.configuration_id
 FROM   soma.checks sc
 WHERE  sc.object_id = $1::uuid;`
+
+	CheckConfigExportNames = `
+SELECT sr.name,
+       sb.bucket_name,
+       CASE scc.configuration_object_type
+            WHEN 'repository' THEN sr.name
+            WHEN 'bucket'     THEN sb.bucket_name
+            WHEN 'group'      THEN sg.group_name
+            WHEN 'cluster'    THEN scl.cluster_name
+            WHEN 'node'       THEN sn.node_name
+       END,
+       sms.monitoring_name || '.' ||
+       smc.capability_view || '.' ||
+       smc.capability_metric
+FROM   soma.check_configurations scc
+JOIN   soma.repository sr
+  ON   scc.repository_id = sr.id
+JOIN   soma.monitoring_capabilities smc
+  ON   scc.capability_id = smc.capability_id
+JOIN   soma.monitoring_systems sms
+  ON   smc.capability_monitoring = sms.monitoring_id
+LEFT   JOIN soma.buckets sb
+  ON   scc.bucket_id = sb.bucket_id
+LEFT   JOIN soma.groups sg
+  ON   scc.configuration_object = sg.group_id
+LEFT   JOIN soma.clusters scl
+  ON   scc.configuration_object = scl.cluster_id
+LEFT   JOIN soma.nodes sn
+  ON   scc.configuration_object = sn.node_id
+WHERE  scc.configuration_id = $1::uuid;`
 )
 
 func init() {
+	m[CheckConfigCapability] = `CheckConfigCapability`
+	m[CheckConfigExportNames] = `CheckConfigExportNames`
 	m[CheckConfigForChecksOnObject] = `CheckConfigForChecksOnObject`
 	m[CheckConfigInstanceInfo] = `CheckConfigInstanceInfo`
 	m[CheckConfigList] = `CheckConfigList`
@@ -181,7 +272,10 @@ func init() {
 	m[CheckConfigShowConstrOncall] = `CheckConfigShowConstrOncall`
 	m[CheckConfigShowConstrService] = `CheckConfigShowConstrService`
 	m[CheckConfigShowConstrSystem] = `CheckConfigShowConstrSystem`
+	m[CheckConfigShowRollout] = `CheckConfigShowRollout`
+	m[CheckConfigShowConstrGroups] = `CheckConfigShowConstrGroups`
 	m[CheckConfigShowThreshold] = `CheckConfigShowThreshold`
+	m[CheckConfigShowThresholdOverrides] = `CheckConfigShowThresholdOverrides`
 	m[CheckDetailsForDelete] = `CheckDetailsForDelete`
 }
 
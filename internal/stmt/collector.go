@@ -0,0 +1,66 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package stmt
+
+import (
+	"github.com/mjolnir42/soma/lib/proto"
+)
+
+const (
+	CollectorStatements = ``
+
+	// CollectorWorkflowStates returns the number of check instance
+	// configurations in each workflow status, including statuses
+	// without configurations
+	CollectorWorkflowStates = `
+SELECT    scis.status,
+          count(scic.check_instance_config_id)
+FROM      soma.check_instance_status scis
+LEFT JOIN soma.check_instance_configurations scic
+  ON      scis.status = scic.status
+ AND      EXISTS (
+          SELECT sci.check_instance_id
+          FROM   soma.check_instances sci
+          WHERE  sci.check_instance_id = scic.check_instance_id
+            AND  NOT sci.deleted)
+GROUP BY  scis.status;`
+
+	// CollectorOutboxBacklog returns the number of undelivered
+	// notifications per monitoring system
+	CollectorOutboxBacklog = `
+SELECT    sms.monitoring_name,
+          count(sno.notification_id)
+FROM      soma.monitoring_systems sms
+LEFT JOIN soma.notification_outbox sno
+  ON      sms.monitoring_id = sno.monitoring_id
+ AND      sno.delivery_state != '` + proto.NotificationDelivered + `'::varchar
+GROUP BY  sms.monitoring_name;`
+
+	// CollectorJobLatency returns the average time in seconds between
+	// queueing and finishing the jobs of each repository, for jobs
+	// that finished during the last $1 seconds
+	CollectorJobLatency = `
+SELECT    sr.name,
+          COALESCE(avg(EXTRACT(EPOCH FROM sj.finished_at - sj.queued_at)), 0)::double precision
+FROM      soma.repository sr
+LEFT JOIN soma.job sj
+  ON      sr.id = sj.repository_id
+ AND      sj.finished_at > NOW() - $1::integer * '1 second'::interval
+WHERE     NOT sr.is_deleted
+GROUP BY  sr.name;`
+)
+
+func init() {
+	m[CollectorJobLatency] = `CollectorJobLatency`
+	m[CollectorOutboxBacklog] = `CollectorOutboxBacklog`
+	m[CollectorWorkflowStates] = `CollectorWorkflowStates`
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
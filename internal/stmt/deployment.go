@@ -79,7 +79,7 @@ AND    scic.check_instance_config_id = sci.current_instance_config_id
 WHERE  sms.monitoring_id = $1::uuid
 AND    sci.update_available
 AND    (  scic.status = '` + proto.DeploymentAwaitingRollout + `'::varchar
-       OR scic.status = '` + proto.DeploymentAwaitingDeprovision + `'::varchar);`
+       OR scic.status = '` + proto.DeploymentAwaitingDeprovision + `'::varchar)` + maintenanceHoldDeployment + rolloutHoldDeployment + `;`
 
 	DeploymentListAll = `
 SELECT sci.check_instance_id
@@ -95,6 +95,34 @@ AND    (  scic.status = '` + proto.DeploymentAwaitingRollout + `'::varchar
        OR scic.status = '` + proto.DeploymentAwaitingDeprovision + `'::varchar
        OR scic.status = '` + proto.DeploymentDeprovisionInProgress + `'::varchar);`
 
+	// DeploymentListPage returns the deployments of monitoring
+	// system $1 with their status, optionally including active
+	// deployments if $2 is true. The list is ordered and paged by
+	// $3 limit and $4 offset, a NULL limit returns all deployments.
+	// Deployments held by a maintenance window or a staged rollout
+	// are reported as blocked.
+	DeploymentListPage = `
+SELECT   sci.check_instance_id,
+         CASE WHEN TRUE` + maintenanceHoldDeployment + rolloutHoldDeployment + `
+         THEN scic.status
+         ELSE '` + proto.DeploymentBlocked + `'::varchar
+         END
+FROM     soma.monitoring_systems sms
+JOIN     soma.check_instance_configurations scic
+ON       sms.monitoring_id = scic.monitoring_id
+JOIN     soma.check_instances sci
+ON       scic.check_instance_id = sci.check_instance_id
+AND      scic.check_instance_config_id = sci.current_instance_config_id
+WHERE    sms.monitoring_id = $1::uuid
+AND      (  scic.status = '` + proto.DeploymentAwaitingRollout + `'::varchar
+         OR scic.status = '` + proto.DeploymentRolloutInProgress + `'::varchar
+         OR scic.status = '` + proto.DeploymentAwaitingDeprovision + `'::varchar
+         OR scic.status = '` + proto.DeploymentDeprovisionInProgress + `'::varchar
+         OR ( $2::boolean AND scic.status = '` + proto.DeploymentActive + `'::varchar ))
+ORDER BY sci.check_instance_id
+LIMIT    $3::bigint
+OFFSET   $4::bigint;`
+
 	DeploymentClearFlag = `
 UPDATE soma.check_instances
 SET    update_available = 'false'::boolean,
@@ -128,23 +156,58 @@ AND    (   status != '` + proto.DeploymentDeprovisioned + `'::varchar
 ORDER  BY version DESC
 LIMIT  1;`
 
+	DeploymentMonitoringSecret = `
+SELECT sms.monitoring_id,
+       sms.monitoring_secret
+FROM   soma.check_instances sci
+JOIN   soma.check_instance_configurations scic
+ON     sci.check_instance_id = scic.check_instance_id
+AND    sci.current_instance_config_id = scic.check_instance_config_id
+JOIN   soma.monitoring_systems sms
+ON     scic.monitoring_id = sms.monitoring_id
+WHERE  sci.check_instance_id = $1::uuid;`
+
+	DeploymentMonitoringSecretByID = `
+SELECT monitoring_id,
+       monitoring_secret
+FROM   soma.monitoring_systems
+WHERE  monitoring_id = $1::uuid;`
+
 	DeploymentDeprovisionStyle = `
 SELECT EXISTS(SELECT scicd.blocked_instance_config_id
 FROM   soma.check_instances sci
 JOIN   soma.check_instance_configuration_dependencies scicd
   ON   sci.current_instance_config_id = scicd.blocking_instance_config_id
 WHERE  sci.check_instance_id = $1::uuid)::boolean AS result;`
+
+	// DeploymentEventDetails returns the information required to
+	// publish a state transition of check instance configuration $1
+	DeploymentEventDetails = `
+SELECT scic.check_instance_id,
+       scic.version,
+       scic.monitoring_id,
+       sc.repository_id
+FROM   soma.check_instance_configurations scic
+JOIN   soma.check_instances sci
+  ON   scic.check_instance_id = sci.check_instance_id
+JOIN   soma.checks sc
+  ON   sci.check_id = sc.check_id
+WHERE  scic.check_instance_config_id = $1::uuid;`
 )
 
 func init() {
 	m[DeploymentActivate] = `DeploymentActivate`
 	m[DeploymentClearFlag] = `DeploymentClearFlag`
 	m[DeploymentDeprovision] = `DeploymentDeprovision`
+	m[DeploymentEventDetails] = `DeploymentEventDetails`
 	m[DeploymentGet] = `DeploymentGet`
 	m[DeploymentInstancesForNode] = `DeploymentInstancesForNode`
 	m[DeploymentLastInstanceVersion] = `DeploymentLastInstanceVersion`
 	m[DeploymentListAll] = `DeploymentListAll`
+	m[DeploymentListPage] = `DeploymentListPage`
 	m[DeploymentList] = `DeploymentList`
+	m[DeploymentMonitoringSecretByID] = `DeploymentMonitoringSecretByID`
+	m[DeploymentMonitoringSecret] = `DeploymentMonitoringSecret`
 	m[DeploymentStatus] = `DeploymentStatus`
 	m[DeploymentDeprovisionStyle] = `DeploymentDeprovisionStyle`
 	m[DeploymentUpdate] = `DeploymentUpdate`
@@ -67,7 +67,8 @@ WHERE  sci.check_instance_id = $1::uuid
   AND  NOT sci.deleted;`
 
 	// InstanceVersions returns the history of all the configurations that
-	// were created for a specific check instance
+	// were created for a specific check instance, including the most
+	// recent automatic rollback each configuration was part of
 	InstanceVersions = `
 SELECT scic.check_instance_config_id,
        scic.version,
@@ -81,19 +82,67 @@ SELECT scic.check_instance_config_id,
        scic.next_status,
        -- always set boolean values correctly, since GoLang zero
        -- values make it look like it was set even if it was not
-       (sc.object_id = sc.source_object_id)::boolean
+       (sc.object_id = sc.source_object_id)::boolean,
+       rb.created_at,
+       rb.version,
+       rs.created_at,
+       rs.version
 FROM   soma.check_instance_configurations scic
 JOIN   soma.check_instances sci
   ON   scic.check_instance_id = sci.check_instance_id
 JOIN   soma.checks sc
   ON   sci.check_id = sc.check_id
+LEFT   JOIN LATERAL (
+       SELECT   scir.created_at,
+                rscic.version
+       FROM     soma.check_instance_rollbacks scir
+       JOIN     soma.check_instance_configurations rscic
+         ON     scir.restored_instance_config_id = rscic.check_instance_config_id
+       WHERE    scir.failed_instance_config_id = scic.check_instance_config_id
+       ORDER BY scir.created_at DESC
+       LIMIT    1) rb
+  ON   true
+LEFT   JOIN LATERAL (
+       SELECT   scir.created_at,
+                fscic.version
+       FROM     soma.check_instance_rollbacks scir
+       JOIN     soma.check_instance_configurations fscic
+         ON     scir.failed_instance_config_id = fscic.check_instance_config_id
+       WHERE    scir.restored_instance_config_id = scic.check_instance_config_id
+       ORDER BY scir.created_at DESC
+       LIMIT    1) rs
+  ON   true
 WHERE  scic.check_instance_id  = $1::uuid;`
+
+	// InstanceConfigVersion returns the deployment details of a
+	// specific version of a check instance configuration
+	InstanceConfigVersion = `
+SELECT scic.version,
+       scic.deployment_details
+FROM   soma.check_instance_configurations scic
+WHERE  scic.check_instance_id = $1::uuid
+  AND  scic.version = $2::integer;`
+
+	// InstanceConfigPrevious returns the deployment details of the
+	// highest version of a check instance configuration that is lower
+	// than the provided version. If no version is provided, the most
+	// recent version is returned.
+	InstanceConfigPrevious = `
+SELECT scic.version,
+       scic.deployment_details
+FROM   soma.check_instance_configurations scic
+WHERE  scic.check_instance_id = $1::uuid
+  AND  ( $2::integer IS NULL OR scic.version < $2::integer )
+ORDER  BY scic.version DESC
+LIMIT  1;`
 )
 
 func init() {
 	m[InstanceScopedList] = `InstanceScopedList`
 	m[InstanceShow] = `InstanceShow`
 	m[InstanceVersions] = `InstanceVersions`
+	m[InstanceConfigVersion] = `InstanceConfigVersion`
+	m[InstanceConfigPrevious] = `InstanceConfigPrevious`
 }
 
 // vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -66,7 +66,7 @@ AND    scic.check_instance_config_id = sci.current_instance_config_id
 WHERE  (  scic.status = '` + proto.DeploymentAwaitingRollout + `'::varchar
        OR scic.status = '` + proto.DeploymentAwaitingDeprovision + `'::varchar)
 AND    sms.monitoring_callback_uri IS NOT NULL
-AND    sci.update_available;`
+AND    sci.update_available` + maintenanceHoldDeployment + rolloutHoldDeployment + `;`
 
 	LifecycleRescheduleDeployments = `
 SELECT scic.check_instance_id,
@@ -87,7 +87,7 @@ AND    scic.status_last_updated_at IS NOT NULL
 AND    scic.notified_at IS NOT NULL
 AND    NOW() > (scic.status_last_updated_at + '5 minute'::interval)
 AND    NOW() > (scic.notified_at + '5 minute'::interval)
-AND    NOT sci.update_available;`
+AND    NOT sci.update_available` + maintenanceHoldDeployment + `;`
 
 	LifecycleSetNotified = `
 UPDATE soma.check_instance_configurations scic
@@ -129,7 +129,8 @@ FROM   soma.check_instances sci
 WHERE  scic.check_instance_id = sci.check_instance_id
 AND    scic.status = '` + proto.DeploymentAwaitingRollout + `'::varchar
 AND    sci.deleted
-AND    sci.update_available;`
+AND    sci.update_available
+RETURNING scic.check_instance_config_id;`
 
 	LifecycleDeleteFailedRollouts = `
 UPDATE soma.check_instance_configurations scic
@@ -139,7 +140,8 @@ SET    status = '` + proto.DeploymentAwaitingDeletion + `'::varchar,
 FROM   soma.check_instances sci
 WHERE  scic.check_instance_id = sci.check_instance_id
 AND    sci.deleted
-AND    scic.status = '` + proto.DeploymentRolloutFailed + `'::varchar;`
+AND    scic.status = '` + proto.DeploymentRolloutFailed + `'::varchar
+RETURNING scic.check_instance_config_id;`
 
 	LifecycleDeleteDeprovisioned = `
 UPDATE soma.check_instance_configurations scic
@@ -150,7 +152,8 @@ FROM   soma.check_instances sci
 WHERE  scic.check_instance_id = sci.check_instance_id
 AND    sci.deleted
 AND    scic.status = '` + proto.DeploymentDeprovisioned + `'::varchar
-AND    scic.next_status = '` + proto.DeploymentNone + `'::varchar;`
+AND    scic.next_status = '` + proto.DeploymentNone + `'::varchar
+RETURNING scic.check_instance_config_id;`
 
 	LifecycleDeprovisionDeletedActive = `
 SELECT scic.check_instance_config_id,
@@ -178,6 +181,100 @@ JOIN   check_instance_configurations cic
 JOIN   check_instance_configuration_dependencies cicd
   ON   ci.current_instance_config_id = cicd.blocking_instance_config_id
 WHERE  cic.status = '` + proto.DeploymentActive + `'::varchar;`
+
+	LifecycleOutboxEnqueue = `
+WITH rearm AS (
+   UPDATE soma.notification_outbox
+   SET    delivery_state = '` + proto.NotificationPending + `'::varchar,
+          attempts = 0::integer,
+          last_error = NULL,
+          next_attempt_at = NOW()::timestamptz
+   WHERE  monitoring_id = $2::uuid
+     AND  check_instance_id = $3::uuid
+     AND  delivery_state = '` + proto.NotificationDead + `'::varchar
+   RETURNING notification_id)
+INSERT INTO soma.notification_outbox (
+            notification_id,
+            monitoring_id,
+            check_instance_id,
+            delivery_state)
+SELECT $1::uuid,
+       $2::uuid,
+       $3::uuid,
+       '` + proto.NotificationPending + `'::varchar
+WHERE  NOT EXISTS (
+   SELECT notification_id
+   FROM   rearm)
+  AND  NOT EXISTS (
+   SELECT notification_id
+   FROM   soma.notification_outbox
+   WHERE  monitoring_id = $2::uuid
+     AND  check_instance_id = $3::uuid
+     AND  delivery_state = '` + proto.NotificationPending + `'::varchar);`
+
+	LifecycleOutboxDueSystems = `
+SELECT DISTINCT snob.monitoring_id
+FROM   soma.notification_outbox snob
+JOIN   soma.monitoring_systems sms
+  ON   snob.monitoring_id = sms.monitoring_id
+JOIN   soma.check_instances sci
+  ON   snob.check_instance_id = sci.check_instance_id
+JOIN   soma.check_instance_configurations scic
+  ON   sci.current_instance_config_id = scic.check_instance_config_id
+WHERE  snob.delivery_state = '` + proto.NotificationPending + `'::varchar
+  AND  snob.next_attempt_at <= NOW()
+  AND  sms.monitoring_callback_uri IS NOT NULL` + maintenanceHoldDeployment + `;`
+
+	LifecycleOutboxDue = `
+SELECT   snob.notification_id,
+         snob.check_instance_id,
+         sms.monitoring_callback_uri,
+         snob.attempts,
+         snob.next_attempt_at,
+         sms.monitoring_batch_size,
+         sms.monitoring_batch_flush,
+         sms.monitoring_secret
+FROM     soma.notification_outbox snob
+JOIN     soma.monitoring_systems sms
+  ON     snob.monitoring_id = sms.monitoring_id
+JOIN     soma.check_instances sci
+  ON     snob.check_instance_id = sci.check_instance_id
+JOIN     soma.check_instance_configurations scic
+  ON     sci.current_instance_config_id = scic.check_instance_config_id
+WHERE    snob.monitoring_id = $1::uuid
+  AND    snob.delivery_state = '` + proto.NotificationPending + `'::varchar
+  AND    snob.next_attempt_at <= NOW()
+  AND    sms.monitoring_callback_uri IS NOT NULL` + maintenanceHoldDeployment + `
+ORDER BY snob.next_attempt_at,
+         snob.created_at
+LIMIT    4096;`
+
+	LifecycleOutboxDelivered = `
+UPDATE soma.notification_outbox
+SET    delivery_state = '` + proto.NotificationDelivered + `'::varchar,
+       attempts = attempts + 1,
+       last_error = NULL,
+       delivered_at = NOW()::timestamptz
+WHERE  notification_id = $1::uuid;`
+
+	LifecycleOutboxRetry = `
+UPDATE soma.notification_outbox
+SET    attempts = attempts + 1,
+       last_error = $2::text,
+       next_attempt_at = NOW()::timestamptz + ($3::integer * '1 second'::interval)
+WHERE  notification_id = $1::uuid;`
+
+	LifecycleOutboxDeadLetter = `
+UPDATE soma.notification_outbox
+SET    delivery_state = '` + proto.NotificationDead + `'::varchar,
+       attempts = attempts + 1,
+       last_error = $2::text
+WHERE  notification_id = $1::uuid;`
+
+	LifecycleOutboxCleanup = `
+DELETE FROM soma.notification_outbox
+WHERE       delivery_state = '` + proto.NotificationDelivered + `'::varchar
+  AND       delivered_at < (NOW() - '1 day'::interval);`
 )
 
 func init() {
@@ -192,6 +289,13 @@ func init() {
 	m[LifecycleDeleteGhosts] = `LifecycleDeleteGhosts`
 	m[LifecycleDeprovisionConfiguration] = `LifecycleDeprovisionConfiguration`
 	m[LifecycleDeprovisionDeletedActive] = `LifecycleDeprovisionDeletedActive`
+	m[LifecycleOutboxCleanup] = `LifecycleOutboxCleanup`
+	m[LifecycleOutboxDeadLetter] = `LifecycleOutboxDeadLetter`
+	m[LifecycleOutboxDelivered] = `LifecycleOutboxDelivered`
+	m[LifecycleOutboxDue] = `LifecycleOutboxDue`
+	m[LifecycleOutboxDueSystems] = `LifecycleOutboxDueSystems`
+	m[LifecycleOutboxEnqueue] = `LifecycleOutboxEnqueue`
+	m[LifecycleOutboxRetry] = `LifecycleOutboxRetry`
 	m[LifecycleReadyDeployments] = `LifecycleReadyDeployments`
 	m[LifecycleRescheduleDeployments] = `LifecycleRescheduleDeployments`
 	m[LifecycleSetNotified] = `LifecycleSetNotified`
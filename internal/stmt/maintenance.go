@@ -0,0 +1,134 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package stmt
+
+const (
+	MaintenanceStatements = ``
+
+	// MaintenanceList returns all maintenance windows that have not
+	// yet ended
+	MaintenanceList = `
+SELECT   smw.maintenance_window_id,
+         smw.scope,
+         smw.repository_id,
+         smw.bucket_id,
+         smw.environment,
+         smw.monitoring_id,
+         smw.description,
+         smw.starts_at,
+         smw.ends_at,
+         smw.hold_jobs,
+         smw.cancelled_at,
+         iu.uid,
+         smw.created_at
+FROM     soma.maintenance_windows smw
+JOIN     inventory.user iu
+  ON     smw.created_by = iu.id
+WHERE    smw.ends_at > NOW()
+ORDER BY smw.starts_at,
+         smw.ends_at;`
+
+	// MaintenanceShow returns the maintenance window with the
+	// given ID
+	MaintenanceShow = `
+SELECT smw.maintenance_window_id,
+       smw.scope,
+       smw.repository_id,
+       smw.bucket_id,
+       smw.environment,
+       smw.monitoring_id,
+       smw.description,
+       smw.starts_at,
+       smw.ends_at,
+       smw.hold_jobs,
+       smw.cancelled_at,
+       iu.uid,
+       smw.created_at
+FROM   soma.maintenance_windows smw
+JOIN   inventory.user iu
+  ON   smw.created_by = iu.id
+WHERE  smw.maintenance_window_id = $1::uuid;`
+
+	// MaintenanceAdd creates a new maintenance window
+	MaintenanceAdd = `
+INSERT INTO soma.maintenance_windows (
+            maintenance_window_id,
+            scope,
+            repository_id,
+            bucket_id,
+            environment,
+            monitoring_id,
+            description,
+            starts_at,
+            ends_at,
+            hold_jobs,
+            created_by)
+SELECT $1::uuid,
+       $2::varchar,
+       $3::uuid,
+       $4::uuid,
+       $5::varchar,
+       $6::uuid,
+       $7::text,
+       $8::timestamptz,
+       $9::timestamptz,
+       $10::boolean,
+       iu.id
+FROM   inventory.user iu
+WHERE  iu.uid = $11::varchar;`
+
+	// MaintenanceCancel ends a maintenance window that has not yet
+	// ended
+	MaintenanceCancel = `
+UPDATE soma.maintenance_windows
+SET    cancelled_at = NOW()::timestamptz
+WHERE  maintenance_window_id = $1::uuid
+  AND  cancelled_at IS NULL
+  AND  ends_at > NOW();`
+
+	// MaintenanceHoldJobs returns the number of active maintenance
+	// windows that hold the jobs of a repository
+	MaintenanceHoldJobs = `
+SELECT COUNT(1)::integer
+FROM   soma.maintenance_windows
+WHERE  repository_id = $1::uuid
+  AND  hold_jobs
+  AND  cancelled_at IS NULL
+  AND  NOW() BETWEEN starts_at AND ends_at;`
+
+	// maintenanceHoldDeployment is the condition that is appended
+	// to lifecycle statements to exclude check instances sci with
+	// configuration scic that are within the scope of an active
+	// maintenance window
+	maintenanceHoldDeployment = `
+AND    NOT EXISTS (
+       SELECT smw.maintenance_window_id
+       FROM   soma.maintenance_windows smw
+       JOIN   soma.checks sc
+         ON   sc.check_id = sci.check_id
+       LEFT JOIN soma.buckets sb
+         ON   sc.bucket_id = sb.bucket_id
+       WHERE  smw.cancelled_at IS NULL
+         AND  NOW() BETWEEN smw.starts_at AND smw.ends_at
+         AND  (   smw.repository_id = sc.repository_id
+               OR smw.bucket_id = sc.bucket_id
+               OR smw.environment = sb.environment
+               OR smw.monitoring_id = scic.monitoring_id))`
+)
+
+func init() {
+	m[MaintenanceAdd] = `MaintenanceAdd`
+	m[MaintenanceCancel] = `MaintenanceCancel`
+	m[MaintenanceHoldJobs] = `MaintenanceHoldJobs`
+	m[MaintenanceList] = `MaintenanceList`
+	m[MaintenanceShow] = `MaintenanceShow`
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -80,7 +80,9 @@ SELECT monitoring_id,
        monitoring_system_mode,
        monitoring_contact,
        monitoring_owner_team,
-       monitoring_callback_uri
+       monitoring_callback_uri,
+       monitoring_batch_size,
+       monitoring_batch_flush
 FROM   soma.monitoring_systems
 WHERE  monitoring_id = $1::uuid;`
 
@@ -96,19 +98,30 @@ INSERT INTO soma.monitoring_systems (
             monitoring_system_mode,
             monitoring_contact,
             monitoring_owner_team,
-            monitoring_callback_uri)
+            monitoring_callback_uri,
+            monitoring_batch_size,
+            monitoring_batch_flush,
+            monitoring_secret)
 SELECT  $1::uuid,
         $2::varchar,
         $3::varchar,
         $4::uuid,
         $5::uuid,
-        $6::text
+        $6::text,
+        $7::integer,
+        $8::integer,
+        $9::text
 WHERE   NOT EXISTS (
    SELECT monitoring_id
    FROM   soma.monitoring_systems
    WHERE  monitoring_id = $1::uuid
       OR  monitoring_name = $2::varchar);`
 
+	MonitoringSystemSecret = `
+UPDATE soma.monitoring_systems
+SET    monitoring_secret = $2::text
+WHERE  monitoring_id = $1::uuid;`
+
 	MonitoringSystemRemove = `
 DELETE FROM soma.monitoring_systems
 WHERE  monitoring_id = $1::uuid;`
@@ -119,6 +132,7 @@ func init() {
 	m[ListScopedMonitoringSystems] = `ListScopedMonitoringSystems`
 	m[MonitoringSystemAdd] = `MonitoringSystemAdd`
 	m[MonitoringSystemRemove] = `MonitoringSystemRemove`
+	m[MonitoringSystemSecret] = `MonitoringSystemSecret`
 	m[SearchAllMonitoringSystems] = `SearchAllMonitoringSystems`
 	m[SearchScopedMonitoringSystems] = `SearchScopedMonitoringSystems`
 	m[ShowMonitoringSystem] = `ShowMonitoringSystem`
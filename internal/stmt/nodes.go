@@ -54,6 +54,11 @@ SELECT snba.bucket_id
 FROM   soma.node_bucket_assignment snba
 WHERE  snba.node_id = $1;`
 
+	NodeServerID = `
+SELECT server_id
+FROM   soma.nodes
+WHERE  node_id = $1::uuid;`
+
 	NodeSync = `
 SELECT node_id,
        node_asset_id,
@@ -151,8 +156,11 @@ SELECT    sn.node_asset_id,
           sn.organizational_team_id,
           sn.server_id,
           sn.node_online,
-          sn.node_deleted
+          sn.node_deleted,
+          ins.server_name
 FROM      soma.nodes sn
+JOIN      inventory.servers ins
+ON        sn.server_id = ins.server_id
 LEFT JOIN soma.node_bucket_assignment snba
 ON        sn.node_id = snba.node_id
 WHERE     sn.node_online = 'yes'
@@ -229,6 +237,7 @@ func init() {
 	m[NodeServicePropertyForDelete] = `NodeServicePropertyForDelete`
 	m[NodeShowConfig] = `NodeShowConfig`
 	m[NodeShow] = `NodeShow`
+	m[NodeServerID] = `NodeServerID`
 	m[NodeSvcProps] = `NodeSvcProps`
 	m[NodeSync] = `NodeSync`
 	m[NodeSysProps] = `NodeSysProps`
@@ -0,0 +1,60 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package stmt
+
+import (
+	"github.com/mjolnir42/soma/lib/proto"
+)
+
+const (
+	OutboxStatements = ``
+
+	// OutboxList returns all undelivered notifications for a
+	// monitoring system
+	OutboxList = `
+SELECT   notification_id,
+         monitoring_id,
+         check_instance_id,
+         delivery_state,
+         attempts,
+         last_error,
+         created_at,
+         next_attempt_at
+FROM     soma.notification_outbox
+WHERE    monitoring_id = $1::uuid
+  AND    delivery_state != '` + proto.NotificationDelivered + `'::varchar
+ORDER BY next_attempt_at,
+         created_at;`
+
+	// OutboxReplay resets all undelivered notifications of a
+	// monitoring system to be delivered immediately
+	OutboxReplay = `
+UPDATE soma.notification_outbox
+SET    delivery_state = '` + proto.NotificationPending + `'::varchar,
+       attempts = 0::integer,
+       next_attempt_at = NOW()::timestamptz
+WHERE  monitoring_id = $1::uuid
+  AND  delivery_state != '` + proto.NotificationDelivered + `'::varchar;`
+
+	// OutboxPurge removes all dead notifications of a monitoring
+	// system
+	OutboxPurge = `
+DELETE FROM soma.notification_outbox
+WHERE       monitoring_id = $1::uuid
+  AND       delivery_state = '` + proto.NotificationDead + `'::varchar;`
+)
+
+func init() {
+	m[OutboxList] = `OutboxList`
+	m[OutboxPurge] = `OutboxPurge`
+	m[OutboxReplay] = `OutboxReplay`
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
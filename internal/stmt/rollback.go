@@ -0,0 +1,127 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package stmt
+
+const (
+	RollbackStatements = ``
+
+	// RollbackPolicyEnableRepository enables automatic rollback of
+	// failed rollouts for all check configurations in repository $2
+	RollbackPolicyEnableRepository = `
+INSERT INTO soma.rollback_policies (
+            rollback_policy_id,
+            repository_id,
+            configuration_id,
+            created_by)
+SELECT $1::uuid,
+       sr.id,
+       NULL::uuid,
+       iu.id
+FROM   soma.repository sr,
+       inventory.user iu
+WHERE  sr.id = $2::uuid
+  AND  NOT sr.is_deleted
+  AND  iu.uid = $3::varchar
+ON CONFLICT ( repository_id ) WHERE configuration_id IS NULL DO NOTHING;`
+
+	// RollbackPolicyDisableRepository disables the repository wide
+	// automatic rollback policy of repository $1
+	RollbackPolicyDisableRepository = `
+DELETE FROM soma.rollback_policies
+WHERE       repository_id = $1::uuid
+  AND       configuration_id IS NULL;`
+
+	// RollbackPolicyEnableConfig enables automatic rollback of failed
+	// rollouts for check configuration $2 in repository $3
+	RollbackPolicyEnableConfig = `
+INSERT INTO soma.rollback_policies (
+            rollback_policy_id,
+            repository_id,
+            configuration_id,
+            created_by)
+SELECT $1::uuid,
+       scc.repository_id,
+       scc.configuration_id,
+       iu.id
+FROM   soma.check_configurations scc,
+       inventory.user iu
+WHERE  scc.configuration_id = $2::uuid
+  AND  scc.repository_id = $3::uuid
+  AND  NOT scc.deleted
+  AND  iu.uid = $4::varchar
+ON CONFLICT ( configuration_id ) WHERE configuration_id IS NOT NULL DO NOTHING;`
+
+	// RollbackPolicyDisableConfig disables the automatic rollback
+	// policy of check configuration $1 in repository $2
+	RollbackPolicyDisableConfig = `
+DELETE FROM soma.rollback_policies
+WHERE       configuration_id = $1::uuid
+  AND       repository_id = $2::uuid;`
+
+	// RollbackPolicyApplies returns true if failed rollouts of check
+	// instance $1 are rolled back, either by a policy for its check
+	// configuration or for its repository
+	RollbackPolicyApplies = `
+SELECT EXISTS (
+       SELECT srp.rollback_policy_id
+       FROM   soma.check_instances sci
+       JOIN   soma.check_configurations scc
+         ON   sci.check_configuration_id = scc.configuration_id
+       JOIN   soma.rollback_policies srp
+         ON   scc.repository_id = srp.repository_id
+        AND   (   srp.configuration_id IS NULL
+               OR srp.configuration_id = scc.configuration_id)
+       WHERE  sci.check_instance_id = $1::uuid
+         AND  NOT sci.deleted
+         AND  NOT scc.deleted)::boolean;`
+
+	// RollbackTarget returns the most recent version of check
+	// instance $1 that was active before check instance configuration
+	// $2 was rolled out
+	RollbackTarget = `
+SELECT   scic.check_instance_config_id,
+         scic.version,
+         scic.status
+FROM     soma.check_instance_configurations scic
+WHERE    scic.check_instance_id = $1::uuid
+  AND    scic.activated_at IS NOT NULL
+  AND    scic.version < (
+         SELECT fscic.version
+         FROM   soma.check_instance_configurations fscic
+         WHERE  fscic.check_instance_config_id = $2::uuid)
+ORDER BY scic.version DESC
+LIMIT    1;`
+
+	// RollbackRecord records that the failed check instance
+	// configuration $3 of check instance $2 was rolled back to
+	// configuration $4
+	RollbackRecord = `
+INSERT INTO soma.check_instance_rollbacks (
+            rollback_id,
+            check_instance_id,
+            failed_instance_config_id,
+            restored_instance_config_id)
+SELECT $1::uuid,
+       $2::uuid,
+       $3::uuid,
+       $4::uuid;`
+)
+
+func init() {
+	m[RollbackPolicyApplies] = `RollbackPolicyApplies`
+	m[RollbackPolicyDisableConfig] = `RollbackPolicyDisableConfig`
+	m[RollbackPolicyDisableRepository] = `RollbackPolicyDisableRepository`
+	m[RollbackPolicyEnableConfig] = `RollbackPolicyEnableConfig`
+	m[RollbackPolicyEnableRepository] = `RollbackPolicyEnableRepository`
+	m[RollbackRecord] = `RollbackRecord`
+	m[RollbackTarget] = `RollbackTarget`
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
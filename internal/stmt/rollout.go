@@ -0,0 +1,157 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package stmt
+
+import (
+	"github.com/mjolnir42/soma/lib/proto"
+)
+
+const (
+	RolloutStatements = ``
+
+	// RolloutStatus returns the rollout state of all check
+	// configurations with a staged rollout policy, together with
+	// the deployment state counts of their check instances
+	RolloutStatus = `
+SELECT   sccr.configuration_id,
+         sccr.wave_size,
+         sccr.wave_percent,
+         sccr.failure_threshold,
+         sccr.wave,
+         sccr.halted_at IS NOT NULL,
+         (   sccr.wave_released_at IS NULL
+          OR NOW() >= sccr.wave_released_at + sccr.soak_seconds * '1 second'::interval),
+         COUNT(sci.check_instance_id) FILTER (
+             WHERE scic.status = '` + proto.DeploymentAwaitingRollout + `'::varchar
+               AND sci.update_available),
+         COUNT(sci.check_instance_id) FILTER (
+             WHERE (    scic.status = '` + proto.DeploymentAwaitingRollout + `'::varchar
+                    AND NOT sci.update_available)
+                OR scic.status = '` + proto.DeploymentRolloutInProgress + `'::varchar),
+         -- failed versions may have been replaced by an automatic
+         -- rollback, they are counted across all versions
+         (SELECT COUNT(DISTINCT fscic.check_instance_id)
+          FROM   soma.check_instances fsci
+          JOIN   soma.check_instance_configurations fscic
+            ON   fsci.check_instance_id = fscic.check_instance_id
+          WHERE  fsci.check_configuration_id = sccr.configuration_id
+            AND  NOT fsci.deleted
+            AND  fscic.status = '` + proto.DeploymentRolloutFailed + `'::varchar
+            AND  sccr.rollout_started_at IS NOT NULL
+            AND  fscic.status_last_updated_at >= sccr.rollout_started_at),
+         COUNT(sci.check_instance_id)
+FROM     soma.check_configuration_rollout sccr
+JOIN     soma.check_instances sci
+  ON     sccr.configuration_id = sci.check_configuration_id
+JOIN     soma.check_instance_configurations scic
+  ON     sci.check_instance_id = scic.check_instance_id
+ AND     sci.current_instance_config_id = scic.check_instance_config_id
+WHERE    NOT sci.deleted
+GROUP BY sccr.configuration_id;`
+
+	// RolloutWave returns up to $2 check instances of check
+	// configuration $1 that are waiting for their release
+	RolloutWave = `
+SELECT   scic.check_instance_id,
+         scic.monitoring_id
+FROM     soma.check_instance_configurations scic
+JOIN     soma.monitoring_systems sms
+  ON     scic.monitoring_id = sms.monitoring_id
+JOIN     soma.check_instances sci
+  ON     scic.check_instance_id = sci.check_instance_id
+ AND     scic.check_instance_config_id = sci.current_instance_config_id
+WHERE    sci.check_configuration_id = $1::uuid
+  AND    scic.status = '` + proto.DeploymentAwaitingRollout + `'::varchar
+  AND    sms.monitoring_callback_uri IS NOT NULL
+  AND    sci.update_available
+  AND    NOT sci.deleted
+  AND    NOT EXISTS (
+         SELECT scir.rollback_id
+         FROM   soma.check_instance_rollbacks scir
+         WHERE  scir.restored_instance_config_id = scic.check_instance_config_id)` + maintenanceHoldDeployment + `
+ORDER BY scic.created,
+         scic.check_instance_id
+LIMIT    $2::integer;`
+
+	// RolloutWaveReleased records that the next wave of check
+	// configuration $1 has been released
+	RolloutWaveReleased = `
+UPDATE soma.check_configuration_rollout
+SET    wave = wave + 1,
+       wave_released_at = NOW()::timestamptz,
+       rollout_started_at = COALESCE(rollout_started_at, NOW()::timestamptz)
+WHERE  configuration_id = $1::uuid;`
+
+	// RolloutHalt halts the rollout of check configuration $1
+	RolloutHalt = `
+UPDATE soma.check_configuration_rollout
+SET    halted_at = NOW()::timestamptz
+WHERE  configuration_id = $1::uuid
+  AND  halted_at IS NULL;`
+
+	// RolloutComplete resets the rollout state of check
+	// configuration $1 once all waves have been rolled out
+	RolloutComplete = `
+UPDATE soma.check_configuration_rollout
+SET    wave = 0,
+       wave_released_at = NULL,
+       rollout_started_at = NULL
+WHERE  configuration_id = $1::uuid
+  AND  wave > 0
+  AND  halted_at IS NULL;`
+
+	// RolloutResume resumes the halted rollout of check
+	// configuration $1 in repository $2. Failures reported before
+	// the resume no longer count towards the failure threshold.
+	RolloutResume = `
+UPDATE soma.check_configuration_rollout sccr
+SET    halted_at = NULL,
+       rollout_started_at = NOW()::timestamptz
+FROM   soma.check_configurations scc
+WHERE  sccr.configuration_id = scc.configuration_id
+  AND  scc.configuration_id = $1::uuid
+  AND  scc.repository_id = $2::uuid
+  AND  NOT scc.deleted
+  AND  sccr.halted_at IS NOT NULL;`
+
+	// rolloutHoldDeployment is appended to deployment selection
+	// statements to exclude check instances whose release is
+	// controlled by the staged rollout policy of their check
+	// configuration. Versions restored by an automatic rollback are
+	// not held back. Waves are pushed to the callback of the
+	// monitoring system, instances of monitoring systems without a
+	// callback are not held back either.
+	rolloutHoldDeployment = `
+AND    NOT (  scic.status = '` + proto.DeploymentAwaitingRollout + `'::varchar
+          AND EXISTS (
+              SELECT sccr.configuration_id
+              FROM   soma.check_configuration_rollout sccr
+              WHERE  sccr.configuration_id = sci.check_configuration_id)
+          AND EXISTS (
+              SELECT hsms.monitoring_id
+              FROM   soma.monitoring_systems hsms
+              WHERE  hsms.monitoring_id = scic.monitoring_id
+                AND  hsms.monitoring_callback_uri IS NOT NULL)
+          AND NOT EXISTS (
+              SELECT scir.rollback_id
+              FROM   soma.check_instance_rollbacks scir
+              WHERE  scir.restored_instance_config_id = scic.check_instance_config_id))`
+)
+
+func init() {
+	m[RolloutComplete] = `RolloutComplete`
+	m[RolloutHalt] = `RolloutHalt`
+	m[RolloutResume] = `RolloutResume`
+	m[RolloutStatus] = `RolloutStatus`
+	m[RolloutWave] = `RolloutWave`
+	m[RolloutWaveReleased] = `RolloutWaveReleased`
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -41,6 +41,19 @@ SELECT server_id,
        server_online,
        server_deleted
 FROM   inventory.servers
+WHERE  server_id = $1::uuid;`
+
+	ServerAssignedNodes = `
+SELECT sn.node_id,
+       snba.bucket_id
+FROM   soma.nodes sn
+JOIN   soma.node_bucket_assignment snba
+  ON   sn.node_id = snba.node_id
+WHERE  sn.server_id = $1::uuid;`
+
+	ServerNameByID = `
+SELECT server_name
+FROM   inventory.servers
 WHERE  server_id = $1::uuid;`
 
 	SearchServer = `
@@ -101,6 +114,8 @@ func init() {
 	m[ListServers] = `ListServers`
 	m[PurgeServers] = `PurgeServers`
 	m[SearchServer] = `SearchServer`
+	m[ServerAssignedNodes] = `ServerAssignedNodes`
+	m[ServerNameByID] = `ServerNameByID`
 	m[ShowServers] = `ShowServers`
 	m[SyncServers] = `SyncServers`
 	m[UpdateServers] = `UpdateServers`
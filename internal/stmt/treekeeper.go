@@ -40,16 +40,22 @@ JOIN   soma.check_instance_configurations scic
 WHERE  scic.status = '` + proto.DeploymentComputed + `'::varchar
   AND  sc.repository_id = $1::uuid;`
 
+	// TreekeeperGetPreviousDeployment prefers the current check
+	// instance configuration, which is not the highest version if a
+	// failed rollout was rolled back
 	TreekeeperGetPreviousDeployment = `
-SELECT check_instance_config_id,
-       version,
-       status,
-       deployment_details
-FROM   soma.check_instance_configurations
-WHERE  status != '` + proto.DeploymentComputed + `'::varchar
-AND    status != '` + proto.DeploymentAwaitingComputation + `'::varchar
-AND    check_instance_id = $1::uuid
-ORDER  BY version DESC
+SELECT scic.check_instance_config_id,
+       scic.version,
+       scic.status,
+       scic.deployment_details
+FROM   soma.check_instance_configurations scic
+JOIN   soma.check_instances sci
+  ON   scic.check_instance_id = sci.check_instance_id
+WHERE  scic.status != '` + proto.DeploymentComputed + `'::varchar
+AND    scic.status != '` + proto.DeploymentAwaitingComputation + `'::varchar
+AND    scic.check_instance_id = $1::uuid
+ORDER  BY (scic.check_instance_config_id = sci.current_instance_config_id) DESC,
+          scic.version DESC
 LIMIT  1;`
 
 	TreekeeperUpdateConfigStatus = `
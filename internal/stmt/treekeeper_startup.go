@@ -93,7 +93,8 @@ WHERE  configuration_id = $1::uuid;`
 	TkStartLoadCheckConstraintCustom = `
 SELECT sccp.custom_property_id,
        scp.custom_property,
-       sccp.property_value
+       sccp.property_value,
+       sccp.operator
 FROM   soma.constraints_custom_property sccp
 JOIN   soma.custom_properties scp
 ON     sccp.custom_property_id = scp.custom_property_id
@@ -101,12 +102,13 @@ AND    sccp.repository_id = scp.repository_id
 WHERE  configuration_id = $1::uuid;`
 
 	// do not get distracted by the squirrels! All constraint
-	// statements are constructed to use three result variables,
+	// statements are constructed to use four result variables,
 	// so they can be loaded in one unified loop.
 	TkStartLoadCheckConstraintNative = `
 SELECT native_property,
        property_value,
-       'squirrel'
+       'squirrel',
+       operator
 FROM   soma.constraints_native_property
 WHERE  configuration_id = $1::uuid;`
 
@@ -114,7 +116,8 @@ WHERE  configuration_id = $1::uuid;`
 	TkStartLoadCheckConstraintOncall = `
 SELECT scop.oncall_duty_id,
        name,
-       phone_number
+       phone_number,
+       '=='
 FROM   soma.constraints_oncall_property scop
 JOIN   inventory.oncall_team iot
 ON     scop.oncall_duty_id = iot.id
@@ -123,24 +126,55 @@ WHERE  scop.configuration_id = $1::uuid;`
 	TkStartLoadCheckConstraintAttribute = `
 SELECT attribute,
        value,
-       'squirrel'
+       'squirrel',
+       operator
 FROM   soma.constraints_service_attribute
 WHERE  configuration_id = $1::uuid;`
 
 	TkStartLoadCheckConstraintService = `
 SELECT team_id,
        name,
-       'squirrel'
+       'squirrel',
+       operator
 FROM   soma.constraints_service_property
 WHERE  configuration_id = $1::uuid;`
 
 	TkStartLoadCheckConstraintSystem = `
 SELECT system_property,
        property_value,
-       'squirrel'
+       'squirrel',
+       operator
 FROM   soma.constraints_system_property
 WHERE  configuration_id = $1::uuid;`
 
+	TkStartLoadCheckConstraintGroups = `
+SELECT groups
+FROM   soma.check_configuration_constraint_groups
+WHERE  configuration_id = $1::uuid;`
+
+	TkStartLoadThresholdOverrides = `
+SELECT sto.override_id,
+       sto.configuration_id,
+       sto.object_id,
+       sto.object_type
+FROM   soma.threshold_overrides sto
+JOIN   soma.check_configurations scc
+ON     sto.configuration_id = scc.configuration_id
+WHERE  sto.repository_id = $1::uuid
+  AND  NOT sto.deleted
+  AND  NOT scc.deleted;`
+
+	TkStartLoadThresholdOverrideThresholds = `
+SELECT stot.predicate,
+       stot.threshold,
+       snl.level_name,
+       snl.level_shortname,
+       snl.level_numeric
+FROM   soma.threshold_override_thresholds stot
+JOIN   soma.notification_levels snl
+ON     stot.notification_level = snl.level_name
+WHERE  stot.override_id = $1::uuid;`
+
 	TkStartLoadCheckInstances = `
 SELECT check_instance_id,
        check_configuration_id
@@ -259,7 +293,8 @@ SELECT    sn.node_id,
           sn.node_deleted,
           snba.bucket_id,
           scm.cluster_id,
-          sgmn.group_id
+          sgmn.group_id,
+          ins.server_name
 FROM      soma.repository
 JOIN      soma.buckets sb
 ON        soma.repository.id = sb.repository_id
@@ -267,6 +302,8 @@ JOIN      soma.node_bucket_assignment snba
 ON        sb.bucket_id = snba.bucket_id
 JOIN      soma.nodes sn
 ON        snba.node_id = sn.node_id
+JOIN      inventory.servers ins
+ON        sn.server_id = ins.server_id
 LEFT JOIN soma.cluster_membership scm
 ON        sn.node_id = scm.node_id
 LEFT JOIN soma.group_membership_nodes sgmn
@@ -854,6 +891,7 @@ func init() {
 	m[TkStartLoadCheckConfiguration] = `TkStartLoadCheckConfiguration`
 	m[TkStartLoadCheckConstraintAttribute] = `TkStartLoadCheckConstraintAttribute`
 	m[TkStartLoadCheckConstraintCustom] = `TkStartLoadCheckConstraintCustom`
+	m[TkStartLoadCheckConstraintGroups] = `TkStartLoadCheckConstraintGroups`
 	m[TkStartLoadCheckConstraintNative] = `TkStartLoadCheckConstraintNative`
 	m[TkStartLoadCheckConstraintOncall] = `TkStartLoadCheckConstraintOncall`
 	m[TkStartLoadCheckConstraintService] = `TkStartLoadCheckConstraintService`
@@ -896,6 +934,8 @@ func init() {
 	m[TkStartLoadRepositoryCstProp] = `TkStartLoadRepositoryCstProp`
 	m[TkStartLoadServicePropInstances] = `TkStartLoadServicePropInstances`
 	m[TkStartLoadSystemPropInstances] = `TkStartLoadSystemPropInstances`
+	m[TkStartLoadThresholdOverrideThresholds] = `TkStartLoadThresholdOverrideThresholds`
+	m[TkStartLoadThresholdOverrides] = `TkStartLoadThresholdOverrides`
 }
 
 // vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
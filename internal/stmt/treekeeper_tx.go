@@ -87,7 +87,9 @@ INSERT INTO soma.check_instance_configurations (
             status,
             next_status,
             awaiting_deletion,
-            deployment_details)
+            deployment_details,
+            threshold_override_id,
+            threshold_values)
 SELECT $1::uuid,
        $2::integer,
        $3::uuid,
@@ -100,7 +102,9 @@ SELECT $1::uuid,
        $10::varchar,
        $11::varchar,
        $12::boolean,
-       $13::jsonb;`
+       $13::jsonb,
+       $14::uuid,
+       $15::jsonb;`
 
 	TxCreateCheckConfigurationBase = `
 INSERT INTO soma.check_configurations (
@@ -146,19 +150,23 @@ SELECT $1::uuid,
 INSERT INTO soma.constraints_system_property (
             configuration_id,
             system_property,
-            property_value)
+            property_value,
+            operator)
 SELECT $1::uuid,
        $2::varchar,
-       $3::text;`
+       $3::text,
+       $4::varchar;`
 
 	TxCreateCheckConfigurationConstraintNative = `
 INSERT INTO soma.constraints_native_property (
             configuration_id,
             native_property,
-            property_value)
+            property_value,
+            operator)
 SELECT $1::uuid,
        $2::varchar,
-       $3::text;`
+       $3::text,
+       $4::varchar;`
 
 	TxCreateCheckConfigurationConstraintOncall = `
 INSERT INTO soma.constraints_oncall_property (
@@ -172,29 +180,96 @@ INSERT INTO soma.constraints_custom_property (
             configuration_id,
             custom_property_id,
             repository_id,
-            property_value)
+            property_value,
+            operator)
 SELECT $1::uuid,
        $2::uuid,
        $3::uuid,
-       $4::text;`
+       $4::text,
+       $5::varchar;`
 
 	TxCreateCheckConfigurationConstraintService = `
 INSERT INTO soma.constraints_service_property (
             configuration_id,
             team_id,
-            name)
+            name,
+            operator)
 SELECT $1::uuid,
        $2::uuid,
-       $3::varchar;`
+       $3::varchar,
+       $4::varchar;`
 
 	TxCreateCheckConfigurationConstraintAttribute = `
 INSERT INTO soma.constraints_service_attribute (
             configuration_id,
             attribute,
-            value)
+            value,
+            operator)
+SELECT $1::uuid,
+       $2::varchar,
+       $3::varchar,
+       $4::varchar;`
+
+	TxCreateCheckConfigurationRollout = `
+INSERT INTO soma.check_configuration_rollout (
+            configuration_id,
+            wave_size,
+            wave_percent,
+            soak_seconds,
+            failure_threshold)
+SELECT $1::uuid,
+       $2::integer,
+       $3::integer,
+       $4::integer,
+       $5::integer;`
+
+	TxCreateCheckConfigurationConstraintGroups = `
+INSERT INTO soma.check_configuration_constraint_groups (
+            configuration_id,
+            groups)
+SELECT $1::uuid,
+       $2::jsonb;`
+
+	TxUpdateCheckConfigurationInterval = `
+UPDATE soma.check_configurations
+SET    interval = $2::integer
+WHERE  configuration_id = $1::uuid
+  AND  NOT deleted;`
+
+	TxDeleteCheckConfigurationThresholds = `
+DELETE FROM soma.configuration_thresholds
+WHERE       configuration_id = $1::uuid;`
+
+	TxCreateThresholdOverride = `
+INSERT INTO soma.threshold_overrides (
+            override_id,
+            configuration_id,
+            repository_id,
+            object_id,
+            object_type)
+SELECT $1::uuid,
+       $2::uuid,
+       $3::uuid,
+       $4::uuid,
+       $5::varchar;`
+
+	TxCreateThresholdOverrideThreshold = `
+INSERT INTO soma.threshold_override_thresholds (
+            override_id,
+            predicate,
+            threshold,
+            notification_level)
 SELECT $1::uuid,
        $2::varchar,
-       $3::varchar;`
+       $3::varchar,
+       $4::varchar;`
+
+	TxMarkThresholdOverrideDeleted = `
+UPDATE soma.threshold_overrides
+SET    deleted = 'yes'::boolean
+WHERE  configuration_id = $1::uuid
+  AND  object_id = $2::uuid
+  AND  NOT deleted;`
 
 	TxPropertyInstanceCreate = `
 INSERT INTO soma.property_instances (
@@ -1014,10 +1089,16 @@ SELECT scic.version,
        scic.instance_service_cfg_hash,
        scic.instance_service_cfg,
        sci.check_id,
-       sci.check_configuration_id
+       sci.check_configuration_id,
+       scic.threshold_override_id,
+       sto.object_id,
+       sto.object_type,
+       scic.threshold_values
 FROM   soma.check_instance_configurations scic
 JOIN   soma.check_instances sci
 ON     scic.check_instance_id = sci.check_instance_id
+LEFT   JOIN soma.threshold_overrides sto
+ON     scic.threshold_override_id = sto.override_id
 WHERE  scic.check_instance_config_id = $1::uuid;`
 
 	TxDeployDetailsCheck = `
@@ -1055,6 +1136,17 @@ JOIN   soma.notification_levels snl
 ON     sct.notification_level = snl.level_name
 WHERE  sct.configuration_id = $1::uuid;`
 
+	TxDeployDetailsThresholdOverride = `
+SELECT stot.predicate,
+       stot.threshold,
+       stot.notification_level,
+       snl.level_shortname,
+       snl.level_numeric
+FROM   soma.threshold_override_thresholds stot
+JOIN   soma.notification_levels snl
+ON     stot.notification_level = snl.level_name
+WHERE  stot.override_id = $1::uuid;`
+
 	TxDeployDetailsCapabilityMonitoringMetric = `
 SELECT smc.capability_metric,
        smc.capability_monitoring,
@@ -1301,11 +1393,16 @@ func init() {
 	m[TxCreateCheckConfigurationConstraintOncall] = `TxCreateCheckConfigurationConstraintOncall`
 	m[TxCreateCheckConfigurationConstraintService] = `TxCreateCheckConfigurationConstraintService`
 	m[TxCreateCheckConfigurationConstraintSystem] = `TxCreateCheckConfigurationConstraintSystem`
+	m[TxCreateCheckConfigurationRollout] = `TxCreateCheckConfigurationRollout`
+	m[TxCreateCheckConfigurationConstraintGroups] = `TxCreateCheckConfigurationConstraintGroups`
 	m[TxCreateCheckConfigurationThreshold] = `TxCreateCheckConfigurationThreshold`
 	m[TxCreateCheckInstanceConfiguration] = `TxCreateCheckInstanceConfiguration`
 	m[TxCreateCheckInstance] = `TxCreateCheckInstance`
 	m[TxCreateCheck] = `TxCreateCheck`
+	m[TxCreateThresholdOverrideThreshold] = `TxCreateThresholdOverrideThreshold`
+	m[TxCreateThresholdOverride] = `TxCreateThresholdOverride`
 	m[TxDeferAllConstraints] = `TxDeferAllConstraints`
+	m[TxDeleteCheckConfigurationThresholds] = `TxDeleteCheckConfigurationThresholds`
 	m[TxDeployDetailClusterCustProp] = `TxDeployDetailClusterCustProp`
 	m[TxDeployDetailClusterSysProp] = `TxDeployDetailClusterSysProp`
 	m[TxDeployDetailDefaultDatacenter] = `TxDeployDetailDefaultDatacenter`
@@ -1330,6 +1427,7 @@ func init() {
 	m[TxDeployDetailsNode] = `TxDeployDetailsNode`
 	m[TxDeployDetailsProviders] = `TxDeployDetailsProviders`
 	m[TxDeployDetailsTeam] = `TxDeployDetailsTeam`
+	m[TxDeployDetailsThresholdOverride] = `TxDeployDetailsThresholdOverride`
 	m[TxDeployDetailsUpdate] = `TxDeployDetailsUpdate`
 	m[TxFinishJob] = `TxFinishJob`
 	m[TxGroupCreate] = `TxGroupCreate`
@@ -1352,6 +1450,7 @@ func init() {
 	m[TxMarkCheckConfigDeleted] = `TxMarkCheckConfigDeleted`
 	m[TxMarkCheckDeleted] = `TxMarkCheckDeleted`
 	m[TxMarkCheckInstanceDeleted] = `TxMarkCheckInstanceDeleted`
+	m[TxMarkThresholdOverrideDeleted] = `TxMarkThresholdOverrideDeleted`
 	m[TxNodePropertyCustomCreate] = `TxNodePropertyCustomCreate`
 	m[TxNodePropertyCustomDelete] = `TxNodePropertyCustomDelete`
 	m[TxNodePropertyOncallCreate] = `TxNodePropertyOncallCreate`
@@ -1371,6 +1470,7 @@ func init() {
 	m[TxRepositoryPropertyServiceDelete] = `TxRepositoryPropertyServiceDelete`
 	m[TxRepositoryPropertySystemCreate] = `TxRepositoryPropertySystemCreate`
 	m[TxRepositoryPropertySystemDelete] = `TxRepositoryPropertySystemDelete`
+	m[TxUpdateCheckConfigurationInterval] = `TxUpdateCheckConfigurationInterval`
 	m[TxUpdateNodeState] = `TxUpdateNodeState`
 	m[TxRepositoryDestroy] = `TxRepositoryDestroy`
 	m[TxRepositoryRename] = `TxRepositoryRename`
@@ -130,6 +130,14 @@ func (ten *Node) SetName(s string) {
 	ten.actionRename()
 }
 
+// SetServer moves the node to the server with the given ID and name.
+// The check instances of the node are updated by the next
+// ComputeCheckInstances.
+func (ten *Node) SetServer(id, name string) {
+	ten.ServerID, _ = uuid.FromString(id)
+	ten.ServerName = name
+}
+
 func (ten *Node) inheritTeamID(newTeamID string) {
 	ten.Team, _ = uuid.FromString(newTeamID)
 	ten.actionRepossess()
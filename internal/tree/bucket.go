@@ -34,6 +34,7 @@ type Bucket struct {
 	PropertySystem  map[string]Property
 	PropertyCustom  map[string]Property
 	Checks          map[string]Check
+	Overrides       map[string]ThresholdOverride
 	Children        map[string]BucketAttacher `json:"-"`
 	Action          chan *Action              `json:"-"`
 	ordNumChildGrp  int
@@ -80,6 +81,7 @@ func NewBucket(spec BucketSpec) *Bucket {
 	teb.PropertySystem = make(map[string]Property)
 	teb.PropertyCustom = make(map[string]Property)
 	teb.Checks = make(map[string]Check)
+	teb.Overrides = make(map[string]ThresholdOverride)
 	teb.ordNumChildGrp = 0
 	teb.ordNumChildClr = 0
 	teb.ordNumChildNod = 0
@@ -143,6 +145,12 @@ func (teb Bucket) CloneRepository() RepositoryAttacher {
 	}
 	cl.Checks = cK
 
+	cO := make(map[string]ThresholdOverride)
+	for k, o := range teb.Overrides {
+		cO[k] = o.Clone()
+	}
+	cl.Overrides = cO
+
 	chLG := make(map[int]string)
 	for i, s := range teb.ordChildrenGrp {
 		chLG[i] = s
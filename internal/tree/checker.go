@@ -14,8 +14,10 @@ import (
 	"encoding/json"
 	"io"
 	"sort"
+	"strconv"
 
 	"github.com/mjolnir42/soma/lib/proto"
+	"github.com/mjolnir42/soma/lib/threshold"
 
 	"github.com/satori/go.uuid"
 )
@@ -24,6 +26,7 @@ type Checker interface {
 	SetCheck(c Check)
 	LoadInstance(i CheckInstance)
 	DeleteCheck(c Check)
+	UpdateCheck(c Check)
 
 	setCheckInherited(c Check)
 	setCheckOnChildren(c Check)
@@ -34,6 +37,10 @@ type Checker interface {
 	deleteCheckLocalAll()
 	rmCheck(c Check)
 
+	updateCheckInherited(c Check)
+	updateCheckOnChildren(c Check)
+	updateCheck(c Check)
+
 	syncCheck(childID string)
 	checkCheck(checkID string) bool
 }
@@ -68,6 +75,9 @@ type Check struct {
 	Thresholds    []CheckThreshold
 	Constraints   []CheckConstraint
 	Items         []CheckItem
+	// ConstraintGroups must all be fulfilled in addition to
+	// Constraints
+	ConstraintGroups []CheckConstraintGroup
 }
 
 func (c *Check) Clone() Check {
@@ -95,6 +105,11 @@ func (c *Check) Clone() Check {
 		ng.Constraints[i] = c.Constraints[i].Clone()
 	}
 
+	ng.ConstraintGroups = make([]CheckConstraintGroup, len(c.ConstraintGroups))
+	for i := range c.ConstraintGroups {
+		ng.ConstraintGroups[i] = c.ConstraintGroups[i].Clone()
+	}
+
 	ng.Items = make([]CheckItem, len(c.Items))
 	for i := range c.Items {
 		ng.Items[i] = c.Items[i].Clone()
@@ -103,6 +118,16 @@ func (c *Check) Clone() Check {
 	return ng
 }
 
+// updateFrom replaces the interval and thresholds of c with the
+// ones of u. All other attributes of a check can not be updated.
+func (c *Check) updateFrom(u Check) {
+	c.Interval = u.Interval
+	c.Thresholds = make([]CheckThreshold, len(u.Thresholds))
+	for i := range u.Thresholds {
+		c.Thresholds[i] = u.Thresholds[i].Clone()
+	}
+}
+
 type CheckItem struct {
 	ObjectID   uuid.UUID
 	ObjectType string
@@ -123,6 +148,7 @@ type CheckThreshold struct {
 	Predicate string
 	Level     uint8
 	Value     int64
+	Template  string // value template, see lib/threshold
 }
 
 func (ct *CheckThreshold) Clone() CheckThreshold {
@@ -130,21 +156,50 @@ func (ct *CheckThreshold) Clone() CheckThreshold {
 		Predicate: ct.Predicate,
 		Level:     ct.Level,
 		Value:     ct.Value,
+		Template:  ct.Template,
 	}
 }
 
+// CheckConstraint is a constraint of a check. Operator is one of the
+// constraint operators of lib/proto, evaluated by lib/constraint.
 type CheckConstraint struct {
-	Type  string
-	Key   string
-	Value string
+	Type     string
+	Key      string
+	Operator string
+	Value    string
 }
 
 func (cc *CheckConstraint) Clone() CheckConstraint {
 	return CheckConstraint{
-		Type:  cc.Type,
-		Key:   cc.Key,
-		Value: cc.Value,
+		Type:     cc.Type,
+		Key:      cc.Key,
+		Operator: cc.Operator,
+		Value:    cc.Value,
+	}
+}
+
+// CheckConstraintGroup combines constraints and nested groups with
+// one of the boolean operators proto.ConstraintGroupAnd,
+// proto.ConstraintGroupOr or proto.ConstraintGroupNot
+type CheckConstraintGroup struct {
+	Operator    string
+	Constraints []CheckConstraint
+	Groups      []CheckConstraintGroup
+}
+
+func (cg *CheckConstraintGroup) Clone() CheckConstraintGroup {
+	ng := CheckConstraintGroup{
+		Operator: cg.Operator,
+	}
+	ng.Constraints = make([]CheckConstraint, len(cg.Constraints))
+	for i := range cg.Constraints {
+		ng.Constraints[i] = cg.Constraints[i].Clone()
 	}
+	ng.Groups = make([]CheckConstraintGroup, len(cg.Groups))
+	for i := range cg.Groups {
+		ng.Groups[i] = cg.Groups[i].Clone()
+	}
+	return ng
 }
 
 type CheckInstance struct {
@@ -164,6 +219,8 @@ type CheckInstance struct {
 	InstanceServiceConfig map[string]string              // attr->value
 	InstanceService       string
 	InstanceSvcCfgHash    string
+	ThresholdOverrideID   uuid.UUID
+	ThresholdValues       map[uint8]int64 // level->resolved template value
 	oldConstraintHash     string
 	oldConstraintValHash  string
 	oldInstanceSvcCfgHash string
@@ -254,6 +311,13 @@ func (tci *CheckInstance) Clone() CheckInstance {
 	cl.InstanceID, _ = uuid.FromString(tci.InstanceID.String())
 	cl.CheckID, _ = uuid.FromString(tci.CheckID.String())
 	cl.ConfigID, _ = uuid.FromString(tci.ConfigID.String())
+	cl.ThresholdOverrideID, _ = uuid.FromString(tci.ThresholdOverrideID.String())
+	if tci.ThresholdValues != nil {
+		cl.ThresholdValues = make(map[uint8]int64)
+		for k, v := range tci.ThresholdValues {
+			cl.ThresholdValues[k] = v
+		}
+	}
 	cl.ConstraintService = make(map[string]string)
 	for k, v := range tci.ConstraintService {
 		t := v
@@ -293,6 +357,10 @@ func (tci *CheckInstance) Clone() CheckInstance {
 	return cl
 }
 
+// calcConstraintHash hashes the bound constraints of the instance.
+// Values bound by constraint groups are recorded in the same maps as
+// values of ungrouped constraints, so moving a constraint into a group
+// does not change the identity of the instance.
 func (tci *CheckInstance) calcConstraintHash() {
 	h := sha512.New()
 	io.WriteString(h, tci.ConstraintOncall)
@@ -437,6 +505,19 @@ func (tci *CheckInstance) calcConstraintValHash() {
 	io.WriteString(h, tci.ConfigID.String())
 	io.WriteString(h, tci.CheckID.String())
 	io.WriteString(h, tci.InstanceService)
+
+	// resolved threshold templates are part of the value hash, so
+	// that a changed property value updates the instance. Instances
+	// without templates keep their previous hash.
+	levels := []int{}
+	for i := range tci.ThresholdValues {
+		levels = append(levels, int(i))
+	}
+	sort.Ints(levels)
+	for _, i := range levels {
+		io.WriteString(h, strconv.Itoa(i))
+		io.WriteString(h, strconv.FormatInt(tci.ThresholdValues[uint8(i)], 10))
+	}
 	tci.ConstraintValHash = base64.URLEncoding.EncodeToString(h.Sum(nil))
 }
 
@@ -466,7 +547,7 @@ func (tci CheckInstance) MakeAction() Action {
 		serviceCfg = []byte{}
 	}
 
-	return Action{
+	action := Action{
 		CheckInstance: proto.CheckInstance{
 			InstanceID:            tci.InstanceID.String(),
 			CheckID:               tci.CheckID.String(),
@@ -480,6 +561,18 @@ func (tci CheckInstance) MakeAction() Action {
 			InstanceServiceConfig: string(serviceCfg),
 		},
 	}
+	if !uuid.Equal(tci.ThresholdOverrideID, uuid.Nil) {
+		action.CheckInstance.ThresholdSource = &proto.CheckThresholdSource{
+			OverrideID: tci.ThresholdOverrideID.String(),
+		}
+	}
+	if len(tci.ThresholdValues) > 0 {
+		action.CheckInstance.ThresholdValues = make(map[uint16]int64)
+		for k, v := range tci.ThresholdValues {
+			action.CheckInstance.ThresholdValues[uint16(k)] = v
+		}
+	}
+	return action
 }
 
 func (tci *CheckInstance) MatchConstraints(target *CheckInstance) bool {
@@ -559,6 +652,10 @@ type checkContext struct {
 	attributeConstr        map[string]map[string][]string // svcID -> attr -> [ value, ... ]
 	newCheckInstances      []string
 	newInstances           map[string]CheckInstance
+	thresholds             []CheckThreshold
+	thresholdOverrideID    uuid.UUID
+	thresholdValues        map[uint8]int64              // level -> resolved value
+	attributeTemplates     map[uint8]threshold.Template // level -> per service template
 }
 
 func newCheckContext(uuid, view string, startup bool) *checkContext {
@@ -575,6 +672,8 @@ func newCheckContext(uuid, view string, startup bool) *checkContext {
 	cc.attributeConstr = make(map[string]map[string][]string)
 	cc.newCheckInstances = []string{}
 	cc.newInstances = make(map[string]CheckInstance)
+	cc.thresholdValues = make(map[uint8]int64)
+	cc.attributeTemplates = make(map[uint8]threshold.Template)
 	return &cc
 }
 
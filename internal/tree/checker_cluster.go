@@ -143,6 +143,52 @@ func (tec *Cluster) rmCheck(c Check) {
 	}
 }
 
+//
+// Checker:> Update Check
+
+func (tec *Cluster) UpdateCheck(c Check) {
+	tec.updateCheckOnChildren(c)
+	tec.updateCheck(c)
+}
+
+func (tec *Cluster) updateCheckInherited(c Check) {
+	tec.updateCheckOnChildren(c)
+	tec.updateCheck(c)
+}
+
+func (tec *Cluster) updateCheckOnChildren(c Check) {
+	switch deterministicInheritanceOrder {
+	case true:
+		for i := 0; i < tec.ordNumChildNod; i++ {
+			if child, ok := tec.ordChildrenNod[i]; ok {
+				tec.Children[child].(Checker).updateCheckInherited(c)
+			}
+		}
+	default:
+		var wg sync.WaitGroup
+		for child, _ := range tec.Children {
+			wg.Add(1)
+			go func(stc Check, ch string) {
+				defer wg.Done()
+				tec.Children[ch].(Checker).updateCheckInherited(stc)
+			}(c, child)
+		}
+		wg.Wait()
+	}
+}
+
+func (tec *Cluster) updateCheck(c Check) {
+	for id := range tec.Checks {
+		if uuid.Equal(tec.Checks[id].SourceID, c.SourceID) {
+			tec.hasUpdate = true
+			f := tec.Checks[id]
+			f.updateFrom(c)
+			tec.Checks[id] = f
+			return
+		}
+	}
+}
+
 //
 // Checker:> Meta
 
@@ -37,6 +37,18 @@ func (tef *Fault) deleteCheckLocalAll() {
 func (tef *Fault) rmCheck(c Check) {
 }
 
+func (tef *Fault) UpdateCheck(c Check) {
+}
+
+func (tef *Fault) updateCheckInherited(c Check) {
+}
+
+func (tef *Fault) updateCheckOnChildren(c Check) {
+}
+
+func (tef *Fault) updateCheck(c Check) {
+}
+
 func (tef *Fault) syncCheck(childID string) {
 }
 
@@ -168,6 +168,65 @@ func (teg *Group) rmCheck(c Check) {
 	}
 }
 
+//
+// Checker:> Update Check
+
+func (teg *Group) UpdateCheck(c Check) {
+	teg.updateCheckOnChildren(c)
+	teg.updateCheck(c)
+}
+
+func (teg *Group) updateCheckInherited(c Check) {
+	teg.updateCheckOnChildren(c)
+	teg.updateCheck(c)
+}
+
+func (teg *Group) updateCheckOnChildren(c Check) {
+	switch deterministicInheritanceOrder {
+	case true:
+		// groups
+		for i := 0; i < teg.ordNumChildGrp; i++ {
+			if child, ok := teg.ordChildrenGrp[i]; ok {
+				teg.Children[child].(Checker).updateCheckInherited(c)
+			}
+		}
+		// clusters
+		for i := 0; i < teg.ordNumChildClr; i++ {
+			if child, ok := teg.ordChildrenClr[i]; ok {
+				teg.Children[child].(Checker).updateCheckInherited(c)
+			}
+		}
+		// nodes
+		for i := 0; i < teg.ordNumChildNod; i++ {
+			if child, ok := teg.ordChildrenNod[i]; ok {
+				teg.Children[child].(Checker).updateCheckInherited(c)
+			}
+		}
+	default:
+		var wg sync.WaitGroup
+		for child, _ := range teg.Children {
+			wg.Add(1)
+			go func(stc Check, ch string) {
+				defer wg.Done()
+				teg.Children[ch].(Checker).updateCheckInherited(stc)
+			}(c, child)
+		}
+		wg.Wait()
+	}
+}
+
+func (teg *Group) updateCheck(c Check) {
+	for id := range teg.Checks {
+		if uuid.Equal(teg.Checks[id].SourceID, c.SourceID) {
+			teg.hasUpdate = true
+			f := teg.Checks[id]
+			f.updateFrom(c)
+			teg.Checks[id] = f
+			return
+		}
+	}
+}
+
 //
 // Checker:> Meta
 
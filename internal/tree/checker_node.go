@@ -92,6 +92,32 @@ func (ten *Node) rmCheck(c Check) {
 	}
 }
 
+//
+// Checker:> Update Check
+
+func (ten *Node) UpdateCheck(c Check) {
+	ten.updateCheck(c)
+}
+
+func (ten *Node) updateCheckInherited(c Check) {
+	ten.updateCheck(c)
+}
+
+func (ten *Node) updateCheckOnChildren(c Check) {
+}
+
+func (ten *Node) updateCheck(c Check) {
+	for id := range ten.Checks {
+		if uuid.Equal(ten.Checks[id].SourceID, c.SourceID) {
+			ten.hasUpdate = true
+			f := ten.Checks[id]
+			f.updateFrom(c)
+			ten.Checks[id] = f
+			return
+		}
+	}
+}
+
 // noop, satisfy interface
 func (ten *Node) syncCheck(childID string) {
 }
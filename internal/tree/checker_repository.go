@@ -141,6 +141,52 @@ func (ter *Repository) rmCheck(c Check) {
 	}
 }
 
+//
+// Checker:> Update Check
+
+func (ter *Repository) UpdateCheck(c Check) {
+	ter.updateCheckOnChildren(c)
+	ter.updateCheck(c)
+}
+
+func (ter *Repository) updateCheckInherited(c Check) {
+	ter.updateCheckOnChildren(c)
+	ter.updateCheck(c)
+}
+
+func (ter *Repository) updateCheckOnChildren(c Check) {
+	switch deterministicInheritanceOrder {
+	case true:
+		// buckets
+		for i := 0; i < ter.ordNumChildBck; i++ {
+			if child, ok := ter.ordChildrenBck[i]; ok {
+				ter.Children[child].(Checker).updateCheckInherited(c)
+			}
+		}
+	default:
+		var wg sync.WaitGroup
+		for child, _ := range ter.Children {
+			wg.Add(1)
+			go func(stc Check, ch string) {
+				defer wg.Done()
+				ter.Children[ch].(Checker).updateCheckInherited(stc)
+			}(c, child)
+		}
+		wg.Wait()
+	}
+}
+
+func (ter *Repository) updateCheck(c Check) {
+	for id := range ter.Checks {
+		if uuid.Equal(ter.Checks[id].SourceID, c.SourceID) {
+			f := ter.Checks[id]
+			f.updateFrom(c)
+			ter.Checks[id] = f
+			return
+		}
+	}
+}
+
 //
 // Checker:> Meta
 
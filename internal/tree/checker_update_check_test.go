@@ -0,0 +1,135 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package tree
+
+import (
+	"testing"
+
+	"github.com/mjolnir42/soma/lib/proto"
+	"github.com/satori/go.uuid"
+)
+
+func TestCheckerUpdateCheck(t *testing.T) {
+	deterministicInheritanceOrder = true
+
+	sTree, actionC, errC := testSpawnCheckTree()
+
+	chk := Check{
+		ID:            uuid.Nil,
+		SourceID:      uuid.Nil,
+		InheritedFrom: uuid.Nil,
+		Inheritance:   true,
+		ChildrenOnly:  false,
+		Interval:      60,
+		ConfigID:      uuid.Must(uuid.NewV4()),
+		CapabilityID:  uuid.Must(uuid.NewV4()),
+		View:          `any`,
+		Thresholds: []CheckThreshold{
+			{
+				Predicate: `>=`,
+				Level:     1,
+				Value:     100,
+			},
+		},
+		Constraints: []CheckConstraint{},
+	}
+
+	repo := sTree.Find(FindRequest{
+		ElementType: `repository`,
+		ElementName: `checkTest`,
+	}, true)
+	repo.SetCheck(chk)
+
+	sTree.ComputeCheckInstances()
+
+	if len(errC) > 0 {
+		t.Error(`Error channel not empty`)
+	}
+	// record the created check instances
+	instances := map[string]proto.CheckInstance{}
+	for len(actionC) > 0 {
+		a := <-actionC
+		if a.Action == ActionCheckInstanceCreate {
+			instances[a.CheckInstance.InstanceID] = a.CheckInstance
+		}
+	}
+	if len(instances) == 0 {
+		t.Fatal(`No check instances were created`)
+	}
+
+	// the update is applied via the source check on the repository
+	var source Check
+	for _, c := range sTree.Child.Checks {
+		source = c.Clone()
+	}
+	source.Interval = 300
+	source.Thresholds = []CheckThreshold{
+		{
+			Predicate: `>=`,
+			Level:     1,
+			Value:     500,
+		},
+	}
+	repo.UpdateCheck(source)
+
+	sTree.ComputeCheckInstances()
+	close(actionC)
+	close(errC)
+
+	if len(errC) > 0 {
+		t.Error(`Error channel not empty`)
+	}
+
+	updates := 0
+	for a := range actionC {
+		switch a.Action {
+		case ActionCheckInstanceUpdate:
+			updates++
+			old, ok := instances[a.CheckInstance.InstanceID]
+			if !ok {
+				t.Errorf("Update for unknown instance %s",
+					a.CheckInstance.InstanceID)
+				continue
+			}
+			if a.CheckInstance.Version != old.Version+1 {
+				t.Errorf("Instance %s: expected version %d, got %d",
+					old.InstanceID, old.Version+1,
+					a.CheckInstance.Version)
+			}
+			if a.CheckInstance.InstanceConfigID == old.InstanceConfigID {
+				t.Errorf("Instance %s: configuration was not renewed",
+					old.InstanceID)
+			}
+		case ActionCheckNew, ActionCheckRemoved,
+			ActionCheckInstanceCreate, ActionCheckInstanceDelete:
+			t.Errorf("Unexpected action: %s", a.Action)
+		}
+	}
+	if updates != len(instances) {
+		t.Errorf("Expected %d instance updates, got %d",
+			len(instances), updates)
+	}
+
+	for _, name := range []string{
+		`testnode1`, `testnode2`, `testnode3`, `testnode4`,
+	} {
+		node := sTree.Find(FindRequest{
+			ElementType: `node`,
+			ElementName: name,
+		}, true).(*Node)
+		for _, c := range node.Checks {
+			if c.Interval != 300 || c.Thresholds[0].Value != 500 {
+				t.Errorf("%s: check was not updated", name)
+			}
+		}
+	}
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -32,6 +32,7 @@ type Cluster struct {
 	PropertySystem  map[string]Property
 	PropertyCustom  map[string]Property
 	Checks          map[string]Check
+	Overrides       map[string]ThresholdOverride
 	CheckInstances  map[string][]string
 	Instances       map[string]CheckInstance
 	Children        map[string]ClusterAttacher `json:"-"`
@@ -70,6 +71,7 @@ func NewCluster(spec ClusterSpec) *Cluster {
 	tec.PropertySystem = make(map[string]Property)
 	tec.PropertyCustom = make(map[string]Property)
 	tec.Checks = make(map[string]Check)
+	tec.Overrides = make(map[string]ThresholdOverride)
 	tec.CheckInstances = make(map[string][]string)
 	tec.Instances = make(map[string]CheckInstance)
 	tec.loadedInstances = make(map[string]map[string]CheckInstance)
@@ -126,6 +128,12 @@ func (tec Cluster) Clone() *Cluster {
 	}
 	cl.Checks = cK
 
+	cO := make(map[string]ThresholdOverride)
+	for k, o := range tec.Overrides {
+		cO[k] = o.Clone()
+	}
+	cl.Overrides = cO
+
 	cki := make(map[string]CheckInstance)
 	for k, chki := range tec.Instances {
 		cki[k] = chki.Clone()
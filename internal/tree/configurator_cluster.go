@@ -12,35 +12,44 @@ import (
 	"sync"
 
 	"github.com/mjolnir42/soma/internal/msg"
+	"github.com/mjolnir42/soma/lib/constraint"
+	"github.com/mjolnir42/soma/lib/proto"
 	uuid "github.com/satori/go.uuid"
 )
 
-func (c *Cluster) evalNativeProp(prop string, val string) bool {
+func (c *Cluster) evalNativeProp(prop string, op string, val string) bool {
 	switch prop {
 	case msg.NativePropertyEnvironment:
-		env := c.Parent.(Bucketeer).GetEnvironment()
-		if val == env {
-			return true
-		}
+		return constraint.Match(op, val, c.Parent.(Bucketeer).GetEnvironment())
 	case msg.NativePropertyEntity:
-		if val == msg.EntityCluster {
-			return true
-		}
+		return constraint.Match(op, val, msg.EntityCluster)
 	case msg.NativePropertyState:
-		if val == c.State {
-			return true
-		}
+		return constraint.Match(op, val, c.State)
 	case msg.NativePropertyHardwareNode:
-		// cluster != hardware
+		// a cluster runs on the servers of its member nodes
+		for _, child := range c.Children {
+			if child.(*Node).evalNativeProp(prop, op, val) {
+				return true
+			}
+		}
 		return false
 	}
 	return false
 }
 
-func (c *Cluster) evalSystemProp(prop string, val string, view string) (string, bool, string) {
+func (c *Cluster) evalSystemProp(prop string, op string, val string, view string) (string, bool, string) {
+	if constraint.Negative(op) {
+		for _, v := range c.PropertySystem {
+			t := v.(*PropertySystem)
+			if t.Key == prop && (t.View == view || t.View == `any`) {
+				return "", false, ""
+			}
+		}
+		return prop, true, ""
+	}
 	for _, v := range c.PropertySystem {
 		t := v.(*PropertySystem)
-		if t.Key == prop && (t.Value == val || val == `@defined`) && (t.View == view || t.View == `any`) {
+		if t.Key == prop && constraint.Match(op, val, t.Value) && (t.View == view || t.View == `any`) {
 			return t.Key, true, t.Value
 		}
 	}
@@ -57,46 +66,80 @@ func (c *Cluster) evalOncallProp(prop string, val string, view string) (string,
 	return "", false
 }
 
-func (c *Cluster) evalCustomProp(prop string, val string, view string) (string, bool, string) {
+func (c *Cluster) evalCustomProp(prop string, op string, val string, view string) (string, bool, string) {
+	if constraint.Negative(op) {
+		for _, v := range c.PropertyCustom {
+			t := v.(*PropertyCustom)
+			if t.Key == prop && (t.View == view || t.View == `any`) {
+				return "", false, ""
+			}
+		}
+		return prop, true, ""
+	}
 	for _, v := range c.PropertyCustom {
 		t := v.(*PropertyCustom)
-		if t.Key == prop && (t.Value == val || val == `@defined`) && (t.View == view || t.View == `any`) {
+		if t.Key == prop && constraint.Match(op, val, t.Value) && (t.View == view || t.View == `any`) {
 			return t.Key, true, t.Value
 		}
 	}
 	return "", false, ""
 }
 
-func (c *Cluster) evalServiceProp(prop string, val string, view string) (string, bool, string) {
+// evalServiceProp returns the first service that matches. A negative
+// operator matches if there is no service in view, without binding a
+// service.
+func (c *Cluster) evalServiceProp(prop string, op string, val string, view string) (string, bool, string) {
+	if prop != "name" {
+		return "", false, ""
+	}
+	if constraint.Negative(op) {
+		for _, v := range c.PropertyService {
+			t := v.(*PropertyService)
+			if t.View == view || t.View == `any` {
+				return "", false, ""
+			}
+		}
+		return "", true, ""
+	}
 	for _, v := range c.PropertyService {
 		t := v.(*PropertyService)
-		if prop == "name" && (t.ServiceName == val || val == `@defined`) && (t.View == view || t.View == `any`) {
+		if constraint.Match(op, val, t.ServiceName) && (t.View == view || t.View == `any`) {
 			return t.ID.String(), true, t.ServiceName
 		}
 	}
 	return "", false, ""
 }
 
-func (c *Cluster) evalAttributeOfService(svcID string, view string, attribute string, value string) (bool, string) {
+func (c *Cluster) evalAttributeOfService(svcID string, view string, attribute string, op string, value string) (bool, string) {
 	t := c.PropertyService[svcID].(*PropertyService)
+	if t.View != view && t.View != `any` {
+		return false, ""
+	}
+	if constraint.Negative(op) {
+		for _, a := range t.Attributes {
+			if a.Name == attribute {
+				return false, ""
+			}
+		}
+		return true, ""
+	}
 	for _, a := range t.Attributes {
-		if a.Name == attribute && (t.View == view || t.View == `any`) && (a.Value == value || value == `@defined`) {
+		if a.Name == attribute && constraint.Match(op, value, a.Value) {
 			return true, a.Value
 		}
 	}
 	return false, ""
 }
 
-func (c *Cluster) evalAttributeProp(view string, attr string, value string) (bool, map[string]string) {
+func (c *Cluster) evalAttributeProp(view string, attr string, op string, value string) (bool, map[string]string) {
 	f := map[string]string{}
-svcloop:
 	for _, v := range c.PropertyService {
 		t := v.(*PropertyService)
-		for _, a := range t.Attributes {
-			if a.Name == attr && (a.Value == value || value == `@defined`) && (t.View == view || t.View == `any`) {
-				f[t.ID.String()] = a.Value
-				continue svcloop
-			}
+		if t.View != view && t.View != `any` {
+			continue
+		}
+		if hit, bind := c.evalAttributeOfService(t.ID.String(), view, attr, op, value); hit {
+			f[t.ID.String()] = bind
 		}
 	}
 	if len(f) > 0 {
@@ -193,6 +236,7 @@ func (c *Cluster) removeDisabledCheckInstances() {
 		// uses.
 		if _, hit, _ := c.evalSystemProp(
 			msg.SystemPropertyDisableAllMonitoring,
+			proto.ConstraintEqual,
 			`true`,
 			c.Checks[chk].View,
 		); hit {
@@ -203,6 +247,7 @@ func (c *Cluster) removeDisabledCheckInstances() {
 		// check_configuration that spawned this check
 		if _, hit, _ := c.evalSystemProp(
 			msg.SystemPropertyDisableCheckConfiguration,
+			proto.ConstraintEqual,
 			c.Checks[chk].ConfigID.String(),
 			c.Checks[chk].View,
 		); hit {
@@ -260,6 +305,7 @@ func (c *Cluster) processCheckForUpdates(chkName string, startup bool) {
 	if _, hit, _ := c.evalSystemProp(
 		// skip check if `disable_all_monitoring` property is set
 		msg.SystemPropertyDisableAllMonitoring,
+		proto.ConstraintEqual,
 		`true`,
 		c.Checks[chkName].View,
 	); hit {
@@ -269,6 +315,7 @@ func (c *Cluster) processCheckForUpdates(chkName string, startup bool) {
 	if _, hit, _ := c.evalSystemProp(
 		// skip check if `disable_check_configuration` property is set
 		msg.SystemPropertyDisableCheckConfiguration,
+		proto.ConstraintEqual,
 		c.Checks[chkName].ConfigID.String(),
 		c.Checks[chkName].View,
 	); hit {
@@ -289,6 +336,17 @@ func (c *Cluster) processCheckForUpdates(chkName string, startup bool) {
 		return
 	}
 
+	// select the thresholds in effect for this check and resolve
+	// their templated values from the properties of this object
+	c.lock.RLock()
+	override, ok := c.thresholdOverride(c.Checks[chkName].ConfigID.String())
+	ctx.selectThresholds(c.Checks[chkName], override, ok)
+	ctx.resolveThresholdTemplates(c)
+	c.lock.RUnlock()
+	if ctx.brokeConstraint {
+		return
+	}
+
 	// check triggered, create instances
 	switch {
 	case !ctx.hasServiceConstraint:
@@ -336,14 +394,14 @@ func (c *Cluster) constraintCheck(ctx *checkContext) {
 	for _, cc := range c.Checks[ctx.uuid].Constraints {
 		switch cc.Type {
 		case msg.ConstraintNative:
-			if c.evalNativeProp(cc.Key, cc.Value) {
+			if c.evalNativeProp(cc.Key, cc.Operator, cc.Value) {
 				ctx.nativeConstr[cc.Key] = cc.Value
 				continue
 			}
 			ctx.brokeConstraint = true
 			return
 		case msg.ConstraintSystem:
-			if id, hit, bind := c.evalSystemProp(cc.Key, cc.Value, ctx.view); hit {
+			if id, hit, bind := c.evalSystemProp(cc.Key, cc.Operator, cc.Value, ctx.view); hit {
 				ctx.systemConstr[id] = bind
 				continue
 			}
@@ -357,26 +415,37 @@ func (c *Cluster) constraintCheck(ctx *checkContext) {
 			ctx.brokeConstraint = true
 			return
 		case msg.ConstraintCustom:
-			if id, hit, bind := c.evalCustomProp(cc.Key, cc.Value, ctx.view); hit {
+			if id, hit, bind := c.evalCustomProp(cc.Key, cc.Operator, cc.Value, ctx.view); hit {
 				ctx.customConstr[id] = bind
 				continue
 			}
 			ctx.brokeConstraint = true
 			return
 		case msg.ConstraintService:
-			ctx.hasServiceConstraint = true
-			if id, hit, bind := c.evalServiceProp(cc.Key, cc.Value, ctx.view); hit {
-				ctx.serviceConstr[id] = bind
+			id, hit, bind := c.evalServiceProp(cc.Key, cc.Operator, cc.Value, ctx.view)
+			if !hit {
+				ctx.brokeConstraint = true
+				return
+			}
+			// the absence of services binds no service
+			if constraint.Negative(cc.Operator) {
 				continue
 			}
-			ctx.brokeConstraint = true
-			return
+			ctx.hasServiceConstraint = true
+			ctx.serviceConstr[id] = bind
 		case msg.ConstraintAttribute:
 			ctx.hasAttributeConstraint = true
 			ctx.attributes = append(ctx.attributes, cc)
 		}
 	}
 
+	// constraint groups must match as well and add the values
+	// they bound, including services
+	ctx.evalConstraintGroups(c, c.Checks[ctx.uuid].ConstraintGroups)
+	if ctx.brokeConstraint {
+		return
+	}
+
 	switch {
 	case ctx.hasServiceConstraint && ctx.hasAttributeConstraint:
 		/* if the check has both service and attribute constraints,
@@ -386,7 +455,7 @@ func (c *Cluster) constraintCheck(ctx *checkContext) {
 		 */
 		for id := range ctx.serviceConstr {
 			for _, attr := range ctx.attributes {
-				hit, bind := c.evalAttributeOfService(id, ctx.view, attr.Key, attr.Value)
+				hit, bind := c.evalAttributeOfService(id, ctx.view, attr.Key, attr.Operator, attr.Value)
 				if hit {
 					// attributeC[id] might still be a nil map
 					if ctx.attributeConstr[id] == nil {
@@ -410,7 +479,7 @@ func (c *Cluster) constraintCheck(ctx *checkContext) {
 		 */
 		attrCount := len(ctx.attributes)
 		for _, attr := range ctx.attributes {
-			if hit, svcIDMap := c.evalAttributeProp(ctx.view, attr.Key, attr.Value); hit {
+			if hit, svcIDMap := c.evalAttributeProp(ctx.view, attr.Key, attr.Operator, attr.Value); hit {
 				for id, bind := range svcIDMap {
 					ctx.serviceConstr[id] = svcIDMap[id]
 					// attributeC[id] might still be a nil map
@@ -471,6 +540,9 @@ func (c *Cluster) createNoServiceCheckInstance(ctx *checkContext) {
 		InstanceServiceConfig: nil,
 		InstanceSvcCfgHash:    ``,
 	}
+	// attribute templates break checks without service constraints,
+	// binding can not fail here
+	ctx.bindThresholds(&inst)
 	inst.calcConstraintHash()
 	inst.calcConstraintValHash()
 
@@ -623,6 +695,11 @@ func (c *Cluster) createPerServiceCheckInstances(ctx *checkContext) {
 				InstanceService:       svcID,
 				InstanceServiceConfig: cfg,
 			}
+			if !ctx.bindThresholds(&inst) {
+				// the service configuration lacks a numeric value
+				// for an attribute threshold template
+				continue
+			}
 			inst.calcConstraintHash()
 			inst.calcConstraintValHash()
 			inst.calcInstanceSvcCfgHash()
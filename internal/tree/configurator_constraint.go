@@ -0,0 +1,165 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package tree
+
+import (
+	"github.com/mjolnir42/soma/internal/msg"
+	"github.com/mjolnir42/soma/lib/constraint"
+	"github.com/mjolnir42/soma/lib/proto"
+)
+
+// constraintEvaluator is implemented by all tree elements that can
+// carry check instances
+type constraintEvaluator interface {
+	evalNativeProp(prop string, op string, val string) bool
+	evalSystemProp(prop string, op string, val string, view string) (string, bool, string)
+	evalOncallProp(prop string, val string, view string) (string, bool)
+	evalCustomProp(prop string, op string, val string, view string) (string, bool, string)
+	evalServiceProp(prop string, op string, val string, view string) (string, bool, string)
+}
+
+// constraintBinding holds the property values a constraint group
+// bound on a tree element
+type constraintBinding struct {
+	oncall  string
+	native  map[string]string
+	system  map[string]string
+	custom  map[string]string
+	service map[string]string
+}
+
+func newConstraintBinding() *constraintBinding {
+	return &constraintBinding{
+		native:  make(map[string]string),
+		system:  make(map[string]string),
+		custom:  make(map[string]string),
+		service: make(map[string]string),
+	}
+}
+
+func (b *constraintBinding) merge(o *constraintBinding) {
+	if o.oncall != `` {
+		b.oncall = o.oncall
+	}
+	for k, v := range o.native {
+		b.native[k] = v
+	}
+	for k, v := range o.system {
+		b.system[k] = v
+	}
+	for k, v := range o.custom {
+		b.custom[k] = v
+	}
+	for k, v := range o.service {
+		b.service[k] = v
+	}
+}
+
+// evalConstraintGroup evaluates grp against e. All members of a group
+// are evaluated, so that an or-group binds every alternative that is
+// present on e. Values bound by members of a not-group are discarded.
+func evalConstraintGroup(e constraintEvaluator, view string, grp *CheckConstraintGroup) (bool, *constraintBinding) {
+	bind := newConstraintBinding()
+	hits, count := 0, 0
+
+	for _, cc := range grp.Constraints {
+		count++
+		if hit, b := evalConstraintMember(e, view, cc); hit {
+			hits++
+			bind.merge(b)
+		}
+	}
+	for i := range grp.Groups {
+		count++
+		if hit, b := evalConstraintGroup(e, view, &grp.Groups[i]); hit {
+			hits++
+			bind.merge(b)
+		}
+	}
+
+	switch grp.Operator {
+	case proto.ConstraintGroupOr:
+		return hits > 0, bind
+	case proto.ConstraintGroupNot:
+		return hits == 0, newConstraintBinding()
+	default:
+		return hits == count, bind
+	}
+}
+
+// evalConstraintMember evaluates a single constraint of a group.
+// Attribute constraints are not supported inside groups and never
+// match.
+func evalConstraintMember(e constraintEvaluator, view string, cc CheckConstraint) (bool, *constraintBinding) {
+	bind := newConstraintBinding()
+	switch cc.Type {
+	case msg.ConstraintNative:
+		if e.evalNativeProp(cc.Key, cc.Operator, cc.Value) {
+			bind.native[cc.Key] = cc.Value
+			return true, bind
+		}
+	case msg.ConstraintSystem:
+		if id, hit, val := e.evalSystemProp(cc.Key, cc.Operator, cc.Value, view); hit {
+			bind.system[id] = val
+			return true, bind
+		}
+	case msg.ConstraintOncall:
+		if id, hit := e.evalOncallProp(cc.Key, cc.Value, view); hit {
+			bind.oncall = id
+			return true, bind
+		}
+	case msg.ConstraintCustom:
+		if id, hit, val := e.evalCustomProp(cc.Key, cc.Operator, cc.Value, view); hit {
+			bind.custom[id] = val
+			return true, bind
+		}
+	case msg.ConstraintService:
+		if id, hit, val := e.evalServiceProp(cc.Key, cc.Operator, cc.Value, view); hit {
+			// the absence of services binds no service
+			if !constraint.Negative(cc.Operator) {
+				bind.service[id] = val
+			}
+			return true, bind
+		}
+	}
+	return false, bind
+}
+
+// evalConstraintGroups evaluates all constraint groups of the check
+// and records the bound values in ctx. Since the values are recorded
+// in the same maps as the values bound by ungrouped constraints,
+// they are part of the instance's constraint hashes.
+func (ctx *checkContext) evalConstraintGroups(e constraintEvaluator, groups []CheckConstraintGroup) {
+	for i := range groups {
+		hit, bind := evalConstraintGroup(e, ctx.view, &groups[i])
+		if !hit {
+			ctx.brokeConstraint = true
+			return
+		}
+		if bind.oncall != `` {
+			ctx.oncallConstr = bind.oncall
+		}
+		for k, v := range bind.native {
+			ctx.nativeConstr[k] = v
+		}
+		for k, v := range bind.system {
+			ctx.systemConstr[k] = v
+		}
+		for k, v := range bind.custom {
+			ctx.customConstr[k] = v
+		}
+		for k, v := range bind.service {
+			ctx.hasServiceConstraint = true
+			ctx.serviceConstr[k] = v
+		}
+	}
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -12,35 +12,55 @@ import (
 	"sync"
 
 	"github.com/mjolnir42/soma/internal/msg"
+	"github.com/mjolnir42/soma/lib/constraint"
+	"github.com/mjolnir42/soma/lib/proto"
 	uuid "github.com/satori/go.uuid"
 )
 
-func (g *Group) evalNativeProp(prop string, val string) bool {
+func (g *Group) evalNativeProp(prop string, op string, val string) bool {
 	switch prop {
 	case msg.NativePropertyEnvironment:
-		env := g.Parent.(Bucketeer).GetEnvironment()
-		if val == env {
-			return true
-		}
+		return constraint.Match(op, val, g.Parent.(Bucketeer).GetEnvironment())
 	case msg.NativePropertyEntity:
-		if val == msg.EntityGroup {
-			return true
-		}
+		return constraint.Match(op, val, msg.EntityGroup)
 	case msg.NativePropertyState:
-		if val == g.State {
-			return true
-		}
+		return constraint.Match(op, val, g.State)
 	case msg.NativePropertyHardwareNode:
-		// group != hardware
+		// a group runs on the servers of its members
+		for _, child := range g.Children {
+			switch member := child.(type) {
+			case *Node:
+				if member.evalNativeProp(prop, op, val) {
+					return true
+				}
+			case *Cluster:
+				if member.evalNativeProp(prop, op, val) {
+					return true
+				}
+			case *Group:
+				if member.evalNativeProp(prop, op, val) {
+					return true
+				}
+			}
+		}
 		return false
 	}
 	return false
 }
 
-func (g *Group) evalSystemProp(prop string, val string, view string) (string, bool, string) {
+func (g *Group) evalSystemProp(prop string, op string, val string, view string) (string, bool, string) {
+	if constraint.Negative(op) {
+		for _, v := range g.PropertySystem {
+			t := v.(*PropertySystem)
+			if t.Key == prop && (t.View == view || t.View == `any`) {
+				return "", false, ""
+			}
+		}
+		return prop, true, ""
+	}
 	for _, v := range g.PropertySystem {
 		t := v.(*PropertySystem)
-		if t.Key == prop && (t.Value == val || val == `@defined`) && (t.View == view || t.View == `any`) {
+		if t.Key == prop && constraint.Match(op, val, t.Value) && (t.View == view || t.View == `any`) {
 			return t.Key, true, t.Value
 		}
 	}
@@ -57,46 +77,80 @@ func (g *Group) evalOncallProp(prop string, val string, view string) (string, bo
 	return "", false
 }
 
-func (g *Group) evalCustomProp(prop string, val string, view string) (string, bool, string) {
+func (g *Group) evalCustomProp(prop string, op string, val string, view string) (string, bool, string) {
+	if constraint.Negative(op) {
+		for _, v := range g.PropertyCustom {
+			t := v.(*PropertyCustom)
+			if t.Key == prop && (t.View == view || t.View == `any`) {
+				return "", false, ""
+			}
+		}
+		return prop, true, ""
+	}
 	for _, v := range g.PropertyCustom {
 		t := v.(*PropertyCustom)
-		if t.Key == prop && (t.Value == val || val == `@defined`) && (t.View == view || t.View == `any`) {
+		if t.Key == prop && constraint.Match(op, val, t.Value) && (t.View == view || t.View == `any`) {
 			return t.Key, true, t.Value
 		}
 	}
 	return "", false, ""
 }
 
-func (g *Group) evalServiceProp(prop string, val string, view string) (string, bool, string) {
+// evalServiceProp returns the first service that matches. A negative
+// operator matches if there is no service in view, without binding a
+// service.
+func (g *Group) evalServiceProp(prop string, op string, val string, view string) (string, bool, string) {
+	if prop != "name" {
+		return "", false, ""
+	}
+	if constraint.Negative(op) {
+		for _, v := range g.PropertyService {
+			t := v.(*PropertyService)
+			if t.View == view || t.View == `any` {
+				return "", false, ""
+			}
+		}
+		return "", true, ""
+	}
 	for _, v := range g.PropertyService {
 		t := v.(*PropertyService)
-		if prop == "name" && (t.ServiceName == val || val == `@defined`) && (t.View == view || t.View == `any`) {
+		if constraint.Match(op, val, t.ServiceName) && (t.View == view || t.View == `any`) {
 			return t.ID.String(), true, t.ServiceName
 		}
 	}
 	return "", false, ""
 }
 
-func (g *Group) evalAttributeOfService(svcID string, view string, attribute string, value string) (bool, string) {
+func (g *Group) evalAttributeOfService(svcID string, view string, attribute string, op string, value string) (bool, string) {
 	t := g.PropertyService[svcID].(*PropertyService)
+	if t.View != view && t.View != `any` {
+		return false, ""
+	}
+	if constraint.Negative(op) {
+		for _, a := range t.Attributes {
+			if a.Name == attribute {
+				return false, ""
+			}
+		}
+		return true, ""
+	}
 	for _, a := range t.Attributes {
-		if a.Name == attribute && (t.View == view || t.View == `any`) && (a.Value == value || value == `@defined`) {
+		if a.Name == attribute && constraint.Match(op, value, a.Value) {
 			return true, a.Value
 		}
 	}
 	return false, ""
 }
 
-func (g *Group) evalAttributeProp(view string, attr string, value string) (bool, map[string]string) {
+func (g *Group) evalAttributeProp(view string, attr string, op string, value string) (bool, map[string]string) {
 	f := map[string]string{}
-svcloop:
 	for _, v := range g.PropertyService {
 		t := v.(*PropertyService)
-		for _, a := range t.Attributes {
-			if a.Name == attr && (a.Value == value || value == `@defined`) && (t.View == view || t.View == `any`) {
-				f[t.ID.String()] = a.Value
-				continue svcloop
-			}
+		if t.View != view && t.View != `any` {
+			continue
+		}
+		if hit, bind := g.evalAttributeOfService(t.ID.String(), view, attr, op, value); hit {
+			f[t.ID.String()] = bind
 		}
 	}
 	if len(f) > 0 {
@@ -193,6 +247,7 @@ func (g *Group) removeDisabledCheckInstances() {
 		// uses.
 		if _, hit, _ := g.evalSystemProp(
 			msg.SystemPropertyDisableAllMonitoring,
+			proto.ConstraintEqual,
 			`true`,
 			g.Checks[chk].View,
 		); hit {
@@ -203,6 +258,7 @@ func (g *Group) removeDisabledCheckInstances() {
 		// check_configuration that spawned this check
 		if _, hit, _ := g.evalSystemProp(
 			msg.SystemPropertyDisableCheckConfiguration,
+			proto.ConstraintEqual,
 			g.Checks[chk].ConfigID.String(),
 			g.Checks[chk].View,
 		); hit {
@@ -260,6 +316,7 @@ func (g *Group) processCheckForUpdates(chkName string, startup bool) {
 	if _, hit, _ := g.evalSystemProp(
 		// skip check if `disable_all_monitoring` property is set
 		msg.SystemPropertyDisableAllMonitoring,
+		proto.ConstraintEqual,
 		`true`,
 		g.Checks[chkName].View,
 	); hit {
@@ -269,6 +326,7 @@ func (g *Group) processCheckForUpdates(chkName string, startup bool) {
 	if _, hit, _ := g.evalSystemProp(
 		// skip check if `disable_check_configuration` property is set
 		msg.SystemPropertyDisableCheckConfiguration,
+		proto.ConstraintEqual,
 		g.Checks[chkName].ConfigID.String(),
 		g.Checks[chkName].View,
 	); hit {
@@ -289,6 +347,17 @@ func (g *Group) processCheckForUpdates(chkName string, startup bool) {
 		return
 	}
 
+	// select the thresholds in effect for this check and resolve
+	// their templated values from the properties of this object
+	g.lock.RLock()
+	override, ok := g.thresholdOverride(g.Checks[chkName].ConfigID.String())
+	ctx.selectThresholds(g.Checks[chkName], override, ok)
+	ctx.resolveThresholdTemplates(g)
+	g.lock.RUnlock()
+	if ctx.brokeConstraint {
+		return
+	}
+
 	// check triggered, create instances
 	switch {
 	case !ctx.hasServiceConstraint:
@@ -336,14 +405,14 @@ func (g *Group) constraintCheck(ctx *checkContext) {
 	for _, c := range g.Checks[ctx.uuid].Constraints {
 		switch c.Type {
 		case msg.ConstraintNative:
-			if g.evalNativeProp(c.Key, c.Value) {
+			if g.evalNativeProp(c.Key, c.Operator, c.Value) {
 				ctx.nativeConstr[c.Key] = c.Value
 				continue
 			}
 			ctx.brokeConstraint = true
 			return
 		case msg.ConstraintSystem:
-			if id, hit, bind := g.evalSystemProp(c.Key, c.Value, ctx.view); hit {
+			if id, hit, bind := g.evalSystemProp(c.Key, c.Operator, c.Value, ctx.view); hit {
 				ctx.systemConstr[id] = bind
 				continue
 			}
@@ -357,26 +426,37 @@ func (g *Group) constraintCheck(ctx *checkContext) {
 			ctx.brokeConstraint = true
 			return
 		case msg.ConstraintCustom:
-			if id, hit, bind := g.evalCustomProp(c.Key, c.Value, ctx.view); hit {
+			if id, hit, bind := g.evalCustomProp(c.Key, c.Operator, c.Value, ctx.view); hit {
 				ctx.customConstr[id] = bind
 				continue
 			}
 			ctx.brokeConstraint = true
 			return
 		case msg.ConstraintService:
-			ctx.hasServiceConstraint = true
-			if id, hit, bind := g.evalServiceProp(c.Key, c.Value, ctx.view); hit {
-				ctx.serviceConstr[id] = bind
+			id, hit, bind := g.evalServiceProp(c.Key, c.Operator, c.Value, ctx.view)
+			if !hit {
+				ctx.brokeConstraint = true
+				return
+			}
+			// the absence of services binds no service
+			if constraint.Negative(c.Operator) {
 				continue
 			}
-			ctx.brokeConstraint = true
-			return
+			ctx.hasServiceConstraint = true
+			ctx.serviceConstr[id] = bind
 		case msg.ConstraintAttribute:
 			ctx.hasAttributeConstraint = true
 			ctx.attributes = append(ctx.attributes, c)
 		}
 	}
 
+	// constraint groups must match as well and add the values
+	// they bound, including services
+	ctx.evalConstraintGroups(g, g.Checks[ctx.uuid].ConstraintGroups)
+	if ctx.brokeConstraint {
+		return
+	}
+
 	switch {
 	case ctx.hasServiceConstraint && ctx.hasAttributeConstraint:
 		/* if the check has both service and attribute constraints,
@@ -386,7 +466,7 @@ func (g *Group) constraintCheck(ctx *checkContext) {
 		 */
 		for id := range ctx.serviceConstr {
 			for _, attr := range ctx.attributes {
-				hit, bind := g.evalAttributeOfService(id, ctx.view, attr.Key, attr.Value)
+				hit, bind := g.evalAttributeOfService(id, ctx.view, attr.Key, attr.Operator, attr.Value)
 				if hit {
 					// attributeC[id] might still be a nil map
 					if ctx.attributeConstr[id] == nil {
@@ -410,7 +490,7 @@ func (g *Group) constraintCheck(ctx *checkContext) {
 		 */
 		attrCount := len(ctx.attributes)
 		for _, attr := range ctx.attributes {
-			if hit, svcIDMap := g.evalAttributeProp(ctx.view, attr.Key, attr.Value); hit {
+			if hit, svcIDMap := g.evalAttributeProp(ctx.view, attr.Key, attr.Operator, attr.Value); hit {
 				for id, bind := range svcIDMap {
 					ctx.serviceConstr[id] = svcIDMap[id]
 					// attributeC[id] might still be a nil map
@@ -471,6 +551,9 @@ func (g *Group) createNoServiceCheckInstance(ctx *checkContext) {
 		InstanceServiceConfig: nil,
 		InstanceSvcCfgHash:    ``,
 	}
+	// attribute templates break checks without service constraints,
+	// binding can not fail here
+	ctx.bindThresholds(&inst)
 	inst.calcConstraintHash()
 	inst.calcConstraintValHash()
 
@@ -623,6 +706,11 @@ func (g *Group) createPerServiceCheckInstances(ctx *checkContext) {
 				InstanceService:       svcID,
 				InstanceServiceConfig: cfg,
 			}
+			if !ctx.bindThresholds(&inst) {
+				// the service configuration lacks a numeric value
+				// for an attribute threshold template
+				continue
+			}
 			inst.calcConstraintHash()
 			inst.calcConstraintValHash()
 			inst.calcInstanceSvcCfgHash()
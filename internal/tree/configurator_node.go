@@ -12,36 +12,42 @@ import (
 	"sync"
 
 	"github.com/mjolnir42/soma/internal/msg"
+	"github.com/mjolnir42/soma/lib/constraint"
+	"github.com/mjolnir42/soma/lib/proto"
 	uuid "github.com/satori/go.uuid"
 )
 
-func (n *Node) evalNativeProp(prop string, val string) bool {
+func (n *Node) evalNativeProp(prop string, op string, val string) bool {
 	switch prop {
 	case msg.NativePropertyEnvironment:
-		env := n.Parent.(Bucketeer).GetEnvironment()
-		if val == env {
-			return true
-		}
+		return constraint.Match(op, val, n.Parent.(Bucketeer).GetEnvironment())
 	case msg.NativePropertyEntity:
-		if val == msg.EntityNode {
-			return true
-		}
+		return constraint.Match(op, val, msg.EntityNode)
 	case msg.NativePropertyState:
-		if val == n.State {
-			return true
-		}
+		return constraint.Match(op, val, n.State)
 	case msg.NativePropertyHardwareNode:
-		// XX needs n.ServerName extension of ten
-		// if val == n.ServerName { return true }
-		return false
+		// the server is given by name, or by ID
+		if _, err := uuid.FromString(val); err == nil {
+			return constraint.Match(op, val, n.ServerID.String())
+		}
+		return constraint.Match(op, val, n.ServerName)
 	}
 	return false
 }
 
-func (n *Node) evalSystemProp(prop string, val string, view string) (string, bool, string) {
+func (n *Node) evalSystemProp(prop string, op string, val string, view string) (string, bool, string) {
+	if constraint.Negative(op) {
+		for _, v := range n.PropertySystem {
+			t := v.(*PropertySystem)
+			if t.Key == prop && (t.View == view || t.View == `any`) {
+				return "", false, ""
+			}
+		}
+		return prop, true, ""
+	}
 	for _, v := range n.PropertySystem {
 		t := v.(*PropertySystem)
-		if t.Key == prop && (t.Value == val || val == `@defined`) && (t.View == view || t.View == `any`) {
+		if t.Key == prop && constraint.Match(op, val, t.Value) && (t.View == view || t.View == `any`) {
 			return t.Key, true, t.Value
 		}
 	}
@@ -58,46 +64,80 @@ func (n *Node) evalOncallProp(prop string, val string, view string) (string, boo
 	return "", false
 }
 
-func (n *Node) evalCustomProp(prop string, val string, view string) (string, bool, string) {
+func (n *Node) evalCustomProp(prop string, op string, val string, view string) (string, bool, string) {
+	if constraint.Negative(op) {
+		for _, v := range n.PropertyCustom {
+			t := v.(*PropertyCustom)
+			if t.Key == prop && (t.View == view || t.View == `any`) {
+				return "", false, ""
+			}
+		}
+		return prop, true, ""
+	}
 	for _, v := range n.PropertyCustom {
 		t := v.(*PropertyCustom)
-		if t.Key == prop && (t.Value == val || val == `@defined`) && (t.View == view || t.View == `any`) {
+		if t.Key == prop && constraint.Match(op, val, t.Value) && (t.View == view || t.View == `any`) {
 			return t.Key, true, t.Value
 		}
 	}
 	return "", false, ""
 }
 
-func (n *Node) evalServiceProp(prop string, val string, view string) (string, bool, string) {
+// evalServiceProp returns the first service that matches. A negative
+// operator matches if there is no service in view, without binding a
+// service.
+func (n *Node) evalServiceProp(prop string, op string, val string, view string) (string, bool, string) {
+	if prop != "name" {
+		return "", false, ""
+	}
+	if constraint.Negative(op) {
+		for _, v := range n.PropertyService {
+			t := v.(*PropertyService)
+			if t.View == view || t.View == `any` {
+				return "", false, ""
+			}
+		}
+		return "", true, ""
+	}
 	for _, v := range n.PropertyService {
 		t := v.(*PropertyService)
-		if prop == "name" && (t.ServiceName == val || val == `@defined`) && (t.View == view || t.View == `any`) {
+		if constraint.Match(op, val, t.ServiceName) && (t.View == view || t.View == `any`) {
 			return t.ID.String(), true, t.ServiceName
 		}
 	}
 	return "", false, ""
 }
 
-func (n *Node) evalAttributeOfService(svcID string, view string, attribute string, value string) (bool, string) {
+func (n *Node) evalAttributeOfService(svcID string, view string, attribute string, op string, value string) (bool, string) {
 	t := n.PropertyService[svcID].(*PropertyService)
+	if t.View != view && t.View != `any` {
+		return false, ""
+	}
+	if constraint.Negative(op) {
+		for _, a := range t.Attributes {
+			if a.Name == attribute {
+				return false, ""
+			}
+		}
+		return true, ""
+	}
 	for _, a := range t.Attributes {
-		if a.Name == attribute && (t.View == view || t.View == `any`) && (a.Value == value || value == `@defined`) {
+		if a.Name == attribute && constraint.Match(op, value, a.Value) {
 			return true, a.Value
 		}
 	}
 	return false, ""
 }
 
-func (n *Node) evalAttributeProp(view string, attr string, value string) (bool, map[string]string) {
+func (n *Node) evalAttributeProp(view string, attr string, op string, value string) (bool, map[string]string) {
 	f := map[string]string{}
-svcloop:
 	for _, v := range n.PropertyService {
 		t := v.(*PropertyService)
-		for _, a := range t.Attributes {
-			if a.Name == attr && (a.Value == value || value == `@defined`) && (t.View == view || t.View == `any`) {
-				f[t.ID.String()] = a.Value
-				continue svcloop
-			}
+		if t.View != view && t.View != `any` {
+			continue
+		}
+		if hit, bind := n.evalAttributeOfService(t.ID.String(), view, attr, op, value); hit {
+			f[t.ID.String()] = bind
 		}
 	}
 	if len(f) > 0 {
@@ -188,6 +228,7 @@ func (n *Node) removeDisabledCheckInstances() {
 		// uses
 		if _, hit, _ := n.evalSystemProp(
 			msg.SystemPropertyDisableAllMonitoring,
+			proto.ConstraintEqual,
 			`true`,
 			n.Checks[chk].View,
 		); hit {
@@ -198,6 +239,7 @@ func (n *Node) removeDisabledCheckInstances() {
 		// check_configuration that spawned this check
 		if _, hit, _ := n.evalSystemProp(
 			msg.SystemPropertyDisableCheckConfiguration,
+			proto.ConstraintEqual,
 			n.Checks[chk].ConfigID.String(),
 			n.Checks[chk].View,
 		); hit {
@@ -247,6 +289,7 @@ func (n *Node) processCheckForUpdates(chkName string, startup bool) {
 	if _, hit, _ := n.evalSystemProp(
 		// skip check if `disable_all_monitoring` property is set
 		msg.SystemPropertyDisableAllMonitoring,
+		proto.ConstraintEqual,
 		`true`,
 		n.Checks[chkName].View,
 	); hit {
@@ -256,6 +299,7 @@ func (n *Node) processCheckForUpdates(chkName string, startup bool) {
 	if _, hit, _ := n.evalSystemProp(
 		// skip check if `disable_check_configuration` property is set
 		msg.SystemPropertyDisableCheckConfiguration,
+		proto.ConstraintEqual,
 		n.Checks[chkName].ConfigID.String(),
 		n.Checks[chkName].View,
 	); hit {
@@ -276,6 +320,17 @@ func (n *Node) processCheckForUpdates(chkName string, startup bool) {
 		return
 	}
 
+	// select the thresholds in effect for this check and resolve
+	// their templated values from the properties of this object
+	n.lock.RLock()
+	override, ok := n.thresholdOverride(n.Checks[chkName].ConfigID.String())
+	ctx.selectThresholds(n.Checks[chkName], override, ok)
+	ctx.resolveThresholdTemplates(n)
+	n.lock.RUnlock()
+	if ctx.brokeConstraint {
+		return
+	}
+
 	// check triggered, create instances
 	switch {
 	case !ctx.hasServiceConstraint:
@@ -323,14 +378,14 @@ func (n *Node) constraintCheck(ctx *checkContext) {
 	for _, cc := range n.Checks[ctx.uuid].Constraints {
 		switch cc.Type {
 		case msg.ConstraintNative:
-			if n.evalNativeProp(cc.Key, cc.Value) {
+			if n.evalNativeProp(cc.Key, cc.Operator, cc.Value) {
 				ctx.nativeConstr[cc.Key] = cc.Value
 				continue
 			}
 			ctx.brokeConstraint = true
 			return
 		case msg.ConstraintSystem:
-			if id, hit, bind := n.evalSystemProp(cc.Key, cc.Value, ctx.view); hit {
+			if id, hit, bind := n.evalSystemProp(cc.Key, cc.Operator, cc.Value, ctx.view); hit {
 				ctx.systemConstr[id] = bind
 				continue
 			}
@@ -344,26 +399,37 @@ func (n *Node) constraintCheck(ctx *checkContext) {
 			ctx.brokeConstraint = true
 			return
 		case msg.ConstraintCustom:
-			if id, hit, bind := n.evalCustomProp(cc.Key, cc.Value, ctx.view); hit {
+			if id, hit, bind := n.evalCustomProp(cc.Key, cc.Operator, cc.Value, ctx.view); hit {
 				ctx.customConstr[id] = bind
 				continue
 			}
 			ctx.brokeConstraint = true
 			return
 		case msg.ConstraintService:
-			ctx.hasServiceConstraint = true
-			if id, hit, bind := n.evalServiceProp(cc.Key, cc.Value, ctx.view); hit {
-				ctx.serviceConstr[id] = bind
+			id, hit, bind := n.evalServiceProp(cc.Key, cc.Operator, cc.Value, ctx.view)
+			if !hit {
+				ctx.brokeConstraint = true
+				return
+			}
+			// the absence of services binds no service
+			if constraint.Negative(cc.Operator) {
 				continue
 			}
-			ctx.brokeConstraint = true
-			return
+			ctx.hasServiceConstraint = true
+			ctx.serviceConstr[id] = bind
 		case msg.ConstraintAttribute:
 			ctx.hasAttributeConstraint = true
 			ctx.attributes = append(ctx.attributes, cc)
 		}
 	}
 
+	// constraint groups must match as well and add the values
+	// they bound, including services
+	ctx.evalConstraintGroups(n, n.Checks[ctx.uuid].ConstraintGroups)
+	if ctx.brokeConstraint {
+		return
+	}
+
 	switch {
 	case ctx.hasServiceConstraint && ctx.hasAttributeConstraint:
 		/* if the check has both service and attribute constraints,
@@ -373,7 +439,7 @@ func (n *Node) constraintCheck(ctx *checkContext) {
 		 */
 		for id := range ctx.serviceConstr {
 			for _, attr := range ctx.attributes {
-				hit, bind := n.evalAttributeOfService(id, ctx.view, attr.Key, attr.Value)
+				hit, bind := n.evalAttributeOfService(id, ctx.view, attr.Key, attr.Operator, attr.Value)
 				if hit {
 					// attributeC[id] might still be a nil map
 					if ctx.attributeConstr[id] == nil {
@@ -397,7 +463,7 @@ func (n *Node) constraintCheck(ctx *checkContext) {
 		 */
 		attrCount := len(ctx.attributes)
 		for _, attr := range ctx.attributes {
-			if hit, svcIDMap := n.evalAttributeProp(ctx.view, attr.Key, attr.Value); hit {
+			if hit, svcIDMap := n.evalAttributeProp(ctx.view, attr.Key, attr.Operator, attr.Value); hit {
 				for id, bind := range svcIDMap {
 					ctx.serviceConstr[id] = svcIDMap[id]
 					// attributeC[id] might still be a nil map
@@ -458,6 +524,9 @@ func (n *Node) createNoServiceCheckInstance(ctx *checkContext) {
 		InstanceServiceConfig: nil,
 		InstanceSvcCfgHash:    ``,
 	}
+	// attribute templates break checks without service constraints,
+	// binding can not fail here
+	ctx.bindThresholds(&inst)
 	inst.calcConstraintHash()
 	inst.calcConstraintValHash()
 
@@ -610,6 +679,11 @@ func (n *Node) createPerServiceCheckInstances(ctx *checkContext) {
 				InstanceService:       svcID,
 				InstanceServiceConfig: cfg,
 			}
+			if !ctx.bindThresholds(&inst) {
+				// the service configuration lacks a numeric value
+				// for an attribute threshold template
+				continue
+			}
 			inst.calcConstraintHash()
 			inst.calcConstraintValHash()
 			inst.calcInstanceSvcCfgHash()
@@ -0,0 +1,275 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package tree
+
+import (
+	"testing"
+
+	"github.com/mjolnir42/soma/internal/msg"
+	"github.com/mjolnir42/soma/lib/proto"
+	"github.com/satori/go.uuid"
+)
+
+func TestNodeEvalSystemPropOperators(t *testing.T) {
+	node := NewNode(NodeSpec{
+		ID:       uuid.Must(uuid.NewV4()).String(),
+		AssetID:  1,
+		Name:     `testnode`,
+		Team:     uuid.Must(uuid.NewV4()).String(),
+		ServerID: uuid.Must(uuid.NewV4()).String(),
+		Online:   true,
+		Deleted:  false,
+	})
+	for key, value := range map[string]string{
+		`fqdn`:      `testnode.example.org`,
+		`cpu_cores`: `16`,
+	} {
+		node.PropertySystem[key] = &PropertySystem{
+			View:  `internal`,
+			Key:   key,
+			Value: value,
+		}
+	}
+
+	tests := []struct {
+		prop, op, val string
+		hit           bool
+	}{
+		{`fqdn`, proto.ConstraintEqual, `testnode.example.org`, true},
+		{`fqdn`, ``, `testnode.example.org`, true},
+		{`fqdn`, proto.ConstraintEqual, proto.ConstraintDefined, true},
+		{`fqdn`, proto.ConstraintNotEqual, `testnode.example.org`, false},
+		{`fqdn`, proto.ConstraintMatch, `\.example\.org$`, true},
+		{`fqdn`, proto.ConstraintNotMatch, `\.lab$`, true},
+		{`fqdn`, proto.ConstraintPrefix, `testnode.`, true},
+		{`fqdn`, proto.ConstraintSuffix, `.lab`, false},
+		{`fqdn`, proto.ConstraintIn, `a.example.org, testnode.example.org`, true},
+		{`fqdn`, proto.ConstraintDefined, ``, true},
+		{`fqdn`, proto.ConstraintUndefined, ``, false},
+		{`cpu_cores`, proto.ConstraintGreaterEqual, `8`, true},
+		{`cpu_cores`, proto.ConstraintLess, `8`, false},
+		{`maintenance`, proto.ConstraintNotEqual, `yes`, false},
+		{`maintenance`, proto.ConstraintUndefined, ``, true},
+	}
+
+	for _, tt := range tests {
+		if _, hit, _ := node.evalSystemProp(tt.prop, tt.op, tt.val,
+			`internal`); hit != tt.hit {
+			t.Errorf("%s %s %s: hit %t, expected %t", tt.prop, tt.op,
+				tt.val, hit, tt.hit)
+		}
+	}
+
+	// properties in other views are not defined for the constraint
+	if _, hit, _ := node.evalSystemProp(`fqdn`, proto.ConstraintUndefined,
+		``, `external`); !hit {
+		t.Errorf("fqdn is defined in view external")
+	}
+}
+
+func TestNodeEvalNativePropHardwareNode(t *testing.T) {
+	serverID := uuid.Must(uuid.NewV4()).String()
+	node := NewNode(NodeSpec{
+		ID:         uuid.Must(uuid.NewV4()).String(),
+		AssetID:    1,
+		Name:       `testnode`,
+		Team:       uuid.Must(uuid.NewV4()).String(),
+		ServerID:   serverID,
+		ServerName: `testserver`,
+		Online:     true,
+		Deleted:    false,
+	})
+
+	tests := []struct {
+		op, val string
+		hit     bool
+	}{
+		{proto.ConstraintEqual, `testserver`, true},
+		{proto.ConstraintEqual, serverID, true},
+		{proto.ConstraintEqual, `otherserver`, false},
+		{proto.ConstraintNotEqual, `otherserver`, true},
+		{proto.ConstraintPrefix, `test`, true},
+	}
+	for _, tt := range tests {
+		if hit := node.evalNativeProp(msg.NativePropertyHardwareNode,
+			tt.op, tt.val); hit != tt.hit {
+			t.Errorf("%s %s: hit %t, expected %t", tt.op, tt.val,
+				hit, tt.hit)
+		}
+	}
+
+	node.SetServer(uuid.Must(uuid.NewV4()).String(), `otherserver`)
+	if node.evalNativeProp(msg.NativePropertyHardwareNode,
+		proto.ConstraintEqual, `testserver`) {
+		t.Errorf("node still on testserver after SetServer")
+	}
+
+	cluster := NewCluster(ClusterSpec{
+		ID:   uuid.Must(uuid.NewV4()).String(),
+		Name: `testcluster`,
+		Team: node.Team.String(),
+	})
+	if cluster.evalNativeProp(msg.NativePropertyHardwareNode,
+		proto.ConstraintEqual, `otherserver`) {
+		t.Errorf("empty cluster runs on otherserver")
+	}
+	cluster.Children[node.GetID()] = node
+	if !cluster.evalNativeProp(msg.NativePropertyHardwareNode,
+		proto.ConstraintEqual, `otherserver`) {
+		t.Errorf("cluster with member on otherserver does not match")
+	}
+}
+
+func TestNodeEvalConstraintGroups(t *testing.T) {
+	node := NewNode(NodeSpec{
+		ID:       uuid.Must(uuid.NewV4()).String(),
+		AssetID:  1,
+		Name:     `testnode`,
+		Team:     uuid.Must(uuid.NewV4()).String(),
+		ServerID: uuid.Must(uuid.NewV4()).String(),
+		Online:   true,
+		Deleted:  false,
+	})
+	node.PropertySystem[`fqdn`] = &PropertySystem{
+		ID:    uuid.Must(uuid.NewV4()),
+		View:  `internal`,
+		Key:   `fqdn`,
+		Value: `testnode.example.org`,
+	}
+	for _, name := range []string{`nginx`, `haproxy`} {
+		id := uuid.Must(uuid.NewV4())
+		node.PropertyService[id.String()] = &PropertyService{
+			ID:          id,
+			View:        `internal`,
+			ServiceName: name,
+		}
+	}
+
+	service := func(name string) CheckConstraint {
+		return CheckConstraint{Type: msg.ConstraintService, Key: `name`,
+			Operator: proto.ConstraintEqual, Value: name}
+	}
+	fqdn := CheckConstraint{Type: msg.ConstraintSystem, Key: `fqdn`,
+		Operator: proto.ConstraintEqual, Value: `testnode.example.org`}
+
+	tests := []struct {
+		name     string
+		group    CheckConstraintGroup
+		hit      bool
+		services int
+	}{
+		{`or binds all alternatives`, CheckConstraintGroup{
+			Operator:    proto.ConstraintGroupOr,
+			Constraints: []CheckConstraint{service(`nginx`), service(`haproxy`), service(`apache`)},
+		}, true, 2},
+		{`or without alternative`, CheckConstraintGroup{
+			Operator:    proto.ConstraintGroupOr,
+			Constraints: []CheckConstraint{service(`apache`), service(`squid`)},
+		}, false, 0},
+		{`and with missing member`, CheckConstraintGroup{
+			Operator:    proto.ConstraintGroupAnd,
+			Constraints: []CheckConstraint{fqdn, service(`apache`)},
+		}, false, 0},
+		{`not binds nothing`, CheckConstraintGroup{
+			Operator:    proto.ConstraintGroupNot,
+			Constraints: []CheckConstraint{service(`apache`)},
+		}, true, 0},
+		{`not of present service`, CheckConstraintGroup{
+			Operator:    proto.ConstraintGroupNot,
+			Constraints: []CheckConstraint{service(`nginx`)},
+		}, false, 0},
+		{`not with one present member`, CheckConstraintGroup{
+			Operator:    proto.ConstraintGroupNot,
+			Constraints: []CheckConstraint{service(`apache`), service(`nginx`)},
+		}, false, 0},
+		{`not without present members`, CheckConstraintGroup{
+			Operator:    proto.ConstraintGroupNot,
+			Constraints: []CheckConstraint{service(`apache`), service(`squid`)},
+		}, true, 0},
+		{`nested groups`, CheckConstraintGroup{
+			Operator:    proto.ConstraintGroupAnd,
+			Constraints: []CheckConstraint{fqdn},
+			Groups: []CheckConstraintGroup{{
+				Operator:    proto.ConstraintGroupOr,
+				Constraints: []CheckConstraint{service(`apache`), service(`haproxy`)},
+			}},
+		}, true, 1},
+	}
+
+	for _, tt := range tests {
+		hit, bind := evalConstraintGroup(node, `internal`, &tt.group)
+		if hit != tt.hit {
+			t.Errorf("%s: hit %t, expected %t", tt.name, hit, tt.hit)
+			continue
+		}
+		if hit && len(bind.service) != tt.services {
+			t.Errorf("%s: bound %d services, expected %d", tt.name,
+				len(bind.service), tt.services)
+		}
+	}
+}
+
+func TestConstraintGroupInstanceHash(t *testing.T) {
+	node := NewNode(NodeSpec{
+		ID:       uuid.Must(uuid.NewV4()).String(),
+		AssetID:  1,
+		Name:     `testnode`,
+		Team:     uuid.Must(uuid.NewV4()).String(),
+		ServerID: uuid.Must(uuid.NewV4()).String(),
+		Online:   true,
+		Deleted:  false,
+	})
+	node.PropertySystem[`fqdn`] = &PropertySystem{
+		ID:    uuid.Must(uuid.NewV4()),
+		View:  `internal`,
+		Key:   `fqdn`,
+		Value: `testnode.example.org`,
+	}
+	fqdn := CheckConstraint{Type: msg.ConstraintSystem, Key: `fqdn`,
+		Operator: proto.ConstraintEqual, Value: `testnode.example.org`}
+
+	checkID := uuid.Must(uuid.NewV4())
+	configID := uuid.Must(uuid.NewV4())
+	instance := func(chk Check) CheckInstance {
+		node.Checks[checkID.String()] = chk
+		ctx := newCheckContext(checkID.String(), `internal`, false)
+		node.constraintCheck(ctx)
+		if ctx.brokeConstraint {
+			t.Fatalf("check does not match testnode")
+		}
+		inst := CheckInstance{
+			CheckID:           checkID,
+			ConfigID:          configID,
+			ConstraintOncall:  ctx.oncallConstr,
+			ConstraintService: ctx.serviceConstr,
+			ConstraintSystem:  ctx.systemConstr,
+			ConstraintCustom:  ctx.customConstr,
+			ConstraintNative:  ctx.nativeConstr,
+		}
+		inst.calcConstraintHash()
+		inst.calcConstraintValHash()
+		return inst
+	}
+
+	flat := instance(Check{Constraints: []CheckConstraint{fqdn}})
+	grouped := instance(Check{ConstraintGroups: []CheckConstraintGroup{{
+		Operator:    proto.ConstraintGroupOr,
+		Constraints: []CheckConstraint{fqdn},
+	}}})
+
+	if flat.ConstraintHash != grouped.ConstraintHash {
+		t.Errorf("grouped constraint changed the constraint hash")
+	}
+	if flat.ConstraintValHash != grouped.ConstraintValHash {
+		t.Errorf("grouped constraint changed the constraint value hash")
+	}
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -0,0 +1,93 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package tree
+
+import (
+	"github.com/mjolnir42/soma/lib/proto"
+	"github.com/mjolnir42/soma/lib/threshold"
+)
+
+// resolveThresholdTemplates resolves the templated values of the
+// selected thresholds that reference system or custom properties of
+// e. Templates that reference service attributes are resolved per
+// service instance by bindThresholds. The check is not active on e
+// if a template can not be resolved.
+func (ctx *checkContext) resolveThresholdTemplates(e constraintEvaluator) {
+	for _, thr := range ctx.thresholds {
+		if thr.Template == `` {
+			continue
+		}
+		tmpl, err := threshold.ParseTemplate(thr.Template)
+		if err != nil {
+			ctx.brokeConstraint = true
+			return
+		}
+
+		var hit bool
+		var value string
+		switch tmpl.Type {
+		case threshold.TemplateSystem:
+			_, hit, value = e.evalSystemProp(tmpl.Property,
+				proto.ConstraintDefined, ``, ctx.view)
+		case threshold.TemplateCustom:
+			_, hit, value = e.evalCustomProp(tmpl.Property,
+				proto.ConstraintDefined, ``, ctx.view)
+		case threshold.TemplateAttribute:
+			// attribute values are only known for instances
+			// created per service
+			if !ctx.hasServiceConstraint {
+				ctx.brokeConstraint = true
+				return
+			}
+			ctx.attributeTemplates[thr.Level] = tmpl
+			continue
+		}
+		if !hit {
+			ctx.brokeConstraint = true
+			return
+		}
+		if ctx.thresholdValues[thr.Level], err = tmpl.Resolve(
+			value,
+		); err != nil {
+			ctx.brokeConstraint = true
+			return
+		}
+	}
+}
+
+// bindThresholds records the threshold override and the resolved
+// threshold templates in inst. It reports false if an attribute
+// template can not be resolved from the service configuration of
+// inst, in which case no instance must be created for it.
+func (ctx *checkContext) bindThresholds(inst *CheckInstance) bool {
+	inst.ThresholdOverrideID = ctx.thresholdOverrideID
+	if len(ctx.thresholdValues) == 0 && len(ctx.attributeTemplates) == 0 {
+		return true
+	}
+
+	inst.ThresholdValues = make(map[uint8]int64)
+	for lvl, val := range ctx.thresholdValues {
+		inst.ThresholdValues[lvl] = val
+	}
+	for lvl, tmpl := range ctx.attributeTemplates {
+		value, ok := inst.InstanceServiceConfig[tmpl.Property]
+		if !ok {
+			return false
+		}
+		res, err := tmpl.Resolve(value)
+		if err != nil {
+			return false
+		}
+		inst.ThresholdValues[lvl] = res
+	}
+	return true
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
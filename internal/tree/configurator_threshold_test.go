@@ -0,0 +1,138 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package tree
+
+import (
+	"testing"
+
+	"github.com/satori/go.uuid"
+)
+
+func TestThresholdTemplateResolve(t *testing.T) {
+	deterministicInheritanceOrder = true
+
+	sTree, actionC, errC := testSpawnCheckTree()
+
+	chk := Check{
+		ID:            uuid.Nil,
+		SourceID:      uuid.Nil,
+		InheritedFrom: uuid.Nil,
+		Inheritance:   true,
+		ChildrenOnly:  false,
+		Interval:      60,
+		ConfigID:      uuid.Must(uuid.NewV4()),
+		CapabilityID:  uuid.Must(uuid.NewV4()),
+		View:          `any`,
+		Thresholds: []CheckThreshold{
+			{
+				Predicate: `>=`,
+				Level:     1,
+				Value:     100,
+			},
+			{
+				Predicate: `>=`,
+				Level:     2,
+				Template:  `50%@system:max_connections`,
+			},
+		},
+		Constraints: []CheckConstraint{},
+	}
+
+	sTree.Find(FindRequest{
+		ElementType: `repository`,
+		ElementName: `checkTest`,
+	}, true).SetCheck(chk)
+
+	propID := uuid.Must(uuid.NewV4())
+	sTree.Find(FindRequest{
+		ElementType: `node`,
+		ElementName: `testnode1`,
+	}, true).(Propertier).SetProperty(&PropertySystem{
+		ID:           propID,
+		SourceID:     propID,
+		Inheritance:  true,
+		ChildrenOnly: false,
+		View:         `any`,
+		Key:          `max_connections`,
+		Value:        `300`,
+	})
+
+	sTree.ComputeCheckInstances()
+
+	if len(errC) > 0 {
+		t.Error(`Error channel not empty`)
+	}
+	for len(actionC) > 0 {
+		<-actionC
+	}
+
+	// nodes without the property do not get an instance
+	node := sTree.Find(FindRequest{
+		ElementType: `node`,
+		ElementName: `testnode2`,
+	}, true).(*Node)
+	if len(node.Instances) != 0 {
+		t.Errorf("testnode2: expected 0 check instances, got %d",
+			len(node.Instances))
+	}
+
+	node = sTree.Find(FindRequest{
+		ElementType: `node`,
+		ElementName: `testnode1`,
+	}, true).(*Node)
+	if len(node.Instances) != 1 {
+		t.Fatalf("testnode1: expected 1 check instance, got %d",
+			len(node.Instances))
+	}
+	var before CheckInstance
+	for _, inst := range node.Instances {
+		before = inst.Clone()
+	}
+	if len(before.ThresholdValues) != 1 || before.ThresholdValues[2] != 150 {
+		t.Errorf("testnode1: expected resolved value 150 for level 2,"+
+			" got %v", before.ThresholdValues)
+	}
+
+	// changing the property updates the instance
+	sTree.Find(FindRequest{
+		ElementType: `node`,
+		ElementName: `testnode1`,
+	}, true).(Propertier).UpdateProperty(&PropertySystem{
+		SourceID:     propID,
+		Inheritance:  true,
+		ChildrenOnly: false,
+		View:         `any`,
+		Key:          `max_connections`,
+		Value:        `500`,
+	})
+
+	sTree.ComputeCheckInstances()
+	close(actionC)
+	close(errC)
+
+	if len(node.Instances) != 1 {
+		t.Fatalf("testnode1: expected 1 check instance, got %d",
+			len(node.Instances))
+	}
+	for _, inst := range node.Instances {
+		if !uuid.Equal(inst.InstanceID, before.InstanceID) {
+			t.Errorf("testnode1: instance was replaced instead of updated")
+		}
+		if inst.ThresholdValues[2] != 250 {
+			t.Errorf("testnode1: expected resolved value 250 for"+
+				" level 2, got %d", inst.ThresholdValues[2])
+		}
+		if inst.ConstraintValHash == before.ConstraintValHash {
+			t.Errorf("testnode1: value hash did not change")
+		}
+	}
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
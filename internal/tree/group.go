@@ -32,6 +32,7 @@ type Group struct {
 	PropertySystem  map[string]Property
 	PropertyCustom  map[string]Property
 	Checks          map[string]Check
+	Overrides       map[string]ThresholdOverride
 	CheckInstances  map[string][]string
 	Instances       map[string]CheckInstance
 	Children        map[string]GroupAttacher `json:"-"`
@@ -74,6 +75,7 @@ func NewGroup(spec GroupSpec) *Group {
 	teg.PropertySystem = make(map[string]Property)
 	teg.PropertyCustom = make(map[string]Property)
 	teg.Checks = make(map[string]Check)
+	teg.Overrides = make(map[string]ThresholdOverride)
 	teg.CheckInstances = make(map[string][]string)
 	teg.Instances = make(map[string]CheckInstance)
 	teg.loadedInstances = make(map[string]map[string]CheckInstance)
@@ -137,6 +139,12 @@ func (teg Group) Clone() *Group {
 	}
 	cl.Checks = cK
 
+	cO := make(map[string]ThresholdOverride)
+	for k, o := range teg.Overrides {
+		cO[k] = o.Clone()
+	}
+	cl.Overrides = cO
+
 	cki := make(map[string]CheckInstance)
 	for k, chki := range teg.Instances {
 		cki[k] = chki.Clone()
@@ -24,6 +24,7 @@ type Node struct {
 	AssetID         uint64
 	Team            uuid.UUID
 	ServerID        uuid.UUID
+	ServerName      string
 	State           string
 	Online          bool
 	Deleted         bool
@@ -36,6 +37,7 @@ type Node struct {
 	PropertySystem  map[string]Property
 	PropertyCustom  map[string]Property
 	Checks          map[string]Check
+	Overrides       map[string]ThresholdOverride
 	CheckInstances  map[string][]string
 	Instances       map[string]CheckInstance
 	loadedInstances map[string]map[string]CheckInstance
@@ -45,13 +47,14 @@ type Node struct {
 }
 
 type NodeSpec struct {
-	ID       string
-	AssetID  uint64
-	Name     string
-	Team     string
-	ServerID string
-	Online   bool
-	Deleted  bool
+	ID         string
+	AssetID    uint64
+	Name       string
+	Team       string
+	ServerID   string
+	ServerName string
+	Online     bool
+	Deleted    bool
 }
 
 //
@@ -68,6 +71,7 @@ func NewNode(spec NodeSpec) *Node {
 	ten.AssetID = spec.AssetID
 	ten.Team, _ = uuid.FromString(spec.Team)
 	ten.ServerID, _ = uuid.FromString(spec.ServerID)
+	ten.ServerName = spec.ServerName
 	ten.Online = spec.Online
 	ten.Deleted = spec.Deleted
 	ten.Type = "node"
@@ -78,6 +82,7 @@ func NewNode(spec NodeSpec) *Node {
 	ten.PropertySystem = make(map[string]Property)
 	ten.PropertyCustom = make(map[string]Property)
 	ten.Checks = make(map[string]Check)
+	ten.Overrides = make(map[string]ThresholdOverride)
 	ten.CheckInstances = make(map[string][]string)
 	ten.Instances = make(map[string]CheckInstance)
 	ten.loadedInstances = make(map[string]map[string]CheckInstance)
@@ -87,12 +92,13 @@ func NewNode(spec NodeSpec) *Node {
 
 func (ten Node) Clone() *Node {
 	cl := Node{
-		Name:    ten.Name,
-		State:   ten.State,
-		Online:  ten.Online,
-		Deleted: ten.Deleted,
-		Type:    ten.Type,
-		log:     ten.log,
+		Name:       ten.Name,
+		ServerName: ten.ServerName,
+		State:      ten.State,
+		Online:     ten.Online,
+		Deleted:    ten.Deleted,
+		Type:       ten.Type,
+		log:        ten.log,
 	}
 	cl.ID, _ = uuid.FromString(ten.ID.String())
 	cl.AssetID = ten.AssetID
@@ -129,6 +135,12 @@ func (ten Node) Clone() *Node {
 	}
 	cl.Checks = cK
 
+	cO := make(map[string]ThresholdOverride)
+	for k, o := range ten.Overrides {
+		cO[k] = o.Clone()
+	}
+	cl.Overrides = cO
+
 	cki := make(map[string]CheckInstance)
 	for k, chki := range ten.Instances {
 		cki[k] = chki.Clone()
@@ -256,14 +268,15 @@ func (ten *Node) ClearLoadInfo() {
 func (ten *Node) export() proto.Node {
 	bucket := ten.Parent.(Bucketeer).GetBucket()
 	return proto.Node{
-		ID:        ten.ID.String(),
-		AssetID:   ten.AssetID,
-		Name:      ten.Name,
-		TeamID:    ten.Team.String(),
-		ServerID:  ten.ServerID.String(),
-		State:     ten.State,
-		IsOnline:  ten.Online,
-		IsDeleted: ten.Deleted,
+		ID:         ten.ID.String(),
+		AssetID:    ten.AssetID,
+		Name:       ten.Name,
+		TeamID:     ten.Team.String(),
+		ServerID:   ten.ServerID.String(),
+		ServerName: ten.ServerName,
+		State:      ten.State,
+		IsOnline:   ten.Online,
+		IsDeleted:  ten.Deleted,
 		Config: &proto.NodeConfig{
 			BucketID: bucket.(Builder).GetID(),
 		},
@@ -0,0 +1,80 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package tree
+
+import (
+	"github.com/satori/go.uuid"
+)
+
+// ThresholdOverrider is implemented by all tree elements that can
+// carry threshold overrides for check configurations
+type ThresholdOverrider interface {
+	SetThresholdOverride(o ThresholdOverride)
+	DeleteThresholdOverride(o ThresholdOverride)
+
+	thresholdOverride(configID string) (ThresholdOverride, bool)
+	updateOverrideDeep()
+}
+
+// ThresholdOverride replaces the thresholds of check configuration
+// ConfigID for all check instances on or below the object it is set
+// on. The override set on the nearest object wins.
+type ThresholdOverride struct {
+	ID         uuid.UUID
+	ConfigID   uuid.UUID
+	ObjectID   uuid.UUID
+	ObjectType string
+	Thresholds []CheckThreshold
+}
+
+func (o *ThresholdOverride) Clone() ThresholdOverride {
+	cl := ThresholdOverride{
+		ObjectType: o.ObjectType,
+	}
+	cl.ID, _ = uuid.FromString(o.ID.String())
+	cl.ConfigID, _ = uuid.FromString(o.ConfigID.String())
+	cl.ObjectID, _ = uuid.FromString(o.ObjectID.String())
+	cl.Thresholds = make([]CheckThreshold, len(o.Thresholds))
+	for i := range o.Thresholds {
+		cl.Thresholds[i] = o.Thresholds[i].Clone()
+	}
+	return cl
+}
+
+// parentThresholdOverride resolves the threshold override for
+// configID on p and its parents
+func parentThresholdOverride(p interface{}, configID string) (ThresholdOverride, bool) {
+	if parent, ok := p.(ThresholdOverrider); ok {
+		return parent.thresholdOverride(configID)
+	}
+	return ThresholdOverride{}, false
+}
+
+// updateOverrideOnChild flags child and its children for check
+// instance recalculation
+func updateOverrideOnChild(child interface{}) {
+	if c, ok := child.(ThresholdOverrider); ok {
+		c.updateOverrideDeep()
+	}
+}
+
+// selectThresholds records the thresholds in effect for check chk,
+// which are the thresholds of the nearest threshold override if one
+// is set
+func (ctx *checkContext) selectThresholds(chk Check, o ThresholdOverride, ok bool) {
+	if !ok {
+		ctx.thresholds = chk.Thresholds
+		return
+	}
+	ctx.thresholds = o.Thresholds
+	ctx.thresholdOverrideID, _ = uuid.FromString(o.ID.String())
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
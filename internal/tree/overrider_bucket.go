@@ -0,0 +1,56 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package tree
+
+import (
+	"github.com/satori/go.uuid"
+)
+
+// Implementation of the `ThresholdOverrider` interface
+
+//
+// ThresholdOverrider:> Set Override
+
+func (teb *Bucket) SetThresholdOverride(o ThresholdOverride) {
+	f := o.Clone()
+	f.ObjectID, _ = uuid.FromString(teb.ID.String())
+	f.ObjectType = teb.Type
+	teb.Overrides[f.ConfigID.String()] = f
+	teb.updateOverrideDeep()
+}
+
+//
+// ThresholdOverrider:> Delete Override
+
+func (teb *Bucket) DeleteThresholdOverride(o ThresholdOverride) {
+	if _, ok := teb.Overrides[o.ConfigID.String()]; !ok {
+		return
+	}
+	delete(teb.Overrides, o.ConfigID.String())
+	teb.updateOverrideDeep()
+}
+
+//
+// ThresholdOverrider:> Resolve Override
+
+func (teb *Bucket) thresholdOverride(configID string) (ThresholdOverride, bool) {
+	if o, ok := teb.Overrides[configID]; ok {
+		return o, true
+	}
+	return parentThresholdOverride(teb.Parent, configID)
+}
+
+func (teb *Bucket) updateOverrideDeep() {
+	for child := range teb.Children {
+		updateOverrideOnChild(teb.Children[child])
+	}
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
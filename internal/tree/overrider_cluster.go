@@ -0,0 +1,57 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package tree
+
+import (
+	"github.com/satori/go.uuid"
+)
+
+// Implementation of the `ThresholdOverrider` interface
+
+//
+// ThresholdOverrider:> Set Override
+
+func (tec *Cluster) SetThresholdOverride(o ThresholdOverride) {
+	f := o.Clone()
+	f.ObjectID, _ = uuid.FromString(tec.ID.String())
+	f.ObjectType = tec.Type
+	tec.Overrides[f.ConfigID.String()] = f
+	tec.updateOverrideDeep()
+}
+
+//
+// ThresholdOverrider:> Delete Override
+
+func (tec *Cluster) DeleteThresholdOverride(o ThresholdOverride) {
+	if _, ok := tec.Overrides[o.ConfigID.String()]; !ok {
+		return
+	}
+	delete(tec.Overrides, o.ConfigID.String())
+	tec.updateOverrideDeep()
+}
+
+//
+// ThresholdOverrider:> Resolve Override
+
+func (tec *Cluster) thresholdOverride(configID string) (ThresholdOverride, bool) {
+	if o, ok := tec.Overrides[configID]; ok {
+		return o, true
+	}
+	return parentThresholdOverride(tec.Parent, configID)
+}
+
+func (tec *Cluster) updateOverrideDeep() {
+	tec.hasUpdate = true
+	for child := range tec.Children {
+		updateOverrideOnChild(tec.Children[child])
+	}
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
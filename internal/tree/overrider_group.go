@@ -0,0 +1,57 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package tree
+
+import (
+	"github.com/satori/go.uuid"
+)
+
+// Implementation of the `ThresholdOverrider` interface
+
+//
+// ThresholdOverrider:> Set Override
+
+func (teg *Group) SetThresholdOverride(o ThresholdOverride) {
+	f := o.Clone()
+	f.ObjectID, _ = uuid.FromString(teg.ID.String())
+	f.ObjectType = teg.Type
+	teg.Overrides[f.ConfigID.String()] = f
+	teg.updateOverrideDeep()
+}
+
+//
+// ThresholdOverrider:> Delete Override
+
+func (teg *Group) DeleteThresholdOverride(o ThresholdOverride) {
+	if _, ok := teg.Overrides[o.ConfigID.String()]; !ok {
+		return
+	}
+	delete(teg.Overrides, o.ConfigID.String())
+	teg.updateOverrideDeep()
+}
+
+//
+// ThresholdOverrider:> Resolve Override
+
+func (teg *Group) thresholdOverride(configID string) (ThresholdOverride, bool) {
+	if o, ok := teg.Overrides[configID]; ok {
+		return o, true
+	}
+	return parentThresholdOverride(teg.Parent, configID)
+}
+
+func (teg *Group) updateOverrideDeep() {
+	teg.hasUpdate = true
+	for child := range teg.Children {
+		updateOverrideOnChild(teg.Children[child])
+	}
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -0,0 +1,54 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package tree
+
+import (
+	"github.com/satori/go.uuid"
+)
+
+// Implementation of the `ThresholdOverrider` interface
+
+//
+// ThresholdOverrider:> Set Override
+
+func (ten *Node) SetThresholdOverride(o ThresholdOverride) {
+	f := o.Clone()
+	f.ObjectID, _ = uuid.FromString(ten.ID.String())
+	f.ObjectType = ten.Type
+	ten.Overrides[f.ConfigID.String()] = f
+	ten.updateOverrideDeep()
+}
+
+//
+// ThresholdOverrider:> Delete Override
+
+func (ten *Node) DeleteThresholdOverride(o ThresholdOverride) {
+	if _, ok := ten.Overrides[o.ConfigID.String()]; !ok {
+		return
+	}
+	delete(ten.Overrides, o.ConfigID.String())
+	ten.updateOverrideDeep()
+}
+
+//
+// ThresholdOverrider:> Resolve Override
+
+func (ten *Node) thresholdOverride(configID string) (ThresholdOverride, bool) {
+	if o, ok := ten.Overrides[configID]; ok {
+		return o, true
+	}
+	return parentThresholdOverride(ten.Parent, configID)
+}
+
+func (ten *Node) updateOverrideDeep() {
+	ten.hasUpdate = true
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -0,0 +1,56 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package tree
+
+import (
+	"github.com/satori/go.uuid"
+)
+
+// Implementation of the `ThresholdOverrider` interface
+
+//
+// ThresholdOverrider:> Set Override
+
+func (ter *Repository) SetThresholdOverride(o ThresholdOverride) {
+	f := o.Clone()
+	f.ObjectID, _ = uuid.FromString(ter.ID.String())
+	f.ObjectType = ter.Type
+	ter.Overrides[f.ConfigID.String()] = f
+	ter.updateOverrideDeep()
+}
+
+//
+// ThresholdOverrider:> Delete Override
+
+func (ter *Repository) DeleteThresholdOverride(o ThresholdOverride) {
+	if _, ok := ter.Overrides[o.ConfigID.String()]; !ok {
+		return
+	}
+	delete(ter.Overrides, o.ConfigID.String())
+	ter.updateOverrideDeep()
+}
+
+//
+// ThresholdOverrider:> Resolve Override
+
+func (ter *Repository) thresholdOverride(configID string) (ThresholdOverride, bool) {
+	if o, ok := ter.Overrides[configID]; ok {
+		return o, true
+	}
+	return parentThresholdOverride(ter.Parent, configID)
+}
+
+func (ter *Repository) updateOverrideDeep() {
+	for child := range ter.Children {
+		updateOverrideOnChild(ter.Children[child])
+	}
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -0,0 +1,131 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package tree
+
+import (
+	"testing"
+
+	"github.com/satori/go.uuid"
+)
+
+func TestThresholdOverrideNearestWins(t *testing.T) {
+	deterministicInheritanceOrder = true
+
+	sTree, actionC, errC := testSpawnCheckTree()
+
+	chkConfigID := uuid.Must(uuid.NewV4())
+
+	chk := Check{
+		ID:            uuid.Nil,
+		SourceID:      uuid.Nil,
+		InheritedFrom: uuid.Nil,
+		Inheritance:   true,
+		ChildrenOnly:  false,
+		Interval:      60,
+		ConfigID:      chkConfigID,
+		CapabilityID:  uuid.Must(uuid.NewV4()),
+		View:          `any`,
+		Thresholds: []CheckThreshold{
+			{
+				Predicate: `>=`,
+				Level:     1,
+				Value:     100,
+			},
+		},
+		Constraints: []CheckConstraint{},
+	}
+
+	sTree.Find(FindRequest{
+		ElementType: `repository`,
+		ElementName: `checkTest`,
+	}, true).SetCheck(chk)
+
+	bucketOverride := ThresholdOverride{
+		ID:       uuid.Must(uuid.NewV4()),
+		ConfigID: chkConfigID,
+		Thresholds: []CheckThreshold{
+			{
+				Predicate: `>=`,
+				Level:     1,
+				Value:     200,
+			},
+		},
+	}
+	sTree.Find(FindRequest{
+		ElementType: `bucket`,
+		ElementName: `checkTest_master`,
+	}, true).(ThresholdOverrider).SetThresholdOverride(bucketOverride)
+
+	nodeOverride := bucketOverride.Clone()
+	nodeOverride.ID = uuid.Must(uuid.NewV4())
+	nodeOverride.Thresholds[0].Value = 300
+	sTree.Find(FindRequest{
+		ElementType: `node`,
+		ElementName: `testnode1`,
+	}, true).(ThresholdOverrider).SetThresholdOverride(nodeOverride)
+
+	sTree.ComputeCheckInstances()
+
+	if len(errC) > 0 {
+		t.Error(`Error channel not empty`)
+	}
+	for len(actionC) > 0 {
+		<-actionC
+	}
+
+	for _, name := range []string{
+		`testnode1`, `testnode2`, `testnode3`, `testnode4`,
+	} {
+		node := sTree.Find(FindRequest{
+			ElementType: `node`,
+			ElementName: name,
+		}, true).(*Node)
+
+		expected := bucketOverride.ID
+		if name == `testnode1` {
+			expected = nodeOverride.ID
+		}
+
+		if len(node.Instances) != 1 {
+			t.Errorf("%s: expected 1 check instance, got %d",
+				name, len(node.Instances))
+		}
+		for _, inst := range node.Instances {
+			if !uuid.Equal(inst.ThresholdOverrideID, expected) {
+				t.Errorf("%s: expected override %s, got %s", name,
+					expected.String(), inst.ThresholdOverrideID.String())
+			}
+		}
+	}
+
+	// removing the node override falls back to the bucket override
+	sTree.Find(FindRequest{
+		ElementType: `node`,
+		ElementName: `testnode1`,
+	}, true).(ThresholdOverrider).DeleteThresholdOverride(nodeOverride)
+
+	sTree.ComputeCheckInstances()
+	close(actionC)
+	close(errC)
+
+	node := sTree.Find(FindRequest{
+		ElementType: `node`,
+		ElementName: `testnode1`,
+	}, true).(*Node)
+	for _, inst := range node.Instances {
+		if !uuid.Equal(inst.ThresholdOverrideID, bucketOverride.ID) {
+			t.Errorf("testnode1: expected override %s, got %s",
+				bucketOverride.ID.String(),
+				inst.ThresholdOverrideID.String())
+		}
+	}
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
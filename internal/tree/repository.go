@@ -33,6 +33,7 @@ type Repository struct {
 	PropertySystem  map[string]Property
 	PropertyCustom  map[string]Property
 	Checks          map[string]Check
+	Overrides       map[string]ThresholdOverride
 	Children        map[string]RepositoryAttacher `json:"-"`
 	Action          chan *Action                  `json:"-"`
 	ordNumChildBck  int
@@ -71,6 +72,7 @@ func NewRepository(spec RepositorySpec) *Repository {
 	ter.PropertySystem = make(map[string]Property)
 	ter.PropertyCustom = make(map[string]Property)
 	ter.Checks = make(map[string]Check)
+	ter.Overrides = make(map[string]ThresholdOverride)
 	ter.ordNumChildBck = 0
 	ter.ordChildrenBck = make(map[int]string)
 
@@ -133,6 +135,12 @@ func (ter Repository) Clone() Repository {
 	}
 	cl.Checks = cK
 
+	cO := make(map[string]ThresholdOverride)
+	for k, o := range ter.Overrides {
+		cO[k] = o.Clone()
+	}
+	cl.Overrides = cO
+
 	chLB := make(map[int]string)
 	for i, s := range ter.ordChildrenBck {
 		chLB[i] = s
@@ -0,0 +1,126 @@
+/*-
+Copyright (c) 2019, Jörg Pernfuß <code.jpe@gmail.com>
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+* Redistributions of source code must retain the above copyright notice, this
+  list of conditions and the following disclaimer.
+
+* Redistributions in binary form must reproduce the above copyright notice,
+  this list of conditions and the following disclaimer in the documentation
+  and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+)
+
+// Header names used to authenticate requests exchanged between SOMA
+// and monitoring systems
+const (
+	HeaderMonitoringSignature = `X-Soma-Signature`
+	HeaderMonitoringTimestamp = `X-Soma-Timestamp`
+	HeaderMonitoringToken     = `X-Soma-Monitoring-Token`
+)
+
+// MonitoringSignatureWindow regulates the maximum allowed age in
+// seconds of a signed monitoring system request. The same amount of
+// clock skew into the future is accepted.
+var MonitoringSignatureWindow int64 = 300
+
+// SignMonitoring computes the hex encoded HMAC-SHA256 signature of a
+// request exchanged between SOMA and a monitoring system. The
+// signature covers the request method, path, timestamp and body.
+func SignMonitoring(secret []byte, method, path, timestamp string, body []byte) string {
+	return hex.EncodeToString(computeMonitoringSignature(
+		secret,
+		[]byte(method),
+		[]byte(path),
+		[]byte(timestamp),
+		body,
+	))
+}
+
+// VerifyMonitoring checks a signature created by SignMonitoring. The
+// timestamp is the unix time in seconds at which the request was
+// signed and has to be within MonitoringSignatureWindow.
+func VerifyMonitoring(secret []byte, method, path, timestamp, signature string, body []byte) bool {
+	var (
+		err       error
+		signedAt  int64
+		signedMAC []byte
+	)
+
+	if len(secret) == 0 {
+		return false
+	}
+	if signedAt, err = strconv.ParseInt(timestamp, 10, 64); err != nil {
+		return false
+	}
+	if skew := time.Now().UTC().Unix() - signedAt; skew > MonitoringSignatureWindow ||
+		skew < -MonitoringSignatureWindow {
+		return false
+	}
+	if signedMAC, err = hex.DecodeString(signature); err != nil {
+		return false
+	}
+
+	calculated := computeMonitoringSignature(
+		secret,
+		[]byte(method),
+		[]byte(path),
+		[]byte(timestamp),
+		body,
+	)
+	return hmac.Equal(signedMAC, calculated)
+}
+
+// VerifyMonitoringToken checks a monitoring system token that was
+// supplied instead of a signature
+func VerifyMonitoringToken(secret []byte, token string) bool {
+	if len(secret) == 0 {
+		return false
+	}
+	return hmac.Equal(secret, []byte(token))
+}
+
+// MonitoringTimestamp returns the current time formatted for use as
+// value of HeaderMonitoringTimestamp
+func MonitoringTimestamp() string {
+	return strconv.FormatInt(time.Now().UTC().Unix(), 10)
+}
+
+// computeMonitoringSignature computes the HMAC over the newline
+// separated request components
+func computeMonitoringSignature(secret, method, path, timestamp, body []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(method)
+	mac.Write([]byte{'\n'})
+	mac.Write(path)
+	mac.Write([]byte{'\n'})
+	mac.Write(timestamp)
+	mac.Write([]byte{'\n'})
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -0,0 +1,185 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+// Package constraint evaluates the values of check configuration
+// constraints against property values. The operators are defined in
+// lib/proto.
+package constraint
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/mjolnir42/soma/lib/proto"
+)
+
+// expressions caches the compiled regular expressions of the match
+// operators, since the same constraint is evaluated for every object
+// of a tree
+var expressions = struct {
+	sync.RWMutex
+	cache map[string]*regexp.Regexp
+}{
+	cache: map[string]*regexp.Regexp{},
+}
+
+// Supported reports if operator is an operator that can be evaluated
+func Supported(operator string) bool {
+	switch operator {
+	case ``,
+		proto.ConstraintEqual,
+		proto.ConstraintNotEqual,
+		proto.ConstraintDefined,
+		proto.ConstraintUndefined,
+		proto.ConstraintMatch,
+		proto.ConstraintNotMatch,
+		proto.ConstraintPrefix,
+		proto.ConstraintSuffix,
+		proto.ConstraintLess,
+		proto.ConstraintLessEqual,
+		proto.ConstraintGreaterEqual,
+		proto.ConstraintGreater,
+		proto.ConstraintIn:
+		return true
+	}
+	return false
+}
+
+// Validate checks that value is a valid value for operator
+func Validate(operator, value string) error {
+	switch operator {
+	case proto.ConstraintMatch, proto.ConstraintNotMatch:
+		if _, err := regexp.Compile(value); err != nil {
+			return fmt.Errorf("constraint: invalid regular expression"+
+				" %q: %s", value, err.Error())
+		}
+	case proto.ConstraintLess, proto.ConstraintLessEqual,
+		proto.ConstraintGreaterEqual, proto.ConstraintGreater:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return fmt.Errorf("constraint: operator %s requires a"+
+				" numeric value, got %q", operator, value)
+		}
+	case proto.ConstraintIn:
+		if len(Set(value)) == 0 {
+			return fmt.Errorf("constraint: operator %s requires a"+
+				" non-empty set", operator)
+		}
+	case proto.ConstraintDefined, proto.ConstraintUndefined:
+	default:
+		if !Supported(operator) {
+			return fmt.Errorf("constraint: unsupported operator %q",
+				operator)
+		}
+	}
+	return nil
+}
+
+// Negative reports if operator matches objects that do not have the
+// constrained property
+func Negative(operator string) bool {
+	return operator == proto.ConstraintUndefined
+}
+
+// Match reports if the value actual of a property matches the
+// constraint value under operator. For compatibility, value
+// @defined under the equality operator matches every value. Negative
+// operators never match an existing property.
+func Match(operator, value, actual string) bool {
+	switch operator {
+	case ``, proto.ConstraintEqual:
+		return actual == value || value == proto.ConstraintDefined
+	case proto.ConstraintNotEqual:
+		return actual != value
+	case proto.ConstraintDefined:
+		return true
+	case proto.ConstraintUndefined:
+		return false
+	case proto.ConstraintMatch, proto.ConstraintNotMatch:
+		re, err := compile(value)
+		if err != nil {
+			return false
+		}
+		return re.MatchString(actual) == (operator == proto.ConstraintMatch)
+	case proto.ConstraintPrefix:
+		return strings.HasPrefix(actual, value)
+	case proto.ConstraintSuffix:
+		return strings.HasSuffix(actual, value)
+	case proto.ConstraintLess, proto.ConstraintLessEqual,
+		proto.ConstraintGreaterEqual, proto.ConstraintGreater:
+		return compare(operator, value, actual)
+	case proto.ConstraintIn:
+		for _, member := range Set(value) {
+			if actual == member {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Set returns the members of the comma separated set value, with
+// surrounding whitespace and empty members removed
+func Set(value string) []string {
+	set := []string{}
+	for _, member := range strings.Split(value, `,`) {
+		if member = strings.TrimSpace(member); member != `` {
+			set = append(set, member)
+		}
+	}
+	return set
+}
+
+// compare reports if actual <operator> value is true. Non-numeric
+// values never match.
+func compare(operator, value, actual string) bool {
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return false
+	}
+	a, err := strconv.ParseFloat(actual, 64)
+	if err != nil {
+		return false
+	}
+
+	switch operator {
+	case proto.ConstraintLess:
+		return a < v
+	case proto.ConstraintLessEqual:
+		return a <= v
+	case proto.ConstraintGreaterEqual:
+		return a >= v
+	case proto.ConstraintGreater:
+		return a > v
+	}
+	return false
+}
+
+// compile returns the compiled regular expression expr
+func compile(expr string) (*regexp.Regexp, error) {
+	expressions.RLock()
+	re, ok := expressions.cache[expr]
+	expressions.RUnlock()
+	if ok {
+		return re, nil
+	}
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	expressions.Lock()
+	expressions.cache[expr] = re
+	expressions.Unlock()
+	return re, nil
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -0,0 +1,142 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package constraint
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mjolnir42/soma/lib/proto"
+)
+
+func TestSupported(t *testing.T) {
+	for _, operator := range []string{
+		``, `==`, `!=`, `@defined`, `@undefined`, `=~`, `!~`, `^=`,
+		`$=`, `<`, `<=`, `>=`, `>`, `@in`,
+	} {
+		if !Supported(operator) {
+			t.Errorf("Operator %q is not supported", operator)
+		}
+	}
+	for _, operator := range []string{`=`, `~`, `in`, `operator`, `<>`} {
+		if Supported(operator) {
+			t.Errorf("Operator %q is supported", operator)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		operator string
+		value    string
+		valid    bool
+	}{
+		{``, `anything`, true},
+		{proto.ConstraintEqual, `anything`, true},
+		{proto.ConstraintDefined, ``, true},
+		{proto.ConstraintUndefined, ``, true},
+		{proto.ConstraintMatch, `\.lab$`, true},
+		{proto.ConstraintNotMatch, `(unclosed`, false},
+		{proto.ConstraintGreaterEqual, `8`, true},
+		{proto.ConstraintLess, `0.5`, true},
+		{proto.ConstraintGreater, `eight`, false},
+		{proto.ConstraintIn, `r1, r2,r3`, true},
+		{proto.ConstraintIn, ` , ,`, false},
+		{`=`, `value`, false},
+	}
+
+	for _, test := range tests {
+		err := Validate(test.operator, test.value)
+		if test.valid && err != nil {
+			t.Errorf("%s %q: unexpected error: %s", test.operator,
+				test.value, err.Error())
+		}
+		if !test.valid && err == nil {
+			t.Errorf("%s %q: expected an error", test.operator,
+				test.value)
+		}
+	}
+}
+
+func TestNegative(t *testing.T) {
+	if !Negative(proto.ConstraintUndefined) {
+		t.Errorf("@undefined is not negative")
+	}
+	for _, operator := range []string{``, `==`, `!=`, `!~`, `@defined`} {
+		if Negative(operator) {
+			t.Errorf("Operator %q is negative", operator)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		operator string
+		value    string
+		actual   string
+		match    bool
+	}{
+		{``, `dc1`, `dc1`, true},
+		{``, `dc1`, `dc2`, false},
+		{proto.ConstraintEqual, `@defined`, `dc2`, true},
+		{proto.ConstraintNotEqual, `dc1`, `dc2`, true},
+		{proto.ConstraintNotEqual, `dc1`, `dc1`, false},
+		{proto.ConstraintDefined, ``, `dc1`, true},
+		{proto.ConstraintUndefined, ``, `dc1`, false},
+		{proto.ConstraintMatch, `\.lab$`, `node1.lab`, true},
+		{proto.ConstraintMatch, `\.lab$`, `node1.prod`, false},
+		{proto.ConstraintNotMatch, `\.lab$`, `node1.prod`, true},
+		{proto.ConstraintNotMatch, `\.lab$`, `node1.lab`, false},
+		{proto.ConstraintMatch, `(unclosed`, `(unclosed`, false},
+		{proto.ConstraintPrefix, `node`, `node1`, true},
+		{proto.ConstraintPrefix, `node`, `host1`, false},
+		{proto.ConstraintSuffix, `.lab`, `node1.lab`, true},
+		{proto.ConstraintSuffix, `.lab`, `node1.prod`, false},
+		{proto.ConstraintLess, `8`, `4`, true},
+		{proto.ConstraintLess, `8`, `8`, false},
+		{proto.ConstraintLessEqual, `8`, `8`, true},
+		{proto.ConstraintGreaterEqual, `8`, `16`, true},
+		{proto.ConstraintGreaterEqual, `8`, `4`, false},
+		{proto.ConstraintGreater, `8`, `8.5`, true},
+		{proto.ConstraintGreater, `8`, `many`, false},
+		{proto.ConstraintIn, `r1, r2,r3`, `r2`, true},
+		{proto.ConstraintIn, `r1, r2,r3`, `r4`, false},
+		{`=`, `dc1`, `dc1`, false},
+	}
+
+	for _, test := range tests {
+		if match := Match(test.operator, test.value,
+			test.actual); match != test.match {
+			t.Errorf("%q %s %q: expected %t, got %t", test.actual,
+				test.operator, test.value, test.match, match)
+		}
+	}
+}
+
+func TestSet(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected []string
+	}{
+		{`r1,r2,r3`, []string{`r1`, `r2`, `r3`}},
+		{` r1 , ,r2,`, []string{`r1`, `r2`}},
+		{``, []string{}},
+	}
+
+	for _, test := range tests {
+		if set := Set(test.value); !reflect.DeepEqual(set,
+			test.expected) {
+			t.Errorf("%q: expected %v, got %v", test.value,
+				test.expected, set)
+		}
+	}
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -20,6 +20,12 @@ type CheckInstance struct {
 	InstanceSvcCfgHash    string `json:"instanceSvcCfghash,omitempty"`
 	InstanceService       string `json:"instanceService,omitempty"`
 	InstanceServiceConfig string `json:"instanceServiceCfg,omitempty"`
+	// ThresholdSource is nil if the instance uses the thresholds of
+	// the check configuration
+	ThresholdSource *CheckThresholdSource `json:"thresholdSource,omitempty"`
+	// ThresholdValues are the resolved values of templated
+	// thresholds, by numeric notification level
+	ThresholdValues map[uint16]int64 `json:"thresholdValues,omitempty"`
 }
 
 func (t *CheckInstance) DeepCompare(a *CheckInstance) bool {
@@ -25,7 +25,14 @@ type CheckConfig struct {
 	ExternalID   string                  `json:"externalID,omitempty"`
 	Constraints  []CheckConfigConstraint `json:"constraints,omitempty"`
 	Thresholds   []CheckConfigThreshold  `json:"thresholds,omitempty"`
+	Rollout      *CheckConfigRollout     `json:"rollout,omitempty"`
 	Details      *CheckConfigDetails     `json:"details,omitempty"`
+	// ConstraintGroups are evaluated in addition to the flat list of
+	// Constraints, all of which must be fulfilled
+	ConstraintGroups []CheckConfigConstraintGroup `json:"constraintGroups,omitempty"`
+	// ThresholdOverrides replace Thresholds for the instances on and
+	// below the object they are attached to
+	ThresholdOverrides []CheckConfigThresholdOverride `json:"thresholdOverrides,omitempty"`
 }
 
 func (c *CheckConfig) Clone() CheckConfig {
@@ -48,18 +55,56 @@ func (c *CheckConfig) Clone() CheckConfig {
 	for i := range c.Constraints {
 		clone.Constraints[i] = c.Constraints[i].Clone()
 	}
+	if c.ConstraintGroups != nil {
+		clone.ConstraintGroups = make([]CheckConfigConstraintGroup,
+			len(c.ConstraintGroups))
+		for i := range c.ConstraintGroups {
+			clone.ConstraintGroups[i] = c.ConstraintGroups[i].Clone()
+		}
+	}
 	clone.Thresholds = make([]CheckConfigThreshold, len(c.Thresholds))
 	for i := range c.Thresholds {
 		clone.Thresholds[i] = c.Thresholds[i].Clone()
 	}
+	if c.ThresholdOverrides != nil {
+		clone.ThresholdOverrides = make([]CheckConfigThresholdOverride,
+			len(c.ThresholdOverrides))
+		for i := range c.ThresholdOverrides {
+			clone.ThresholdOverrides[i] = c.ThresholdOverrides[i].Clone()
+		}
+	}
+	if c.Rollout != nil {
+		clone.Rollout = c.Rollout.Clone()
+	}
 	if c.Details != nil {
 		clone.Details = c.Details.Clone()
 	}
 	return clone
 }
 
+// Constraint operators, as evaluated by lib/constraint. An empty
+// operator is ConstraintEqual. The value of ConstraintIn is a comma
+// separated list, ConstraintDefined and ConstraintUndefined ignore
+// the value.
+const (
+	ConstraintEqual        = `==`
+	ConstraintNotEqual     = `!=`
+	ConstraintDefined      = `@defined`
+	ConstraintUndefined    = `@undefined`
+	ConstraintMatch        = `=~`
+	ConstraintNotMatch     = `!~`
+	ConstraintPrefix       = `^=`
+	ConstraintSuffix       = `$=`
+	ConstraintLess         = `<`
+	ConstraintLessEqual    = `<=`
+	ConstraintGreaterEqual = `>=`
+	ConstraintGreater      = `>`
+	ConstraintIn           = `@in`
+)
+
 type CheckConfigConstraint struct {
 	ConstraintType string            `json:"constraintType,omitempty"`
+	Operator       string            `json:"operator,omitempty"`
 	Native         *PropertyNative   `json:"native,omitempty"`
 	Oncall         *PropertyOncall   `json:"oncall,omitempty"`
 	Custom         *PropertyCustom   `json:"custom,omitempty"`
@@ -71,6 +116,7 @@ type CheckConfigConstraint struct {
 func (c *CheckConfigConstraint) Clone() CheckConfigConstraint {
 	clone := CheckConfigConstraint{
 		ConstraintType: c.ConstraintType,
+		Operator:       c.Operator,
 	}
 	if c.Native != nil {
 		clone.Native = c.Native.Clone()
@@ -88,7 +134,8 @@ func (c *CheckConfigConstraint) Clone() CheckConfigConstraint {
 		clone.Service = c.Service.Clone()
 	}
 	if c.Attribute != nil {
-		*clone.Attribute = c.Attribute.Clone()
+		attr := c.Attribute.Clone()
+		clone.Attribute = &attr
 	}
 	return clone
 }
@@ -97,6 +144,9 @@ func (c *CheckConfigConstraint) DeepCompare(a *CheckConfigConstraint) bool {
 	if c.ConstraintType != a.ConstraintType {
 		return false
 	}
+	if c.EffectiveOperator() != a.EffectiveOperator() {
+		return false
+	}
 	switch c.ConstraintType {
 	case "native":
 		if c.Native.DeepCompare(a.Native) {
@@ -126,6 +176,15 @@ func (c *CheckConfigConstraint) DeepCompare(a *CheckConfigConstraint) bool {
 	return false
 }
 
+// EffectiveOperator returns the operator of the constraint, with the
+// empty operator resolved to ConstraintEqual
+func (c *CheckConfigConstraint) EffectiveOperator() string {
+	if c.Operator == `` {
+		return ConstraintEqual
+	}
+	return c.Operator
+}
+
 func (c *CheckConfigConstraint) DeepCompareSlice(a []CheckConfigConstraint) bool {
 	if a == nil {
 		return false
@@ -138,17 +197,87 @@ func (c *CheckConfigConstraint) DeepCompareSlice(a []CheckConfigConstraint) bool
 	return false
 }
 
+// Constraint group operators. A ConstraintGroupAnd group is fulfilled
+// if all its members are, a ConstraintGroupOr group if at least one
+// member is and a ConstraintGroupNot group if none of its members
+// is.
+const (
+	ConstraintGroupAnd = `and`
+	ConstraintGroupOr  = `or`
+	ConstraintGroupNot = `not`
+)
+
+// CheckConfigConstraintGroup combines constraints and nested groups
+// with a boolean operator
+type CheckConfigConstraintGroup struct {
+	Operator    string                       `json:"operator,omitempty"`
+	Constraints []CheckConfigConstraint      `json:"constraints,omitempty"`
+	Groups      []CheckConfigConstraintGroup `json:"groups,omitempty"`
+}
+
+func (c *CheckConfigConstraintGroup) Clone() CheckConfigConstraintGroup {
+	clone := CheckConfigConstraintGroup{
+		Operator: c.Operator,
+	}
+	if c.Constraints != nil {
+		clone.Constraints = make([]CheckConfigConstraint, len(c.Constraints))
+		for i := range c.Constraints {
+			clone.Constraints[i] = c.Constraints[i].Clone()
+		}
+	}
+	if c.Groups != nil {
+		clone.Groups = make([]CheckConfigConstraintGroup, len(c.Groups))
+		for i := range c.Groups {
+			clone.Groups[i] = c.Groups[i].Clone()
+		}
+	}
+	return clone
+}
+
+func (c *CheckConfigConstraintGroup) DeepCompare(a *CheckConfigConstraintGroup) bool {
+	if c.Operator != a.Operator || len(c.Constraints) != len(a.Constraints) ||
+		len(c.Groups) != len(a.Groups) {
+		return false
+	}
+	for i := range c.Constraints {
+		if !c.Constraints[i].DeepCompare(&a.Constraints[i]) {
+			return false
+		}
+	}
+	for i := range c.Groups {
+		if !c.Groups[i].DeepCompare(&a.Groups[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Walk calls fn for every constraint of the group and its nested
+// groups
+func (c *CheckConfigConstraintGroup) Walk(fn func(*CheckConfigConstraint)) {
+	for i := range c.Constraints {
+		fn(&c.Constraints[i])
+	}
+	for i := range c.Groups {
+		c.Groups[i].Walk(fn)
+	}
+}
+
 type CheckConfigThreshold struct {
 	Predicate Predicate
 	Level     Level
 	Value     int64
+	// ValueTemplate computes Value from a property of the object
+	// of each check instance, see lib/threshold
+	ValueTemplate string `json:",omitempty"`
 }
 
 func (c *CheckConfigThreshold) Clone() CheckConfigThreshold {
 	return CheckConfigThreshold{
-		Predicate: c.Predicate,
-		Level:     c.Level,
-		Value:     c.Value,
+		Predicate:     c.Predicate,
+		Level:         c.Level,
+		Value:         c.Value,
+		ValueTemplate: c.ValueTemplate,
 	}
 }
 
@@ -166,15 +295,90 @@ func (c *CheckConfigThreshold) DeepCompareSlice(a []CheckConfigThreshold) bool {
 
 func (c *CheckConfigThreshold) DeepCompare(a *CheckConfigThreshold) bool {
 	if c.Value != a.Value || c.Level.Name != a.Level.Name ||
-		c.Predicate.Symbol != a.Predicate.Symbol {
+		c.Predicate.Symbol != a.Predicate.Symbol ||
+		c.ValueTemplate != a.ValueTemplate {
 		return false
 	}
 	return true
 }
 
+// CheckConfigThresholdOverride replaces the thresholds of a check
+// configuration for all check instances on or below the object. If
+// overrides exist on multiple objects, the one nearest to the
+// instance wins.
+type CheckConfigThresholdOverride struct {
+	ID         string                 `json:"ID,omitempty"`
+	ObjectID   string                 `json:"objectID,omitempty"`
+	ObjectType string                 `json:"objectType,omitempty"`
+	Thresholds []CheckConfigThreshold `json:"thresholds,omitempty"`
+}
+
+func (c *CheckConfigThresholdOverride) Clone() CheckConfigThresholdOverride {
+	clone := CheckConfigThresholdOverride{
+		ID:         c.ID,
+		ObjectID:   c.ObjectID,
+		ObjectType: c.ObjectType,
+	}
+	clone.Thresholds = make([]CheckConfigThreshold, len(c.Thresholds))
+	for i := range c.Thresholds {
+		clone.Thresholds[i] = c.Thresholds[i].Clone()
+	}
+	return clone
+}
+
+// CheckThresholdSource identifies the threshold override that
+// provided the thresholds of a check instance
+type CheckThresholdSource struct {
+	OverrideID string `json:"overrideID,omitempty"`
+	ObjectID   string `json:"objectID,omitempty"`
+	ObjectType string `json:"objectType,omitempty"`
+}
+
+func (c *CheckThresholdSource) Clone() *CheckThresholdSource {
+	return &CheckThresholdSource{
+		OverrideID: c.OverrideID,
+		ObjectID:   c.ObjectID,
+		ObjectType: c.ObjectType,
+	}
+}
+
+// CheckConfigRollout is the staged rollout policy of a check
+// configuration. New check instance versions are released in waves
+// of either WaveSize instances or WavePercent percent of all
+// instances. The next wave is released once all instances of the
+// previous wave have finished their rollout and SoakTime seconds
+// have passed. The rollout is halted once FailureThreshold instances
+// have reported rollout_failed. Wave, Failures and IsHalted are
+// read-only status fields.
+type CheckConfigRollout struct {
+	WaveSize         uint64 `json:"waveSize,omitempty"`
+	WavePercent      uint64 `json:"wavePercent,omitempty"`
+	SoakTime         uint64 `json:"soakTime,omitempty"`
+	FailureThreshold uint64 `json:"failureThreshold,omitempty"`
+	Wave             uint64 `json:"wave,omitempty"`
+	Failures         uint64 `json:"failures,omitempty"`
+	IsHalted         bool   `json:"isHalted,omitempty"`
+	HaltedAt         string `json:"haltedAt,omitempty"`
+}
+
+func (c *CheckConfigRollout) Clone() *CheckConfigRollout {
+	return &CheckConfigRollout{
+		WaveSize:         c.WaveSize,
+		WavePercent:      c.WavePercent,
+		SoakTime:         c.SoakTime,
+		FailureThreshold: c.FailureThreshold,
+		Wave:             c.Wave,
+		Failures:         c.Failures,
+		IsHalted:         c.IsHalted,
+		HaltedAt:         c.HaltedAt,
+	}
+}
+
 type CheckConfigDetails struct {
 	Creation  *DetailsCreation    `json:"creation,omitempty"`
 	Instances []CheckInstanceInfo `json:"instances,omitempty"`
+	// Names is only set by the export of check configurations
+	Names *CheckConfigNames `json:"names,omitempty"`
 }
 
 func (c *CheckConfigDetails) Clone() *CheckConfigDetails {
@@ -182,6 +386,10 @@ func (c *CheckConfigDetails) Clone() *CheckConfigDetails {
 	if c.Creation != nil {
 		clone.Creation = c.Creation.Clone()
 	}
+	if c.Names != nil {
+		names := *c.Names
+		clone.Names = &names
+	}
 	clone.Instances = make([]CheckInstanceInfo, len(c.Instances))
 	for i := range c.Instances {
 		clone.Instances[i] = c.Instances[i].Clone()
@@ -189,6 +397,15 @@ func (c *CheckConfigDetails) Clone() *CheckConfigDetails {
 	return clone
 }
 
+// CheckConfigNames holds the names of the objects a check
+// configuration references by ID
+type CheckConfigNames struct {
+	Repository string `json:"repository,omitempty"`
+	Bucket     string `json:"bucket,omitempty"`
+	Object     string `json:"object,omitempty"`
+	Capability string `json:"capability,omitempty"`
+}
+
 type CheckConfigFilter struct {
 	ID           string `json:"ID,omitempty"`
 	Name         string `json:"name,omitempty"`
@@ -201,16 +418,23 @@ type CheckInstanceInfo struct {
 	ObjectType    string `json:"objectType,omitempty"`
 	CurrentStatus string `json:"currentStatus,omitempty"`
 	NextStatus    string `json:"nextStatus,omitempty"`
+	// ThresholdSource is nil if the instance uses the thresholds of
+	// the check configuration
+	ThresholdSource *CheckThresholdSource `json:"thresholdSource,omitempty"`
 }
 
 func (c *CheckInstanceInfo) Clone() CheckInstanceInfo {
-	return CheckInstanceInfo{
+	clone := CheckInstanceInfo{
 		ID:            c.ID,
 		ObjectID:      c.ObjectID,
 		ObjectType:    c.ObjectType,
 		CurrentStatus: c.CurrentStatus,
 		NextStatus:    c.NextStatus,
 	}
+	if c.ThresholdSource != nil {
+		clone.ThresholdSource = c.ThresholdSource.Clone()
+	}
+	return clone
 }
 
 func (c *CheckConfig) DeepCompare(a *CheckConfig) bool {
@@ -253,6 +477,14 @@ revconstrloop:
 		}
 		return false
 	}
+	if len(c.ConstraintGroups) != len(a.ConstraintGroups) {
+		return false
+	}
+	for i := range c.ConstraintGroups {
+		if !c.ConstraintGroups[i].DeepCompare(&a.ConstraintGroups[i]) {
+			return false
+		}
+	}
 	return true
 }
 
@@ -59,6 +59,15 @@ type Deployment struct {
 	CheckConfig      *CheckConfig      `json:"checkConfig"`
 	Check            *Check            `json:"check"`
 	CheckInstance    *CheckInstance    `json:"checkInstance"`
+	State            string            `json:"state,omitempty"`
+}
+
+// DeploymentFilter selects the deployments of a monitoring system
+// that are listed. Limit 0 lists all deployments.
+type DeploymentFilter struct {
+	IncludeActive bool   `json:"includeActive,omitempty"`
+	Limit         uint64 `json:"limit,omitempty"`
+	Offset        uint64 `json:"offset,omitempty"`
 }
 
 func (dd *Deployment) DeepCompare(alternate *Deployment) bool {
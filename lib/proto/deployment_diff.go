@@ -0,0 +1,418 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package proto
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// Constants for the categories of deployment changes
+const (
+	DiffCategoryDeployment = `deployment`
+	DiffCategoryCheck      = `check`
+	DiffCategoryThreshold  = `threshold`
+	DiffCategoryProperty   = `property`
+	DiffCategoryOncall     = `oncall`
+	DiffCategoryService    = `service`
+	DiffCategoryMembership = `membership`
+)
+
+// DeploymentDiff describes the changes between two versions of a
+// check instance configuration
+type DeploymentDiff struct {
+	Version uint64             `json:"version"`
+	Against uint64             `json:"against"`
+	Changes []DeploymentChange `json:"changes"`
+}
+
+// DeploymentChange describes a single changed field of a deployment.
+// Fields that hold multiple values, like properties or thresholds,
+// are reported per value, identified by Key.
+type DeploymentChange struct {
+	Category string `json:"category"`
+	Field    string `json:"field"`
+	Key      string `json:"key,omitempty"`
+	Old      string `json:"old,omitempty"`
+	New      string `json:"new,omitempty"`
+}
+
+// Diff returns the field by field changes from deployment previous
+// to dd. It covers the same fields that DeepCompare evaluates.
+func (dd *Deployment) Diff(previous *Deployment) []DeploymentChange {
+	d := &deploymentDiffer{changes: []DeploymentChange{}}
+
+	d.value(DiffCategoryDeployment, `repository`, previous.Repository, dd.Repository)
+	d.value(DiffCategoryDeployment, `environment`, previous.Environment, dd.Environment)
+	d.value(DiffCategoryDeployment, `bucket`, previous.Bucket, dd.Bucket)
+	d.value(DiffCategoryDeployment, `objectType`, previous.ObjectType, dd.ObjectType)
+	d.value(DiffCategoryDeployment, `view`, previous.View, dd.View)
+	d.value(DiffCategoryDeployment, `datacenter`, previous.Datacenter, dd.Datacenter)
+	d.value(DiffCategoryDeployment, `capability`, diffCapability(previous.Capability), diffCapability(dd.Capability))
+	d.value(DiffCategoryDeployment, `monitoringSystem`, diffMonitoring(previous.Monitoring), diffMonitoring(dd.Monitoring))
+	d.value(DiffCategoryDeployment, `metric`, diffMetric(previous.Metric), diffMetric(dd.Metric))
+	d.value(DiffCategoryDeployment, `unit`, diffUnit(previous.Unit), diffUnit(dd.Unit))
+	d.value(DiffCategoryDeployment, `organizationalTeam`, diffTeam(previous.Team), diffTeam(dd.Team))
+
+	d.checkConfig(previous.CheckConfig, dd.CheckConfig)
+	d.check(previous, dd)
+	d.oncall(previous.Oncall, dd.Oncall)
+	d.service(previous.Service, dd.Service)
+
+	d.keyed(DiffCategoryProperty, `properties`,
+		diffSystemProperties(previous.Properties),
+		diffSystemProperties(dd.Properties))
+	d.keyed(DiffCategoryProperty, `customProperties`,
+		diffCustomProperties(previous.CustomProperties),
+		diffCustomProperties(dd.CustomProperties))
+
+	d.membership(previous, dd)
+	return d.changes
+}
+
+// deploymentDiffer collects the changes between two deployments
+type deploymentDiffer struct {
+	changes []DeploymentChange
+}
+
+// value records a change of a single valued field
+func (d *deploymentDiffer) value(category, field, old, curr string) {
+	if old == curr {
+		return
+	}
+	d.changes = append(d.changes, DeploymentChange{
+		Category: category,
+		Field:    field,
+		Old:      old,
+		New:      curr,
+	})
+}
+
+// keyed records the changes of a multi valued field, where every
+// value is identified by a key. Keys may hold multiple values.
+func (d *deploymentDiffer) keyed(category, field string, old, curr map[string][]string) {
+	keys := []string{}
+	for key := range old {
+		keys = append(keys, key)
+	}
+	for key := range curr {
+		if _, ok := old[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		removed, added := diffValueSets(old[key], curr[key])
+		// a single replaced value is reported as one change
+		if len(removed) == 1 && len(added) == 1 {
+			d.changes = append(d.changes, DeploymentChange{
+				Category: category,
+				Field:    field,
+				Key:      key,
+				Old:      removed[0],
+				New:      added[0],
+			})
+			continue
+		}
+		for _, val := range removed {
+			d.changes = append(d.changes, DeploymentChange{
+				Category: category,
+				Field:    field,
+				Key:      key,
+				Old:      val,
+			})
+		}
+		for _, val := range added {
+			d.changes = append(d.changes, DeploymentChange{
+				Category: category,
+				Field:    field,
+				Key:      key,
+				New:      val,
+			})
+		}
+	}
+}
+
+// checkConfig records changes of the check configuration and its
+// thresholds
+func (d *deploymentDiffer) checkConfig(old, curr *CheckConfig) {
+	if old == nil {
+		old = &CheckConfig{}
+	}
+	if curr == nil {
+		curr = &CheckConfig{}
+	}
+	d.value(DiffCategoryCheck, `checkConfig.name`, old.Name, curr.Name)
+	d.value(DiffCategoryCheck, `checkConfig.interval`,
+		diffUint(old.Interval), diffUint(curr.Interval))
+	d.value(DiffCategoryCheck, `checkConfig.isActive`,
+		strconv.FormatBool(old.IsActive), strconv.FormatBool(curr.IsActive))
+	d.value(DiffCategoryCheck, `checkConfig.isEnabled`,
+		strconv.FormatBool(old.IsEnabled), strconv.FormatBool(curr.IsEnabled))
+	d.keyed(DiffCategoryThreshold, `checkConfig.thresholds`,
+		diffThresholds(old.Thresholds), diffThresholds(curr.Thresholds))
+}
+
+// check records changes of the check and check instance
+func (d *deploymentDiffer) check(old, curr *Deployment) {
+	oldCheck, newCheck := &Check{}, &Check{}
+	if old.Check != nil {
+		oldCheck = old.Check
+	}
+	if curr.Check != nil {
+		newCheck = curr.Check
+	}
+	d.value(DiffCategoryCheck, `check.checkId`, oldCheck.CheckID, newCheck.CheckID)
+	d.value(DiffCategoryCheck, `check.sourceCheckID`, oldCheck.SourceCheckID, newCheck.SourceCheckID)
+	d.value(DiffCategoryCheck, `check.checkConfigID`, oldCheck.CheckConfigID, newCheck.CheckConfigID)
+	d.value(DiffCategoryCheck, `check.inheritedFrom`, oldCheck.InheritedFrom, newCheck.InheritedFrom)
+
+	oldInstance, newInstance := &CheckInstance{}, &CheckInstance{}
+	if old.CheckInstance != nil {
+		oldInstance = old.CheckInstance
+	}
+	if curr.CheckInstance != nil {
+		newInstance = curr.CheckInstance
+	}
+	d.value(DiffCategoryCheck, `checkInstance.constraintHash`,
+		oldInstance.ConstraintHash, newInstance.ConstraintHash)
+	d.value(DiffCategoryCheck, `checkInstance.constraintValHash`,
+		oldInstance.ConstraintValHash, newInstance.ConstraintValHash)
+	d.value(DiffCategoryCheck, `checkInstance.instanceService`,
+		oldInstance.InstanceService, newInstance.InstanceService)
+	d.value(DiffCategoryCheck, `checkInstance.instanceSvcCfghash`,
+		oldInstance.InstanceSvcCfgHash, newInstance.InstanceSvcCfgHash)
+}
+
+// oncall records changes of the oncall duty
+func (d *deploymentDiffer) oncall(old, curr *Oncall) {
+	if old == nil {
+		old = &Oncall{}
+	}
+	if curr == nil {
+		curr = &Oncall{}
+	}
+	d.value(DiffCategoryOncall, `oncallDuty.name`, old.Name, curr.Name)
+	d.value(DiffCategoryOncall, `oncallDuty.number`, old.Number, curr.Number)
+	d.keyed(DiffCategoryOncall, `oncallDuty.members`,
+		diffOncallMembers(old.Members), diffOncallMembers(curr.Members))
+}
+
+// service records changes of the service and its attributes
+func (d *deploymentDiffer) service(old, curr *PropertyService) {
+	if old == nil {
+		old = &PropertyService{}
+	}
+	if curr == nil {
+		curr = &PropertyService{}
+	}
+	d.value(DiffCategoryService, `service.name`, old.Name, curr.Name)
+	d.value(DiffCategoryService, `service.teamID`, old.TeamID, curr.TeamID)
+
+	oldAttr, newAttr := map[string][]string{}, map[string][]string{}
+	for _, attr := range old.Attributes {
+		oldAttr[attr.Name] = append(oldAttr[attr.Name], attr.Value)
+	}
+	for _, attr := range curr.Attributes {
+		newAttr[attr.Name] = append(newAttr[attr.Name], attr.Value)
+	}
+	d.keyed(DiffCategoryService, `service.attributes`, oldAttr, newAttr)
+}
+
+// membership records changes of the objects the check instance is
+// deployed on and their members
+func (d *deploymentDiffer) membership(old, curr *Deployment) {
+	oldNode, newNode := ``, ``
+	if old.Node != nil {
+		oldNode = old.Node.Name
+	}
+	if curr.Node != nil {
+		newNode = curr.Node.Name
+	}
+	d.value(DiffCategoryMembership, `node`, oldNode, newNode)
+
+	oldServer, newServer := ``, ``
+	if old.Server != nil {
+		oldServer = old.Server.Name
+	}
+	if curr.Server != nil {
+		newServer = curr.Server.Name
+	}
+	d.value(DiffCategoryMembership, `server`, oldServer, newServer)
+
+	oldCluster, newCluster := &Cluster{}, &Cluster{}
+	if old.Cluster != nil {
+		oldCluster = old.Cluster
+	}
+	if curr.Cluster != nil {
+		newCluster = curr.Cluster
+	}
+	d.value(DiffCategoryMembership, `cluster`, oldCluster.Name, newCluster.Name)
+	d.keyed(DiffCategoryMembership, `cluster.members`,
+		diffNodes(oldCluster.Members), diffNodes(newCluster.Members))
+
+	oldGroup, newGroup := &Group{}, &Group{}
+	if old.Group != nil {
+		oldGroup = old.Group
+	}
+	if curr.Group != nil {
+		newGroup = curr.Group
+	}
+	d.value(DiffCategoryMembership, `group`, oldGroup.Name, newGroup.Name)
+	d.keyed(DiffCategoryMembership, `group.memberNodes`,
+		diffNodes(oldGroup.MemberNodes), diffNodes(newGroup.MemberNodes))
+	d.keyed(DiffCategoryMembership, `group.memberClusters`,
+		diffClusters(oldGroup.MemberClusters), diffClusters(newGroup.MemberClusters))
+	d.keyed(DiffCategoryMembership, `group.memberGroups`,
+		diffGroups(oldGroup.MemberGroups), diffGroups(newGroup.MemberGroups))
+}
+
+// diffValueSets returns the values only present in old and the
+// values only present in curr, respecting duplicate values
+func diffValueSets(old, curr []string) (removed, added []string) {
+	count := map[string]int{}
+	for _, val := range old {
+		count[val]++
+	}
+	for _, val := range curr {
+		if count[val] > 0 {
+			count[val]--
+			continue
+		}
+		added = append(added, val)
+	}
+	for _, val := range old {
+		if count[val] > 0 {
+			count[val]--
+			removed = append(removed, val)
+		}
+	}
+	sort.Strings(removed)
+	sort.Strings(added)
+	return
+}
+
+func diffUint(i uint64) string {
+	return strconv.FormatUint(i, 10)
+}
+
+func diffCapability(c *Capability) string {
+	if c == nil {
+		return ``
+	}
+	return c.Name
+}
+
+func diffMonitoring(m *Monitoring) string {
+	if m == nil {
+		return ``
+	}
+	return m.Name
+}
+
+func diffMetric(m *Metric) string {
+	if m == nil {
+		return ``
+	}
+	return m.Path
+}
+
+func diffUnit(u *Unit) string {
+	if u == nil {
+		return ``
+	}
+	return u.Unit
+}
+
+func diffTeam(t *Team) string {
+	if t == nil {
+		return ``
+	}
+	return t.Name
+}
+
+func diffThresholds(thresholds []CheckConfigThreshold) map[string][]string {
+	m := map[string][]string{}
+	for _, thr := range thresholds {
+		m[thr.Level.Name] = append(m[thr.Level.Name],
+			fmt.Sprintf("%s %d", thr.Predicate.Symbol, thr.Value))
+	}
+	return m
+}
+
+func diffSystemProperties(props *[]PropertySystem) map[string][]string {
+	m := map[string][]string{}
+	if props == nil {
+		return m
+	}
+	for _, prop := range *props {
+		m[prop.Name] = append(m[prop.Name], prop.Value)
+	}
+	return m
+}
+
+func diffCustomProperties(props *[]PropertyCustom) map[string][]string {
+	m := map[string][]string{}
+	if props == nil {
+		return m
+	}
+	for _, prop := range *props {
+		m[prop.Name] = append(m[prop.Name], prop.Value)
+	}
+	return m
+}
+
+func diffOncallMembers(members *[]OncallMember) map[string][]string {
+	m := map[string][]string{}
+	if members == nil {
+		return m
+	}
+	for _, member := range *members {
+		m[member.UserName] = append(m[member.UserName], member.UserID)
+	}
+	return m
+}
+
+func diffNodes(nodes *[]Node) map[string][]string {
+	m := map[string][]string{}
+	if nodes == nil {
+		return m
+	}
+	for _, node := range *nodes {
+		m[node.Name] = append(m[node.Name], node.ID)
+	}
+	return m
+}
+
+func diffClusters(clusters *[]Cluster) map[string][]string {
+	m := map[string][]string{}
+	if clusters == nil {
+		return m
+	}
+	for _, cluster := range *clusters {
+		m[cluster.Name] = append(m[cluster.Name], cluster.ID)
+	}
+	return m
+}
+
+func diffGroups(groups *[]Group) map[string][]string {
+	m := map[string][]string{}
+	if groups == nil {
+		return m
+	}
+	for _, group := range *groups {
+		m[group.Name] = append(m[group.Name], group.ID)
+	}
+	return m
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
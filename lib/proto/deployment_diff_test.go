@@ -0,0 +1,225 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package proto
+
+import (
+	"reflect"
+	"testing"
+)
+
+func testDiffThreshold(level, predicate string, value int64) CheckConfigThreshold {
+	return CheckConfigThreshold{
+		Predicate: Predicate{Symbol: predicate},
+		Level:     Level{Name: level},
+		Value:     value,
+	}
+}
+
+func testDiffDeployment(thresholds []CheckConfigThreshold,
+	props []PropertySystem, custom []PropertyCustom,
+	constraintHash, constraintValHash string) *Deployment {
+	return &Deployment{
+		CheckConfig: &CheckConfig{
+			Name:       `test`,
+			Interval:   60,
+			Thresholds: thresholds,
+		},
+		CheckInstance: &CheckInstance{
+			ConstraintHash:    constraintHash,
+			ConstraintValHash: constraintValHash,
+		},
+		Properties:       &props,
+		CustomProperties: &custom,
+	}
+}
+
+func TestDeploymentDiff(t *testing.T) {
+	warning := testDiffThreshold(`warning`, `>=`, 80)
+	critical := testDiffThreshold(`critical`, `>=`, 95)
+	location := PropertySystem{Name: `location`, Value: `dc1`}
+	owner := PropertyCustom{Name: `owner`, Value: `ops`}
+
+	tests := []struct {
+		name     string
+		previous *Deployment
+		current  *Deployment
+		expected []DeploymentChange
+	}{
+		{
+			name: `unchanged`,
+			previous: testDiffDeployment(
+				[]CheckConfigThreshold{warning}, []PropertySystem{location},
+				[]PropertyCustom{owner}, `a`, `b`),
+			current: testDiffDeployment(
+				[]CheckConfigThreshold{warning}, []PropertySystem{location},
+				[]PropertyCustom{owner}, `a`, `b`),
+			expected: []DeploymentChange{},
+		},
+		{
+			name:     `empty deployments`,
+			previous: &Deployment{},
+			current:  &Deployment{},
+			expected: []DeploymentChange{},
+		},
+		{
+			name: `threshold added`,
+			previous: testDiffDeployment(
+				[]CheckConfigThreshold{warning}, nil, nil, ``, ``),
+			current: testDiffDeployment(
+				[]CheckConfigThreshold{warning, critical}, nil, nil, ``, ``),
+			expected: []DeploymentChange{{
+				Category: DiffCategoryThreshold,
+				Field:    `checkConfig.thresholds`,
+				Key:      `critical`,
+				New:      `>= 95`,
+			}},
+		},
+		{
+			name: `threshold removed`,
+			previous: testDiffDeployment(
+				[]CheckConfigThreshold{warning, critical}, nil, nil, ``, ``),
+			current: testDiffDeployment(
+				[]CheckConfigThreshold{warning}, nil, nil, ``, ``),
+			expected: []DeploymentChange{{
+				Category: DiffCategoryThreshold,
+				Field:    `checkConfig.thresholds`,
+				Key:      `critical`,
+				Old:      `>= 95`,
+			}},
+		},
+		{
+			name: `threshold changed`,
+			previous: testDiffDeployment(
+				[]CheckConfigThreshold{warning}, nil, nil, ``, ``),
+			current: testDiffDeployment(
+				[]CheckConfigThreshold{
+					testDiffThreshold(`warning`, `>`, 85),
+				}, nil, nil, ``, ``),
+			expected: []DeploymentChange{{
+				Category: DiffCategoryThreshold,
+				Field:    `checkConfig.thresholds`,
+				Key:      `warning`,
+				Old:      `>= 80`,
+				New:      `> 85`,
+			}},
+		},
+		{
+			name: `property added`,
+			previous: testDiffDeployment(nil, nil,
+				[]PropertyCustom{owner}, ``, ``),
+			current: testDiffDeployment(nil, []PropertySystem{location},
+				[]PropertyCustom{owner}, ``, ``),
+			expected: []DeploymentChange{{
+				Category: DiffCategoryProperty,
+				Field:    `properties`,
+				Key:      `location`,
+				New:      `dc1`,
+			}},
+		},
+		{
+			name: `property removed`,
+			previous: testDiffDeployment(nil, []PropertySystem{location},
+				[]PropertyCustom{owner}, ``, ``),
+			current: testDiffDeployment(nil, []PropertySystem{location},
+				nil, ``, ``),
+			expected: []DeploymentChange{{
+				Category: DiffCategoryProperty,
+				Field:    `customProperties`,
+				Key:      `owner`,
+				Old:      `ops`,
+			}},
+		},
+		{
+			name: `property changed`,
+			previous: testDiffDeployment(nil, []PropertySystem{location},
+				nil, ``, ``),
+			current: testDiffDeployment(nil, []PropertySystem{
+				{Name: `location`, Value: `dc2`},
+			}, nil, ``, ``),
+			expected: []DeploymentChange{{
+				Category: DiffCategoryProperty,
+				Field:    `properties`,
+				Key:      `location`,
+				Old:      `dc1`,
+				New:      `dc2`,
+			}},
+		},
+		{
+			name: `multi valued property extended`,
+			previous: testDiffDeployment(nil, []PropertySystem{location},
+				nil, ``, ``),
+			current: testDiffDeployment(nil, []PropertySystem{
+				location,
+				{Name: `location`, Value: `dc2`},
+			}, nil, ``, ``),
+			expected: []DeploymentChange{{
+				Category: DiffCategoryProperty,
+				Field:    `properties`,
+				Key:      `location`,
+				New:      `dc2`,
+			}},
+		},
+		{
+			name:     `constraints added`,
+			previous: testDiffDeployment(nil, nil, nil, ``, ``),
+			current:  testDiffDeployment(nil, nil, nil, `a`, `b`),
+			expected: []DeploymentChange{
+				{
+					Category: DiffCategoryCheck,
+					Field:    `checkInstance.constraintHash`,
+					New:      `a`,
+				},
+				{
+					Category: DiffCategoryCheck,
+					Field:    `checkInstance.constraintValHash`,
+					New:      `b`,
+				},
+			},
+		},
+		{
+			name:     `constraints removed`,
+			previous: testDiffDeployment(nil, nil, nil, `a`, `b`),
+			current:  testDiffDeployment(nil, nil, nil, ``, ``),
+			expected: []DeploymentChange{
+				{
+					Category: DiffCategoryCheck,
+					Field:    `checkInstance.constraintHash`,
+					Old:      `a`,
+				},
+				{
+					Category: DiffCategoryCheck,
+					Field:    `checkInstance.constraintValHash`,
+					Old:      `b`,
+				},
+			},
+		},
+		{
+			name:     `constraint values changed`,
+			previous: testDiffDeployment(nil, nil, nil, `a`, `b`),
+			current:  testDiffDeployment(nil, nil, nil, `a`, `c`),
+			expected: []DeploymentChange{{
+				Category: DiffCategoryCheck,
+				Field:    `checkInstance.constraintValHash`,
+				Old:      `b`,
+				New:      `c`,
+			}},
+		},
+	}
+
+	for _, test := range tests {
+		changes := test.current.Diff(test.previous)
+		if !reflect.DeepEqual(changes, test.expected) {
+			t.Errorf("%s: expected %+v, got %+v",
+				test.name, test.expected, changes)
+		}
+	}
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
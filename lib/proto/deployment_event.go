@@ -0,0 +1,61 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package proto
+
+// Constants for the components that emit deployment events
+const (
+	EventSourceTreeKeeper = `treekeeper`
+	EventSourceLifeCycle  = `lifecycle`
+	EventSourceDeployment = `deployment`
+)
+
+// DeploymentEvent describes a single state transition of a check
+// instance configuration
+type DeploymentEvent struct {
+	ID               string `json:"id"`
+	CheckInstanceID  string `json:"checkInstanceID"`
+	InstanceConfigID string `json:"instanceConfigID"`
+	Version          uint64 `json:"version"`
+	MonitoringID     string `json:"monitoringID"`
+	RepositoryID     string `json:"repositoryID"`
+	Status           string `json:"status"`
+	NextStatus       string `json:"nextStatus"`
+	Source           string `json:"source"`
+	Timestamp        string `json:"timestamp"`
+}
+
+// DeploymentEventFilter selects the deployment events a client
+// receives. Empty fields match all events.
+type DeploymentEventFilter struct {
+	MonitoringID string   `json:"monitoringID,omitempty"`
+	RepositoryID string   `json:"repositoryID,omitempty"`
+	Status       []string `json:"status,omitempty"`
+}
+
+// Match returns true if the event e is selected by the filter
+func (f *DeploymentEventFilter) Match(e *DeploymentEvent) bool {
+	if f.MonitoringID != `` && f.MonitoringID != e.MonitoringID {
+		return false
+	}
+	if f.RepositoryID != `` && f.RepositoryID != e.RepositoryID {
+		return false
+	}
+	if len(f.Status) == 0 {
+		return true
+	}
+	for _, status := range f.Status {
+		if status == e.Status {
+			return true
+		}
+	}
+	return false
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
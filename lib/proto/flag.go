@@ -20,6 +20,7 @@ type Flags struct {
 	Forced   bool `json:"forced"`   // workflow
 	Add      bool `json:"add"`      // permission map
 	Remove   bool `json:"remove"`   // permission unmap
+	DryRun   bool `json:"dryRun"`   // tree changes
 }
 
 // vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -24,6 +24,7 @@ type Instance struct {
 	IsInherited      bool                 `json:"isInherited"`
 	Info             *InstanceVersionInfo `json:"instanceVersionInfo,omitempty"`
 	Deployment       *Deployment          `json:"deployment,omitempty"`
+	Diff             *DeploymentDiff      `json:"deploymentDiff,omitempty"`
 }
 
 type InstanceVersionInfo struct {
@@ -32,6 +33,10 @@ type InstanceVersionInfo struct {
 	DeprovisionedAt     string `json:"deprovisionedAt"`
 	StatusLastUpdatedAt string `json:"statusLastUpdatedAt"`
 	NotifiedAt          string `json:"notifiedAt"`
+	RolledBackAt        string `json:"rolledBackAt,omitempty"`
+	RolledBackTo        uint64 `json:"rolledBackTo,omitempty"`
+	RestoredAt          string `json:"restoredAt,omitempty"`
+	RestoredFrom        uint64 `json:"restoredFrom,omitempty"`
 }
 
 func NewInstanceResult() Result {
@@ -0,0 +1,65 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package proto // import "github.com/mjolnir42/soma/lib/proto"
+
+// Constants for the scopes a maintenance window can target
+const (
+	MaintenanceScopeRepository  = `repository`
+	MaintenanceScopeBucket      = `bucket`
+	MaintenanceScopeEnvironment = `environment`
+	MaintenanceScopeMonitoring  = `monitoring`
+)
+
+// MaintenanceWindow is a time-bounded rollout freeze. While it is
+// active, no deployment notifications are sent for check instances
+// within its scope. Windows with scope repository can additionally
+// hold all tree changing jobs of the repository until they end.
+type MaintenanceWindow struct {
+	ID           string                    `json:"id,omitempty"`
+	Scope        string                    `json:"scope,omitempty"`
+	RepositoryID string                    `json:"repositoryID,omitempty"`
+	BucketID     string                    `json:"bucketID,omitempty"`
+	Environment  string                    `json:"environment,omitempty"`
+	MonitoringID string                    `json:"monitoringID,omitempty"`
+	Description  string                    `json:"description,omitempty"`
+	StartsAt     string                    `json:"startsAt,omitempty"`
+	EndsAt       string                    `json:"endsAt,omitempty"`
+	HoldJobs     bool                      `json:"holdJobs"`
+	IsActive     bool                      `json:"isActive"`
+	IsCancelled  bool                      `json:"isCancelled"`
+	CancelledAt  string                    `json:"cancelledAt,omitempty"`
+	Details      *MaintenanceWindowDetails `json:"details,omitempty"`
+}
+
+// MaintenanceWindowDetails contains metadata about a maintenance
+// window
+type MaintenanceWindowDetails struct {
+	DetailsCreation
+}
+
+// NewMaintenanceWindowRequest returns a new request for maintenance
+// windows
+func NewMaintenanceWindowRequest() Request {
+	return Request{
+		Flags:             &Flags{},
+		MaintenanceWindow: &MaintenanceWindow{},
+	}
+}
+
+// NewMaintenanceWindowResult returns a new result for maintenance
+// windows
+func NewMaintenanceWindowResult() Result {
+	return Result{
+		Errors:             &[]string{},
+		MaintenanceWindows: &[]MaintenanceWindow{},
+	}
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
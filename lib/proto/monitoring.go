@@ -10,13 +10,16 @@
 package proto
 
 type Monitoring struct {
-	ID       string             `json:"id,omitempty"`
-	Name     string             `json:"name,omitempty"`
-	Mode     string             `json:"mode,omitempty"`
-	Contact  string             `json:"contact,omitempty"`
-	TeamID   string             `json:"teamId,omitempty"`
-	Callback string             `json:"callback,omitempty"`
-	Details  *MonitoringDetails `json:"details,omitempty"`
+	ID         string             `json:"id,omitempty"`
+	Name       string             `json:"name,omitempty"`
+	Mode       string             `json:"mode,omitempty"`
+	Contact    string             `json:"contact,omitempty"`
+	TeamID     string             `json:"teamId,omitempty"`
+	Callback   string             `json:"callback,omitempty"`
+	BatchSize  uint64             `json:"batchSize,omitempty"`
+	BatchFlush uint64             `json:"batchFlushSeconds,omitempty"`
+	Secret     string             `json:"secret,omitempty"`
+	Details    *MonitoringDetails `json:"details,omitempty"`
 }
 
 type MonitoringFilter struct {
@@ -34,7 +37,8 @@ type MonitoringDetails struct {
 
 func (p *Monitoring) DeepCompare(a *Monitoring) bool {
 	if p.ID != a.ID || p.Name != a.Name || p.Mode != a.Mode ||
-		p.Contact != a.Contact || p.TeamID != a.TeamID || p.Callback != a.Callback {
+		p.Contact != a.Contact || p.TeamID != a.TeamID || p.Callback != a.Callback ||
+		p.BatchSize != a.BatchSize || p.BatchFlush != a.BatchFlush {
 		return false
 	}
 	return true
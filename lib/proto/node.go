@@ -15,6 +15,7 @@ type Node struct {
 	Name       string      `json:"name,omitempty"`
 	TeamID     string      `json:"teamID,omitempty"`
 	ServerID   string      `json:"serverID,omitempty"`
+	ServerName string      `json:"serverName,omitempty"`
 	State      string      `json:"state,omitempty"`
 	IsOnline   bool        `json:"isOnline,omitempty"`
 	IsDeleted  bool        `json:"isDeleted,omitempty"`
@@ -25,14 +26,15 @@ type Node struct {
 
 func (p *Node) Clone() Node {
 	clone := Node{
-		ID:        p.ID,
-		AssetID:   p.AssetID,
-		Name:      p.Name,
-		TeamID:    p.TeamID,
-		ServerID:  p.ServerID,
-		State:     p.State,
-		IsOnline:  p.IsOnline,
-		IsDeleted: p.IsDeleted,
+		ID:         p.ID,
+		AssetID:    p.AssetID,
+		Name:       p.Name,
+		TeamID:     p.TeamID,
+		ServerID:   p.ServerID,
+		ServerName: p.ServerName,
+		State:      p.State,
+		IsOnline:   p.IsOnline,
+		IsDeleted:  p.IsDeleted,
 	}
 	if p.Details != nil {
 		clone.Details = p.Details.Clone()
@@ -0,0 +1,42 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package proto // import "github.com/mjolnir42/soma/lib/proto"
+
+// Constants for the delivery states of an outbox notification
+const (
+	NotificationPending   = `pending`
+	NotificationDelivered = `delivered`
+	NotificationDead      = `dead`
+)
+
+// Notification is an entry in the notification outbox of a
+// monitoring system. Every notification signals the availability of
+// an updated deployment for check instance CheckInstanceID.
+type Notification struct {
+	ID              string `json:"id,omitempty"`
+	MonitoringID    string `json:"monitoringID,omitempty"`
+	CheckInstanceID string `json:"checkInstanceID,omitempty"`
+	State           string `json:"state,omitempty"`
+	Attempts        uint64 `json:"attempts"`
+	LastError       string `json:"lastError,omitempty"`
+	CreatedAt       string `json:"createdAt,omitempty"`
+	NextAttemptAt   string `json:"nextAttemptAt,omitempty"`
+	DeliveredAt     string `json:"deliveredAt,omitempty"`
+}
+
+// NewNotificationResult returns a new result for notifications
+func NewNotificationResult() Result {
+	return Result{
+		Errors:        &[]string{},
+		Notifications: &[]Notification{},
+	}
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
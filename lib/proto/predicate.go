@@ -9,6 +9,17 @@
 
 package proto
 
+// Predicates with a defined comparison, as evaluated by
+// lib/threshold
+const (
+	PredicateLess         = `<`
+	PredicateLessEqual    = `<=`
+	PredicateEqual        = `==`
+	PredicateNotEqual     = `!=`
+	PredicateGreaterEqual = `>=`
+	PredicateGreater      = `>`
+)
+
 type Predicate struct {
 	Symbol  string            `json:"symbol,omitempty"`
 	Details *PredicateDetails `json:"details,omitempty"`
@@ -16,9 +16,46 @@ type PushNotification struct {
 	Path string `json:"path" valid:"abspath"`
 }
 
+// PushNotificationBatch is used to signal monitoring systems that
+// updated checkinstances are available for all checkinstanceIDs in
+// UUIDs
+type PushNotificationBatch struct {
+	UUIDs []string `json:"uuids"`
+	Path  string   `json:"path" valid:"abspath"`
+}
+
+// PushNotificationAck is the per checkinstanceID acknowledgement of
+// a PushNotificationBatch by the monitoring system
+type PushNotificationAck struct {
+	UUID     string `json:"uuid"`
+	Accepted bool   `json:"accepted"`
+	Error    string `json:"error,omitempty"`
+}
+
+// PushNotificationReply is the reply body of a monitoring system to
+// a PushNotificationBatch
+type PushNotificationReply struct {
+	Acks []PushNotificationAck `json:"acks"`
+}
+
 // NewPushNotification returns a new push notification
 func NewPushNotification() PushNotification {
 	return PushNotification{}
 }
 
+// NewPushNotificationBatch returns a new batched push notification
+func NewPushNotificationBatch() PushNotificationBatch {
+	return PushNotificationBatch{
+		UUIDs: []string{},
+	}
+}
+
+// NewPushNotificationReply returns a new reply to a batched push
+// notification
+func NewPushNotificationReply() PushNotificationReply {
+	return PushNotificationReply{
+		Acks: []PushNotificationAck{},
+	}
+}
+
 // vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
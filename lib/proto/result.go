@@ -54,50 +54,53 @@ type Result struct {
 	DeploymentsList *[]string `json:"deploymentsList,omitempty"`
 
 	// Request dependent data
-	Actions          *[]Action          `json:"actions,omitempty"`
-	Admins           *[]Admin           `json:"admins,omitempty"`
-	Attributes       *[]Attribute       `json:"attributes,omitempty"`
-	Buckets          *[]Bucket          `json:"buckets,omitempty"`
-	Capabilities     *[]Capability      `json:"capability,omitempty"`
-	Categories       *[]Category        `json:"categories,omitempty"`
-	CheckConfigs     *[]CheckConfig     `json:"checkConfigs,omitempty"`
-	Clusters         *[]Cluster         `json:"clusters,omitempty"`
-	DatacenterGroups *[]DatacenterGroup `json:"datacenterGroups,omitempty"`
-	Datacenters      *[]Datacenter      `json:"datacenter,omitempty"`
-	Deployments      *[]Deployment      `json:"deployments,omitempty"`
-	Entities         *[]Entity          `json:"entities,omitempty"`
-	Environments     *[]Environment     `json:"environment,omitempty"`
-	Grants           *[]Grant           `json:"grants,omitempty"`
-	Groups           *[]Group           `json:"groups,omitempty"`
-	HostDeployments  *[]HostDeployment  `json:"hostDeployments,omitempty"`
-	Instances        *[]Instance        `json:"instances,omitempty"`
-	JobResults       *[]JobResult       `json:"jobResults,omitempty"`
-	JobStatus        *[]JobStatus       `json:"jobStatus,omitempty"`
-	JobTypes         *[]JobType         `json:"jobTypes,omitempty"`
-	Jobs             *[]Job             `json:"jobs,omitempty"`
-	Levels           *[]Level           `json:"levels,omitempty"`
-	Metrics          *[]Metric          `json:"metrics,omitempty"`
-	Modes            *[]Mode            `json:"modes,omitempty"`
-	Monitorings      *[]Monitoring      `json:"monitorings,omitempty"`
-	Nodes            *[]Node            `json:"nodes,omitempty"`
-	Oncalls          *[]Oncall          `json:"oncall,omitempty"`
-	Permissions      *[]Permission      `json:"permissions,omitempty"`
-	Predicates       *[]Predicate       `json:"predicates,omitempty"`
-	Properties       *[]Property        `json:"properties,omitempty"`
-	Providers        *[]Provider        `json:"providers,omitempty"`
-	Repositories     *[]Repository      `json:"repositories,omitempty"`
-	Sections         *[]Section         `json:"sections,omitempty"`
-	Servers          *[]Server          `json:"servers,omitempty"`
-	States           *[]State           `json:"states,omitempty"`
-	Status           *[]Status          `json:"status,omitempty"`
-	Systems          *[]System          `json:"system,omitempty"`
-	Teams            *[]Team            `json:"teams,omitempty"`
-	Tree             *Tree              `json:"tree,omitempty"`
-	Units            *[]Unit            `json:"units,omitempty"`
-	Users            *[]User            `json:"users,omitempty"`
-	Validities       *[]Validity        `json:"validities,omitempty"`
-	Views            *[]View            `json:"views,omitempty"`
-	Workflows        *[]Workflow        `json:"workflows,omitempty"`
+	Actions            *[]Action            `json:"actions,omitempty"`
+	Admins             *[]Admin             `json:"admins,omitempty"`
+	Attributes         *[]Attribute         `json:"attributes,omitempty"`
+	Buckets            *[]Bucket            `json:"buckets,omitempty"`
+	Capabilities       *[]Capability        `json:"capability,omitempty"`
+	Categories         *[]Category          `json:"categories,omitempty"`
+	CheckConfigs       *[]CheckConfig       `json:"checkConfigs,omitempty"`
+	Clusters           *[]Cluster           `json:"clusters,omitempty"`
+	DatacenterGroups   *[]DatacenterGroup   `json:"datacenterGroups,omitempty"`
+	Datacenters        *[]Datacenter        `json:"datacenter,omitempty"`
+	Deployments        *[]Deployment        `json:"deployments,omitempty"`
+	Entities           *[]Entity            `json:"entities,omitempty"`
+	Environments       *[]Environment       `json:"environment,omitempty"`
+	Grants             *[]Grant             `json:"grants,omitempty"`
+	Groups             *[]Group             `json:"groups,omitempty"`
+	HostDeployments    *[]HostDeployment    `json:"hostDeployments,omitempty"`
+	Instances          *[]Instance          `json:"instances,omitempty"`
+	JobResults         *[]JobResult         `json:"jobResults,omitempty"`
+	JobStatus          *[]JobStatus         `json:"jobStatus,omitempty"`
+	JobTypes           *[]JobType           `json:"jobTypes,omitempty"`
+	Jobs               *[]Job               `json:"jobs,omitempty"`
+	Levels             *[]Level             `json:"levels,omitempty"`
+	MaintenanceWindows *[]MaintenanceWindow `json:"maintenanceWindows,omitempty"`
+	Metrics            *[]Metric            `json:"metrics,omitempty"`
+	Modes              *[]Mode              `json:"modes,omitempty"`
+	Monitorings        *[]Monitoring        `json:"monitorings,omitempty"`
+	Nodes              *[]Node              `json:"nodes,omitempty"`
+	Notifications      *[]Notification      `json:"notifications,omitempty"`
+	Oncalls            *[]Oncall            `json:"oncall,omitempty"`
+	Permissions        *[]Permission        `json:"permissions,omitempty"`
+	Predicates         *[]Predicate         `json:"predicates,omitempty"`
+	Properties         *[]Property          `json:"properties,omitempty"`
+	Providers          *[]Provider          `json:"providers,omitempty"`
+	Repositories       *[]Repository        `json:"repositories,omitempty"`
+	Sections           *[]Section           `json:"sections,omitempty"`
+	Servers            *[]Server            `json:"servers,omitempty"`
+	States             *[]State             `json:"states,omitempty"`
+	Status             *[]Status            `json:"status,omitempty"`
+	Systems            *[]System            `json:"system,omitempty"`
+	Teams              *[]Team              `json:"teams,omitempty"`
+	Tree               *Tree                `json:"tree,omitempty"`
+	TreeActions        *[]TreeAction        `json:"treeActions,omitempty"`
+	Units              *[]Unit              `json:"units,omitempty"`
+	Users              *[]User              `json:"users,omitempty"`
+	Validities         *[]Validity          `json:"validities,omitempty"`
+	Views              *[]View              `json:"views,omitempty"`
+	Workflows          *[]Workflow          `json:"workflows,omitempty"`
 }
 
 func (r *Result) Error(err error) bool {
@@ -218,6 +221,7 @@ func (r *Result) DataClean() {
 	r.JobTypes = nil
 	r.Jobs = nil
 	r.Levels = nil
+	r.MaintenanceWindows = nil
 	r.Metrics = nil
 	r.Modes = nil
 	r.Monitorings = nil
@@ -235,6 +239,7 @@ func (r *Result) DataClean() {
 	r.Systems = nil
 	r.Teams = nil
 	r.Tree = nil
+	r.TreeActions = nil
 	r.Units = nil
 	r.Users = nil
 	r.Validities = nil
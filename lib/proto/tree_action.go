@@ -0,0 +1,32 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package proto
+
+// TreeAction is an action computed by a repository tree while
+// processing a request. It is returned for requests that were
+// processed as dry run and mirrors the internal tree action.
+type TreeAction struct {
+	Action        string        `json:"action,omitempty"`
+	Type          string        `json:"type,omitempty"`
+	Bucket        Bucket        `json:"bucket,omitempty"`
+	Check         Check         `json:"check,omitempty"`
+	CheckInstance CheckInstance `json:"check_instance,omitempty"`
+	ChildCluster  Cluster       `json:"child_cluster,omitempty"`
+	ChildGroup    Group         `json:"child_group,omitempty"`
+	ChildNode     Node          `json:"child_node,omitempty"`
+	ChildType     string        `json:"child_type,omitempty"`
+	Cluster       Cluster       `json:"cluster,omitempty"`
+	Group         Group         `json:"group,omitempty"`
+	Node          Node          `json:"node,omitempty"`
+	Property      Property      `json:"property,omitempty"`
+	Repository    Repository    `json:"repository,omitempty"`
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -0,0 +1,110 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package threshold
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/mjolnir42/soma/lib/proto"
+)
+
+// Property types a threshold value template can reference
+const (
+	TemplateSystem    = proto.PropertyTypeSystem
+	TemplateCustom    = proto.PropertyTypeCustom
+	TemplateAttribute = `attribute`
+)
+
+// Template is a threshold value that is computed from a property
+// of the object a check instance is created on. Its text form is
+// [<percent>%]@<type>:<property>, for example
+// 80%@attribute:max_connections. The percentage defaults to 100.
+type Template struct {
+	Percent  int64
+	Type     string
+	Property string
+}
+
+// IsTemplate reports if s is the text form of a threshold value
+// template
+func IsTemplate(s string) bool {
+	return strings.Contains(s, `@`)
+}
+
+// ParseTemplate parses the text form of a threshold value template
+func ParseTemplate(s string) (Template, error) {
+	t := Template{Percent: 100}
+
+	i := strings.Index(s, `@`)
+	if i < 0 {
+		return Template{}, fmt.Errorf(
+			"threshold: %q is not a value template", s)
+	}
+	if i > 0 {
+		if !strings.HasSuffix(s[:i], `%`) {
+			return Template{}, fmt.Errorf(
+				"threshold: invalid percentage in template %q", s)
+		}
+		p, err := strconv.ParseInt(strings.TrimSuffix(s[:i], `%`), 10, 64)
+		if err != nil || p <= 0 {
+			return Template{}, fmt.Errorf(
+				"threshold: invalid percentage in template %q", s)
+		}
+		t.Percent = p
+	}
+
+	ref := strings.SplitN(s[i+1:], `:`, 2)
+	if len(ref) != 2 || ref[1] == `` {
+		return Template{}, fmt.Errorf(
+			"threshold: template %q does not reference a property", s)
+	}
+	switch ref[0] {
+	case TemplateSystem, TemplateCustom, TemplateAttribute:
+	default:
+		return Template{}, fmt.Errorf(
+			"threshold: unsupported property type %q in template %q",
+			ref[0], s)
+	}
+	t.Type = ref[0]
+	t.Property = ref[1]
+	return t, nil
+}
+
+// String returns the text form of the template
+func (t Template) String() string {
+	if t.Percent == 100 {
+		return fmt.Sprintf("@%s:%s", t.Type, t.Property)
+	}
+	return fmt.Sprintf("%d%%@%s:%s", t.Percent, t.Type, t.Property)
+}
+
+// Resolve computes the threshold value from the property value. The
+// result is rounded down to the next integer.
+func (t Template) Resolve(value string) (int64, error) {
+	v, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, fmt.Errorf(
+			"threshold: property %s:%s has non-numeric value %q",
+			t.Type, t.Property, value)
+	}
+	res := math.Floor(v * float64(t.Percent) / 100)
+	// math.MaxInt64 is rounded up to 2^63 as float64, which is
+	// already out of range
+	if res >= math.MaxInt64 || res < math.MinInt64 {
+		return 0, fmt.Errorf(
+			"threshold: value of template %s is out of range", t)
+	}
+	return int64(res), nil
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -0,0 +1,89 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package threshold
+
+import (
+	"math"
+	"strconv"
+	"testing"
+)
+
+func TestParseTemplate(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected Template
+		fails    bool
+	}{
+		{`@system:disk_size`, Template{100, TemplateSystem, `disk_size`}, false},
+		{`80%@attribute:max_connections`, Template{80, TemplateAttribute, `max_connections`}, false},
+		{`150%@custom:limit`, Template{150, TemplateCustom, `limit`}, false},
+		{`80@system:disk_size`, Template{}, true},
+		{`0%@system:disk_size`, Template{}, true},
+		{`-5%@system:disk_size`, Template{}, true},
+		{`80%@system`, Template{}, true},
+		{`80%@system:`, Template{}, true},
+		{`80%@oncall:name`, Template{}, true},
+		{`80`, Template{}, true},
+	}
+
+	for _, test := range tests {
+		tmpl, err := ParseTemplate(test.text)
+		switch {
+		case test.fails && err == nil:
+			t.Errorf("%s: invalid template was accepted", test.text)
+		case !test.fails && err != nil:
+			t.Errorf("%s: unexpected error: %s", test.text, err.Error())
+		case tmpl != test.expected:
+			t.Errorf("%s: expected %+v, got %+v", test.text,
+				test.expected, tmpl)
+		case !test.fails && tmpl.String() != test.text:
+			t.Errorf("%s: text form is %s", test.text, tmpl.String())
+		}
+	}
+}
+
+func TestTemplateResolve(t *testing.T) {
+	tests := []struct {
+		percent  int64
+		value    string
+		expected int64
+		fails    bool
+	}{
+		{100, `1000`, 1000, false},
+		{80, `1000`, 800, false},
+		{80, ` 999 `, 799, false},
+		{50, `-3`, -2, false},
+		{80, `12.5`, 10, false},
+		{100, `many`, 0, true},
+		{100, `NaN`, 0, true},
+		{100, `+Inf`, 0, true},
+		{100, strconv.FormatFloat(math.Ldexp(1, 63), 'f', -1, 64), 0, true},
+		{100, strconv.FormatFloat(-math.Ldexp(1, 63), 'f', -1, 64), math.MinInt64, false},
+		{200, strconv.FormatFloat(math.Ldexp(1, 62), 'f', -1, 64), 0, true},
+	}
+
+	for _, test := range tests {
+		tmpl := Template{Percent: test.percent, Type: TemplateSystem, Property: `test`}
+		res, err := tmpl.Resolve(test.value)
+		switch {
+		case test.fails && err == nil:
+			t.Errorf("%d%% of %s: invalid value was accepted as %d",
+				test.percent, test.value, res)
+		case !test.fails && err != nil:
+			t.Errorf("%d%% of %s: unexpected error: %s",
+				test.percent, test.value, err.Error())
+		case res != test.expected:
+			t.Errorf("%d%% of %s: expected %d, got %d",
+				test.percent, test.value, test.expected, res)
+		}
+	}
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
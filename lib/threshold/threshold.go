@@ -0,0 +1,97 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+// Package threshold evaluates metric values against the thresholds of
+// a check configuration. The comparisons are defined for the
+// predicates in lib/proto.
+package threshold
+
+import (
+	"fmt"
+
+	"github.com/mjolnir42/soma/lib/proto"
+)
+
+// Threshold is a single threshold of a check configuration. It is
+// breached if the comparison value <Predicate> Value is true.
+type Threshold struct {
+	Predicate string `json:"predicate"`
+	Level     uint16 `json:"level"`
+	Value     int64  `json:"value"`
+}
+
+// Result is the result of an evaluation. Level is the highest level
+// of all breached thresholds, or 0 if no threshold was breached.
+type Result struct {
+	Level    uint16      `json:"level"`
+	Breached []Threshold `json:"breached"`
+}
+
+// Supported reports if predicate is a predicate that can be
+// evaluated
+func Supported(predicate string) bool {
+	switch predicate {
+	case proto.PredicateLess,
+		proto.PredicateLessEqual,
+		proto.PredicateEqual,
+		proto.PredicateNotEqual,
+		proto.PredicateGreaterEqual,
+		proto.PredicateGreater:
+		return true
+	}
+	return false
+}
+
+// Compare reports if value <predicate> threshold is true
+func Compare(predicate string, value float64, threshold int64) (bool, error) {
+	t := float64(threshold)
+
+	switch predicate {
+	case proto.PredicateLess:
+		return value < t, nil
+	case proto.PredicateLessEqual:
+		return value <= t, nil
+	case proto.PredicateEqual:
+		return value == t, nil
+	case proto.PredicateNotEqual:
+		return value != t, nil
+	case proto.PredicateGreaterEqual:
+		return value >= t, nil
+	case proto.PredicateGreater:
+		return value > t, nil
+	}
+	return false, fmt.Errorf("threshold: unsupported predicate %q",
+		predicate)
+}
+
+// Evaluate compares value against all thresholds. An error is
+// returned if a threshold uses an unsupported predicate, even if
+// other thresholds were breached.
+func Evaluate(value float64, thresholds []Threshold) (Result, error) {
+	res := Result{
+		Breached: []Threshold{},
+	}
+
+	for _, thr := range thresholds {
+		breached, err := Compare(thr.Predicate, value, thr.Value)
+		if err != nil {
+			return Result{}, err
+		}
+		if !breached {
+			continue
+		}
+		res.Breached = append(res.Breached, thr)
+		if thr.Level > res.Level {
+			res.Level = thr.Level
+		}
+	}
+	return res, nil
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -0,0 +1,101 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * Copyright (c) 2019, 1&1 IONOS SE
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package threshold
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mjolnir42/soma/lib/proto"
+)
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		predicate string
+		value     float64
+		threshold int64
+		breached  bool
+	}{
+		{proto.PredicateLess, 79.9, 80, true},
+		{proto.PredicateLess, 80, 80, false},
+		{proto.PredicateLessEqual, 80, 80, true},
+		{proto.PredicateLessEqual, 80.1, 80, false},
+		{proto.PredicateEqual, 80, 80, true},
+		{proto.PredicateEqual, 80.5, 80, false},
+		{proto.PredicateNotEqual, 80.5, 80, true},
+		{proto.PredicateNotEqual, 80, 80, false},
+		{proto.PredicateGreaterEqual, 80, 80, true},
+		{proto.PredicateGreaterEqual, 79.9, 80, false},
+		{proto.PredicateGreater, 80.1, 80, true},
+		{proto.PredicateGreater, 80, 80, false},
+	}
+
+	for _, test := range tests {
+		breached, err := Compare(test.predicate, test.value, test.threshold)
+		if err != nil {
+			t.Errorf("%v %s %d: unexpected error: %s", test.value,
+				test.predicate, test.threshold, err.Error())
+			continue
+		}
+		if breached != test.breached {
+			t.Errorf("%v %s %d: expected %t, got %t", test.value,
+				test.predicate, test.threshold, test.breached, breached)
+		}
+	}
+
+	if _, err := Compare(`=~`, 1, 1); err == nil {
+		t.Error(`Unsupported predicate was accepted`)
+	}
+	if Supported(`=~`) || !Supported(proto.PredicateGreater) {
+		t.Error(`Supported reports wrong predicates`)
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	warning := Threshold{Predicate: proto.PredicateGreaterEqual, Level: 1, Value: 80}
+	critical := Threshold{Predicate: proto.PredicateGreaterEqual, Level: 2, Value: 95}
+	thresholds := []Threshold{critical, warning}
+
+	tests := []struct {
+		name     string
+		value    float64
+		level    uint16
+		breached []Threshold
+	}{
+		{`below all levels`, 50, 0, []Threshold{}},
+		{`warning level`, 80, 1, []Threshold{warning}},
+		{`highest level wins`, 99, 2, []Threshold{critical, warning}},
+	}
+
+	for _, test := range tests {
+		res, err := Evaluate(test.value, thresholds)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test.name, err.Error())
+			continue
+		}
+		if res.Level != test.level {
+			t.Errorf("%s: expected level %d, got %d", test.name,
+				test.level, res.Level)
+		}
+		if !reflect.DeepEqual(res.Breached, test.breached) {
+			t.Errorf("%s: expected breached %v, got %v", test.name,
+				test.breached, res.Breached)
+		}
+	}
+
+	if _, err := Evaluate(99, []Threshold{
+		critical,
+		{Predicate: `=~`, Level: 1, Value: 1},
+	}); err == nil {
+		t.Error(`Unsupported predicate was accepted`)
+	}
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix